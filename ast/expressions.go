@@ -365,6 +365,8 @@ type DefaultExpr struct {
 	exprNode
 	// Name is the column name.
 	Name *ColumnName
+	// Refer is the result field the column name refers to, it's nil if Name is nil.
+	Refer *ResultField
 }
 
 // Accept implements Node Accept interface.
@@ -508,7 +510,7 @@ type PatternLikeExpr struct {
 	Pattern ExprNode
 	// Not is true, the expression is "not like".
 	Not bool
-
+	// Escape is the escape character, 0 means the default escape character of the sql_mode.
 	Escape byte
 
 	PatChars []byte
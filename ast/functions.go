@@ -17,6 +17,7 @@ import (
 	"bytes"
 
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/distinct"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -56,6 +57,8 @@ const (
 	UnaryPlus  = "unaryplus"
 	UnaryMinus = "unaryminus"
 	In         = "in"
+	Between    = "between"
+	NotBetween = "notbetween"
 	Like       = "like"
 	Case       = "case"
 	Regexp     = "regexp"
@@ -66,18 +69,25 @@ const (
 	SetVar     = "setvar"
 	GetVar     = "getvar"
 	Values     = "values"
+	Default    = "default"
+	Collate    = "collate"
 
 	// common functions
 	Coalesce = "coalesce"
 	Greatest = "greatest"
 	Least    = "least"
+	Interval = "interval"
 
 	// math functions
 	Abs     = "abs"
 	Ceil    = "ceil"
 	Ceiling = "ceiling"
 	Conv    = "conv"
+	Cos     = "cos"
+	Cot     = "cot"
 	CRC32   = "crc32"
+	Exp     = "exp"
+	Floor   = "floor"
 	Ln      = "ln"
 	Log     = "log"
 	Log2    = "log2"
@@ -86,8 +96,13 @@ const (
 	Power   = "power"
 	Rand    = "rand"
 	Round   = "round"
+	Sin     = "sin"
+	Sqrt    = "sqrt"
+	Tan     = "tan"
 
 	// time functions
+	AddDate          = "adddate"
+	AddTime          = "addtime"
 	Curdate          = "curdate"
 	CurrentDate      = "current_date"
 	CurrentTime      = "current_time"
@@ -109,10 +124,13 @@ const (
 	MonthName        = "monthname"
 	Now              = "now"
 	Second           = "second"
+	SecToTime        = "sec_to_time"
 	StrToDate        = "str_to_date"
+	SubDate          = "subdate"
 	Sysdate          = "sysdate"
 	Time             = "time"
 	TimeDiff         = "timediff"
+	TimestampAdd     = "timestampadd"
 	UTCDate          = "utc_date"
 	Week             = "week"
 	Weekday          = "weekday"
@@ -145,14 +163,27 @@ const (
 	Ucase          = "ucase"
 	Hex            = "hex"
 	Unhex          = "unhex"
+	Lpad           = "lpad"
 	Rpad           = "rpad"
 	BitLength      = "bit_length"
 	CharFunc       = "char_func"
 	CharLength     = "char_length"
+	WeightString   = "weight_string"
+	ToBase64       = "to_base64"
+	FromBase64     = "from_base64"
+	MakeSet        = "make_set"
+	ExportSet      = "export_set"
+	Bin            = "bin"
+	Oct            = "oct"
+	Ord            = "ord"
 
 	// information functions
+	Charset      = "charset"
+	Coercibility = "coercibility"
+	Collation    = "collation"
 	ConnectionID = "connection_id"
 	CurrentUser  = "current_user"
+	CurrentRole  = "current_role"
 	Database     = "database"
 	Schema       = "schema"
 	FoundRows    = "found_rows"
@@ -165,13 +196,48 @@ const (
 	Ifnull = "ifnull"
 	Nullif = "nullif"
 
-	// miscellaneous functions
-	Sleep = "sleep"
+	// encryption and compression functions
+	Encode                   = "encode"
+	Decode                   = "decode"
+	Password                 = "password"
+	OldPassword              = "old_password"
+	ValidatePasswordStrength = "validate_password_strength"
 
-	// get_lock() and release_lock() is parsed but do nothing.
-	// It is used for preventing error in Ruby's activerecord migrations.
+	// miscellaneous functions
+	Sleep     = "sleep"
+	UUID      = "uuid"
+	UUIDToBin = "uuid_to_bin"
+	BinToUUID = "bin_to_uuid"
+	Benchmark = "benchmark"
+	AnyValue  = "any_value"
+	NameConst = "name_const"
+	Grouping  = "grouping"
+
+	// locking functions
 	GetLock     = "get_lock"
 	ReleaseLock = "release_lock"
+	IsFreeLock  = "is_free_lock"
+	IsUsedLock  = "is_used_lock"
+
+	ReleaseAllLocks = "release_all_locks"
+
+	// performance schema functions
+	FormatBytes    = "format_bytes"
+	FormatPicoTime = "format_pico_time"
+
+	// GTID functions
+	GTIDSubset   = "gtid_subset"
+	GTIDSubtract = "gtid_subtract"
+
+	// json functions
+	JSONPretty      = "json_pretty"
+	JSONStorageSize = "json_storage_size"
+	JSONStorageFree = "json_storage_free"
+	JSONQuote       = "json_quote"
+	JSONSearch      = "json_search"
+	JSONRemove      = "json_remove"
+	JSONArrayAppend = "json_array_append"
+	JSONArrayInsert = "json_array_insert"
 )
 
 // FuncCallExpr is for function expression.
@@ -286,6 +352,20 @@ const (
 	AggFuncMin = "min"
 	// AggFuncGroupConcat is the name of group_concat function.
 	AggFuncGroupConcat = "group_concat"
+	// AggFuncBitAnd is the name of bit_and function.
+	AggFuncBitAnd = "bit_and"
+	// AggFuncBitOr is the name of bit_or function.
+	AggFuncBitOr = "bit_or"
+	// AggFuncBitXor is the name of bit_xor function.
+	AggFuncBitXor = "bit_xor"
+	// AggFuncStddevPop is the name of stddev_pop function, std and stddev are its synonyms.
+	AggFuncStddevPop = "stddev_pop"
+	// AggFuncStddevSamp is the name of stddev_samp function.
+	AggFuncStddevSamp = "stddev_samp"
+	// AggFuncVarPop is the name of var_pop function, variance is its synonym.
+	AggFuncVarPop = "var_pop"
+	// AggFuncVarSamp is the name of var_samp function.
+	AggFuncVarSamp = "var_samp"
 )
 
 // AggregateFuncExpr represents aggregate function expression.
@@ -299,6 +379,10 @@ type AggregateFuncExpr struct {
 	// For example, column c1 values are "1", "2", "2",  "sum(c1)" is "5",
 	// but "sum(distinct c1)" is "3".
 	Distinct bool
+	// Order is the ORDER BY clause of group_concat, it's nil if not specified.
+	Order *OrderByClause
+	// Separator is the string used to join the values of group_concat.
+	Separator string
 
 	CurrentGroup []byte
 	// contextPerGroupMap is used to store aggregate evaluation context.
@@ -320,6 +404,16 @@ func (n *AggregateFuncExpr) Accept(v Visitor) (Node, bool) {
 		}
 		n.Args[i] = node.(ExprNode)
 	}
+	if n.Order != nil {
+		// Only visit the expressions of the items, they shouldn't be resolved as the ORDER BY clause of a select statement.
+		for _, item := range n.Order.Items {
+			node, ok := item.Expr.Accept(v)
+			if !ok {
+				return n, false
+			}
+			item.Expr = node.(ExprNode)
+		}
+	}
 	return v.Leave(n)
 }
 
@@ -378,6 +472,15 @@ type AggEvaluateContext struct {
 	DistinctChecker *distinct.Checker
 	Count           int64
 	Value           types.Datum
-	Buffer          *bytes.Buffer // Buffer is used for group_concat.
-	GotFirstRow     bool          // It will check if the agg has met the first row key.
+	Buffer          *bytes.Buffer   // Buffer is used for group_concat.
+	SortedRows      [][]types.Datum // SortedRows is used for group_concat with ORDER BY, the rows are sorted before being concatenated.
+	Truncated       bool            // Truncated is used for group_concat, it's true if the result is cut by group_concat_max_len.
+	Mean            float64         // Mean is used for the standard deviation and variance functions.
+	SquareSum       float64         // SquareSum is the sum of squares of differences from the Mean.
+	GotFirstRow     bool            // It will check if the agg has met the first row key.
+
+	// MaxLen and StmtCtx are used for group_concat, they are the group_concat_max_len and the statement context
+	// of the evaluation, which are needed to build the result after all the rows are processed.
+	MaxLen  uint64
+	StmtCtx *variable.StatementContext
 }
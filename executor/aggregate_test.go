@@ -273,6 +273,126 @@ func (s *testSuite) TestAggregation(c *C) {
 	result.Check(testkit.Rows("529"))
 }
 
+func (s *testSuite) TestGroupConcat(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(10), c int)")
+	tk.MustExec("insert into t values (1, 'x', 3), (1, 'y', 1), (1, 'x', 2), (1, null, 4), (2, 'z', 1)")
+	result := tk.MustQuery("select group_concat(b) from t group by a order by a")
+	result.Check(testkit.Rows("x,y,x", "z"))
+	result = tk.MustQuery("select group_concat(distinct b) from t group by a order by a")
+	result.Check(testkit.Rows("x,y", "z"))
+	result = tk.MustQuery("select group_concat(b, c) from t where a = 1")
+	result.Check(testkit.Rows("x3,y1,x2"))
+	result = tk.MustQuery("select group_concat(b order by c) from t where a = 1")
+	result.Check(testkit.Rows("y,x,x"))
+	result = tk.MustQuery("select group_concat(b order by b desc, c) from t where a = 1")
+	result.Check(testkit.Rows("y,x,x"))
+	result = tk.MustQuery("select group_concat(c order by b desc, c) from t where a = 1")
+	result.Check(testkit.Rows("1,2,3,4"))
+	result = tk.MustQuery("select group_concat(distinct b order by b desc separator '|') from t")
+	result.Check(testkit.Rows("z|y|x"))
+	result = tk.MustQuery("select group_concat(c separator '') from t group by a order by a")
+	result.Check(testkit.Rows("3124", "1"))
+	result = tk.MustQuery("select group_concat(b separator ';'), group_concat(b) from t where a = 1")
+	result.Check(testkit.Rows("x;y;x x,y,x"))
+	result = tk.MustQuery("select group_concat(b) from t where a = 3")
+	result.Check(testkit.Rows("<nil>"))
+
+	tk.MustExec("set @@group_concat_max_len = 4")
+	result = tk.MustQuery("select group_concat(b) from t group by a order by a")
+	result.Check(testkit.Rows("x,y,", "z"))
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.GetWarnings(), HasLen, 1)
+	result = tk.MustQuery("select group_concat(c order by c desc separator '--') from t where a = 1")
+	result.Check(testkit.Rows("4--3"))
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.GetWarnings(), HasLen, 1)
+	result = tk.MustQuery("select group_concat(c order by c) from t where a = 1")
+	result.Check(testkit.Rows("1,2,"))
+	result = tk.MustQuery("select group_concat(c) from t where a = 2")
+	result.Check(testkit.Rows("1"))
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.GetWarnings(), HasLen, 0)
+
+	// The result is cut on a character boundary.
+	tk.MustExec("insert into t values (3, 'ééé', 1), (3, 'é', 2)")
+	tk.MustExec("set @@group_concat_max_len = 5")
+	result = tk.MustQuery("select group_concat(b), group_concat(b order by c desc) from t where a = 3")
+	result.Check(testkit.Rows("éé é,é"))
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.GetWarnings(), HasLen, 2)
+	result = tk.MustQuery("select hex(group_concat(cast(b as binary))) from t where a = 3")
+	result.Check(testkit.Rows("C3A9C3A9C3"))
+}
+
+func (s *testSuite) TestGroupConcatArgs(c *C) {
+	defer testleak.AfterTest(c)()
+	col := &expression.Column{Index: 0}
+	orderCol := &expression.Column{Index: 1}
+	args := make([]expression.Expression, 1, 2)
+	args[0] = col
+	concatAgg := expression.NewGroupConcatFunction(args, false, []expression.Expression{orderCol}, []bool{false}, ",")
+	// The ORDER BY items are never appended to the array of the caller.
+	args = append(args, col)
+	c.Assert(concatAgg.GetArgs(), HasLen, 2)
+	c.Assert(concatAgg.GetArgs()[1], Equals, orderCol)
+}
+
+func (s *testSuite) TestBitAggregation(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b bigint, c bigint unsigned)")
+	tk.MustExec("insert into t values (1, 7, 18446744073709551615), (1, 13, 1), (1, null, null), (2, -1, 6), (3, null, null)")
+	result := tk.MustQuery("select bit_and(b), bit_or(b), bit_xor(b) from t group by a order by a")
+	result.Check(testkit.Rows("5 15 10", "18446744073709551615 18446744073709551615 18446744073709551615",
+		"18446744073709551615 0 0"))
+	result = tk.MustQuery("select bit_and(c), bit_or(c), bit_xor(c) from t where a = 1")
+	result.Check(testkit.Rows("1 18446744073709551615 18446744073709551614"))
+	result = tk.MustQuery("select bit_and(b), bit_or(b), bit_xor(b) from t where a > 3")
+	result.Check(testkit.Rows("18446744073709551615 0 0"))
+	result = tk.MustQuery("select bit_and(b), bit_or(b), bit_xor(b) from t where a > 3 group by a")
+	result.Check(testkit.Rows())
+	result = tk.MustQuery("select bit_xor(distinct a), bit_xor(a) from t where c is not null")
+	result.Check(testkit.Rows("3 2"))
+}
+
+func (s *testSuite) TestVarianceAggregation(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b double)")
+	tk.MustExec("insert into t values (1, 2), (1, 4), (1, 4), (1, 4), (1, 5), (1, 5), (1, 7), (1, 9), (1, null), (2, 3), (3, null)")
+	result := tk.MustQuery("select std(b), stddev(b), stddev_pop(b), stddev_samp(b) from t where a = 1")
+	result.Check(testkit.Rows("2 2 2 2.138089935299395"))
+	result = tk.MustQuery("select variance(b), var_pop(b), var_samp(b), var_pop(distinct b) from t where a = 1")
+	result.Check(testkit.Rows("4 4 4.571428571428571 5.84"))
+	result = tk.MustQuery("select var_pop(b), var_samp(b), stddev_samp(b) from t group by a order by a")
+	result.Check(testkit.Rows("4 4.571428571428571 2.138089935299395", "0 <nil> <nil>", "<nil> <nil> <nil>"))
+	result = tk.MustQuery("select var_pop(b), stddev_pop(b) from t where a > 3")
+	result.Check(testkit.Rows("<nil> <nil>"))
+
+	// The naive algorithm which computes the sum of squares suffers from catastrophic cancellation here.
+	tk.MustExec("truncate table t")
+	tk.MustExec("insert into t values (1, 1e9 + 4), (1, 1e9 + 7), (1, 1e9 + 13), (1, 1e9 + 16)")
+	result = tk.MustQuery("select var_pop(b), var_samp(b) from t")
+	result.Check(testkit.Rows("22.5 30"))
+	tk.MustExec("truncate table t")
+	tk.MustExec("insert into t values (1, 1e15 + 1), (1, 1e15 + 2), (1, 1e15 + 3)")
+	result = tk.MustQuery("select var_samp(b), stddev_samp(b) from t")
+	result.Check(testkit.Rows("1 1"))
+}
+
 func (s *testSuite) TestStreamAgg(c *C) {
 	col := &expression.Column{
 		Index: 1,
@@ -284,6 +404,9 @@ func (s *testSuite) TestStreamAgg(c *C) {
 	cntAgg := expression.NewAggFunction(ast.AggFuncCount, []expression.Expression{col}, false)
 	avgAgg := expression.NewAggFunction(ast.AggFuncAvg, []expression.Expression{col}, false)
 	maxAgg := expression.NewAggFunction(ast.AggFuncMax, []expression.Expression{col}, false)
+	bitOrAgg := expression.NewAggFunction(ast.AggFuncBitOr, []expression.Expression{col}, false)
+	varSampAgg := expression.NewAggFunction(ast.AggFuncVarSamp, []expression.Expression{col}, false)
+	concatAgg := expression.NewGroupConcatFunction([]expression.Expression{col}, false, []expression.Expression{col}, []bool{true}, "-")
 	cases := []struct {
 		aggFunc expression.AggregationFunction
 		result  string
@@ -330,6 +453,36 @@ func (s *testSuite) TestStreamAgg(c *C) {
 				"1", "3",
 			},
 		},
+		{
+			bitOrAgg,
+			"0",
+			[][]interface{}{
+				{0, 1}, {0, nil}, {1, 2}, {1, 5},
+			},
+			[]string{
+				"1", "7",
+			},
+		},
+		{
+			varSampAgg,
+			"<nil>",
+			[][]interface{}{
+				{0, 1}, {0, nil}, {1, 2}, {1, 4},
+			},
+			[]string{
+				"<nil>", "2",
+			},
+		},
+		{
+			concatAgg,
+			"<nil>",
+			[][]interface{}{
+				{0, 1}, {0, nil}, {1, 2}, {1, 3},
+			},
+			[]string{
+				"1", "3-2",
+			},
+		},
 	}
 	ctx := mock.NewContext()
 	for _, ca := range cases {
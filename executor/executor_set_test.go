@@ -123,6 +123,30 @@ func (s *testSuite) TestSetVar(c *C) {
 	c.Assert(vars.SkipConstraintCheck, IsTrue)
 	tk.MustExec("set @@tidb_skip_constraint_check = '0'")
 	c.Assert(vars.SkipConstraintCheck, IsFalse)
+
+	c.Assert(vars.GreatestLeastIgnoreNull, IsFalse)
+	tk.MustQuery("select greatest(1, null, 3), least(1, null, 3)").Check(testkit.Rows("<nil> <nil>"))
+	tk.MustExec("set @@tidb_greatest_least_ignore_null = '1'")
+	c.Assert(vars.GreatestLeastIgnoreNull, IsTrue)
+	tk.MustQuery("select greatest(1, null, 3), least(1, null, 3), greatest(null, null)").Check(testkit.Rows("3 1 <nil>"))
+	tk.MustExec("set @@tidb_greatest_least_ignore_null = '0'")
+	c.Assert(vars.GreatestLeastIgnoreNull, IsFalse)
+
+	c.Assert(vars.RoundTrigResult, IsFalse)
+	tk.MustQuery("select sin(0.5235987755982988), cos(1.0471975511965976)").Check(testkit.Rows("0.49999999999999994 0.5000000000000001"))
+	tk.MustExec("set @@tidb_round_trig_result = '1'")
+	c.Assert(vars.RoundTrigResult, IsTrue)
+	tk.MustQuery("select sin(0.5235987755982988), cos(1.0471975511965976)").Check(testkit.Rows("0.5 0.5"))
+	tk.MustExec("set @@tidb_round_trig_result = '0'")
+	c.Assert(vars.RoundTrigResult, IsFalse)
+
+	c.Assert(vars.RoundHalfEven, IsFalse)
+	tk.MustQuery("select round(2.5), round(3.5), round(-2.5)").Check(testkit.Rows("3 4 -3"))
+	tk.MustExec("set @@tidb_round_half_even = '1'")
+	c.Assert(vars.RoundHalfEven, IsTrue)
+	tk.MustQuery("select round(2.5), round(3.5), round(-2.5)").Check(testkit.Rows("2 4 -2"))
+	tk.MustExec("set @@tidb_round_half_even = '0'")
+	c.Assert(vars.RoundHalfEven, IsFalse)
 }
 
 func (s *testSuite) TestSetCharset(c *C) {
@@ -29,6 +29,7 @@ import (
 	"github.com/pingcap/tidb/inspectkv"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
@@ -1048,6 +1049,12 @@ func (s *testSuite) TestBuiltin(c *C) {
 	result.Check(testkit.Rows("11:11:11"))
 	result = tk.MustQuery("select * from t where a > cast(2 as decimal)")
 	result.Check(testkit.Rows("3 2"))
+	result = tk.MustQuery(`select cast('{"b": 1, "a": [true, null]}' as json), cast(1.5 as json), cast(cast('2017-01-02' as date) as json), cast(null as json)`)
+	result.Check(testkit.Rows(`{"a": [true, null], "b": 1} 1.5 "2017-01-02" <nil>`))
+	rs, err := tk.Exec(`select cast('[1' as json)`)
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err.Error(), Equals, `[expression:3141]Invalid JSON text in argument 1 to function cast_as_json: "unexpected EOF" at position 0.`)
 
 	// test unhex and hex
 	result = tk.MustQuery("select unhex('4D7953514C')")
@@ -1085,6 +1092,608 @@ func (s *testSuite) TestBuiltin(c *C) {
 	result = tk.MustQuery("select strcmp('abc', 'abc')")
 	result.Check(testkit.Rows("0"))
 
+	// test uuid_to_bin and bin_to_uuid
+	result = tk.MustQuery("select hex(uuid_to_bin('6ccd780c-baba-1026-9564-5b8c656024db', 1))")
+	result.Check(testkit.Rows("1026BABA6CCD780C95645B8C656024DB"))
+	result = tk.MustQuery("select bin_to_uuid(uuid_to_bin('6ccd780c-baba-1026-9564-5b8c656024db', 1), 1)")
+	result.Check(testkit.Rows("6ccd780c-baba-1026-9564-5b8c656024db"))
+	result = tk.MustQuery("select length(uuid())")
+	result.Check(testkit.Rows("36"))
+
+	// test date and time field extractors
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a date, b time(6))")
+	tk.MustExec("insert t values ('2017-03-04', '05:06:07.123456')")
+	result = tk.MustQuery("select year(a), month(a), day(a), hour(a), microsecond(a), year(b), dayofmonth(b), hour(b), minute(b), second(b), microsecond(b) from t")
+	result.Check(testkit.Rows("2017 3 4 0 0 0 0 5 6 7 123456"))
+	result = tk.MustQuery("select month(null), hour('2011-11-11 10:10:10.11.12')")
+	result.Check(testkit.Rows("<nil> <nil>"))
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.GetWarnings(), HasLen, 1)
+
+	// test time
+	result = tk.MustQuery("select time('2017-03-04 05:06:07.123'), time('100:00:00'), time(b), time('839:00:00') from t")
+	result.Check(testkit.Rows("05:06:07.123 100:00:00 05:06:07.123456 <nil>"))
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.GetWarnings(), HasLen, 1)
+
+	// test date
+	result = tk.MustQuery("select date('2017-03-04 05:06:07.123'), date(a), date('2011-13-45') from t")
+	result.Check(testkit.Rows("2017-03-04 2017-03-04 <nil>"))
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.GetWarnings(), HasLen, 1)
+
+	// test format_bytes and format_pico_time
+	result = tk.MustQuery("select format_bytes(1536), format_bytes(1024 * 1024), format_pico_time(1230000000), format_pico_time(null)")
+	result.Check(testkit.Rows("1.50 KiB 1.00 MiB 1.23 ms <nil>"))
+
+	// test current_role
+	tk.MustQuery("select current_role()").Check(testkit.Rows("NONE"))
+
+	// test json_pretty
+	tk.MustQuery(`select json_pretty('{"b": [1, 2], "a": null}'), json_pretty(null)`).Check(testkit.Rows("{\n  \"a\": null,\n  \"b\": [\n    1,\n    2\n  ]\n} <nil>"))
+	rs, err = tk.Exec(`select json_pretty('{"a": 1')`)
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err.Error(), Equals, `[expression:3141]Invalid JSON text in argument 1 to function json_pretty: "unexpected EOF" at position 0.`)
+
+	// test json_storage_size and json_storage_free
+	result = tk.MustQuery(`select json_storage_size('[100, "sakila", [1, 3, 5], 425.05]'), json_storage_free('{"a": 1}'), json_storage_size(null)`)
+	result.Check(testkit.Rows("45 0 <nil>"))
+
+	// test json_quote
+	result = tk.MustQuery(`select json_quote('a"b\\c'), json_quote(null)`)
+	result.Check(testkit.Rows(`"a\"b\\c" <nil>`))
+
+	// test json_search
+	result = tk.MustQuery(`select json_search('["abc", [{"k": "10"}, "def"], {"x": "abc"}]', 'all', 'abc'), json_search('["abc"]', 'one', 'x%'), json_search('[]', 'one', null)`)
+	result.Check(testkit.Rows(`["$[0]", "$[2].x"] <nil> <nil>`))
+	rs, err = tk.Exec(`select json_search('[]', 'two', 'a')`)
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err.Error(), Equals, "[expression:3154]The oneOrAll argument to json_search may take these values: 'one' or 'all'.")
+
+	// test json_remove
+	result = tk.MustQuery(`select json_remove('[1, {"a": 2, "b": 3}, 4]', '$[0]', '$[0].a'), json_remove('[1]', '$.a'), json_remove('[1]', null)`)
+	result.Check(testkit.Rows(`[{"b": 3}, 4] [1] <nil>`))
+	rs, err = tk.Exec(`select json_remove('[1]', '$')`)
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err.Error(), Equals, "[expression:3153]The path expression '$' is not allowed in this context.")
+
+	// test json_array_append and json_array_insert
+	result = tk.MustQuery(`select json_array_append('["a", ["b"]]', '$[1]', 1, '$[0]', 'c'), json_array_insert('["a", "b"]', '$[1]', 1.5, '$[9]', null), json_array_append('[]', null, 1)`)
+	result.Check(testkit.Rows(`[["a", "c"], ["b", 1]] ["a", 1.5, "b", null] <nil>`))
+	rs, err = tk.Exec(`select json_array_insert('["a"]', '$', 1)`)
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err.Error(), Equals, "[expression:3165]A path expression is not a path to a cell in an array.")
+
+	// test gtid_subset and gtid_subtract
+	result = tk.MustQuery("select gtid_subset('3e11fa47-71ca-11e1-9e33-c80aa9429562:23-25', '3e11fa47-71ca-11e1-9e33-c80aa9429562:21-57'), " +
+		"gtid_subtract('3e11fa47-71ca-11e1-9e33-c80aa9429562:21-57', '3e11fa47-71ca-11e1-9e33-c80aa9429562:23-25'), gtid_subset(null, '')")
+	result.Check(testkit.Rows("1 3e11fa47-71ca-11e1-9e33-c80aa9429562:21-22:26-57 <nil>"))
+	rs, err = tk.Exec("select gtid_subset('3e11fa47-71ca-11e1-9e33-c80aa9429562:0', '')")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err, NotNil)
+
+	// test char_length
+	result = tk.MustQuery("select char_length('你好'), char_length(binary '你好'), char_length(cast('你好' as binary)), char_length(null)")
+	result.Check(testkit.Rows("2 6 6 <nil>"))
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a varchar(10), b varbinary(10), c blob)")
+	tk.MustExec("insert t values ('你好', '你好', '你好')")
+	result = tk.MustQuery("select char_length(a), char_length(b), char_length(c) from t")
+	result.Check(testkit.Rows("2 6 6"))
+
+	// test weight_string
+	result = tk.MustQuery("select hex(weight_string('aB')), hex(weight_string('Ab')), hex(weight_string('ab' as char(3))), hex(weight_string('ab' as binary(3)))")
+	result.Check(testkit.Rows("00410042 00410042 004100420020 616200"))
+
+	// test to_base64 and from_base64
+	result = tk.MustQuery("select to_base64('abc'), from_base64(to_base64('abc')), to_base64(''), from_base64('YWJj\nZA=='), from_base64('YW?'), to_base64(null)")
+	result.Check(testkit.Rows("YWJj abc  abcd <nil> <nil>"))
+
+	// test encode and decode
+	result = tk.MustQuery("select decode(encode('TiDB', 'key'), 'key'), encode('TiDB', 'key') = encode('TiDB', 'k e y'), encode('TiDB', null), length(encode('TiDB', ''))")
+	result.Check(testkit.Rows("TiDB 1 <nil> 4"))
+
+	// test password
+	result = tk.MustQuery("select password('abc'), password(''), password(null)")
+	result.Check(testkit.Rows("*0D3CED9BEC10A777AEC23CCC353A8C08A633045E  "))
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a varchar(20))")
+	tk.MustExec("insert into t values ('abc'), ('mypass'), (null)")
+	result = tk.MustQuery("select password(a) from t")
+	result.Check(testkit.Rows("*0D3CED9BEC10A777AEC23CCC353A8C08A633045E", "*6C8989366EAF75BB670AD8EA7A7FC1176A95CEF4", ""))
+	warnings := tk.Se.GetSessionVars().StmtCtx.GetWarnings()
+	c.Assert(warnings, HasLen, 1)
+	c.Assert(warnings[0].Error(), Equals, "[expression:1681]'PASSWORD' is deprecated and will be removed in a future release.")
+
+	// test old_password and validate_password_strength
+	result = tk.MustQuery("select old_password('mypass'), old_password(''), validate_password_strength('abc'), validate_password_strength('Abc123!@'), validate_password_strength(null)")
+	result.Check(testkit.Rows("6f8c114b58f2ce9e  0 100 <nil>"))
+
+	// test trigonometric functions
+	result = tk.MustQuery("select sin(0), cos(0), tan(0), cot(1) > 0.64, tan(null), pow(-8, 1/3)")
+	result.Check(testkit.Rows("0 1 0 1 <nil> <nil>"))
+	rs, err = tk.Exec("select cot(0)")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err, NotNil)
+	rs, err = tk.Exec("select pow(10, 400)")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err, NotNil)
+	result = tk.MustQuery("select exp(0), exp(null)")
+	result.Check(testkit.Rows("1 <nil>"))
+	rs, err = tk.Exec("select exp(1000)")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err, NotNil)
+
+	// test ceil, floor and round on enum and set columns
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (e enum('7.5', 'b', '1'), s set('a', '2.5'))")
+	tk.MustExec("insert into t values ('7.5', 'a,2.5'), ('1', '2.5'), (null, null)")
+	result = tk.MustQuery("select ceil(e), floor(e), round(e), ceil(s), floor(s), round(s) from t")
+	result.Check(testkit.Rows("1 1 1 3 3 3", "3 3 3 2 2 2", "<nil> <nil> <nil> <nil> <nil> <nil>"))
+
+	// test round on datetime and time columns
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a datetime(6), b time(6))")
+	tk.MustExec("insert into t values ('2017-01-02 03:04:05.123456', '10:20:30.987654'), (null, null)")
+	result = tk.MustQuery("select round(a, 3), round(b, 3), round(a), round(b, 1) from t")
+	result.Check(testkit.Rows("2017-01-02 03:04:05.123 10:20:30.988 2017-01-02 03:04:05 10:20:31.0", "<nil> <nil> <nil> <nil>"))
+
+	// test round on big integers
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a bigint unsigned, b bigint)")
+	tk.MustExec("insert into t values (18446744073709551615, -9007199254740995)")
+	result = tk.MustQuery("select round(a), round(a, 2), round(b, -1), round(b, -3) from t")
+	result.Check(testkit.Rows("18446744073709551615 18446744073709551615 -9007199254741000 -9007199254741000"))
+	rs, err = tk.Exec("select round(a, -1) from t")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err, NotNil)
+	result = tk.MustQuery("select round('3.14159', 2), round('12.5'), round('9x', 0)")
+	result.Check(testkit.Rows("3.14 13 9"))
+
+	// test greatest and least on decimal columns
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a decimal(10, 2), b decimal(10, 3), c int)")
+	tk.MustExec("insert into t values (1.10, 1.055, 1)")
+	result = tk.MustQuery("select greatest(a, b), least(a, b), greatest(a, c), least(b, c) from t")
+	result.Check(testkit.Rows("1.100 1.055 1.10 1.000"))
+
+	// test between on non-column expressions
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int, c date)")
+	tk.MustExec("insert into t values (1, 3, '2017-01-02'), (null, 3, null), (null, 1, '2017-02-01')")
+	result = tk.MustQuery("select 2 between a and b, 2 not between a and b, '2017-1-5' between c and '2017-01-31', a + 1 between 2 and b from t")
+	result.Check(testkit.Rows("1 0 1 1", "<nil> <nil> <nil> <nil>", "0 1 0 <nil>"))
+
+	// test substring edge cases
+	result = tk.MustQuery("select substring('Sakila', 7), substring('Sakila', -7), substring('Sakila', 0), substring('Sakila', 5, 1000), substring('Sakila', 2, 9223372036854775807), substring('Sakila', 18446744073709551615)")
+	result.Check(testkit.Rows("   la akila "))
+
+	// test log with bases in (0, 1)
+	result = tk.MustQuery("select log(0.5, 4), log(2, 0.5), log(1, 100), log(0, 100), log(-2, 100)")
+	result.Check(testkit.Rows("-2 -1 <nil> <nil> <nil>"))
+
+	// test truncated string arguments of math functions
+	result = tk.MustQuery("select abs('-12abc'), pow('3x', 2)")
+	result.Check(testkit.Rows("12 9"))
+	warnings = tk.Se.GetSessionVars().StmtCtx.GetWarnings()
+	c.Assert(warnings, HasLen, 2)
+	c.Assert(warnings[0].Error(), Equals, "[expression:1292]Truncated incorrect DOUBLE value: '-12abc'")
+	c.Assert(warnings[1].Error(), Equals, "[expression:1292]Truncated incorrect DOUBLE value: '3x'")
+
+	// test incorrect parameter count
+	_, err = tk.Exec("select round(1, 2, 3)")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "[expression:1582]Incorrect parameter count in the call to native function 'round'")
+	_, err = tk.Exec("select greatest(1)")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "[expression:1582]Incorrect parameter count in the call to native function 'greatest'")
+	_, err = tk.Exec("select least(1)")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "[expression:1582]Incorrect parameter count in the call to native function 'least'")
+	tk.MustQuery("select greatest(1, 2), least(1, 2)").Check(testkit.Rows("2 1"))
+	tk.MustQuery("select greatest(18446744073709551615, -1), least(18446744073709551615, -1), greatest(-9223372036854775808, 9223372036854775808)").
+		Check(testkit.Rows("18446744073709551615 -1 9223372036854775808"))
+
+	// test greatest and least on a date column with strings
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a date, b datetime)")
+	tk.MustExec("insert into t values ('2017-03-01', '2017-03-01 12:00:00')")
+	result = tk.MustQuery("select greatest(a, 'abc'), least(a, 'abc'), greatest(a, '2017-3-2'), least(a, '2017-03-01 00:00:00') from t")
+	result.Check(testkit.Rows("abc 2017-03-01 2017-3-2 2017-03-01"))
+	result = tk.MustQuery("select greatest(a, b), least(a, b), greatest(a, b, 'x'), least(a, null, 'x') from t")
+	result.Check(testkit.Rows("2017-03-01 12:00:00 2017-03-01 x <nil>"))
+
+	// test sqrt
+	result = tk.MustQuery("select sqrt(16), sqrt(2.25), sqrt(-1), sqrt(null), pow(2, 3)")
+	result.Check(testkit.Rows("4 1.5 <nil> <nil> 8"))
+
+	// test pow and exp overflow in strict and non-strict modes
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a double)")
+	_, err = tk.Exec("insert into t values (pow(10, 400))")
+	c.Assert(err, NotNil)
+	tk.MustExec("set sql_mode = ''")
+	tk.MustExec("insert into t values (pow(10, 400)), (exp(1000))")
+	warnings = tk.Se.GetSessionVars().StmtCtx.GetWarnings()
+	c.Assert(warnings, HasLen, 2)
+	c.Assert(warnings[0].Error(), Equals, "[expression:1690]DOUBLE value is out of range in 'pow(10,400)'")
+	c.Assert(warnings[1].Error(), Equals, "[expression:1690]DOUBLE value is out of range in 'exp(1000)'")
+	tk.MustExec("set sql_mode = default")
+	result = tk.MustQuery("select a from t")
+	result.Check(testkit.Rows("<nil>", "<nil>"))
+
+	// test an expression wrapping rand() is evaluated for every row
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int)")
+	for i := 0; i < 20; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t values (%d)", i))
+	}
+	result = tk.MustQuery("select count(distinct r) > 1, min(r) >= 1, max(r) <= 1000000 from (select floor(1 + rand() * (1000000 - 1 + 1)) as r from t) as s")
+	result.Check(testkit.Rows("1 1 1"))
+
+	// test make_set and export_set
+	result = tk.MustQuery("select make_set(1 | 4, 'hello', 'nice', 'world'), make_set(18446744073709551615 - 9223372036854775807, 'a'), export_set(5, 'Y', 'N', ',', 4), export_set(9223372036854775808, '1', '0', '') = concat(repeat('0', 63), '1')")
+	result.Check(testkit.Rows("hello,world  Y,N,Y,N 1"))
+
+	// test conv
+	result = tk.MustQuery("select conv('ffffffffffffffff', 16, 10), conv('-1', 10, 16), conv('-17', -10, -16), conv('1', 10, 1)")
+	result.Check(testkit.Rows("18446744073709551615 FFFFFFFFFFFFFFFF -11 <nil>"))
+
+	// test bin, oct and hex
+	result = tk.MustQuery("select bin(12), oct(12), hex(12), bin(-1) = repeat('1', 64), oct(-1), hex(-1), bin(null)")
+	result.Check(testkit.Rows("1100 14 C 1 1777777777777777777777 FFFFFFFFFFFFFFFF <nil>"))
+
+	// test any_value
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int)")
+	tk.MustExec("insert into t values (1, 2), (1, 2), (3, 4)")
+	result = tk.MustQuery("select a, any_value(b), any_value(null) from t group by a order by a")
+	result.Check(testkit.Rows("1 2 <nil>", "3 4 <nil>"))
+
+	// test name_const
+	rs, err = tk.Exec("select name_const('myname', 14), name_const('s', 'abc'), name_const('neg', -1.5) as n")
+	c.Assert(err, IsNil)
+	fields, err := rs.Fields()
+	c.Assert(err, IsNil)
+	c.Assert(fields, HasLen, 3)
+	c.Assert(fields[0].ColumnAsName.O, Equals, "myname")
+	c.Assert(fields[0].Column.Tp, Equals, mysql.TypeLonglong)
+	c.Assert(fields[1].ColumnAsName.O, Equals, "s")
+	c.Assert(fields[1].Column.Tp, Equals, mysql.TypeVarString)
+	c.Assert(fields[2].ColumnAsName.O, Equals, "n")
+	rows, err := tidb.GetRows(rs)
+	c.Assert(err, IsNil)
+	c.Assert(fmt.Sprint(rows[0][0].GetValue(), " ", rows[0][1].GetValue(), " ", rows[0][2].GetValue()), Equals, "14 abc -1.5")
+	tk.MustQuery("select myname from (select name_const('myname', 14)) t").Check(testkit.Rows("14"))
+	_, err = tk.Exec("select name_const('a', b) from t")
+	c.Assert(err, NotNil)
+
+	// test date_add with microseconds
+	rs, err = tk.Exec("select date_add('2011-11-11 10:10:10', interval 500000 microsecond), date_sub('2011-11-11 10:10:10', interval 1.5 second), date_add('2011-11-11 10:10:10', interval 1 second)")
+	c.Assert(err, IsNil)
+	fields, err = rs.Fields()
+	c.Assert(err, IsNil)
+	c.Assert(fields[0].Column.Decimal, Equals, types.MaxFsp)
+	c.Assert(fields[1].Column.Decimal, Equals, types.MaxFsp)
+	c.Assert(fields[2].Column.Decimal, Not(Equals), types.MaxFsp)
+	rows, err = tidb.GetRows(rs)
+	c.Assert(err, IsNil)
+	c.Assert(fmt.Sprint(rows[0][0].GetValue(), " ", rows[0][1].GetValue(), " ", rows[0][2].GetValue()), Equals, "2011-11-11 10:10:10.500000 2011-11-11 10:10:08.500000 2011-11-11 10:10:11")
+	rs, err = tk.Exec("select timestampadd(microsecond, 500000, '2011-11-11 10:10:10'), timestampadd(second, 1.5, '2011-11-11 10:10:10'), timestampadd(minute, 1, '2011-11-11')")
+	c.Assert(err, IsNil)
+	fields, err = rs.Fields()
+	c.Assert(err, IsNil)
+	c.Assert(fields[0].Column.Decimal, Equals, types.MaxFsp)
+	c.Assert(fields[1].Column.Decimal, Equals, types.MaxFsp)
+	c.Assert(fields[2].Column.Decimal, Not(Equals), types.MaxFsp)
+	rows, err = tidb.GetRows(rs)
+	c.Assert(err, IsNil)
+	c.Assert(fmt.Sprint(rows[0][0].GetValue(), " ", rows[0][1].GetValue(), " ", rows[0][2].GetValue()), Equals, "2011-11-11 10:10:10.500000 2011-11-11 10:10:11.500000 2011-11-11 00:01:00")
+
+	// test concat_ws with null and numeric separators
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(10), c double)")
+	tk.MustExec("insert into t values (1, null, 2.5), (null, 'x', null)")
+	result = tk.MustQuery("select concat_ws(a, b, c, 'y'), concat_ws(',', b, null), concat_ws(b, a, c), concat_ws(null, 'a', 'b') from t")
+	result.Check(testkit.Rows("2.51y  <nil> <nil>", "<nil> x  <nil>"))
+	_, err = tk.Exec("select concat_ws(',')")
+	c.Assert(err, NotNil)
+
+	// test week reading default_week_format, which yearweek ignores
+	result = tk.MustQuery("select week('2008-02-20'), yearweek('1987-01-01'), week('2008-02-20', 0), weekofyear('2008-02-20')")
+	result.Check(testkit.Rows("7 198652 7 8"))
+	tk.MustExec("set @@default_week_format = 1")
+	result = tk.MustQuery("select week('2008-02-20'), yearweek('1987-01-01'), yearweek('1987-01-01', 1), week('2008-02-20', 0), weekofyear('2008-02-20')")
+	result.Check(testkit.Rows("8 198652 198701 7 8"))
+	tk.MustExec("set @@default_week_format = default")
+
+	// test adddate and subdate in the interval and the days forms
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a date, b int)")
+	tk.MustExec("insert into t values ('2011-11-11', 20), ('2011-11-11', null)")
+	result = tk.MustQuery("select adddate(a, interval 1 day), subdate(a, interval 10 hour), adddate(a, b), subdate(a, b), adddate(a, 1), subdate('2011-11-11 10:10:10', 1) from t")
+	result.Check(testkit.Rows("2011-11-12 2011-11-10 14:00:00 2011-12-01 2011-10-22 2011-11-12 2011-11-10 10:10:10",
+		"2011-11-12 2011-11-10 14:00:00 <nil> <nil> 2011-11-12 2011-11-10 10:10:10"))
+	rs, err = tk.Exec("select adddate('2011-11-11 10:10:10', interval 1.5 second), subdate('2011-11-11', 1), subdate('2011-11-11', interval '0.5' second)")
+	c.Assert(err, IsNil)
+	fields, err = rs.Fields()
+	c.Assert(err, IsNil)
+	c.Assert(fields[0].Column.Decimal, Equals, types.MaxFsp)
+	c.Assert(fields[1].Column.Decimal, Not(Equals), types.MaxFsp)
+	c.Assert(fields[2].Column.Decimal, Equals, types.MaxFsp)
+	rs.Close()
+
+	// test str_to_date on an invalid date in strict and non-strict modes
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a datetime)")
+	_, err = tk.Exec("insert into t values (str_to_date('2016-13-40', '%Y-%m-%d'))")
+	c.Assert(err, NotNil)
+	tk.MustExec("set sql_mode = ''")
+	tk.MustExec("insert into t values (str_to_date('2016-13-40', '%Y-%m-%d'))")
+	warnings = tk.Se.GetSessionVars().StmtCtx.GetWarnings()
+	c.Assert(warnings, HasLen, 1)
+	c.Assert(warnings[0].Error(), Equals, "[expression:1411]Incorrect datetime value: '2016-13-40' for function str_to_date")
+	tk.MustExec("set sql_mode = default")
+	tk.MustQuery("select a, str_to_date('2016-13-40', '%Y-%m-%d') from t").Check(testkit.Rows("<nil> <nil>"))
+
+	// test isnull
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(10), c date not null, d datetime)")
+	tk.MustExec("set sql_mode = ''")
+	tk.MustExec("insert into t values (0, '', '0000-00-00', '0000-00-00 00:00:00'), (null, null, '2016-01-01', null)")
+	tk.MustExec("set sql_mode = default")
+	result = tk.MustQuery("select isnull(a), isnull(b), isnull(c), isnull(d), isnull(a + 1), isnull(1/0) from t")
+	result.Check(testkit.Rows("0 0 0 0 0 1", "1 1 0 1 1 1"))
+	for _, mode := range []string{"''", "'STRICT_TRANS_TABLES,NO_ZERO_DATE,NO_ZERO_IN_DATE'"} {
+		tk.MustExec("set sql_mode = " + mode)
+		result = tk.MustQuery("select isnull(c), ifnull(c, 'null'), coalesce(null, c, 'null'), isnull(d), ifnull(d, 'null'), coalesce(d, 'null') from t where a = 0")
+		result.Check(testkit.Rows("0 0000-00-00 0000-00-00 0 0000-00-00 00:00:00 0000-00-00 00:00:00"))
+	}
+	tk.MustExec("set sql_mode = default")
+
+	// test coercibility
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a varchar(20), b int)")
+	tk.MustExec("insert into t values ('abc', 1)")
+	result = tk.MustQuery("select coercibility(a), coercibility('abc'), coercibility(b + 1), coercibility(null), coercibility(user()), coercibility(concat(a, 'x')) from t")
+	result.Check(testkit.Rows("2 4 5 6 3 2"))
+
+	// test charset and collation
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a varchar(20) charset utf8mb4, b varchar(20) charset latin1, c int)")
+	tk.MustExec("insert into t values ('abc', 'abc', 1)")
+	result = tk.MustQuery("select charset(a), collation(a), charset(b), collation(b), charset(c), collation(c + 1), charset('abc') from t")
+	result.Check(testkit.Rows("utf8mb4 utf8mb4_general_ci latin1 latin1_swedish_ci binary binary utf8"))
+
+	// test collation derivation of concat
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a varchar(20) charset utf8mb4 collate utf8mb4_bin, b varchar(20) charset utf8mb4)")
+	tk.MustExec("insert into t values ('a', 'b')")
+	result = tk.MustQuery("select concat(a, b collate utf8mb4_general_ci), collation(concat(a, b collate utf8mb4_general_ci)), coercibility(b collate utf8mb4_general_ci), collation(concat(a, b)), collation(concat(b, 'x')) from t")
+	result.Check(testkit.Rows("ab utf8mb4_general_ci 0 utf8mb4_bin utf8mb4_general_ci"))
+	_, err = tk.Exec("select concat(a collate utf8mb4_general_ci, b collate utf8mb4_bin) from t")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "[expression:1267]Illegal mix of collations (utf8mb4_general_ci,EXPLICIT) and (utf8mb4_bin,EXPLICIT) for operation 'concat'")
+	_, err = tk.Exec("select a collate latin1_bin from t")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "[expression:1253]COLLATION 'latin1_bin' is not valid for CHARACTER SET 'utf8mb4'")
+	// A constant with a COLLATE clause keeps its explicit coercibility.
+	tk.MustExec("drop table if exists t1")
+	tk.MustExec("create table t1 (a varchar(20) charset utf8 collate utf8_bin)")
+	tk.MustExec("insert into t1 values ('a')")
+	result = tk.MustQuery("select coercibility('a' collate utf8_bin), collation(concat(a, 'x' collate utf8_general_ci)), concat(a, 'x' collate utf8_general_ci) from t1")
+	result.Check(testkit.Rows("0 utf8_general_ci ax"))
+	tk.MustExec("drop table t1")
+
+	// test collation coercion of string comparisons
+	result = tk.MustQuery("select a = b, a < b collate utf8mb4_general_ci, strcmp(a, b), a like b, b regexp a from t")
+	result.Check(testkit.Rows("0 1 -1 0 0"))
+	for _, expr := range []string{"a collate utf8mb4_general_ci = b collate utf8mb4_bin", "strcmp(a collate utf8mb4_general_ci, b collate utf8mb4_bin)",
+		"a collate utf8mb4_general_ci like b collate utf8mb4_bin", "a collate utf8mb4_general_ci regexp b collate utf8mb4_bin"} {
+		_, err = tk.Exec("select " + expr + " from t")
+		c.Assert(err, NotNil, Commentf("%s", expr))
+		c.Assert(err.Error(), Matches, ".*Illegal mix of collations \\(utf8mb4_general_ci,EXPLICIT\\) and \\(utf8mb4_bin,EXPLICIT\\).*", Commentf("%s", expr))
+	}
+
+	// test lower and upper
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a varchar(20) charset utf8mb4, b varbinary(20))")
+	tk.MustExec("insert into t values ('Straße', 'TiDB')")
+	result = tk.MustQuery("select lower(a), upper(a), lcase(b), ucase(b) from t")
+	result.Check(testkit.Rows("straße STRAßE TiDB TiDB"))
+
+	// test ord
+	result = tk.MustQuery("select ord(a), ord(b), ord('数据库'), ord(''), ord(null) from t")
+	result.Check(testkit.Rows("83 84 15111600 0 <nil>"))
+
+	// test lpad and rpad, which count characters rather than bytes unless a string is binary
+	result = tk.MustQuery("select hex(lpad('héllo', 2, 'x')), lpad(a, 8, 'é'), rpad(a, 3, 'x'), hex(rpad(b, 5, 'é')) from t")
+	result.Check(testkit.Rows("68C3A9 ééStraße Str 54694442C3"))
+
+	// test substring_index
+	result = tk.MustQuery("select substring_index('www.mysql.com', '.', 100), substring_index('www.mysql.com', '.', -100), substring_index('www.mysql.com', '', 1), substring_index(null, '.', 1), substring_index('www.mysql.com', '.', -9223372036854775808)")
+	result.Check(testkit.Rows("www.mysql.com www.mysql.com  <nil> www.mysql.com"))
+
+	// test ceil and floor on decimal column
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a decimal(10, 2))")
+	tk.MustExec("insert into t values (1.23), (-1.23), (2), (null)")
+	result = tk.MustQuery("select ceil(a), ceiling(a), floor(a) from t")
+	result.Check(testkit.Rows("2 2 1", "-1 -1 -2", "2 2 2", "<nil> <nil> <nil>"))
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a decimal(40, 20))")
+	tk.MustExec("insert into t values (-12345678901234567890.12345678901234567890)")
+	result = tk.MustQuery("select abs(a), ceil(a), floor(a) from t")
+	result.Check(testkit.Rows("12345678901234567890.12345678901234567890 -12345678901234567890 -12345678901234567891"))
+
+	// test div
+	result = tk.MustQuery("select 7 div 2, -7 div 2, 7.9 div -2, '-7.9' div 2, 18446744073709551615 div 1, a div 10, 1 div 0 from t")
+	result.Check(testkit.Rows("3 -3 -3 -3 18446744073709551615 -1234567890123456789 <nil>"))
+	rs, err = tk.Exec("select -9223372036854775808 div -1")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err, NotNil)
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int)")
+	tk.MustExec("insert into t values (1 div 0)")
+	tk.MustExec("set sql_mode = 'STRICT_TRANS_TABLES,ERROR_FOR_DIVISION_BY_ZERO'")
+	_, err = tk.Exec("insert into t values (1 div 0)")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "[expression:1365]Division by 0")
+	tk.MustQuery("select 1 div 0").Check(testkit.Rows("<nil>"))
+	warnings = tk.Se.GetSessionVars().StmtCtx.GetWarnings()
+	c.Assert(warnings, HasLen, 1)
+	tk.MustExec("set sql_mode = 'ERROR_FOR_DIVISION_BY_ZERO'")
+	tk.MustExec("insert into t values (1 div 0)")
+	warnings = tk.Se.GetSessionVars().StmtCtx.GetWarnings()
+	c.Assert(warnings, HasLen, 1)
+	tk.MustExec("set sql_mode = default")
+	tk.MustQuery("select count(*) from t where a is null").Check(testkit.Rows("2"))
+
+	// test arithmetic operators
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a decimal(10, 2), b decimal(10, 3), c bigint, d bigint unsigned)")
+	tk.MustExec("insert into t values (1.10, 2.205, 9223372036854775807, 18446744073709551615)")
+	result = tk.MustQuery("select a + b, a - b, a * b, a / 3, c / 2, 1 / 3, d - 1, c + 0, 1 / 0 from t")
+	result.Check(testkit.Rows("3.305 -1.105 2.42550 0.366667 4611686018427387903.5000 0.3333 18446744073709551614 9223372036854775807 <nil>"))
+	for _, sql := range []string{"select c + 1 from t", "select d + 1 from t", "select -c - 2 from t", "select c * 2 from t"} {
+		rs, err = tk.Exec(sql)
+		c.Assert(err, IsNil)
+		_, err = rs.Next()
+		c.Assert(err, NotNil, Commentf("sql: %s", sql))
+		c.Assert(err.Error(), Matches, ".*value is out of range.*")
+	}
+
+	// test unary minus
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a bigint, b bigint unsigned, c decimal(10, 2))")
+	tk.MustExec("insert into t values (-9223372036854775808, 18446744073709551615, 1.10), (1, 2, -2.5)")
+	result = tk.MustQuery("select -9223372036854775808, -18446744073709551615, -c from t")
+	result.Check(testkit.Rows("-9223372036854775808 -18446744073709551615 -1.10", "-9223372036854775808 -18446744073709551615 2.50"))
+	for _, mode := range []string{"''", "'NO_UNSIGNED_SUBTRACTION'"} {
+		tk.MustExec("set sql_mode = " + mode)
+		tk.MustQuery("select -a, -b from t where a = 1").Check(testkit.Rows("-1 -2"))
+		for _, sql := range []string{"select -a from t", "select -b from t"} {
+			rs, err = tk.Exec(sql)
+			c.Assert(err, IsNil)
+			_, err = rs.Next()
+			c.Assert(err, NotNil, Commentf("sql: %s", sql))
+			c.Assert(err.Error(), Matches, ".*BIGINT value is out of range in '-\\(.*\\)'")
+		}
+	}
+	tk.MustQuery("select b - 3 from t where a = 1").Check(testkit.Rows("-1"))
+	tk.MustExec("set sql_mode = default")
+	rs, err = tk.Exec("select b - 3 from t where a = 1")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err, NotNil)
+
+	// test timediff out of the time range
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a datetime, b datetime)")
+	tk.MustExec("insert into t values ('2010-01-01 00:00:00', '2000-01-01 00:00:00'), ('2000-01-01 00:00:00', '2000-01-02 00:00:01')")
+	result = tk.MustQuery("select timediff(a, b), timediff(b, a) from t")
+	result.Check(testkit.Rows("838:59:59 -838:59:59", "-24:00:01 24:00:01"))
+	warnings = tk.Se.GetSessionVars().StmtCtx.GetWarnings()
+	c.Assert(warnings, HasLen, 2)
+	c.Assert(warnings[0].Error(), Equals, "[expression:1292]Truncated incorrect time value: '87672:00:00'")
+	c.Assert(warnings[1].Error(), Equals, "[expression:1292]Truncated incorrect time value: '-87672:00:00'")
+
+	// test addtime and sec_to_time
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a time, b datetime, c int)")
+	tk.MustExec("insert into t values ('10:00:00', '2017-12-31 23:00:00', 2378)")
+	result = tk.MustQuery("select addtime(a, '1:30:00'), addtime(b, '1:30:00'), sec_to_time(c), sec_to_time(-c) from t")
+	result.Check(testkit.Rows("11:30:00 2018-01-01 00:30:00 00:39:38 -00:39:38"))
+	result = tk.MustQuery("select addtime(a, '830:00:00'), sec_to_time(c * 10000) from t")
+	result.Check(testkit.Rows("838:59:59 838:59:59"))
+	warnings = tk.Se.GetSessionVars().StmtCtx.GetWarnings()
+	c.Assert(warnings, HasLen, 2)
+	c.Assert(warnings[0].Error(), Equals, "[expression:1292]Truncated incorrect time value: '840:00:00'")
+	c.Assert(warnings[1].Error(), Equals, "[expression:1292]Truncated incorrect time value: '23780000'")
+
+	// test sec_to_time out of the time range in strict and non-strict modes
+	_, err = tk.Exec("insert into t (a) values (sec_to_time(3600000))")
+	c.Assert(err, NotNil)
+	tk.MustExec("set sql_mode = ''")
+	tk.MustExec("insert into t (a) values (sec_to_time(3600000))")
+	warnings = tk.Se.GetSessionVars().StmtCtx.GetWarnings()
+	c.Assert(warnings, HasLen, 1)
+	c.Assert(warnings[0].Error(), Equals, "[expression:1292]Truncated incorrect time value: '3600000'")
+	tk.MustExec("set sql_mode = default")
+	tk.MustQuery("select a from t where c is null").Check(testkit.Rows("838:59:59"))
+
+	// test null-safe equal
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(10), c date)")
+	tk.MustExec("insert into t values (1, '1.0', '2017-01-02'), (null, null, null)")
+	result = tk.MustQuery("select a <=> b, b <=> '1', c <=> '2017-1-2', a <=> null, null <=> null, a <=> 'abc' from t")
+	result.Check(testkit.Rows("1 0 1 0 1 0", "1 0 0 1 1 0"))
+
+	// test comparison operators
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(10), c date, d datetime, e time)")
+	tk.MustExec("insert into t values (1, '1.0', '2017-01-02', '2017-01-02 00:00:00', '10:00:00'), (null, null, null, null, null)")
+	result = tk.MustQuery("select a = b, b = '1', a < '1a', c = d, c < '2017-1-10', d >= '2017-01-02', e > '9:00:00', c <> 'abc' from t")
+	result.Check(testkit.Rows("1 0 0 1 1 1 1 1", "<nil> <nil> <nil> <nil> <nil> <nil> <nil> <nil>"))
+	warnings = tk.Se.GetSessionVars().StmtCtx.GetWarnings()
+	c.Assert(warnings, HasLen, 2)
+	c.Assert(warnings[0].Error(), Equals, "[expression:1292]Truncated incorrect DOUBLE value: '1a'")
+	c.Assert(warnings[1].Error(), Equals, "[expression:1292]Truncated incorrect datetime value: 'abc'")
+
+	// test logical operators
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a double, b varchar(10), c int)")
+	tk.MustExec("insert into t values (0.1, '0.1', null), (0, '0', 1)")
+	result = tk.MustQuery("select a and 1, not a, b or 0, not b, c and 0, c or 1, c xor 1, not c from t")
+	result.Check(testkit.Rows("1 0 1 0 0 1 <nil> <nil>", "0 1 0 1 0 1 0 0"))
+
+	// test cast to decimal
+	result = tk.MustQuery("select cast(1.235 as decimal(5,2)), cast('1.5' as decimal(5,2)), cast(2.5 as decimal), cast(1000 as decimal(5,2))")
+	result.Check(testkit.Rows("1.24 1.50 3 999.99"))
+	warnings = tk.Se.GetSessionVars().StmtCtx.GetWarnings()
+	c.Assert(warnings, HasLen, 1)
+	c.Assert(warnings[0].Error(), Equals, "[expression:1264]Out of range value for column 'cast(1000 as decimal(5,2))' at row 1")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a decimal(10,2))")
+	tk.MustExec("set sql_mode = 'STRICT_TRANS_TABLES'")
+	_, err = tk.Exec("insert into t select cast(1000 as decimal(5,2))")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*Out of range value for column 'cast\\(1000 as decimal\\(5,2\\)\\)' at row 1")
+	tk.MustExec("set sql_mode = ''")
+	tk.MustExec("insert into t select cast(1000 as decimal(5,2))")
+	tk.MustQuery("select a from t").Check(testkit.Rows("999.99"))
+	tk.MustExec("set sql_mode = default")
+
+	// test round on values which are not exact in binary
+	result = tk.MustQuery("select round(2.675, 2), round(0.285, 2), round(2.675e0, 2), round(0.285e0, 2), round('1.005', 2)")
+	result.Check(testkit.Rows("2.68 0.29 2.68 0.29 1.01"))
+
+	// test crc32 on binary and numeric values
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a blob, b int, c double)")
+	tk.MustExec("insert into t values (x'610062', 123, 1.5)")
+	result = tk.MustQuery("select crc32(a), crc32('a\\0b'), crc32(b), crc32(c), crc32(null) from t")
+	result.Check(testkit.Rows("367556721 367556721 2286445522 2270993338 <nil>"))
+
+	// test math functions on string column
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a varchar(20), b double)")
+	tk.MustExec("insert into t values ('1.5', 1.5), (' -2 ', -2), ('1e2', 100), ('4x', 4), ('x', 0)")
+	result = tk.MustQuery("select pow(a, 2), abs(a), ceil(a), round(a), pow(a, 2) = pow(b, 2), sin(a) = sin(b) from t")
+	result.Check(testkit.Rows("2.25 1.5 2 2 1 1", "4 2 -2 -2 1 1", "10000 100 100 100 1 1", "16 4 4 4 1 1", "0 0 0 0 1 1"))
+
+	// test interval
+	result = tk.MustQuery("select interval(23, 1, 15, 17, 30, 44, 200), interval(10, 1, 10, 100, 1000), interval(22, 23, 30, 44, 200), interval(null, 1)")
+	result.Check(testkit.Rows("3 2 0 -1"))
+
 	// for case
 	tk.MustExec("drop table if exists t")
 	tk.MustExec("create table t (a varchar(255), b int)")
@@ -1150,6 +1759,17 @@ func (s *testSuite) TestBuiltin(c *C) {
 		{"", "a", 0},
 	}
 	patternMatching(c, tk, "like", testCases)
+	tk.MustExec("drop table if exists t")
+	tk.MustExec(`create table t (id int, a varchar(255), b varbinary(255))`)
+	tk.MustExec(`insert into t values (1, 'a_b', 'a_b'), (2, 'axb', 'AXB'), (3, 'a\\b', 'a\\b')`)
+	tk.MustQuery(`select id from t where a like 'a|_b' escape '|'`).Check(testkit.Rows("1"))
+	tk.MustQuery(`select id from t where a like 'a\\_b'`).Check(testkit.Rows("1"))
+	tk.MustQuery(`select id from t where b like 'a%'`).Check(testkit.Rows("1", "3"))
+	tk.MustQuery(`select id from t where a like 'A%B'`).Check(testkit.Rows("1", "2", "3"))
+	sqlMode := tk.MustQuery("select @@sql_mode").Rows()[0][0]
+	tk.MustExec("set @@sql_mode = 'NO_BACKSLASH_ESCAPES'")
+	tk.MustQuery(`select id from t where a like 'a\\_'`).Check(testkit.Rows("3"))
+	tk.MustExec(fmt.Sprintf("set @@sql_mode = '%s'", sqlMode))
 	// for regexp
 	testCases = []testCase{
 		{"^$", "a", 0},
@@ -1498,6 +2118,66 @@ func (s *testSuite) TestSelectVar(c *C) {
 	result.Check(testkit.Rows("2 2", "2 3", "3 2"))
 }
 
+func (s *testSuite) TestConcatMaxAllowedPacket(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("set global max_allowed_packet = 1024")
+	defer tk.MustExec("set global max_allowed_packet = default")
+
+	// The session gets max_allowed_packet from the global variable when it's created.
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustQuery("select length(concat(repeat('a', 1000), repeat('b', 24))), concat(repeat('a', 1000), repeat('b', 25))").Check(testkit.Rows("1024 <nil>"))
+	warnings := tk1.Se.GetSessionVars().StmtCtx.GetWarnings()
+	c.Assert(warnings, HasLen, 1)
+	c.Assert(warnings[0].Error(), Equals, "[expression:1301]Result of concat() was larger than max_allowed_packet (1024) - truncated")
+	tk1.MustQuery("select concat_ws(',', repeat('a', 1000), repeat('b', 24))").Check(testkit.Rows("<nil>"))
+	tk1.MustQuery("select length(lpad('a', 1024, 'b')), length(rpad('a', 1024, 'b')), lpad('a', 1025, 'b'), rpad('a', 1 << 62, 'b')").Check(testkit.Rows("1024 1024 <nil> <nil>"))
+	warnings = tk1.Se.GetSessionVars().StmtCtx.GetWarnings()
+	c.Assert(warnings, HasLen, 2)
+	c.Assert(warnings[0].Error(), Equals, "[expression:1301]Result of lpad() was larger than max_allowed_packet (1024) - truncated")
+	c.Assert(warnings[1].Error(), Equals, "[expression:1301]Result of rpad() was larger than max_allowed_packet (1024) - truncated")
+}
+
+func (s *testSuite) TestUserLock(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk2 := testkit.NewTestKit(c, s.store)
+	tk1.MustQuery("select get_lock('test_lock', 1), is_free_lock('test_lock')").Check(testkit.Rows("1 0"))
+	connID := fmt.Sprint(tk1.Se.GetSessionVars().ConnectionID)
+	tk2.MustQuery("select is_used_lock('test_lock'), get_lock('test_lock', 0.1)").Check(testkit.Rows(connID + " 0"))
+	tk2.MustQuery("select release_lock('test_lock'), release_lock('no_lock')").Check(testkit.Rows("0 <nil>"))
+
+	// tk2 gets the lock whether it starts waiting before or after tk1 releases it,
+	// the waiting itself is covered by the expression tests.
+	ch := make(chan struct{})
+	go func() {
+		tk2.MustQuery("select get_lock('test_lock', 10)").Check(testkit.Rows("1"))
+		close(ch)
+	}()
+	tk1.MustQuery("select release_lock('test_lock')").Check(testkit.Rows("1"))
+	<-ch
+	tk1.MustQuery("select is_used_lock('test_lock') = connection_id()").Check(testkit.Rows("0"))
+
+	tk1.MustQuery("select get_lock('l1', 0), get_lock('l2', 0), get_lock('l2', 0), release_all_locks()").Check(testkit.Rows("1 1 1 3"))
+	tk1.MustQuery("select is_used_lock('l1'), is_used_lock('l2'), release_all_locks()").Check(testkit.Rows("<nil> <nil> 0"))
+
+	rs, err := tk1.Exec("select get_lock(repeat('a', 65), 0)")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*Incorrect user-level lock name 'a{65}'.*")
+
+	// The locks are released when the session is closed.
+	tk2.Se.Close()
+	tk1.MustQuery("select is_free_lock('test_lock')").Check(testkit.Rows("1"))
+}
+
 func (s *testSuite) TestHistoryRead(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -431,6 +431,36 @@ func (s *testSuite) TestUpdate(c *C) {
 	tk.MustExec("commit")
 }
 
+func (s *testSuite) TestDefaultFunc(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(id int primary key, a int default 10, b varchar(10) default 'x', c int, d int not null)")
+	tk.MustExec("insert into t values (1, 1, 'a', 1, 1)")
+	tk.MustQuery("select default(a), default(b), default(c), default(t.a) + 1 from t").Check(testkit.Rows("10 x <nil> 11"))
+
+	tk.MustExec("update t set a = default(a) + 1, c = default(c), b = concat(default(b), b)")
+	tk.MustQuery("select a, b = 'xa', c from t").Check(testkit.Rows("11 1 <nil>"))
+
+	tk.MustExec("insert into t (id, d) values (1, 2) on duplicate key update a = default(a) * 2, c = default(a)")
+	tk.MustQuery("select a, c, d from t").Check(testkit.Rows("20 10 1"))
+
+	// d has no default value, it's an error in strict mode and the zero value otherwise.
+	rs, err := tk.Exec("select default(d) from t")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err, NotNil)
+	tk.MustExec("set sql_mode = ''")
+	tk.MustQuery("select default(d) from t").Check(testkit.Rows("0"))
+
+	_, err = tk.Exec("select default(e) from t")
+	c.Assert(err, NotNil)
+}
+
 func (s *testSuite) fillMultiTableForUpdate(tk *testkit.TestKit) {
 	// Create and fill table items
 	tk.MustExec("CREATE TABLE items (id int, price TEXT);")
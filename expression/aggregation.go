@@ -17,6 +17,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
 	"strings"
 
 	"github.com/juju/errors"
@@ -24,6 +26,7 @@ import (
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/distinct"
 	"github.com/pingcap/tidb/util/types"
@@ -94,13 +97,23 @@ func NewAggFunction(funcType string, funcArgs []Expression, distinct bool) Aggre
 	case ast.AggFuncAvg:
 		return &avgFunction{aggFunction: newAggFunc(tp, funcArgs, distinct)}
 	case ast.AggFuncGroupConcat:
-		return &concatFunction{aggFunction: newAggFunc(tp, funcArgs, distinct)}
+		return NewGroupConcatFunction(funcArgs, distinct, nil, nil, ",")
 	case ast.AggFuncMax:
 		return &maxMinFunction{aggFunction: newAggFunc(tp, funcArgs, distinct), isMax: true}
 	case ast.AggFuncMin:
 		return &maxMinFunction{aggFunction: newAggFunc(tp, funcArgs, distinct), isMax: false}
 	case ast.AggFuncFirstRow:
 		return &firstRowFunction{aggFunction: newAggFunc(tp, funcArgs, distinct)}
+	case ast.AggFuncBitAnd, ast.AggFuncBitOr, ast.AggFuncBitXor:
+		return newBitFunction(tp, funcArgs, distinct)
+	case ast.AggFuncStddevPop:
+		return &varianceFunction{aggFunction: newAggFunc(tp, funcArgs, distinct), stddev: true}
+	case ast.AggFuncStddevSamp:
+		return &varianceFunction{aggFunction: newAggFunc(tp, funcArgs, distinct), stddev: true, sample: true}
+	case ast.AggFuncVarPop:
+		return &varianceFunction{aggFunction: newAggFunc(tp, funcArgs, distinct)}
+	case ast.AggFuncVarSamp:
+		return &varianceFunction{aggFunction: newAggFunc(tp, funcArgs, distinct), sample: true}
 	}
 	return nil
 }
@@ -588,6 +601,23 @@ func (af *avgFunction) GetStreamResult() (d types.Datum) {
 
 type concatFunction struct {
 	aggFunction
+	separator string
+	// byItemsDesc holds the orders of the ORDER BY items, the expressions of the items are the last len(byItemsDesc) arguments.
+	byItemsDesc []bool
+}
+
+// NewGroupConcatFunction creates a group_concat function, the orderBy expressions are sorted by the desc orders
+// before being concatenated with the separator.
+func NewGroupConcatFunction(args []Expression, distinct bool, orderBy []Expression, desc []bool, separator string) AggregationFunction {
+	// The arguments are copied, so appending the ORDER BY items never writes to the array of the caller.
+	allArgs := make([]Expression, 0, len(args)+len(orderBy))
+	allArgs = append(allArgs, args...)
+	allArgs = append(allArgs, orderBy...)
+	return &concatFunction{
+		aggFunction: newAggFunc(ast.AggFuncGroupConcat, allArgs, distinct),
+		separator:   separator,
+		byItemsDesc: desc,
+	}
 }
 
 // Clone implements AggregationFunction interface.
@@ -600,6 +630,20 @@ func (cf *concatFunction) Clone() AggregationFunction {
 	return &nf
 }
 
+// Equal implements AggregationFunction interface.
+func (cf *concatFunction) Equal(b AggregationFunction, ctx context.Context) bool {
+	bf, ok := b.(*concatFunction)
+	if !ok || cf.separator != bf.separator || len(cf.byItemsDesc) != len(bf.byItemsDesc) {
+		return false
+	}
+	for i, desc := range cf.byItemsDesc {
+		if desc != bf.byItemsDesc[i] {
+			return false
+		}
+	}
+	return cf.aggFunction.Equal(b, ctx)
+}
+
 // GetType implements AggregationFunction interface.
 func (cf *concatFunction) GetType() *types.FieldType {
 	return types.NewFieldType(mysql.TypeVarString)
@@ -607,20 +651,31 @@ func (cf *concatFunction) GetType() *types.FieldType {
 
 // Update implements AggregationFunction interface.
 func (cf *concatFunction) Update(row []types.Datum, groupKey []byte, ectx context.Context) error {
-	ctx := cf.getContext(groupKey)
-	vals := make([]interface{}, 0, len(cf.Args))
+	return cf.updateConcat(cf.getContext(groupKey), row, ectx)
+}
+
+// StreamUpdate implements AggregationFunction interface.
+func (cf *concatFunction) StreamUpdate(row []types.Datum, ectx context.Context) error {
+	return cf.updateConcat(cf.getStreamedContext(), row, ectx)
+}
+
+func (cf *concatFunction) updateConcat(ctx *ast.AggEvaluateContext, row []types.Datum, ectx context.Context) error {
+	vals := make([]types.Datum, 0, len(cf.Args))
 	for _, a := range cf.Args {
 		value, err := a.Eval(row, ectx)
 		if err != nil {
 			return errors.Trace(err)
 		}
-		if value.GetValue() == nil {
+		vals = append(vals, value)
+	}
+	concatVals := vals[:len(vals)-len(cf.byItemsDesc)]
+	for _, val := range concatVals {
+		if val.IsNull() {
 			return nil
 		}
-		vals = append(vals, value.GetValue())
 	}
 	if cf.Distinct {
-		d, err := ctx.DistinctChecker.Check(vals)
+		d, err := ctx.DistinctChecker.Check(types.DatumsToInterfaces(concatVals))
 		if err != nil {
 			return errors.Trace(err)
 		}
@@ -628,58 +683,77 @@ func (cf *concatFunction) Update(row []types.Datum, groupKey []byte, ectx contex
 			return nil
 		}
 	}
+	// The statement context and group_concat_max_len are kept in the context of the group,
+	// because the ordered result is built after all the rows are processed.
+	if ctx.Buffer == nil && ctx.SortedRows == nil {
+		ctx.StmtCtx = ectx.GetSessionVars().StmtCtx
+		ctx.MaxLen = getUintSysVar(ectx, groupConcatMaxLen)
+	}
+	if len(cf.byItemsDesc) > 0 {
+		ctx.SortedRows = append(ctx.SortedRows, vals)
+		return nil
+	}
+	return errors.Trace(cf.writeConcatValues(ctx, vals))
+}
+
+// writeConcatValues writes the values to the buffer of the context, the result is cut by group_concat_max_len
+// on a character boundary.
+func (cf *concatFunction) writeConcatValues(ctx *ast.AggEvaluateContext, vals []types.Datum) error {
+	if ctx.Truncated {
+		return nil
+	}
 	if ctx.Buffer == nil {
 		ctx.Buffer = &bytes.Buffer{}
 	} else {
-		// now use comma separator
-		ctx.Buffer.WriteString(",")
+		ctx.Buffer.WriteString(cf.separator)
 	}
-	for _, val := range vals {
-		ctx.Buffer.WriteString(fmt.Sprintf("%v", val))
+	for _, val := range vals[:len(vals)-len(cf.byItemsDesc)] {
+		str, err := val.ToString()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		ctx.Buffer.WriteString(str)
+	}
+	ctx.Count++
+	if uint64(ctx.Buffer.Len()) > ctx.MaxLen {
+		ctx.Buffer.Truncate(cf.charBoundary(ctx.Buffer.String(), int(ctx.MaxLen)))
+		ctx.Truncated = true
+		ctx.StmtCtx.AppendWarning(errCutValueGroupConcat.GenByArgs(ctx.Count))
 	}
-	// TODO: if total length is greater than global var group_concat_max_len, truncate it.
 	return nil
 }
 
-// StreamUpdate implements AggregationFunction interface.
-func (cf *concatFunction) StreamUpdate(row []types.Datum, ectx context.Context) error {
-	ctx := cf.getStreamedContext()
-	vals := make([]interface{}, 0, len(cf.Args))
-	for _, a := range cf.Args {
-		value, err := a.Eval(row, ectx)
-		if err != nil {
-			return errors.Trace(err)
-		}
-		if value.GetValue() == nil {
-			return nil
+// charBoundary returns the length of the longest prefix of s, which is at most maxLen bytes and doesn't cut a character.
+// The result is a binary string if any of the concatenated values is binary.
+func (cf *concatFunction) charBoundary(s string, maxLen int) int {
+	cs := charset.CharsetUTF8
+	for _, arg := range cf.Args[:len(cf.Args)-len(cf.byItemsDesc)] {
+		if isBinaryStr(arg.GetType()) {
+			cs = charset.CharsetBin
 		}
-		vals = append(vals, value)
 	}
-	if cf.Distinct {
-		d, err := ctx.DistinctChecker.Check(vals)
-		if err != nil {
-			return errors.Trace(err)
-		}
-		if !d {
-			return nil
+	end := 0
+	for end < len(s) {
+		size := charSize(s[end:], cs)
+		if end+size > maxLen {
+			break
 		}
+		end += size
 	}
-	if ctx.Buffer == nil {
-		ctx.Buffer = &bytes.Buffer{}
-	} else {
-		// now use comma separator
-		ctx.Buffer.WriteString(",")
-	}
-	for _, val := range vals {
-		ctx.Buffer.WriteString(fmt.Sprintf("%v", val))
-	}
-	// TODO: if total length is greater than global var group_concat_max_len, truncate it.
-	return nil
+	return end
 }
 
-// GetGroupResult implements AggregationFunction interface.
-func (cf *concatFunction) GetGroupResult(groupKey []byte) (d types.Datum) {
-	ctx := cf.getContext(groupKey)
+func (cf *concatFunction) calculateResult(ctx *ast.AggEvaluateContext) (d types.Datum) {
+	if ctx.SortedRows != nil {
+		cf.sortRows(ctx.StmtCtx, ctx.SortedRows)
+		for _, vals := range ctx.SortedRows {
+			if err := cf.writeConcatValues(ctx, vals); err != nil {
+				ctx.StmtCtx.AppendWarning(err)
+				break
+			}
+		}
+		ctx.SortedRows = nil
+	}
 	if ctx.Buffer != nil {
 		d.SetString(ctx.Buffer.String())
 	} else {
@@ -688,20 +762,65 @@ func (cf *concatFunction) GetGroupResult(groupKey []byte) (d types.Datum) {
 	return d
 }
 
+// sortRows sorts the rows by the ORDER BY items, which are the last len(cf.byItemsDesc) values of the rows.
+func (cf *concatFunction) sortRows(sc *variable.StatementContext, rows [][]types.Datum) {
+	sort.Stable(&concatRowSorter{
+		rows:   rows,
+		offset: len(cf.Args) - len(cf.byItemsDesc),
+		desc:   cf.byItemsDesc,
+		sc:     sc,
+	})
+}
+
+type concatRowSorter struct {
+	rows   [][]types.Datum
+	offset int
+	desc   []bool
+	sc     *variable.StatementContext
+}
+
+// Len implements sort.Interface Len interface.
+func (s *concatRowSorter) Len() int {
+	return len(s.rows)
+}
+
+// Swap implements sort.Interface Swap interface.
+func (s *concatRowSorter) Swap(i, j int) {
+	s.rows[i], s.rows[j] = s.rows[j], s.rows[i]
+}
+
+// Less implements sort.Interface Less interface.
+func (s *concatRowSorter) Less(i, j int) bool {
+	for k, desc := range s.desc {
+		cmp, err := s.rows[i][s.offset+k].CompareDatum(s.sc, s.rows[j][s.offset+k])
+		if err != nil {
+			s.sc.AppendWarning(err)
+			return false
+		}
+		if cmp != 0 {
+			return (cmp < 0) != desc
+		}
+	}
+	return false
+}
+
+// GetGroupResult implements AggregationFunction interface.
+func (cf *concatFunction) GetGroupResult(groupKey []byte) (d types.Datum) {
+	return cf.calculateResult(cf.getContext(groupKey))
+}
+
 // GetStreamResult implements AggregationFunction interface.
 func (cf *concatFunction) GetStreamResult() (d types.Datum) {
 	if cf.streamCtx == nil {
 		return
 	}
-	if cf.streamCtx.Buffer != nil {
-		d.SetString(cf.streamCtx.Buffer.String())
-	} else {
-		d.SetNull()
-	}
+	d = cf.calculateResult(cf.streamCtx)
 	cf.streamCtx = nil
 	return
 }
 
+const groupConcatMaxLen = "group_concat_max_len"
+
 type maxMinFunction struct {
 	aggFunction
 	isMax bool
@@ -890,3 +1009,242 @@ func (ff *firstRowFunction) CalculateDefaultValue(schema Schema, ctx context.Con
 	}
 	return d, false
 }
+
+type bitFunction struct {
+	aggFunction
+	// identity is the result of an empty group, it's all-ones for bit_and and 0 for bit_or and bit_xor.
+	identity uint64
+	op       func(x, y uint64) uint64
+}
+
+func newBitFunction(name string, args []Expression, distinct bool) *bitFunction {
+	bf := &bitFunction{aggFunction: newAggFunc(name, args, distinct)}
+	switch name {
+	case ast.AggFuncBitAnd:
+		bf.identity = math.MaxUint64
+		bf.op = func(x, y uint64) uint64 { return x & y }
+	case ast.AggFuncBitOr:
+		bf.op = func(x, y uint64) uint64 { return x | y }
+	case ast.AggFuncBitXor:
+		bf.op = func(x, y uint64) uint64 { return x ^ y }
+	}
+	return bf
+}
+
+// Clone implements AggregationFunction interface.
+func (bf *bitFunction) Clone() AggregationFunction {
+	nf := *bf
+	for i, arg := range bf.Args {
+		nf.Args[i] = arg.Clone()
+	}
+	nf.resultMapper = make(aggCtxMapper)
+	return &nf
+}
+
+// GetType implements AggregationFunction interface.
+func (bf *bitFunction) GetType() *types.FieldType {
+	ft := types.NewFieldType(mysql.TypeLonglong)
+	ft.Flen = 21
+	ft.Flag |= mysql.UnsignedFlag
+	ft.Charset = charset.CharsetBin
+	ft.Collate = charset.CollationBin
+	return ft
+}
+
+// CalculateDefaultValue implements AggregationFunction interface.
+func (bf *bitFunction) CalculateDefaultValue(schema Schema, ctx context.Context) (d types.Datum, valid bool) {
+	result, err := EvaluateExprWithNull(ctx, schema, bf.Args[0])
+	if err != nil {
+		log.Warnf("Evaluate expr with null failed in function %s, err msg is %s", bf, err.Error())
+		return d, false
+	}
+	con, ok := result.(*Constant)
+	if !ok {
+		return d, false
+	}
+	if con.Value.IsNull() {
+		d.SetUint64(bf.identity)
+		return d, true
+	}
+	val, err := datumToUint64(ctx.GetSessionVars().StmtCtx, con.Value)
+	if err != nil {
+		return d, false
+	}
+	d.SetUint64(bf.op(bf.identity, val))
+	return d, true
+}
+
+// Update implements AggregationFunction interface.
+func (bf *bitFunction) Update(row []types.Datum, groupKey []byte, ectx context.Context) error {
+	return bf.updateBit(bf.getContext(groupKey), row, ectx)
+}
+
+// StreamUpdate implements AggregationFunction interface.
+func (bf *bitFunction) StreamUpdate(row []types.Datum, ectx context.Context) error {
+	return bf.updateBit(bf.getStreamedContext(), row, ectx)
+}
+
+func (bf *bitFunction) updateBit(ctx *ast.AggEvaluateContext, row []types.Datum, ectx context.Context) error {
+	if len(bf.Args) != 1 {
+		return errors.New("Wrong number of args for AggFuncBit")
+	}
+	value, err := bf.Args[0].Eval(row, ectx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if value.IsNull() {
+		return nil
+	}
+	if bf.Distinct {
+		d, err1 := ctx.DistinctChecker.Check([]interface{}{value.GetValue()})
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		if !d {
+			return nil
+		}
+	}
+	val, err := datumToUint64(ectx.GetSessionVars().StmtCtx, value)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !ctx.GotFirstRow {
+		ctx.Value.SetUint64(bf.identity)
+		ctx.GotFirstRow = true
+	}
+	ctx.Value.SetUint64(bf.op(ctx.Value.GetUint64(), val))
+	return nil
+}
+
+func (bf *bitFunction) calculateResult(ctx *ast.AggEvaluateContext) (d types.Datum) {
+	if !ctx.GotFirstRow {
+		d.SetUint64(bf.identity)
+		return d
+	}
+	return ctx.Value
+}
+
+// GetGroupResult implements AggregationFunction interface.
+func (bf *bitFunction) GetGroupResult(groupKey []byte) types.Datum {
+	return bf.calculateResult(bf.getContext(groupKey))
+}
+
+// GetStreamResult implements AggregationFunction interface.
+func (bf *bitFunction) GetStreamResult() (d types.Datum) {
+	if bf.streamCtx == nil {
+		d.SetUint64(bf.identity)
+		return
+	}
+	d = bf.calculateResult(bf.streamCtx)
+	bf.streamCtx = nil
+	return
+}
+
+// datumToUint64 converts a datum to uint64 for bitwise operations, negative integers keep their two's complement bits.
+func datumToUint64(sc *variable.StatementContext, d types.Datum) (uint64, error) {
+	if d.Kind() == types.KindUint64 {
+		return d.GetUint64(), nil
+	}
+	val, err := d.ToInt64(sc)
+	return uint64(val), errors.Trace(err)
+}
+
+type varianceFunction struct {
+	aggFunction
+	// sample is true for the sample variance, which divides the sum of squares by count-1 instead of count.
+	sample bool
+	// stddev is true for the standard deviation, which is the square root of the variance.
+	stddev bool
+}
+
+// Clone implements AggregationFunction interface.
+func (vf *varianceFunction) Clone() AggregationFunction {
+	nf := *vf
+	for i, arg := range vf.Args {
+		nf.Args[i] = arg.Clone()
+	}
+	nf.resultMapper = make(aggCtxMapper)
+	return &nf
+}
+
+// GetType implements AggregationFunction interface.
+func (vf *varianceFunction) GetType() *types.FieldType {
+	ft := types.NewFieldType(mysql.TypeDouble)
+	ft.Charset = charset.CharsetBin
+	ft.Collate = charset.CollationBin
+	return ft
+}
+
+// Update implements AggregationFunction interface.
+func (vf *varianceFunction) Update(row []types.Datum, groupKey []byte, ectx context.Context) error {
+	return vf.updateVariance(vf.getContext(groupKey), row, ectx)
+}
+
+// StreamUpdate implements AggregationFunction interface.
+func (vf *varianceFunction) StreamUpdate(row []types.Datum, ectx context.Context) error {
+	return vf.updateVariance(vf.getStreamedContext(), row, ectx)
+}
+
+// updateVariance uses Welford's online algorithm, which is stable even if the values are large and close to each other.
+// See https://en.wikipedia.org/wiki/Algorithms_for_calculating_variance#Welford's_online_algorithm
+func (vf *varianceFunction) updateVariance(ctx *ast.AggEvaluateContext, row []types.Datum, ectx context.Context) error {
+	if len(vf.Args) != 1 {
+		return errors.New("Wrong number of args for AggFuncVariance")
+	}
+	value, err := vf.Args[0].Eval(row, ectx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if value.IsNull() {
+		return nil
+	}
+	if vf.Distinct {
+		d, err1 := ctx.DistinctChecker.Check([]interface{}{value.GetValue()})
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		if !d {
+			return nil
+		}
+	}
+	x, err := value.ToFloat64(ectx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ctx.Count++
+	delta := x - ctx.Mean
+	ctx.Mean += delta / float64(ctx.Count)
+	ctx.SquareSum += delta * (x - ctx.Mean)
+	return nil
+}
+
+func (vf *varianceFunction) calculateResult(ctx *ast.AggEvaluateContext) (d types.Datum) {
+	count := ctx.Count
+	if vf.sample {
+		count--
+	}
+	if count <= 0 {
+		return
+	}
+	variance := ctx.SquareSum / float64(count)
+	if vf.stddev {
+		variance = math.Sqrt(variance)
+	}
+	d.SetFloat64(variance)
+	return
+}
+
+// GetGroupResult implements AggregationFunction interface.
+func (vf *varianceFunction) GetGroupResult(groupKey []byte) types.Datum {
+	return vf.calculateResult(vf.getContext(groupKey))
+}
+
+// GetStreamResult implements AggregationFunction interface.
+func (vf *varianceFunction) GetStreamResult() (d types.Datum) {
+	if vf.streamCtx == nil {
+		return
+	}
+	d = vf.calculateResult(vf.streamCtx)
+	vf.streamCtx = nil
+	return
+}
@@ -0,0 +1,221 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var likeRows = composeLikeRows(1000)
+
+func composeLikeRows(size int) []string {
+	rows := make([]string, 0, size)
+	for i := 0; i < size; i++ {
+		rows = append(rows, fmt.Sprintf("user_%08d@example.com", i))
+	}
+	return rows
+}
+
+func benchmarkLikeCompileEachRow(b *testing.B, pattern string) {
+	for i := 0; i < b.N; i++ {
+		for _, row := range likeRows {
+			patChars, patTypes := compilePattern(pattern, '\\')
+			doMatch(row, patChars, patTypes, true)
+		}
+	}
+}
+
+func benchmarkLikeMatcher(b *testing.B, pattern string) {
+	patChars, patTypes := compilePattern(pattern, '\\')
+	m := newLikeMatcher(patChars, patTypes, true)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, row := range likeRows {
+			m.match(row)
+		}
+	}
+}
+
+func BenchmarkLikePrefixCompileEachRow(b *testing.B) {
+	benchmarkLikeCompileEachRow(b, `user\_0000%`)
+}
+
+func BenchmarkLikePrefixMatcher(b *testing.B) {
+	benchmarkLikeMatcher(b, `user\_0000%`)
+}
+
+func BenchmarkLikeContainsCompileEachRow(b *testing.B) {
+	benchmarkLikeCompileEachRow(b, "%99@%")
+}
+
+func BenchmarkLikeContainsMatcher(b *testing.B) {
+	benchmarkLikeMatcher(b, "%99@%")
+}
+
+func BenchmarkLikeGenericCompileEachRow(b *testing.B) {
+	benchmarkLikeCompileEachRow(b, "user_%9_@%.com")
+}
+
+func BenchmarkLikeGenericMatcher(b *testing.B) {
+	benchmarkLikeMatcher(b, "user_%9_@%.com")
+}
+
+var evalRows = composeEvalRows(1000)
+
+func composeEvalRows(size int) [][]types.Datum {
+	rows := make([][]types.Datum, 0, size)
+	for i := 0; i < size; i++ {
+		rows = append(rows, types.MakeDatums(float64(i-size/2)/8))
+	}
+	return rows
+}
+
+// newAbsPowExpr builds abs(pow(col, 2)).
+func newAbsPowExpr(b *testing.B) Expression {
+	col := &Column{RetType: types.NewFieldType(mysql.TypeDouble), Index: 0}
+	two := &Constant{Value: types.NewIntDatum(2), RetType: types.NewFieldType(mysql.TypeLonglong)}
+	pow, err := NewFunction(ast.Pow, nil, col, two)
+	if err != nil {
+		b.Fatal(err)
+	}
+	abs, err := NewFunction(ast.Abs, nil, pow)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return abs
+}
+
+func BenchmarkEvalRowByRow(b *testing.B) {
+	expr, ctx := newAbsPowExpr(b), mock.NewContext()
+	result := make([]types.Datum, len(evalRows))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j, row := range evalRows {
+			result[j], _ = expr.Eval(row, ctx)
+		}
+	}
+}
+
+func BenchmarkEvalBatch(b *testing.B) {
+	expr, ctx := newAbsPowExpr(b), mock.NewContext()
+	result := make([]types.Datum, len(evalRows))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		EvalBatch(expr, evalRows, ctx, result)
+	}
+}
+
+var roundRows = composeRoundRows(1000)
+
+// composeRoundRows composes rows of an int64, a float64 and a decimal column.
+func composeRoundRows(size int) [][]types.Datum {
+	rows := make([][]types.Datum, 0, size)
+	for i := 0; i < size; i++ {
+		v := int64(i-size/2) * 12345
+		rows = append(rows, types.MakeDatums(v, float64(v)/1000, types.NewDecFromStringForTest(fmt.Sprintf("%d.%03d", v/1000, absInt64(v%1000)))))
+	}
+	return rows
+}
+
+// benchmarkRound evaluates ROUND on the column of roundRows at colIdx, with D if withD is true.
+func benchmarkRound(b *testing.B, colIdx int, tp byte, withD bool) {
+	args := []Expression{&Column{RetType: types.NewFieldType(tp), Index: colIdx}}
+	if withD {
+		args = append(args, &Constant{Value: types.NewIntDatum(2), RetType: types.NewFieldType(mysql.TypeLonglong)})
+	}
+	expr, err := NewFunction(ast.Round, nil, args...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	ctx := mock.NewContext()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, row := range roundRows {
+			if _, err = expr.Eval(row, ctx); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkRoundInt(b *testing.B) {
+	benchmarkRound(b, 0, mysql.TypeLonglong, false)
+}
+
+func BenchmarkRoundIntWithD(b *testing.B) {
+	benchmarkRound(b, 0, mysql.TypeLonglong, true)
+}
+
+func BenchmarkRoundFloat(b *testing.B) {
+	benchmarkRound(b, 1, mysql.TypeDouble, false)
+}
+
+func BenchmarkRoundFloatWithD(b *testing.B) {
+	benchmarkRound(b, 1, mysql.TypeDouble, true)
+}
+
+func BenchmarkRoundDecimal(b *testing.B) {
+	benchmarkRound(b, 2, mysql.TypeNewDecimal, false)
+}
+
+func BenchmarkRoundDecimalWithD(b *testing.B) {
+	benchmarkRound(b, 2, mysql.TypeNewDecimal, true)
+}
+
+var jsonRows = composeJSONRows(1000)
+
+// composeJSONRows composes rows of a JSON document and a path to remove from it.
+func composeJSONRows(size int) [][]types.Datum {
+	rows := make([][]types.Datum, 0, size)
+	for i := 0; i < size; i++ {
+		rows = append(rows, types.MakeDatums(fmt.Sprintf(`{"a": {"b": [%d, %d]}, "c": "%d"}`, i, i+1, i), "$.a.b[1]"))
+	}
+	return rows
+}
+
+// benchmarkJSONRemove evaluates JSON_REMOVE on jsonRows, with a constant path or the path column.
+func benchmarkJSONRemove(b *testing.B, constPath bool) {
+	strTp := types.NewFieldType(mysql.TypeVarchar)
+	var path Expression = &Column{RetType: strTp, Index: 1}
+	if constPath {
+		path = &Constant{Value: types.NewStringDatum("$.a.b[1]"), RetType: strTp}
+	}
+	expr, err := NewFunction(ast.JSONRemove, nil, &Column{RetType: strTp, Index: 0}, path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	ctx := mock.NewContext()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, row := range jsonRows {
+			if _, err = expr.Eval(row, ctx); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkJSONRemoveConstPath(b *testing.B) {
+	benchmarkJSONRemove(b, true)
+}
+
+func BenchmarkJSONRemoveColumnPath(b *testing.B) {
+	benchmarkJSONRemove(b, false)
+}
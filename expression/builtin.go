@@ -18,12 +18,15 @@
 package expression
 
 import (
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -53,8 +56,19 @@ func (b *baseBuiltinFunc) evalArgs(row []types.Datum) (_ []types.Datum, err erro
 	return b.argValues, nil
 }
 
-// isDeterministic will be true by default. Non-deterministic function will override this function.
-func (b *baseBuiltinFunc) isDeterministic() bool {
+// evalBatch evaluates the function row by row by default, a function can override it to evaluate the rows faster.
+func (b *baseBuiltinFunc) evalBatch(rows [][]types.Datum, result []types.Datum) (err error) {
+	for i, row := range rows {
+		result[i], err = b.self.eval(row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// IsDeterministic will be true by default. Non-deterministic function will override this function.
+func (b *baseBuiltinFunc) IsDeterministic() bool {
 	return true
 }
 
@@ -65,7 +79,7 @@ func (b *baseBuiltinFunc) getArgs() []Expression {
 // equal only checks if both functions are non-deterministic and if these arguments are same.
 // Function name will be checked outside.
 func (b *baseBuiltinFunc) equal(fun builtinFunc) bool {
-	if !b.self.isDeterministic() || !fun.isDeterministic() {
+	if !b.self.IsDeterministic() || !fun.IsDeterministic() {
 		return false
 	}
 	funArgs := fun.getArgs()
@@ -84,16 +98,24 @@ func (b *baseBuiltinFunc) getCtx() context.Context {
 	return b.ctx
 }
 
+func (b *baseBuiltinFunc) setSelf(f builtinFunc) builtinFunc {
+	b.self = f
+	return f
+}
+
 // builtinFunc stands for a particular function signature.
 type builtinFunc interface {
 	// eval does evaluation by the given row.
 	eval([]types.Datum) (types.Datum, error)
+	// evalBatch does evaluation by every row of the given rows and stores the results in result, which is as long as rows.
+	evalBatch(rows [][]types.Datum, result []types.Datum) error
 	// getArgs returns the arguments expressions.
 	getArgs() []Expression
-	// isDeterministic checks if a function is deterministic.
-	// A function is deterministic if it returns same results for same inputs.
+	// IsDeterministic checks if a function is deterministic.
+	// A function is deterministic if it returns same results for same inputs,
+	// only a deterministic function can be constant folded or have its result cached.
 	// e.g. random is non-deterministic.
-	isDeterministic() bool
+	IsDeterministic() bool
 	// equal check if this function equals to another function.
 	equal(builtinFunc) bool
 	// getCtx returns this function's context.
@@ -104,6 +126,31 @@ type builtinFunc interface {
 type functionClass interface {
 	// getFunction gets a function signature by the types and the counts of given arguments.
 	getFunction(args []Expression, ctx context.Context) (builtinFunc, error)
+	// checkValid checks if the given arguments are valid for this function class.
+	checkValid(args []Expression) error
+	// argsRange returns the minimal and maximal numbers of arguments, -1 for infinity.
+	argsRange() (minArgs, maxArgs int)
+}
+
+// baseFuncClass will be contained in every struct that implement functionClass interface.
+type baseFuncClass struct {
+	funcName string
+	minArgs  int
+	maxArgs  int
+}
+
+// argsRange returns the minimal and maximal numbers of arguments of this function class, -1 for infinity.
+func (b *baseFuncClass) argsRange() (minArgs, maxArgs int) {
+	return b.minArgs, b.maxArgs
+}
+
+// checkValid checks if the count of arguments is valid for this function class.
+func (b *baseFuncClass) checkValid(args []Expression) error {
+	l := len(args)
+	if l < b.minArgs || (b.maxArgs != -1 && l > b.maxArgs) {
+		return errIncorrectParameterCount.GenByArgs(b.funcName)
+	}
+	return nil
 }
 
 // BuiltinFunc is the function signature for builtin functions
@@ -121,89 +168,65 @@ type Func struct {
 
 // Funcs holds all registered builtin functions.
 var Funcs = map[string]Func{
-	// common functions
-	ast.Coalesce: {builtinCoalesce, 1, -1},
-	ast.IsNull:   {builtinIsNull, 1, 1},
-	ast.Greatest: {builtinGreatest, 2, -1},
-	ast.Least:    {builtinLeast, 2, -1},
-
 	// math functions
 	ast.Abs:     {builtinAbs, 1, 1},
 	ast.Ceil:    {builtinCeil, 1, 1},
 	ast.Ceiling: {builtinCeil, 1, 1},
+	ast.Floor:   {builtinFloor, 1, 1},
 	ast.Ln:      {builtinLog, 1, 1},
 	ast.Log:     {builtinLog, 1, 2},
 	ast.Log2:    {builtinLog2, 1, 1},
 	ast.Log10:   {builtinLog10, 1, 1},
+	ast.Exp:     {builtinExp, 1, 1},
 	ast.Pow:     {builtinPow, 2, 2},
 	ast.Power:   {builtinPow, 2, 2},
 	ast.Rand:    {builtinRand, 0, 1},
 	ast.Round:   {builtinRound, 1, 2},
+	ast.Sqrt:    {builtinSqrt, 1, 1},
 	ast.Conv:    {builtinConv, 3, 3},
-	ast.CRC32:   {builtinCRC32, 1, 1},
+	ast.Sin:     {builtinSin, 1, 1},
+	ast.Cos:     {builtinCos, 1, 1},
+	ast.Tan:     {builtinTan, 1, 1},
+	ast.Cot:     {builtinCot, 1, 1},
 
 	// time functions
 	ast.Curdate:          {builtinCurrentDate, 0, 0},
 	ast.CurrentDate:      {builtinCurrentDate, 0, 0},
 	ast.CurrentTime:      {builtinCurrentTime, 0, 1},
-	ast.Date:             {builtinDate, 1, 1},
 	ast.DateArith:        {builtinDateArith, 3, 3},
 	ast.DateFormat:       {builtinDateFormat, 2, 2},
 	ast.CurrentTimestamp: {builtinNow, 0, 1},
 	ast.Curtime:          {builtinCurrentTime, 0, 1},
-	ast.Day:              {builtinDay, 1, 1},
 	ast.DayName:          {builtinDayName, 1, 1},
-	ast.DayOfMonth:       {builtinDayOfMonth, 1, 1},
 	ast.DayOfWeek:        {builtinDayOfWeek, 1, 1},
 	ast.DayOfYear:        {builtinDayOfYear, 1, 1},
 	ast.Extract:          {builtinExtract, 2, 2},
-	ast.Hour:             {builtinHour, 1, 1},
-	ast.MicroSecond:      {builtinMicroSecond, 1, 1},
-	ast.Minute:           {builtinMinute, 1, 1},
-	ast.Month:            {builtinMonth, 1, 1},
 	ast.MonthName:        {builtinMonthName, 1, 1},
 	ast.Now:              {builtinNow, 0, 1},
-	ast.Second:           {builtinSecond, 1, 1},
 	ast.StrToDate:        {builtinStrToDate, 2, 2},
 	ast.Sysdate:          {builtinSysDate, 0, 1},
-	ast.Time:             {builtinTime, 1, 1},
 	ast.UTCDate:          {builtinUTCDate, 0, 0},
 	ast.Week:             {builtinWeek, 1, 2},
 	ast.Weekday:          {builtinWeekDay, 1, 1},
 	ast.WeekOfYear:       {builtinWeekOfYear, 1, 1},
-	ast.Year:             {builtinYear, 1, 1},
 	ast.YearWeek:         {builtinYearWeek, 1, 2},
 	ast.FromUnixTime:     {builtinFromUnixTime, 1, 2},
-	ast.TimeDiff:         {builtinTimeDiff, 2, 2},
 
 	// string functions
-	ast.ASCII:          {builtinASCII, 1, 1},
-	ast.Concat:         {builtinConcat, 1, -1},
-	ast.ConcatWS:       {builtinConcatWS, 2, -1},
-	ast.Convert:        {builtinConvert, 2, 2},
-	ast.Lcase:          {builtinLower, 1, 1},
-	ast.Left:           {builtinLeft, 2, 2},
-	ast.Length:         {builtinLength, 1, 1},
-	ast.Locate:         {builtinLocate, 2, 3},
-	ast.Lower:          {builtinLower, 1, 1},
-	ast.Ltrim:          {trimFn(strings.TrimLeft, spaceChars), 1, 1},
-	ast.Repeat:         {builtinRepeat, 2, 2},
-	ast.Replace:        {builtinReplace, 3, 3},
-	ast.Reverse:        {builtinReverse, 1, 1},
-	ast.Rtrim:          {trimFn(strings.TrimRight, spaceChars), 1, 1},
-	ast.Space:          {builtinSpace, 1, 1},
-	ast.Strcmp:         {builtinStrcmp, 2, 2},
-	ast.Substring:      {builtinSubstring, 2, 3},
-	ast.SubstringIndex: {builtinSubstringIndex, 3, 3},
-	ast.Trim:           {builtinTrim, 1, 3},
-	ast.Upper:          {builtinUpper, 1, 1},
-	ast.Ucase:          {builtinUpper, 1, 1},
-	ast.Hex:            {builtinHex, 1, 1},
-	ast.Unhex:          {builtinUnHex, 1, 1},
-	ast.Rpad:           {builtinRpad, 3, 3},
-	ast.BitLength:      {builtinBitLength, 1, 1},
-	ast.CharFunc:       {builtinChar, 2, -1},
-	ast.CharLength:     {builtinCharLength, 1, 1},
+	ast.ASCII:     {builtinASCII, 1, 1},
+	ast.Convert:   {builtinConvert, 2, 2},
+	ast.Length:    {builtinLength, 1, 1},
+	ast.Locate:    {builtinLocate, 2, 3},
+	ast.Ltrim:     {trimFn(strings.TrimLeft, spaceChars), 1, 1},
+	ast.Repeat:    {builtinRepeat, 2, 2},
+	ast.Replace:   {builtinReplace, 3, 3},
+	ast.Rtrim:     {trimFn(strings.TrimRight, spaceChars), 1, 1},
+	ast.Space:     {builtinSpace, 1, 1},
+	ast.Trim:      {builtinTrim, 1, 3},
+	ast.Hex:       {builtinHex, 1, 1},
+	ast.Unhex:     {builtinUnHex, 1, 1},
+	ast.BitLength: {builtinBitLength, 1, 1},
+	ast.CharFunc:  {builtinChar, 2, -1},
 
 	// information functions
 	ast.ConnectionID: {builtinConnectionID, 0, 0},
@@ -219,54 +242,165 @@ var Funcs = map[string]Func{
 
 	// control functions
 	ast.If:     {builtinIf, 3, 3},
-	ast.Ifnull: {builtinIfNull, 2, 2},
 	ast.Nullif: {builtinNullIf, 2, 2},
 
-	// miscellaneous functions
-	ast.Sleep: {builtinSleep, 1, 1},
-
-	// get_lock() and release_lock() is parsed but do nothing.
-	// It is used for preventing error in Ruby's activerecord migrations.
-	ast.GetLock:     {builtinLock, 2, 2},
-	ast.ReleaseLock: {builtinReleaseLock, 1, 1},
-
 	// only used by new plan
-	ast.AndAnd:     {builtinAndAnd, 2, 2},
-	ast.OrOr:       {builtinOrOr, 2, 2},
-	ast.GE:         {compareFuncFactory(opcode.GE), 2, 2},
-	ast.LE:         {compareFuncFactory(opcode.LE), 2, 2},
-	ast.EQ:         {compareFuncFactory(opcode.EQ), 2, 2},
-	ast.NE:         {compareFuncFactory(opcode.NE), 2, 2},
-	ast.LT:         {compareFuncFactory(opcode.LT), 2, 2},
-	ast.GT:         {compareFuncFactory(opcode.GT), 2, 2},
-	ast.NullEQ:     {compareFuncFactory(opcode.NullEQ), 2, 2},
-	ast.Plus:       {arithmeticFuncFactory(opcode.Plus), 2, 2},
-	ast.Minus:      {arithmeticFuncFactory(opcode.Minus), 2, 2},
 	ast.Mod:        {arithmeticFuncFactory(opcode.Mod), 2, 2},
-	ast.Div:        {arithmeticFuncFactory(opcode.Div), 2, 2},
-	ast.Mul:        {arithmeticFuncFactory(opcode.Mul), 2, 2},
-	ast.IntDiv:     {arithmeticFuncFactory(opcode.IntDiv), 2, 2},
 	ast.LeftShift:  {bitOpFactory(opcode.LeftShift), 2, 2},
 	ast.RightShift: {bitOpFactory(opcode.RightShift), 2, 2},
 	ast.And:        {bitOpFactory(opcode.And), 2, 2},
 	ast.Or:         {bitOpFactory(opcode.Or), 2, 2},
 	ast.Xor:        {bitOpFactory(opcode.Xor), 2, 2},
-	ast.LogicXor:   {builtinLogicXor, 2, 2},
-	ast.UnaryNot:   {unaryOpFactory(opcode.Not), 1, 1},
 	ast.BitNeg:     {unaryOpFactory(opcode.BitNeg), 1, 1},
 	ast.UnaryPlus:  {unaryOpFactory(opcode.Plus), 1, 1},
-	ast.UnaryMinus: {unaryOpFactory(opcode.Minus), 1, 1},
-	ast.In:         {builtinIn, 1, -1},
 	ast.IsTruth:    {isTrueOpFactory(opcode.IsTruth), 1, 1},
 	ast.IsFalsity:  {isTrueOpFactory(opcode.IsFalsity), 1, 1},
-	ast.Like:       {builtinLike, 3, 3},
-	ast.Regexp:     {builtinRegexp, 2, 2},
-	ast.Case:       {builtinCaseWhen, 1, -1},
 	ast.RowFunc:    {builtinRow, 2, -1},
 	ast.SetVar:     {builtinSetVar, 2, 2},
 	ast.GetVar:     {builtinGetVar, 1, 1},
 }
 
+// funcs holds all registered builtin function classes.
+// A function registered here takes precedence over the one in Funcs.
+var funcs = map[string]functionClass{
+	// common functions
+	ast.Coalesce: &coalesceFuncClass{baseFuncClass{ast.Coalesce, 1, -1}},
+	ast.IsNull:   &isNullFuncClass{baseFuncClass{ast.IsNull, 1, 1}},
+	ast.Interval: &intervalFuncClass{baseFuncClass{ast.Interval, 2, -1}},
+	ast.Greatest: &greatestFuncClass{baseFuncClass{ast.Greatest, 2, -1}},
+	ast.Least:    &leastFuncClass{baseFuncClass{ast.Least, 2, -1}},
+
+	// control functions
+	ast.Ifnull: &ifNullFuncClass{baseFuncClass{ast.Ifnull, 2, 2}},
+
+	// comparison operators
+	ast.EQ:         &compareFuncClass{baseFuncClass{ast.EQ, 2, 2}, opcode.EQ},
+	ast.NE:         &compareFuncClass{baseFuncClass{ast.NE, 2, 2}, opcode.NE},
+	ast.LT:         &compareFuncClass{baseFuncClass{ast.LT, 2, 2}, opcode.LT},
+	ast.LE:         &compareFuncClass{baseFuncClass{ast.LE, 2, 2}, opcode.LE},
+	ast.GT:         &compareFuncClass{baseFuncClass{ast.GT, 2, 2}, opcode.GT},
+	ast.GE:         &compareFuncClass{baseFuncClass{ast.GE, 2, 2}, opcode.GE},
+	ast.Between:    &betweenFuncClass{baseFuncClass{ast.Between, 3, 3}, false},
+	ast.NotBetween: &betweenFuncClass{baseFuncClass{ast.NotBetween, 3, 3}, true},
+	ast.In:         &inFuncClass{baseFuncClass{ast.In, 1, -1}},
+	ast.Like:       &likeFuncClass{baseFuncClass{ast.Like, 3, 3}},
+	ast.NullEQ:     &nullEqFuncClass{baseFuncClass{ast.NullEQ, 2, 2}},
+	ast.Regexp:     &regexpFuncClass{baseFuncClass{ast.Regexp, 2, 2}},
+
+	// logical operators
+	ast.AndAnd:   &andFuncClass{baseFuncClass{ast.AndAnd, 2, 2}},
+	ast.OrOr:     &orFuncClass{baseFuncClass{ast.OrOr, 2, 2}},
+	ast.LogicXor: &xorFuncClass{baseFuncClass{ast.LogicXor, 2, 2}},
+	ast.UnaryNot: &notFuncClass{baseFuncClass{ast.UnaryNot, 1, 1}},
+
+	// arithmetic operators
+	ast.Plus:   &plusFuncClass{baseFuncClass{ast.Plus, 2, 2}},
+	ast.Minus:  &minusFuncClass{baseFuncClass{ast.Minus, 2, 2}},
+	ast.Mul:    &mulFuncClass{baseFuncClass{ast.Mul, 2, 2}},
+	ast.Div:    &divFuncClass{baseFuncClass{ast.Div, 2, 2}},
+	ast.IntDiv: &intDivFuncClass{baseFuncClass{ast.IntDiv, 2, 2}},
+
+	ast.UnaryMinus: &unaryMinusFuncClass{baseFuncClass{ast.UnaryMinus, 1, 1}},
+
+	// math functions
+	ast.CRC32: &crc32FuncClass{baseFuncClass{ast.CRC32, 1, 1}},
+
+	// information functions
+	ast.Charset:      &charsetFuncClass{baseFuncClass{ast.Charset, 1, 1}},
+	ast.Collate:      &collateFuncClass{baseFuncClass{ast.Collate, 2, 2}},
+	ast.Coercibility: &coercibilityFuncClass{baseFuncClass{ast.Coercibility, 1, 1}},
+	ast.Collation:    &collationFuncClass{baseFuncClass{ast.Collation, 1, 1}},
+	ast.CurrentRole:  &currentRoleFuncClass{baseFuncClass{ast.CurrentRole, 0, 0}},
+
+	// control functions
+	ast.Case: &caseWhenFuncClass{baseFuncClass{ast.Case, 1, -1}},
+
+	// string functions
+	ast.Concat:         &concatFuncClass{baseFuncClass{ast.Concat, 1, -1}},
+	ast.ConcatWS:       &concatWSFuncClass{baseFuncClass{ast.ConcatWS, 2, -1}},
+	ast.CharLength:     &charLengthFuncClass{baseFuncClass{ast.CharLength, 1, 1}},
+	ast.Left:           &leftFuncClass{baseFuncClass{ast.Left, 2, 2}},
+	ast.Reverse:        &reverseFuncClass{baseFuncClass{ast.Reverse, 1, 1}},
+	ast.Substring:      &substringFuncClass{baseFuncClass{ast.Substring, 2, 3}},
+	ast.WeightString:   &weightStringFuncClass{baseFuncClass{ast.WeightString, 1, 3}},
+	ast.ToBase64:       &toBase64FuncClass{baseFuncClass{ast.ToBase64, 1, 1}},
+	ast.FromBase64:     &fromBase64FuncClass{baseFuncClass{ast.FromBase64, 1, 1}},
+	ast.MakeSet:        &makeSetFuncClass{baseFuncClass{ast.MakeSet, 2, -1}},
+	ast.ExportSet:      &exportSetFuncClass{baseFuncClass{ast.ExportSet, 3, 5}},
+	ast.Bin:            &binFuncClass{baseFuncClass{ast.Bin, 1, 1}},
+	ast.Oct:            &octFuncClass{baseFuncClass{ast.Oct, 1, 1}},
+	ast.Lower:          &lowerFuncClass{baseFuncClass{ast.Lower, 1, 1}},
+	ast.Lcase:          &lowerFuncClass{baseFuncClass{ast.Lcase, 1, 1}},
+	ast.Upper:          &upperFuncClass{baseFuncClass{ast.Upper, 1, 1}},
+	ast.Ucase:          &upperFuncClass{baseFuncClass{ast.Ucase, 1, 1}},
+	ast.Ord:            &ordFuncClass{baseFuncClass{ast.Ord, 1, 1}},
+	ast.SubstringIndex: &substringIndexFuncClass{baseFuncClass{ast.SubstringIndex, 3, 3}},
+	ast.Strcmp:         &strcmpFuncClass{baseFuncClass{ast.Strcmp, 2, 2}},
+	ast.Lpad:           &lpadFuncClass{baseFuncClass{ast.Lpad, 3, 3}},
+	ast.Rpad:           &rpadFuncClass{baseFuncClass{ast.Rpad, 3, 3}},
+
+	// time functions
+	ast.Date:         &dateFuncClass{baseFuncClass{ast.Date, 1, 1}},
+	ast.Day:          &dayFuncClass{baseFuncClass{ast.Day, 1, 1}},
+	ast.DayOfMonth:   &dayOfMonthFuncClass{baseFuncClass{ast.DayOfMonth, 1, 1}},
+	ast.Hour:         &hourFuncClass{baseFuncClass{ast.Hour, 1, 1}},
+	ast.MicroSecond:  &microSecondFuncClass{baseFuncClass{ast.MicroSecond, 1, 1}},
+	ast.Minute:       &minuteFuncClass{baseFuncClass{ast.Minute, 1, 1}},
+	ast.Month:        &monthFuncClass{baseFuncClass{ast.Month, 1, 1}},
+	ast.Second:       &secondFuncClass{baseFuncClass{ast.Second, 1, 1}},
+	ast.SecToTime:    &secToTimeFuncClass{baseFuncClass{ast.SecToTime, 1, 1}},
+	ast.Time:         &timeFuncClass{baseFuncClass{ast.Time, 1, 1}},
+	ast.TimeDiff:     &timeDiffFuncClass{baseFuncClass{ast.TimeDiff, 2, 2}},
+	ast.AddDate:      &adddateFuncClass{baseFuncClass{ast.AddDate, 2, 2}},
+	ast.SubDate:      &subdateFuncClass{baseFuncClass{ast.SubDate, 2, 2}},
+	ast.AddTime:      &addTimeFuncClass{baseFuncClass{ast.AddTime, 2, 2}},
+	ast.TimestampAdd: &timestampAddFuncClass{baseFuncClass{ast.TimestampAdd, 3, 3}},
+	ast.Year:         &yearFuncClass{baseFuncClass{ast.Year, 1, 1}},
+
+	// encryption and compression functions
+	ast.Encode:                   &encodeFuncClass{baseFuncClass{ast.Encode, 2, 2}},
+	ast.Decode:                   &decodeFuncClass{baseFuncClass{ast.Decode, 2, 2}},
+	ast.Password:                 &passwordFuncClass{baseFuncClass{ast.Password, 1, 1}},
+	ast.OldPassword:              &oldPasswordFuncClass{baseFuncClass{ast.OldPassword, 1, 1}},
+	ast.ValidatePasswordStrength: &validatePasswordStrengthFuncClass{baseFuncClass{ast.ValidatePasswordStrength, 1, 1}},
+
+	// miscellaneous functions
+	ast.Sleep:     &sleepFuncClass{baseFuncClass{ast.Sleep, 1, 1}},
+	ast.UUID:      &uuidFuncClass{baseFuncClass{ast.UUID, 0, 0}},
+	ast.UUIDToBin: &uuidToBinFuncClass{baseFuncClass{ast.UUIDToBin, 1, 2}},
+	ast.BinToUUID: &binToUUIDFuncClass{baseFuncClass{ast.BinToUUID, 1, 2}},
+	ast.Benchmark: &benchmarkFuncClass{baseFuncClass{ast.Benchmark, 2, 2}},
+	ast.AnyValue:  &anyValueFuncClass{baseFuncClass{ast.AnyValue, 1, 1}},
+	ast.NameConst: &nameConstFuncClass{baseFuncClass{ast.NameConst, 2, 2}},
+	ast.Grouping:  &groupingFuncClass{baseFuncClass{ast.Grouping, 1, -1}},
+
+	// performance schema functions
+	ast.FormatBytes:    &formatBytesFuncClass{baseFuncClass{ast.FormatBytes, 1, 1}},
+	ast.FormatPicoTime: &formatPicoTimeFuncClass{baseFuncClass{ast.FormatPicoTime, 1, 1}},
+
+	// GTID functions
+	ast.GTIDSubset:   &gtidSubsetFuncClass{baseFuncClass{ast.GTIDSubset, 2, 2}},
+	ast.GTIDSubtract: &gtidSubtractFuncClass{baseFuncClass{ast.GTIDSubtract, 2, 2}},
+
+	// json functions
+	ast.JSONPretty:      &jsonPrettyFuncClass{baseFuncClass{ast.JSONPretty, 1, 1}},
+	ast.JSONStorageSize: &jsonStorageSizeFuncClass{baseFuncClass{ast.JSONStorageSize, 1, 1}},
+	ast.JSONStorageFree: &jsonStorageFreeFuncClass{baseFuncClass{ast.JSONStorageFree, 1, 1}},
+	ast.JSONQuote:       &jsonQuoteFuncClass{baseFuncClass{ast.JSONQuote, 1, 1}},
+	ast.JSONSearch:      &jsonSearchFuncClass{baseFuncClass{ast.JSONSearch, 3, -1}},
+	ast.JSONRemove:      &jsonRemoveFuncClass{baseFuncClass{ast.JSONRemove, 2, -1}},
+	ast.JSONArrayAppend: &jsonArrayAppendFuncClass{jsonPathValueFuncClass{baseFuncClass{ast.JSONArrayAppend, 3, -1}}},
+	ast.JSONArrayInsert: &jsonArrayInsertFuncClass{jsonPathValueFuncClass{baseFuncClass{ast.JSONArrayInsert, 3, -1}}},
+
+	// locking functions
+	ast.GetLock:     &getLockFuncClass{baseFuncClass{ast.GetLock, 2, 2}},
+	ast.ReleaseLock: &releaseLockFuncClass{baseFuncClass{ast.ReleaseLock, 1, 1}},
+	ast.IsFreeLock:  &isFreeLockFuncClass{baseFuncClass{ast.IsFreeLock, 1, 1}},
+	ast.IsUsedLock:  &isUsedLockFuncClass{baseFuncClass{ast.IsUsedLock, 1, 1}},
+
+	ast.ReleaseAllLocks: &releaseAllLocksFuncClass{baseFuncClass{ast.ReleaseAllLocks, 0, 0}},
+}
+
 // DynamicFuncs are those functions that
 // use input parameter ctx or
 // return an uncertain result would not be constant folded
@@ -276,29 +410,279 @@ var DynamicFuncs = map[string]int{
 	"connection_id":  0,
 	"current_user":   0,
 	"database":       0,
+	"schema":         0,
 	"found_rows":     0,
 	"last_insert_id": 0,
 	"user":           0,
 	"version":        0,
-	"sleep":          0,
 	ast.GetVar:       0,
 	ast.SetVar:       0,
 	ast.Values:       0,
+
+	// time functions returning the current time
+	ast.Curdate:          0,
+	ast.CurrentDate:      0,
+	ast.CurrentTime:      0,
+	ast.CurrentTimestamp: 0,
+	ast.Curtime:          0,
+	ast.Now:              0,
+	ast.Sysdate:          0,
+	ast.UTCDate:          0,
+}
+
+// customFuncs holds the functions registered by RegisterFunction.
+var customFuncs = struct {
+	sync.RWMutex
+	m map[string]functionClass
+}{m: make(map[string]functionClass)}
+
+// RegisterFunction registers a custom scalar function, so that it can be built by NewFunction like a builtin function.
+// It returns an error if the name is used by a builtin function or another registered function.
+// A custom function is never constant folded, since it may depend on the context.
+func RegisterFunction(name string, fn Func) error {
+	name = strings.ToLower(name)
+	if _, ok := funcs[name]; ok {
+		return errors.Errorf("function %s is a builtin function", name)
+	}
+	if _, ok := Funcs[name]; ok {
+		return errors.Errorf("function %s is a builtin function", name)
+	}
+	customFuncs.Lock()
+	defer customFuncs.Unlock()
+	if _, ok := customFuncs.m[name]; ok {
+		return errors.Errorf("function %s is already registered", name)
+	}
+	customFuncs.m[name] = &customFuncClass{baseFuncClass{name, fn.MinArgs, fn.MaxArgs}, fn.F}
+	return nil
+}
+
+// unregisterFunction removes a function registered by RegisterFunction, it's used by tests.
+func unregisterFunction(name string) {
+	customFuncs.Lock()
+	delete(customFuncs.m, strings.ToLower(name))
+	customFuncs.Unlock()
+}
+
+// FuncInfo describes a function that can be built by NewFunction.
+type FuncInfo struct {
+	Name string
+	// MinArgs is the minimal arguments needed,
+	MinArgs int
+	// MaxArgs is the maximal arguments needed, -1 for infinity.
+	MaxArgs int
+}
+
+// ListFunctions returns the builtin functions and the functions registered by RegisterFunction, sorted by name.
+func ListFunctions() []FuncInfo {
+	infos := make([]FuncInfo, 0, len(funcs)+len(Funcs))
+	for name, fc := range funcs {
+		minArgs, maxArgs := fc.argsRange()
+		infos = append(infos, FuncInfo{Name: name, MinArgs: minArgs, MaxArgs: maxArgs})
+	}
+	for name, f := range Funcs {
+		if _, ok := funcs[name]; ok {
+			continue
+		}
+		infos = append(infos, FuncInfo{Name: name, MinArgs: f.MinArgs, MaxArgs: f.MaxArgs})
+	}
+	customFuncs.RLock()
+	for name, fc := range customFuncs.m {
+		minArgs, maxArgs := fc.argsRange()
+		infos = append(infos, FuncInfo{Name: name, MinArgs: minArgs, MaxArgs: maxArgs})
+	}
+	customFuncs.RUnlock()
+	sort.Sort(funcInfosByName(infos))
+	return infos
+}
+
+type funcInfosByName []FuncInfo
+
+func (s funcInfosByName) Len() int           { return len(s) }
+func (s funcInfosByName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+func (s funcInfosByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// getCustomFunc returns the function class of the function registered by RegisterFunction.
+func getCustomFunc(name string) (functionClass, bool) {
+	customFuncs.RLock()
+	defer customFuncs.RUnlock()
+	fc, ok := customFuncs.m[name]
+	return fc, ok
+}
+
+type customFuncClass struct {
+	baseFuncClass
+	fn BuiltinFunc
+}
+
+func (c *customFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinCustom{newBaseBuiltinFunc(args, ctx), c.fn}
+	return sig.setSelf(sig), nil
+}
+
+type builtinCustom struct {
+	baseBuiltinFunc
+	fn BuiltinFunc
+}
+
+// IsDeterministic implements builtinFunc interface, custom functions are not constant folded.
+func (b *builtinCustom) IsDeterministic() bool {
+	return false
+}
+
+func (b *builtinCustom) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	d, err = b.fn(args, b.ctx)
+	return d, errors.Trace(err)
+}
+
+type coalesceFuncClass struct {
+	baseFuncClass
+}
+
+func (c *coalesceFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinCoalesce{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinCoalesce struct {
+	baseBuiltinFunc
 }
 
 // See http://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_coalesce
-func builtinCoalesce(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-	for _, d = range args {
-		if !d.IsNull() {
-			return d, nil
+func (b *builtinCoalesce) eval(row []types.Datum) (types.Datum, error) {
+	d, err := b.evalFirstNonNull(row)
+	return d, errors.Trace(err)
+}
+
+// evalFirstNonNull evaluates the arguments from left to right and stops at the first non-NULL one,
+// so the arguments after it, which may be subqueries or expensive functions, are not evaluated.
+// It returns NULL if all the arguments are NULL. COALESCE, IFNULL and ISNULL all use it to decide
+// whether a value is NULL: the zero date '0000-00-00' is a value rather than NULL in any sql_mode,
+// NO_ZERO_DATE and NO_ZERO_IN_DATE only reject it when it is stored.
+func (b *baseBuiltinFunc) evalFirstNonNull(row []types.Datum) (d types.Datum, err error) {
+	for _, arg := range b.args {
+		d, err = arg.Eval(row, b.ctx)
+		if err != nil || !d.IsNull() {
+			return d, errors.Trace(err)
 		}
 	}
 	return d, nil
 }
 
-// See https://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_isnull
-func builtinIsNull(args []types.Datum, _ context.Context) (d types.Datum, err error) {
+type intervalFuncClass struct {
+	baseFuncClass
+}
+
+func (c *intervalFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinInterval{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinInterval struct {
+	baseBuiltinFunc
+}
+
+// eval returns 0 if N < N1, 1 if N < N2 and so on or -1 if N is NULL.
+// N1, N2, ... are required to be in ascending order, so a binary search is used to find the position of N.
+// A NULL Ni is compared as 0 like MySQL, which may break the order, so N is searched linearly then.
+// See https://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_interval
+func (b *builtinInterval) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
 	if args[0].IsNull() {
+		d.SetInt64(-1)
+		return d, nil
+	}
+	sc := b.ctx.GetSessionVars().StmtCtx
+	// The arguments are compared as numbers.
+	hasNull := false
+	for i, arg := range args {
+		switch arg.Kind() {
+		case types.KindNull:
+			hasNull = true
+			args[i].SetInt64(0)
+		case types.KindInt64, types.KindUint64, types.KindFloat32, types.KindFloat64, types.KindMysqlDecimal:
+		default:
+			f, err := arg.ToFloat64(sc)
+			if err != nil {
+				return d, errors.Trace(err)
+			}
+			args[i].SetFloat64(f)
+		}
+	}
+	if hasNull {
+		idx := 0
+		for ; idx < len(args)-1; idx++ {
+			cmp, err := args[idx+1].CompareDatum(sc, args[0])
+			if err != nil {
+				return d, errors.Trace(err)
+			}
+			if cmp > 0 {
+				break
+			}
+		}
+		d.SetInt64(int64(idx))
+		return d, nil
+	}
+	// Find the first Ni which is greater than N.
+	var cmpErr error
+	idx := sort.Search(len(args)-1, func(i int) bool {
+		if cmpErr != nil {
+			return false
+		}
+		cmp, err := args[i+1].CompareDatum(sc, args[0])
+		if err != nil {
+			cmpErr = err
+			return false
+		}
+		return cmp > 0
+	})
+	if cmpErr != nil {
+		return d, errors.Trace(cmpErr)
+	}
+	d.SetInt64(int64(idx))
+	return d, nil
+}
+
+type isNullFuncClass struct {
+	baseFuncClass
+}
+
+func (c *isNullFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinIsNull{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinIsNull struct {
+	baseBuiltinFunc
+}
+
+// eval returns 1 if the argument is NULL and 0 otherwise, it never returns NULL.
+// The zero date '0000-00-00' is a value rather than NULL, so ISNULL of it is 0 in any sql_mode.
+// See https://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_isnull
+func (b *builtinIsNull) eval(row []types.Datum) (d types.Datum, err error) {
+	arg, err := b.evalFirstNonNull(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if arg.IsNull() {
 		d.SetInt64(1)
 	} else {
 		d.SetInt64(0)
@@ -306,52 +690,178 @@ func builtinIsNull(args []types.Datum, _ context.Context) (d types.Datum, err er
 	return d, nil
 }
 
+type greatestFuncClass struct {
+	baseFuncClass
+}
+
+func (c *greatestFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinGreatest{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinGreatest struct {
+	baseBuiltinFunc
+}
+
 // See http://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_greatest
-func builtinGreatest(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-	if args[0].IsNull() {
-		return
+func (b *builtinGreatest) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
 	}
-	max := 0
-	sc := ctx.GetSessionVars().StmtCtx
-	for i := 1; i < len(args); i++ {
+	return selectExtremum(args, b.ctx, 1)
+}
+
+type leastFuncClass struct {
+	baseFuncClass
+}
+
+func (c *leastFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinLeast{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinLeast struct {
+	baseBuiltinFunc
+}
+
+// See http://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_least
+func (b *builtinLeast) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	return selectExtremum(args, b.ctx, -1)
+}
+
+// selectExtremum returns the greatest argument if sign is 1, or the least one if sign is -1.
+// The arguments are compared as decimals if they are integers or decimals and at least one of them is a decimal.
+// They are compared as strings if temporal values are mixed with strings, like MySQL does, a DATE, DATETIME,
+// TIMESTAMP or TIME is only compared as a temporal value if all the arguments are temporal. So the result is a string,
+// e.g. GREATEST(DATE '2017-03-01', 'abc') is 'abc', even if a string looks like a date. Otherwise they're compared
+// by CompareDatum.
+// Any NULL argument makes the result NULL, unless the session sets GreatestLeastIgnoreNull,
+// then the NULL arguments are skipped and NULL is returned only if all the arguments are NULL.
+func selectExtremum(args []types.Datum, ctx context.Context, sign int) (d types.Datum, err error) {
+	sessVars := ctx.GetSessionVars()
+	sc := sessVars.StmtCtx
+	frac, isDecimal := decimalExtremumFrac(args)
+	if isDecimal {
+		args, err = decimalArgs(sc, args)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+	} else if hasTemporalAndString(args) {
+		args, err = stringArgs(args)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+	}
+	idx := -1
+	for i := range args {
 		if args[i].IsNull() {
-			return
+			if sessVars.GreatestLeastIgnoreNull {
+				continue
+			}
+			return d, nil
+		}
+		if idx == -1 {
+			idx = i
+			continue
 		}
 
 		var cmp int
-		if cmp, err = args[i].CompareDatum(sc, args[max]); err != nil {
-			return
+		if cmp, err = args[i].CompareDatum(sc, args[idx]); err != nil {
+			return d, errors.Trace(err)
 		}
 
-		if cmp > 0 {
-			max = i
+		if cmp*sign > 0 {
+			idx = i
 		}
 	}
-	d = args[max]
-	return
+	if idx == -1 {
+		return d, nil
+	}
+	if !isDecimal {
+		return args[idx], nil
+	}
+	// The result has the max scale of the arguments, e.g. GREATEST(1.50, 2) is 2.00.
+	dec := new(types.MyDecimal)
+	if err = args[idx].GetMysqlDecimal().Round(dec, frac); err != nil {
+		return d, errors.Trace(err)
+	}
+	d.SetMysqlDecimal(dec)
+	return d, nil
 }
 
-// See http://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_least
-func builtinLeast(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-	if args[0].IsNull() {
-		return
+// decimalExtremumFrac checks whether GREATEST or LEAST is evaluated in the DECIMAL context,
+// that is all the arguments are integers or decimals and there is at least one decimal.
+// It returns the max scale of the decimal arguments.
+func decimalExtremumFrac(args []types.Datum) (frac int, isDecimal bool) {
+	for _, arg := range args {
+		switch arg.Kind() {
+		case types.KindNull, types.KindInt64, types.KindUint64:
+		case types.KindMysqlDecimal:
+			isDecimal = true
+			if _, f := arg.GetMysqlDecimal().PrecisionAndFrac(); f > frac {
+				frac = f
+			}
+		default:
+			return 0, false
+		}
 	}
-	min := 0
-	sc := ctx.GetSessionVars().StmtCtx
-	for i := 1; i < len(args); i++ {
-		if args[i].IsNull() {
-			return
+	return frac, isDecimal
+}
+
+// hasTemporalAndString checks whether the arguments of GREATEST or LEAST mix temporal values and strings,
+// they are compared as strings then.
+func hasTemporalAndString(args []types.Datum) bool {
+	var hasTemporal, hasString bool
+	for _, arg := range args {
+		switch arg.Kind() {
+		case types.KindMysqlTime, types.KindMysqlDuration:
+			hasTemporal = true
+		case types.KindString, types.KindBytes:
+			hasString = true
 		}
+	}
+	return hasTemporal && hasString
+}
 
-		var cmp int
-		if cmp, err = args[i].CompareDatum(sc, args[min]); err != nil {
-			return
+// stringArgs converts the non-NULL arguments to strings, so a temporal value is compared with its string form.
+func stringArgs(args []types.Datum) ([]types.Datum, error) {
+	strs := make([]types.Datum, len(args))
+	for i, arg := range args {
+		if arg.IsNull() {
+			continue
+		}
+		str, err := arg.ToString()
+		if err != nil {
+			return nil, errors.Trace(err)
 		}
+		strs[i].SetString(str)
+	}
+	return strs, nil
+}
 
-		if cmp < 0 {
-			min = i
+// decimalArgs converts the non-NULL arguments to decimals, so they are compared exactly.
+func decimalArgs(sc *variable.StatementContext, args []types.Datum) ([]types.Datum, error) {
+	decs := make([]types.Datum, len(args))
+	for i, arg := range args {
+		if arg.IsNull() {
+			continue
+		}
+		dec, err := arg.ToDecimal(sc)
+		if err != nil {
+			return nil, errors.Trace(err)
 		}
+		decs[i].SetMysqlDecimal(dec)
 	}
-	d = args[min]
-	return
+	return decs, nil
 }
@@ -16,35 +16,117 @@ package expression
 import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/types"
 )
 
-// See https://dev.mysql.com/doc/refman/5.7/en/case.html
-func builtinCaseWhen(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-	sc := ctx.GetSessionVars().StmtCtx
+type caseWhenFuncClass struct {
+	baseFuncClass
+}
+
+func (c *caseWhenFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinCaseWhen{newBaseBuiltinFunc(args, ctx), caseWhenRetType(args)}
+	return sig.setSelf(sig), nil
+}
+
+// caseWhenRetType returns the aggregated type of all the THEN and ELSE branches of a CASE expression.
+// The args are in the searched form: cond1, result1, cond2, result2, ..., [else].
+func caseWhenRetType(args []Expression) *types.FieldType {
+	var results []Expression
 	l := len(args)
+	for i := 1; i < l; i += 2 {
+		results = append(results, args[i])
+	}
+	if l%2 == 1 {
+		results = append(results, args[l-1])
+	}
+	tp := types.NewFieldType(mysql.TypeUnspecified)
+	for _, result := range results {
+		t := result.GetType()
+		if t == nil || t.Tp == mysql.TypeNull {
+			continue
+		}
+		if tp.Tp == mysql.TypeUnspecified {
+			tp.Tp, tp.Flag = t.Tp, t.Flag
+			tp.Charset, tp.Collate = t.Charset, t.Collate
+			continue
+		}
+		mtp := types.MergeFieldType(tp.Tp, t.Tp)
+		if mtp == t.Tp && mtp != tp.Tp {
+			tp.Charset, tp.Collate = t.Charset, t.Collate
+		}
+		if mysql.HasUnsignedFlag(tp.Flag) != mysql.HasUnsignedFlag(t.Flag) {
+			tp.Flag &^= mysql.UnsignedFlag
+		}
+		tp.Tp = mtp
+	}
+	return tp
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/case.html
+// The simple form CASE value WHEN v THEN r is rewritten to the searched form CASE WHEN value = v THEN r
+// by the planner, so the args are always: cond1, result1, cond2, result2, ..., [else].
+type builtinCaseWhen struct {
+	baseBuiltinFunc
+
+	// tp is the aggregated type of all the results.
+	tp *types.FieldType
+}
+
+// eval evaluates the conditions in order, and only evaluates the result of the first matched branch.
+func (b *builtinCaseWhen) eval(row []types.Datum) (d types.Datum, err error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	l := len(b.args)
 	for i := 0; i < l-1; i += 2 {
-		if args[i].IsNull() {
+		cond, err := b.args[i].Eval(row, b.ctx)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		if cond.IsNull() {
 			continue
 		}
-		b, err1 := args[i].ToBool(sc)
-		if err1 != nil {
-			return d, errors.Trace(err1)
+		t, err := cond.ToBool(sc)
+		if err != nil {
+			return d, errors.Trace(err)
 		}
-		if b == 1 {
-			d = args[i+1]
-			return
+		if t == 1 {
+			return b.evalResult(b.args[i+1], row)
 		}
 	}
 	// when clause(condition, result) -> args[i], args[i+1]; (i >= 0 && i+1 < l-1)
 	// else clause -> args[l-1]
 	// If case clause has else clause, l%2 == 1.
 	if l%2 == 1 {
-		d = args[l-1]
+		return b.evalResult(b.args[l-1], row)
 	}
 	return
 }
 
+// evalResult evaluates a result branch and converts it to the aggregated numeric or string type.
+func (b *builtinCaseWhen) evalResult(result Expression, row []types.Datum) (d types.Datum, err error) {
+	d, err = result.Eval(row, b.ctx)
+	if err != nil || d.IsNull() {
+		return d, errors.Trace(err)
+	}
+	switch b.tp.Tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong,
+		mysql.TypeNewDecimal, mysql.TypeFloat, mysql.TypeDouble:
+	case mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeString:
+		if d.Kind() == types.KindString || d.Kind() == types.KindBytes {
+			return d, nil
+		}
+	default:
+		return d, nil
+	}
+	tp := *b.tp
+	tp.Flen, tp.Decimal = types.UnspecifiedLength, types.UnspecifiedLength
+	d, err = d.ConvertTo(b.ctx.GetSessionVars().StmtCtx, &tp)
+	return d, errors.Trace(err)
+}
+
 // See https://dev.mysql.com/doc/refman/5.7/en/control-flow-functions.html#function_if
 func builtinIf(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
 	// if(expr1, expr2, expr3)
@@ -71,18 +153,27 @@ func builtinIf(args []types.Datum, ctx context.Context) (d types.Datum, err erro
 	return v3, nil
 }
 
-// See https://dev.mysql.com/doc/refman/5.7/en/control-flow-functions.html#function_ifnull
-func builtinIfNull(args []types.Datum, _ context.Context) (d types.Datum, err error) {
-	// ifnull(expr1, expr2)
-	// if expr1 is not null, return expr1, otherwise, return expr2
-	v1 := args[0]
-	v2 := args[1]
+type ifNullFuncClass struct {
+	baseFuncClass
+}
 
-	if !v1.IsNull() {
-		return v1, nil
+func (c *ifNullFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
 	}
+	sig := &builtinIfNull{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinIfNull struct {
+	baseBuiltinFunc
+}
 
-	return v2, nil
+// eval returns expr1 if it is not NULL, otherwise it returns expr2, which is only evaluated in that case.
+// See https://dev.mysql.com/doc/refman/5.7/en/control-flow-functions.html#function_ifnull
+func (b *builtinIfNull) eval(row []types.Datum) (types.Datum, error) {
+	d, err := b.evalFirstNonNull(row)
+	return d, errors.Trace(err)
 }
 
 // See https://dev.mysql.com/doc/refman/5.7/en/control-flow-functions.html#function_nullif
@@ -18,11 +18,109 @@ import (
 
 	. "github.com/pingcap/check"
 
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
 	"github.com/pingcap/tidb/util/types"
 )
 
+func (s *testEvaluatorSuite) TestCaseWhen(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		Arg []interface{}
+		Ret interface{}
+	}{
+		{[]interface{}{true, 1, true, 2, 3}, 1},
+		{[]interface{}{false, 1, true, 2, 3}, 2},
+		{[]interface{}{nil, 1, false, 2, 3}, 3},
+		{[]interface{}{false, 1, false, 2}, nil},
+		{[]interface{}{nil, 1, nil, 2}, nil},
+		{[]interface{}{false, 1, nil, 2, 3}, 3},
+		{[]interface{}{1, 1, 2, 2, 3}, 1},
+		{[]interface{}{0, 1, 1, 2}, 2},
+	}
+	fc := funcs[ast.Case]
+	for _, t := range tbl {
+		f, err := fc.getFunction(datumsToConstants(types.MakeDatums(t.Arg...)), s.ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.Ret))
+	}
+
+	// The simple form CASE 2 WHEN 1 THEN 'a' WHEN 2 THEN 'b' ELSE 'c' END is built on EQ conditions.
+	args := make([]Expression, 0, 5)
+	for i, r := range []string{"a", "b"} {
+		eq, err := NewFunction(ast.EQ, types.NewFieldType(mysql.TypeTiny), &Constant{Value: types.NewDatum(2)}, &Constant{Value: types.NewDatum(i + 1)})
+		c.Assert(err, IsNil)
+		args = append(args, eq, &Constant{Value: types.NewDatum(r)})
+	}
+	args = append(args, &Constant{Value: types.NewDatum("c")})
+	f, err := fc.getFunction(args, s.ctx)
+	c.Assert(err, IsNil)
+	d, err := f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(d, testutil.DatumEquals, types.NewDatum("b"))
+
+	// Only the matched branch is evaluated.
+	cond := &countingExpr{Constant: &Constant{Value: types.NewDatum(0)}}
+	args = []Expression{
+		cond, &panicExpr{&Constant{Value: types.NewDatum(1)}},
+		&Constant{Value: types.NewDatum(1)}, &Constant{Value: types.NewDatum(2)},
+		&panicExpr{&Constant{Value: types.NewDatum(3)}}, &panicExpr{&Constant{Value: types.NewDatum(4)}},
+		&panicExpr{&Constant{Value: types.NewDatum(5)}},
+	}
+	f, err = fc.getFunction(args, s.ctx)
+	c.Assert(err, IsNil)
+	d, err = f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(d, testutil.DatumEquals, types.NewDatum(2))
+	c.Assert(cond.count, Equals, 1)
+
+	_, err = fc.getFunction(nil, s.ctx)
+	c.Assert(err, NotNil)
+}
+
+func (s *testEvaluatorSuite) TestCaseWhenRetType(c *C) {
+	defer testleak.AfterTest(c)()
+	newConst := func(v interface{}, tp byte) Expression {
+		return &Constant{Value: types.NewDatum(v), RetType: types.NewFieldType(tp)}
+	}
+	tbl := []struct {
+		Arg   []Expression
+		RetTp byte
+		Ret   interface{}
+	}{
+		// CASE WHEN 1 THEN 1 ELSE 2 END
+		{[]Expression{newConst(1, mysql.TypeLonglong), newConst(1, mysql.TypeLonglong), newConst(2, mysql.TypeLonglong)},
+			mysql.TypeLonglong, int64(1)},
+		// CASE WHEN 1 THEN 1 ELSE 2.5 END
+		{[]Expression{newConst(1, mysql.TypeLonglong), newConst(1, mysql.TypeLonglong), newConst(types.NewDecFromFloatForTest(2.5), mysql.TypeNewDecimal)},
+			mysql.TypeNewDecimal, types.NewDecFromInt(1)},
+		// CASE WHEN 1 THEN 1 WHEN 0 THEN 1.5e0 END
+		{[]Expression{newConst(1, mysql.TypeLonglong), newConst(1, mysql.TypeLonglong), newConst(0, mysql.TypeLonglong), newConst(1.5, mysql.TypeDouble)},
+			mysql.TypeDouble, float64(1)},
+		// CASE WHEN 1 THEN 1 ELSE 'a' END
+		{[]Expression{newConst(1, mysql.TypeLonglong), newConst(1, mysql.TypeLonglong), newConst("a", mysql.TypeVarString)},
+			mysql.TypeVarchar, "1"},
+		// CASE WHEN 1 THEN 1 ELSE NULL END
+		{[]Expression{newConst(1, mysql.TypeLonglong), newConst(1, mysql.TypeLonglong), newConst(nil, mysql.TypeNull)},
+			mysql.TypeLonglong, int64(1)},
+		// CASE WHEN 0 THEN 1 END
+		{[]Expression{newConst(0, mysql.TypeLonglong), newConst(1, mysql.TypeLonglong)},
+			mysql.TypeLonglong, nil},
+	}
+	for _, t := range tbl {
+		c.Assert(caseWhenRetType(t.Arg).Tp, Equals, t.RetTp)
+		f, err := funcs[ast.Case].getFunction(t.Arg, s.ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.Ret))
+	}
+}
+
 func (s *testEvaluatorSuite) TestIf(c *C) {
 	defer testleak.AfterTest(c)()
 	tbl := []struct {
@@ -59,7 +157,7 @@ func (s *testEvaluatorSuite) TestIfNull(c *C) {
 	}
 
 	for _, t := range tbl {
-		d, err := builtinIfNull(types.MakeDatums([]interface{}{t.Arg1, t.Arg2}...), s.ctx)
+		d, err := evalFuncClass(ast.Ifnull, types.MakeDatums(t.Arg1, t.Arg2), s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.Ret))
 	}
@@ -0,0 +1,348 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/types"
+)
+
+type encodeFuncClass struct {
+	baseFuncClass
+}
+
+func (c *encodeFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinEncode{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinEncode struct {
+	baseBuiltinFunc
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/encryption-functions.html#function_encode
+func (b *builtinEncode) eval(row []types.Datum) (d types.Datum, err error) {
+	str, key, isNull, err := b.evalStrAndKey(row)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	d.SetString(string(newSQLCrypt(key).encode([]byte(str))))
+	return d, nil
+}
+
+type decodeFuncClass struct {
+	baseFuncClass
+}
+
+func (c *decodeFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinDecode{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinDecode struct {
+	baseBuiltinFunc
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/encryption-functions.html#function_decode
+func (b *builtinDecode) eval(row []types.Datum) (d types.Datum, err error) {
+	str, key, isNull, err := b.evalStrAndKey(row)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	d.SetString(string(newSQLCrypt(key).decode([]byte(str))))
+	return d, nil
+}
+
+type passwordFuncClass struct {
+	baseFuncClass
+}
+
+func (c *passwordFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinPassword{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinPassword struct {
+	baseBuiltinFunc
+}
+
+// eval returns the MySQL 4.1 password hash, which is '*' followed by the upper case hex of SHA1(SHA1(str)).
+// It returns an empty string for an empty password.
+// See https://dev.mysql.com/doc/refman/5.7/en/encryption-functions.html#function_password
+func (b *builtinPassword) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		d.SetString("")
+		return d, nil
+	}
+	pass, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if len(pass) == 0 {
+		d.SetString("")
+		return d, nil
+	}
+	hash := util.Sha1Hash(util.Sha1Hash([]byte(pass)))
+	d.SetString("*" + strings.ToUpper(hex.EncodeToString(hash)))
+	return d, nil
+}
+
+type oldPasswordFuncClass struct {
+	baseFuncClass
+}
+
+func (c *oldPasswordFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinOldPassword{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinOldPassword struct {
+	baseBuiltinFunc
+}
+
+// eval returns the 16 characters password hash used before MySQL 4.1.
+// See https://dev.mysql.com/doc/refman/5.6/en/encryption-functions.html#function_old-password
+func (b *builtinOldPassword) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		d.SetString("")
+		return d, nil
+	}
+	pass, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if len(pass) == 0 {
+		d.SetString("")
+		return d, nil
+	}
+	nr, nr2 := hashPassword(pass)
+	d.SetString(fmt.Sprintf("%08x%08x", nr, nr2))
+	return d, nil
+}
+
+type validatePasswordStrengthFuncClass struct {
+	baseFuncClass
+}
+
+func (c *validatePasswordStrengthFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinValidatePasswordStrength{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinValidatePasswordStrength struct {
+	baseBuiltinFunc
+}
+
+// The default options of the validate_password plugin.
+const (
+	validatePasswordLength           = 8
+	validatePasswordMixedCaseCount   = 1
+	validatePasswordNumberCount      = 1
+	validatePasswordSpecialCharCount = 1
+)
+
+// eval returns the strength of a password from 0 (weak) to 100 (strong):
+// 0 if the length is less than 4, 25 if the length is less than validate_password_length,
+// 50 if it satisfies the LOW policy, 75 if it satisfies the MEDIUM policy and 100 if it satisfies the STRONG policy.
+// There is no dictionary file, so a password satisfying the MEDIUM policy always satisfies the STRONG policy.
+// See https://dev.mysql.com/doc/refman/5.7/en/encryption-functions.html#function_validate-password-strength
+func (b *builtinValidatePasswordStrength) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	pass, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	runes := []rune(pass)
+	switch {
+	case len(runes) < 4:
+		d.SetInt64(0)
+	case len(runes) < validatePasswordLength:
+		d.SetInt64(25)
+	case !satisfyMediumPasswordPolicy(runes):
+		d.SetInt64(50)
+	default:
+		d.SetInt64(100)
+	}
+	return d, nil
+}
+
+// satisfyMediumPasswordPolicy checks whether the password has enough numeric, lowercase, uppercase and special characters.
+func satisfyMediumPasswordPolicy(pass []rune) bool {
+	var lower, upper, number, special int
+	for _, r := range pass {
+		switch {
+		case unicode.IsLower(r):
+			lower++
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsDigit(r):
+			number++
+		default:
+			special++
+		}
+	}
+	return lower >= validatePasswordMixedCaseCount && upper >= validatePasswordMixedCaseCount &&
+		number >= validatePasswordNumberCount && special >= validatePasswordSpecialCharCount
+}
+
+// evalStrAndKey evaluates the two string arguments of the ENCODE and DECODE, isNull is true if any of them is NULL.
+func (b *baseBuiltinFunc) evalStrAndKey(row []types.Datum) (str, key string, isNull bool, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return "", "", false, errors.Trace(err)
+	}
+	if args[0].IsNull() || args[1].IsNull() {
+		return "", "", true, nil
+	}
+	if str, err = args[0].ToString(); err != nil {
+		return "", "", false, errors.Trace(err)
+	}
+	if key, err = args[1].ToString(); err != nil {
+		return "", "", false, errors.Trace(err)
+	}
+	return str, key, false, nil
+}
+
+// evalBytesArg evaluates the only argument of a hashing or checksum function to the bytes to be hashed.
+// String and binary values are hashed as-is, other values are hashed on their string form.
+func (b *baseBuiltinFunc) evalBytesArg(row []types.Datum) (bs []byte, isNull bool, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	switch args[0].Kind() {
+	case types.KindNull:
+		return nil, true, nil
+	case types.KindString, types.KindBytes:
+		return args[0].GetBytes(), false, nil
+	}
+	s, err := args[0].ToString()
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	return []byte(s), false, nil
+}
+
+// sqlCrypt is the legacy stream cipher used by ENCODE and DECODE, it is ported from sql/sql_crypt.cc of MySQL.
+type sqlCrypt struct {
+	rand       mysqlRand
+	encodeBuff [256]byte
+	decodeBuff [256]byte
+	shift      uint32
+}
+
+func newSQLCrypt(key string) *sqlCrypt {
+	nr, nr2 := hashPassword(key)
+	c := &sqlCrypt{rand: newMysqlRand(nr, nr2)}
+	for i := range c.decodeBuff {
+		c.decodeBuff[i] = byte(i)
+	}
+	for i := range c.decodeBuff {
+		idx := uint32(c.rand.next() * 255.0)
+		c.decodeBuff[idx], c.decodeBuff[i] = c.decodeBuff[i], c.decodeBuff[idx]
+	}
+	for i := range c.decodeBuff {
+		c.encodeBuff[c.decodeBuff[i]] = byte(i)
+	}
+	return c
+}
+
+func (c *sqlCrypt) encode(str []byte) []byte {
+	res := make([]byte, len(str))
+	for i, ch := range str {
+		c.shift ^= uint32(c.rand.next() * 255.0)
+		res[i] = c.encodeBuff[ch] ^ byte(c.shift)
+		c.shift ^= uint32(ch)
+	}
+	return res
+}
+
+func (c *sqlCrypt) decode(str []byte) []byte {
+	res := make([]byte, len(str))
+	for i, ch := range str {
+		c.shift ^= uint32(c.rand.next() * 255.0)
+		res[i] = c.decodeBuff[ch^byte(c.shift)]
+		c.shift ^= uint32(res[i])
+	}
+	return res
+}
+
+// hashPassword is the password hash of MySQL 3.23, spaces and tabs in the password are ignored.
+func hashPassword(password string) (uint64, uint64) {
+	nr, add, nr2 := uint64(1345345333), uint64(7), uint64(0x12345671)
+	for i := 0; i < len(password); i++ {
+		if password[i] == ' ' || password[i] == '\t' {
+			continue
+		}
+		tmp := uint64(password[i])
+		nr ^= (((nr & 63) + add) * tmp) + (nr << 8)
+		nr2 += (nr2 << 8) ^ nr
+		add += tmp
+	}
+	return nr & (1<<31 - 1), nr2 & (1<<31 - 1)
+}
+
+const mysqlRandMax = 0x3FFFFFFF
+
+// mysqlRand is the pseudo random number generator of MySQL, it is ported from mysys/my_rnd.cc.
+type mysqlRand struct {
+	seed1, seed2 uint64
+}
+
+func newMysqlRand(seed1, seed2 uint64) mysqlRand {
+	return mysqlRand{seed1: seed1 % mysqlRandMax, seed2: seed2 % mysqlRandMax}
+}
+
+// next returns a float64 in [0, 1).
+func (r *mysqlRand) next() float64 {
+	r.seed1 = (r.seed1*3 + r.seed2) % mysqlRandMax
+	r.seed2 = (r.seed1 + r.seed2 + 33) % mysqlRandMax
+	return float64(r.seed1) / float64(mysqlRandMax)
+}
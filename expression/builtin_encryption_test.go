@@ -0,0 +1,146 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/testutil"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func (s *testEvaluatorSuite) TestEncodeAndDecode(c *C) {
+	defer testleak.AfterTest(c)()
+	evalFunc := func(name string, args ...interface{}) types.Datum {
+		f, err := funcs[name].getFunction(datumsToConstants(types.MakeDatums(args...)), s.ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		return d
+	}
+
+	tbl := []struct {
+		str string
+		key string
+	}{
+		{"", "key"},
+		{"TiDB", "key"},
+		{"TiDB", ""},
+		{"你好, TiDB", "another key"},
+		{"\x00\x01\xff binary", "\x00\xff"},
+	}
+	for _, t := range tbl {
+		encoded := evalFunc(ast.Encode, t.str, t.key)
+		c.Assert(encoded.GetString(), HasLen, len(t.str))
+		if len(t.str) > 0 {
+			c.Assert(encoded.GetString(), Not(Equals), t.str)
+		}
+		// The transform is deterministic.
+		c.Assert(evalFunc(ast.Encode, t.str, t.key), testutil.DatumEquals, encoded)
+
+		decoded := evalFunc(ast.Decode, encoded.GetString(), t.key)
+		c.Assert(decoded, testutil.DatumEquals, types.NewDatum(t.str))
+	}
+
+	// Spaces and tabs in the key are ignored.
+	c.Assert(evalFunc(ast.Encode, "TiDB", " k\te y "), testutil.DatumEquals, evalFunc(ast.Encode, "TiDB", "key"))
+	// A wrong key does not recover the original string.
+	encoded := evalFunc(ast.Encode, "TiDB", "key")
+	decoded := evalFunc(ast.Decode, encoded.GetString(), "yek")
+	c.Assert(decoded.GetString(), Not(Equals), "TiDB")
+
+	for _, args := range [][]interface{}{{nil, "key"}, {"TiDB", nil}} {
+		d := evalFunc(ast.Encode, args...)
+		c.Assert(d.IsNull(), IsTrue)
+		d = evalFunc(ast.Decode, args...)
+		c.Assert(d.IsNull(), IsTrue)
+	}
+}
+
+func (s *testEvaluatorSuite) TestPassword(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		input  interface{}
+		result string
+	}{
+		{"abc", "*0D3CED9BEC10A777AEC23CCC353A8C08A633045E"},
+		{"mypass", "*6C8989366EAF75BB670AD8EA7A7FC1176A95CEF4"},
+		{123, "*23AE809DDACAF96AF0FD78ED04B6A265E05AA257"},
+		{"", ""},
+		{nil, ""},
+	}
+	sc := s.ctx.GetSessionVars().StmtCtx
+	warnCnt := len(sc.GetWarnings())
+	fc := funcs[ast.Password]
+	for _, t := range tbl {
+		f, err := fc.getFunction(datumsToConstants(types.MakeDatums(t.input)), s.ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.result))
+	}
+	// The deprecation is reported by the plan builder, not by building or evaluating the signature.
+	c.Assert(sc.GetWarnings(), HasLen, warnCnt)
+}
+
+func (s *testEvaluatorSuite) TestOldPassword(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		input  interface{}
+		result string
+	}{
+		{"mypass", "6f8c114b58f2ce9e"},
+		{"my pass", "6f8c114b58f2ce9e"},
+		{"abc", "7cd2b5942be28759"},
+		{123, "773359240eb9a1d9"},
+		{"", ""},
+		{nil, ""},
+	}
+	for _, t := range tbl {
+		f, err := funcs[ast.OldPassword].getFunction(datumsToConstants(types.MakeDatums(t.input)), s.ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.result))
+	}
+}
+
+func (s *testEvaluatorSuite) TestValidatePasswordStrength(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		input  interface{}
+		result interface{}
+	}{
+		{"", 0},
+		{"abc", 0},
+		{"abcd", 25},
+		{"Ab1!xyz", 25},
+		{"abcdefgh", 50},
+		{"Abcdefg1", 50},
+		{"abcdef1!", 50},
+		{"ABCDEF1!", 50},
+		{"Abcdef1!", 100},
+		{"你好Ab12!?", 100},
+		{12345678, 50},
+		{nil, nil},
+	}
+	for _, t := range tbl {
+		f, err := funcs[ast.ValidatePasswordStrength].getFunction(datumsToConstants(types.MakeDatums(t.input)), s.ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.result), Commentf("%v", t.input))
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// gtidInterval is the closed interval of transaction ids [start, end].
+type gtidInterval struct {
+	start, end int64
+}
+
+type gtidIntervals []gtidInterval
+
+func (s gtidIntervals) Len() int           { return len(s) }
+func (s gtidIntervals) Less(i, j int) bool { return s[i].start < s[j].start }
+func (s gtidIntervals) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// gtidSet maps the source uuid to the sorted and disjoint intervals of the transactions executed on it.
+type gtidSet map[string]gtidIntervals
+
+// parseGtidSet parses a GTID set like '3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5:7, 4e11fa47-71ca-11e1-9e33-c80aa9429562:3'.
+// An empty string is the empty set.
+// See https://dev.mysql.com/doc/refman/5.7/en/replication-gtids-concepts.html#replication-gtids-concepts-gtid-sets
+func parseGtidSet(str string) (gtidSet, error) {
+	set := make(gtidSet)
+	if strings.TrimSpace(str) == "" {
+		return set, nil
+	}
+	for _, item := range strings.Split(str, ",") {
+		parts := strings.Split(item, ":")
+		bin, ok := parseUUID(strings.TrimSpace(parts[0]))
+		if !ok || len(parts) < 2 {
+			return nil, errMalformedGtidSet.GenByArgs(str)
+		}
+		uuid := formatUUID(bin)
+		for _, part := range parts[1:] {
+			iv, ok := parseGtidInterval(strings.TrimSpace(part))
+			if !ok {
+				return nil, errMalformedGtidSet.GenByArgs(str)
+			}
+			set[uuid] = append(set[uuid], iv)
+		}
+	}
+	for uuid, ivs := range set {
+		set[uuid] = mergeGtidIntervals(ivs)
+	}
+	return set, nil
+}
+
+// parseGtidInterval parses 'n' or 'n-m', the transaction ids start from 1.
+func parseGtidInterval(str string) (iv gtidInterval, ok bool) {
+	startStr, endStr := str, str
+	if i := strings.IndexByte(str, '-'); i >= 0 {
+		startStr, endStr = str[:i], str[i+1:]
+	}
+	var err error
+	if iv.start, err = strconv.ParseInt(startStr, 10, 64); err != nil {
+		return iv, false
+	}
+	if iv.end, err = strconv.ParseInt(endStr, 10, 64); err != nil {
+		return iv, false
+	}
+	return iv, iv.start >= 1 && iv.start <= iv.end
+}
+
+// mergeGtidIntervals sorts the intervals and merges the overlapping or adjacent ones.
+func mergeGtidIntervals(ivs gtidIntervals) gtidIntervals {
+	sort.Sort(ivs)
+	merged := ivs[:0]
+	for _, iv := range ivs {
+		if n := len(merged); n > 0 && merged[n-1].end != math.MaxInt64 && iv.start <= merged[n-1].end+1 {
+			if iv.end > merged[n-1].end {
+				merged[n-1].end = iv.end
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
+
+// contains returns whether every transaction in other is in the set.
+func (s gtidSet) contains(other gtidSet) bool {
+	for uuid, ivs := range other {
+		own := s[uuid]
+		for _, iv := range ivs {
+			// The intervals are disjoint, so iv must be inside a single interval of the set.
+			i := sort.Search(len(own), func(i int) bool { return own[i].end >= iv.start })
+			if i == len(own) || own[i].start > iv.start || own[i].end < iv.end {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// subtract returns the transactions in the set but not in other.
+func (s gtidSet) subtract(other gtidSet) gtidSet {
+	res := make(gtidSet, len(s))
+	for uuid, ivs := range s {
+		removed := other[uuid]
+		var left gtidIntervals
+		for _, iv := range ivs {
+			for _, r := range removed {
+				if r.end < iv.start || r.start > iv.end {
+					continue
+				}
+				if r.start > iv.start {
+					left = append(left, gtidInterval{iv.start, r.start - 1})
+				}
+				if r.end >= iv.end {
+					iv.start = iv.end + 1
+					break
+				}
+				iv.start = r.end + 1
+			}
+			if iv.start <= iv.end {
+				left = append(left, iv)
+			}
+		}
+		if len(left) > 0 {
+			res[uuid] = left
+		}
+	}
+	return res
+}
+
+// String formats the set in the format of MySQL, the uuids are sorted and separated by ",\n".
+func (s gtidSet) String() string {
+	uuids := make([]string, 0, len(s))
+	for uuid := range s {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+	var buf bytes.Buffer
+	for i, uuid := range uuids {
+		if i > 0 {
+			buf.WriteString(",\n")
+		}
+		buf.WriteString(uuid)
+		for _, iv := range s[uuid] {
+			buf.WriteByte(':')
+			buf.WriteString(strconv.FormatInt(iv.start, 10))
+			if iv.end != iv.start {
+				buf.WriteByte('-')
+				buf.WriteString(strconv.FormatInt(iv.end, 10))
+			}
+		}
+	}
+	return buf.String()
+}
+
+// evalGtidSets evaluates and parses the two GTID set arguments, isNull is true if any of them is NULL.
+func (b *baseBuiltinFunc) evalGtidSets(row []types.Datum) (set1, set2 gtidSet, isNull bool, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return nil, nil, false, errors.Trace(err)
+	}
+	if args[0].IsNull() || args[1].IsNull() {
+		return nil, nil, true, nil
+	}
+	sets := make([]gtidSet, 0, 2)
+	for _, arg := range args {
+		str, err := arg.ToString()
+		if err != nil {
+			return nil, nil, false, errors.Trace(err)
+		}
+		set, err := parseGtidSet(str)
+		if err != nil {
+			return nil, nil, false, errors.Trace(err)
+		}
+		sets = append(sets, set)
+	}
+	return sets[0], sets[1], false, nil
+}
+
+type gtidSubsetFuncClass struct {
+	baseFuncClass
+}
+
+func (c *gtidSubsetFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinGtidSubset{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinGtidSubset struct {
+	baseBuiltinFunc
+}
+
+// eval returns 1 if all the GTIDs in the first set are also in the second set, and 0 otherwise.
+// See https://dev.mysql.com/doc/refman/5.7/en/gtid-functions.html#function_gtid-subset
+func (b *builtinGtidSubset) eval(row []types.Datum) (d types.Datum, err error) {
+	subset, set, isNull, err := b.evalGtidSets(row)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	d.SetInt64(boolToInt64(set.contains(subset)))
+	return d, nil
+}
+
+type gtidSubtractFuncClass struct {
+	baseFuncClass
+}
+
+func (c *gtidSubtractFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinGtidSubtract{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinGtidSubtract struct {
+	baseBuiltinFunc
+}
+
+// eval returns the GTIDs in the first set that are not in the second set.
+// See https://dev.mysql.com/doc/refman/5.7/en/gtid-functions.html#function_gtid-subtract
+func (b *builtinGtidSubtract) eval(row []types.Datum) (d types.Datum, err error) {
+	set1, set2, isNull, err := b.evalGtidSets(row)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	d.SetString(set1.subtract(set2).String())
+	return d, nil
+}
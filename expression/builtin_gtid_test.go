@@ -0,0 +1,97 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/testutil"
+	"github.com/pingcap/tidb/util/types"
+)
+
+const (
+	testUUID1 = "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	testUUID2 = "4e11fa47-71ca-11e1-9e33-c80aa9429562"
+)
+
+func (s *testEvaluatorSuite) TestGtidSubset(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		subset interface{}
+		set    interface{}
+		ret    interface{}
+	}{
+		{testUUID1 + ":23-25", testUUID1 + ":21-57", int64(1)},
+		{testUUID1 + ":20-25", testUUID1 + ":21-57", int64(0)},
+		{testUUID1 + ":23-25:30", testUUID1 + ":21-57", int64(1)},
+		{testUUID1 + ":23:24:25", testUUID1 + ":1-22:23-25", int64(1)},
+		{testUUID1 + ":1-3", testUUID1 + ":1:3", int64(0)},
+		{testUUID1 + ":1-5", testUUID2 + ":1-5", int64(0)},
+		{testUUID1 + ":1-5", testUUID2 + ":1-5," + testUUID1 + ":1-10", int64(1)},
+		{"3E11FA47-71CA-11E1-9E33-C80AA9429562:2", testUUID1 + ":1-3", int64(1)},
+		{"", testUUID1 + ":1", int64(1)},
+		{testUUID1 + ":1", "", int64(0)},
+		{nil, testUUID1 + ":1", nil},
+		{testUUID1 + ":1", nil, nil},
+	}
+	for _, t := range tbl {
+		d, err := evalFuncClass(ast.GTIDSubset, types.MakeDatums(t.subset, t.set), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v %v", t.subset, t.set))
+	}
+}
+
+func (s *testEvaluatorSuite) TestGtidSubtract(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		set1 interface{}
+		set2 interface{}
+		ret  interface{}
+	}{
+		{testUUID1 + ":21-57", testUUID1 + ":23-25", testUUID1 + ":21-22:26-57"},
+		{testUUID1 + ":21-57", testUUID1 + ":20-25", testUUID1 + ":26-57"},
+		{testUUID1 + ":21-57", testUUID1 + ":21-57", ""},
+		{testUUID1 + ":21-57", testUUID1 + ":21", testUUID1 + ":22-57"},
+		{testUUID1 + ":1-10:20-30", testUUID1 + ":5-25", testUUID1 + ":1-4:26-30"},
+		{testUUID1 + ":1-3:4-6", testUUID2 + ":1-6", testUUID1 + ":1-6"},
+		{testUUID2 + ":1-5, " + testUUID1 + ":3", testUUID2 + ":2-4", testUUID1 + ":3,\n" + testUUID2 + ":1:5"},
+		{"", testUUID1 + ":1", ""},
+		{nil, "", nil},
+	}
+	for _, t := range tbl {
+		d, err := evalFuncClass(ast.GTIDSubtract, types.MakeDatums(t.set1, t.set2), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v %v", t.set1, t.set2))
+	}
+}
+
+func (s *testEvaluatorSuite) TestMalformedGtidSet(c *C) {
+	defer testleak.AfterTest(c)()
+	for _, set := range []string{
+		testUUID1,
+		testUUID1 + ":",
+		testUUID1 + ":0",
+		testUUID1 + ":5-3",
+		testUUID1 + ":a",
+		testUUID1 + ":1,",
+		"3e11fa47:1",
+	} {
+		for _, name := range []string{ast.GTIDSubset, ast.GTIDSubtract} {
+			_, err := evalFuncClass(name, types.MakeDatums(set, ""), s.ctx)
+			c.Assert(terror.ErrorEqual(err, errMalformedGtidSet), IsTrue, Commentf("%s %s", name, set))
+		}
+	}
+}
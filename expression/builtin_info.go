@@ -18,9 +18,14 @@
 package expression
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -94,3 +99,362 @@ func builtinVersion(args []types.Datum, ctx context.Context) (d types.Datum, err
 	d.SetString(mysql.ServerVersion)
 	return d, nil
 }
+
+// The collation coercibility levels, a lower value has a higher precedence.
+// See https://dev.mysql.com/doc/refman/5.7/en/charset-collation-coercibility.html
+const (
+	coercibilityExplicit  = 0
+	coercibilityNone      = 1
+	coercibilityImplicit  = 2
+	coercibilitySysconst  = 3
+	coercibilityCoercible = 4
+	coercibilityNumeric   = 5
+	coercibilityIgnorable = 6
+)
+
+// sysconstFuncs are the functions returning a system constant string.
+var sysconstFuncs = map[string]struct{}{
+	ast.CurrentUser: {},
+	ast.Database:    {},
+	ast.Schema:      {},
+	ast.User:        {},
+	ast.Version:     {},
+}
+
+// deriveCoercibility returns the collation coercibility of the expression.
+func deriveCoercibility(expr Expression) int64 {
+	switch x := expr.(type) {
+	case *Constant:
+		switch x.Value.Kind() {
+		case types.KindNull:
+			return coercibilityIgnorable
+		case types.KindString, types.KindBytes:
+			return coercibilityCoercible
+		}
+		return coercibilityNumeric
+	case *Column:
+		if isStringType(x.GetType()) {
+			return coercibilityImplicit
+		}
+		return coercibilityNumeric
+	case *ScalarFunction:
+		if !isStringType(x.GetType()) {
+			return coercibilityNumeric
+		}
+		if x.FuncName.L == ast.Collate {
+			return coercibilityExplicit
+		}
+		if _, ok := sysconstFuncs[x.FuncName.L]; ok {
+			return coercibilitySysconst
+		}
+		// The result of a string function has the lowest coercibility of its string arguments.
+		res := int64(coercibilityCoercible)
+		for _, arg := range x.GetArgs() {
+			if c := deriveCoercibility(arg); c < res {
+				res = c
+			}
+		}
+		return res
+	}
+	return coercibilityCoercible
+}
+
+func isStringType(ft *types.FieldType) bool {
+	if ft == nil {
+		return false
+	}
+	switch ft.Tp {
+	case mysql.TypeVarString, mysql.TypeEnum, mysql.TypeSet:
+		return true
+	}
+	return types.IsTypeChar(ft.Tp) || types.IsTypeBlob(ft.Tp)
+}
+
+type coercibilityFuncClass struct {
+	baseFuncClass
+}
+
+func (c *coercibilityFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinCoercibility{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinCoercibility struct {
+	baseBuiltinFunc
+}
+
+// eval returns the collation coercibility of the argument, it depends on the argument expression rather than its value.
+// See https://dev.mysql.com/doc/refman/5.7/en/information-functions.html#function_coercibility
+func (b *builtinCoercibility) eval(_ []types.Datum) (d types.Datum, err error) {
+	d.SetInt64(deriveCoercibility(b.args[0]))
+	return d, nil
+}
+
+// charsetAndCollation returns the charset and collation of the expression's result,
+// the result of a non-string expression is a binary string.
+func charsetAndCollation(expr Expression) (cs, collation string, err error) {
+	if fn, ok := expr.(*ScalarFunction); ok {
+		if _, ok := collationAggregatedFuncs[fn.FuncName.L]; ok {
+			info, ok, err := aggregateCollations(fn.FuncName.L, fn.GetArgs())
+			if err != nil || ok {
+				return info.charset, info.collation, errors.Trace(err)
+			}
+		}
+	}
+	ft := expr.GetType()
+	if con, ok := expr.(*Constant); ok && ft == nil {
+		ft = new(types.FieldType)
+		types.DefaultTypeForValue(con.Value.GetValue(), ft)
+	}
+	if !isStringType(ft) || ft.Charset == charset.CharsetBin {
+		return charset.CharsetBin, charset.CollationBin, nil
+	}
+	cs, collation = ft.Charset, ft.Collate
+	if cs == "" {
+		cs = mysql.DefaultCharset
+	}
+	if collation == "" {
+		collation, err = charset.GetDefaultCollation(cs)
+	}
+	return cs, collation, errors.Trace(err)
+}
+
+type charsetFuncClass struct {
+	baseFuncClass
+}
+
+func (c *charsetFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinCharset{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinCharset struct {
+	baseBuiltinFunc
+}
+
+// eval returns the charset of the argument.
+// See https://dev.mysql.com/doc/refman/5.7/en/information-functions.html#function_charset
+func (b *builtinCharset) eval(_ []types.Datum) (d types.Datum, err error) {
+	cs, _, err := charsetAndCollation(b.args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	d.SetString(cs)
+	return d, nil
+}
+
+type collationFuncClass struct {
+	baseFuncClass
+}
+
+func (c *collationFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinCollation{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinCollation struct {
+	baseBuiltinFunc
+}
+
+// eval returns the collation of the argument.
+// See https://dev.mysql.com/doc/refman/5.7/en/information-functions.html#function_collation
+func (b *builtinCollation) eval(_ []types.Datum) (d types.Datum, err error) {
+	_, collation, err := charsetAndCollation(b.args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	d.SetString(collation)
+	return d, nil
+}
+
+// collationAggregatedFuncs are the functions whose result collation is aggregated from the arguments.
+var collationAggregatedFuncs = map[string]struct{}{
+	ast.Concat: {},
+}
+
+// collationInfo is the charset, collation and coercibility of a string expression.
+type collationInfo struct {
+	charset      string
+	collation    string
+	coercibility int64
+}
+
+var coercibilityNames = []string{"EXPLICIT", "NONE", "IMPLICIT", "SYSCONST", "COERCIBLE", "NUMERIC", "IGNORABLE"}
+
+// aggregateCollations derives the collation of the result of the function named funcName from its arguments,
+// ok is false if there is no string argument, numeric and NULL arguments don't take part in it.
+// See https://dev.mysql.com/doc/refman/5.7/en/charset-collation-coercibility.html
+func aggregateCollations(funcName string, args []Expression) (res collationInfo, ok bool, err error) {
+	for _, arg := range args {
+		coercibility := deriveCoercibility(arg)
+		if coercibility >= coercibilityNumeric {
+			continue
+		}
+		cs, collation, err := charsetAndCollation(arg)
+		if err != nil {
+			return res, false, errors.Trace(err)
+		}
+		info := collationInfo{charset: cs, collation: collation, coercibility: coercibility}
+		if !ok {
+			res, ok = info, true
+			continue
+		}
+		if res, err = aggregateCollation(funcName, res, info); err != nil {
+			return res, false, errors.Trace(err)
+		}
+	}
+	return res, ok, nil
+}
+
+// charsetRank ranks the charsets a string may be converted to without loss,
+// a binary string is ranked first as any string can be treated as bytes.
+func charsetRank(cs string) int {
+	switch cs {
+	case charset.CharsetBin:
+		return 3
+	case charset.CharsetUTF8MB4:
+		return 2
+	case charset.CharsetUTF8:
+		return 1
+	}
+	return 0
+}
+
+// aggregateCollation derives the collation from the ones of two arguments like MySQL does.
+func aggregateCollation(funcName string, a, b collationInfo) (collationInfo, error) {
+	if a.charset != b.charset {
+		// The one with the lower coercibility wins, or the one whose charset is a superset,
+		// the other one must be converted without loss or be a constant.
+		win, lose := a, b
+		if b.coercibility < a.coercibility || (b.coercibility == a.coercibility && charsetRank(b.charset) > charsetRank(a.charset)) {
+			win, lose = b, a
+		}
+		if charsetRank(win.charset) > charsetRank(lose.charset) || lose.charset == charset.CharsetBin ||
+			lose.coercibility >= coercibilitySysconst {
+			return win, nil
+		}
+		return a, illegalCollationMix(funcName, a, b)
+	}
+	if a.coercibility != b.coercibility {
+		if b.coercibility < a.coercibility {
+			return b, nil
+		}
+		return a, nil
+	}
+	if a.collation == b.collation {
+		return a, nil
+	}
+	if a.coercibility == coercibilityExplicit {
+		return a, illegalCollationMix(funcName, a, b)
+	}
+	// Of two different implicit collations, a binary one wins, or it's the binary collation of the charset.
+	if strings.HasSuffix(a.collation, "_bin") {
+		return a, nil
+	}
+	if strings.HasSuffix(b.collation, "_bin") {
+		return b, nil
+	}
+	return collationInfo{charset: a.charset, collation: a.charset + "_bin", coercibility: coercibilityNone}, nil
+}
+
+// checkCollations checks that the string operands of the function named funcName, which compares them,
+// can be coerced to a common collation. It's shared by the comparison operators, STRCMP, LIKE and REGEXP.
+func checkCollations(funcName string, args []Expression) error {
+	_, _, err := aggregateCollations(funcName, args)
+	return errors.Trace(err)
+}
+
+func illegalCollationMix(funcName string, a, b collationInfo) error {
+	return errCantAggregateCollations.GenByArgs(a.collation, coercibilityNames[a.coercibility],
+		b.collation, coercibilityNames[b.coercibility], funcName)
+}
+
+type collateFuncClass struct {
+	baseFuncClass
+}
+
+// checkValid implements functionClass interface, the collation must be a valid one for the charset of the expression.
+func (c *collateFuncClass) checkValid(args []Expression) error {
+	if err := c.baseFuncClass.checkValid(args); err != nil {
+		return errors.Trace(err)
+	}
+	con, ok := args[1].(*Constant)
+	if !ok {
+		return errIncorrectArgs.GenByArgs("COLLATE")
+	}
+	name, err := con.Value.ToString()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	co, err := charset.GetCollationByName(name)
+	if err != nil {
+		return errUnknownCollation.GenByArgs(name)
+	}
+	cs, _, err := charsetAndCollation(args[0])
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if cs != co.CharsetName {
+		return errCollationCharsetMismatch.GenByArgs(co.Name, cs)
+	}
+	return nil
+}
+
+func (c *collateFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinCollate{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinCollate struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinCollate, the COLLATE clause only changes the collation of the expression, not its value.
+// See https://dev.mysql.com/doc/refman/5.7/en/charset-collate.html
+func (b *builtinCollate) eval(row []types.Datum) (d types.Datum, err error) {
+	d, err = b.args[0].Eval(row, b.ctx)
+	return d, errors.Trace(err)
+}
+
+type currentRoleFuncClass struct {
+	baseFuncClass
+}
+
+func (c *currentRoleFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinCurrentRole{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinCurrentRole struct {
+	baseBuiltinFunc
+}
+
+// eval returns the sorted active roles of the session separated by commas, or 'NONE' if there is no active role.
+// See https://dev.mysql.com/doc/refman/8.0/en/information-functions.html#function_current-role
+func (b *builtinCurrentRole) eval(_ []types.Datum) (d types.Datum, err error) {
+	sessVars := b.ctx.GetSessionVars()
+	if sessVars == nil || len(sessVars.ActiveRoles) == 0 {
+		d.SetString("NONE")
+		return d, nil
+	}
+	roles := make([]string, len(sessVars.ActiveRoles))
+	copy(roles, sessVars.ActiveRoles)
+	sort.Strings(roles)
+	d.SetString(strings.Join(roles, ","))
+	return d, nil
+}
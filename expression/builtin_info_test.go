@@ -17,6 +17,8 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
@@ -70,6 +72,21 @@ func (s *testEvaluatorSuite) TestCurrentUser(c *C) {
 	c.Assert(d.GetString(), Equals, "root@localhost")
 }
 
+func (s *testEvaluatorSuite) TestCurrentRole(c *C) {
+	defer testleak.AfterTest(c)()
+	ctx := mock.NewContext()
+	d, err := evalFuncClass(ast.CurrentRole, nil, ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetString(), Equals, "NONE")
+
+	sessionVars := ctx.GetSessionVars()
+	sessionVars.ActiveRoles = []string{"`r2`@`%`", "`r1`@`localhost`"}
+	d, err = evalFuncClass(ast.CurrentRole, nil, ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetString(), Equals, "`r1`@`localhost`,`r2`@`%`")
+	c.Assert(sessionVars.ActiveRoles[0], Equals, "`r2`@`%`")
+}
+
 func (s *testEvaluatorSuite) TestConnectionID(c *C) {
 	defer testleak.AfterTest(c)()
 	ctx := mock.NewContext()
@@ -87,3 +104,193 @@ func (s *testEvaluatorSuite) TestVersion(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(v.GetString(), Equals, mysql.ServerVersion)
 }
+
+func (s *testEvaluatorSuite) TestCoercibility(c *C) {
+	defer testleak.AfterTest(c)()
+	strCol := &Column{RetType: types.NewFieldType(mysql.TypeVarchar)}
+	intCol := &Column{RetType: types.NewFieldType(mysql.TypeLonglong)}
+	strConst := &Constant{Value: types.NewStringDatum("abc")}
+	newFunc := func(name string, tp byte, args ...Expression) Expression {
+		f, err := NewFunction(name, types.NewFieldType(tp), args...)
+		c.Assert(err, IsNil)
+		return f
+	}
+
+	tbl := []struct {
+		arg Expression
+		ret int64
+	}{
+		{strCol, coercibilityImplicit},
+		{intCol, coercibilityNumeric},
+		{strConst, coercibilityCoercible},
+		{&Constant{Value: types.NewIntDatum(1)}, coercibilityNumeric},
+		{&Constant{Value: types.Datum{}}, coercibilityIgnorable},
+		{newFunc(ast.Plus, mysql.TypeLonglong, intCol, &Constant{Value: types.NewIntDatum(1)}), coercibilityNumeric},
+		{newFunc(ast.User, mysql.TypeVarString), coercibilitySysconst},
+		{newFunc(ast.Concat, mysql.TypeVarString, strConst, strConst), coercibilityCoercible},
+		{newFunc(ast.Concat, mysql.TypeVarString, strConst, strCol), coercibilityImplicit},
+		{newFunc(ast.Concat, mysql.TypeVarString, strConst, intCol), coercibilityCoercible},
+	}
+	for _, t := range tbl {
+		f, err := funcs[ast.Coercibility].getFunction([]Expression{t.arg}, s.ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d.GetInt64(), Equals, t.ret, Commentf("%v", t.arg))
+	}
+}
+
+func (s *testEvaluatorSuite) TestCharsetAndCollation(c *C) {
+	defer testleak.AfterTest(c)()
+	utf8mb4Tp := types.NewFieldType(mysql.TypeVarchar)
+	utf8mb4Tp.Charset, utf8mb4Tp.Collate = "utf8mb4", "utf8mb4_bin"
+	latin1Tp := types.NewFieldType(mysql.TypeVarString)
+	latin1Tp.Charset = "latin1"
+
+	tbl := []struct {
+		arg       Expression
+		charset   string
+		collation string
+	}{
+		{&Column{RetType: utf8mb4Tp}, "utf8mb4", "utf8mb4_bin"},
+		{&Constant{Value: types.NewStringDatum("abc"), RetType: latin1Tp}, "latin1", "latin1_swedish_ci"},
+		{&Constant{Value: types.NewStringDatum("abc")}, mysql.DefaultCharset, mysql.DefaultCollationName},
+		{&Constant{Value: types.NewIntDatum(1)}, "binary", "binary"},
+		{&Column{RetType: types.NewFieldType(mysql.TypeDouble)}, "binary", "binary"},
+		{&Constant{Value: types.Datum{}}, "binary", "binary"},
+	}
+	for _, t := range tbl {
+		f, err := funcs[ast.Charset].getFunction([]Expression{t.arg}, s.ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d.GetString(), Equals, t.charset, Commentf("%v", t.arg))
+
+		f, err = funcs[ast.Collation].getFunction([]Expression{t.arg}, s.ctx)
+		c.Assert(err, IsNil)
+		d, err = f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d.GetString(), Equals, t.collation, Commentf("%v", t.arg))
+	}
+}
+
+func (s *testEvaluatorSuite) TestConcatCollation(c *C) {
+	defer testleak.AfterTest(c)()
+	newType := func(cs, collation string) *types.FieldType {
+		ft := types.NewFieldType(mysql.TypeVarString)
+		ft.Charset, ft.Collate = cs, collation
+		return ft
+	}
+	collate := func(expr Expression, collation string) Expression {
+		co, err := charset.GetCollationByName(collation)
+		c.Assert(err, IsNil)
+		f, err := NewFunction(ast.Collate, newType(co.CharsetName, co.Name), expr, &Constant{Value: types.NewStringDatum(collation)})
+		c.Assert(err, IsNil)
+		return f
+	}
+	binCol := &Column{RetType: newType("utf8mb4", "utf8mb4_bin")}
+	ciCol := &Column{RetType: newType("utf8mb4", "utf8mb4_general_ci")}
+	unicodeCol := &Column{RetType: newType("utf8mb4", "utf8mb4_unicode_ci")}
+	latin1Col := &Column{RetType: newType("latin1", "latin1_swedish_ci")}
+	asciiCol := &Column{RetType: newType("ascii", "ascii_general_ci")}
+	ciConst := &Constant{Value: types.NewStringDatum("a"), RetType: newType("utf8mb4", "utf8mb4_general_ci")}
+	utf8Const := &Constant{Value: types.NewStringDatum("a"), RetType: newType("utf8", "utf8_general_ci")}
+
+	tbl := []struct {
+		args      []Expression
+		collation string
+	}{
+		// An explicit collation wins over the implicit one of a column.
+		{[]Expression{collate(ciConst, "utf8mb4_general_ci"), binCol}, "utf8mb4_general_ci"},
+		{[]Expression{binCol, collate(ciCol, "utf8mb4_general_ci")}, "utf8mb4_general_ci"},
+		// An implicit collation wins over the one of a constant, which is converted to the superset charset.
+		{[]Expression{utf8Const, ciCol}, "utf8mb4_general_ci"},
+		{[]Expression{latin1Col, utf8Const}, "latin1_swedish_ci"},
+		{[]Expression{binCol, &Constant{Value: types.NewIntDatum(1)}, &Constant{Value: types.Datum{}}}, "utf8mb4_bin"},
+		// Of different implicit collations of a charset, the binary one wins.
+		{[]Expression{ciCol, binCol}, "utf8mb4_bin"},
+		{[]Expression{ciCol, unicodeCol}, "utf8mb4_bin"},
+		{[]Expression{latin1Col, ciCol}, "utf8mb4_general_ci"},
+		{[]Expression{collate(ciConst, "utf8mb4_bin"), collate(binCol, "utf8mb4_bin")}, "utf8mb4_bin"},
+	}
+	for _, t := range tbl {
+		f, err := NewFunction(ast.Concat, types.NewFieldType(mysql.TypeVarString), t.args...)
+		c.Assert(err, IsNil, Commentf("%v", t.args))
+		coll, err := NewFunction(ast.Collation, types.NewFieldType(mysql.TypeVarString), f)
+		c.Assert(err, IsNil)
+		d, err := coll.Eval(nil, s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(d.GetString(), Equals, t.collation, Commentf("%v", t.args))
+	}
+
+	errTbl := [][]Expression{
+		{collate(ciConst, "utf8mb4_general_ci"), collate(binCol, "utf8mb4_bin")},
+		{latin1Col, asciiCol},
+		{collate(latin1Col, "latin1_bin"), ciCol},
+	}
+	for _, args := range errTbl {
+		_, err := NewFunction(ast.Concat, types.NewFieldType(mysql.TypeVarString), args...)
+		c.Assert(terror.ErrorEqual(err, errCantAggregateCollations), IsTrue, Commentf("%v", args))
+	}
+	_, err := NewFunction(ast.Concat, types.NewFieldType(mysql.TypeVarString), collate(ciConst, "utf8mb4_general_ci"), collate(binCol, "utf8mb4_bin"))
+	c.Assert(err.Error(), Equals, "[expression:1267]Illegal mix of collations (utf8mb4_general_ci,EXPLICIT) and (utf8mb4_bin,EXPLICIT) for operation 'concat'")
+
+	_, err = NewFunction(ast.Collate, types.NewFieldType(mysql.TypeVarString), binCol, &Constant{Value: types.NewStringDatum("unknown_ci")})
+	c.Assert(terror.ErrorEqual(err, errUnknownCollation), IsTrue)
+	_, err = NewFunction(ast.Collate, types.NewFieldType(mysql.TypeVarString), binCol, &Constant{Value: types.NewStringDatum("latin1_bin")})
+	c.Assert(terror.ErrorEqual(err, errCollationCharsetMismatch), IsTrue)
+	_, err = NewFunction(ast.Collate, types.NewFieldType(mysql.TypeVarString), &Constant{Value: types.NewIntDatum(1)}, &Constant{Value: types.NewStringDatum("utf8_bin")})
+	c.Assert(err.Error(), Equals, "[expression:1253]COLLATION 'utf8_bin' is not valid for CHARACTER SET 'binary'")
+
+	// A COLLATE clause on a constant isn't folded, or it would lose its explicit coercibility.
+	f, ok := FoldConstant(s.ctx, collate(ciConst, "utf8mb4_bin")).(*ScalarFunction)
+	c.Assert(ok, IsTrue)
+	c.Assert(deriveCoercibility(f), Equals, int64(coercibilityExplicit))
+}
+
+func (s *testEvaluatorSuite) TestCompareCollation(c *C) {
+	defer testleak.AfterTest(c)()
+	newType := func(cs, collation string) *types.FieldType {
+		ft := types.NewFieldType(mysql.TypeVarString)
+		ft.Charset, ft.Collate = cs, collation
+		return ft
+	}
+	collate := func(expr Expression, collation string) Expression {
+		co, err := charset.GetCollationByName(collation)
+		c.Assert(err, IsNil)
+		f, err := NewFunction(ast.Collate, newType(co.CharsetName, co.Name), expr, &Constant{Value: types.NewStringDatum(collation)})
+		c.Assert(err, IsNil)
+		return f
+	}
+	newArgs := func(funcName string, args ...Expression) []Expression {
+		if funcName == ast.Like {
+			args = append(args, &Constant{Value: types.NewIntDatum(int64('\\'))})
+		}
+		return args
+	}
+	ciConst := &Constant{Value: types.NewStringDatum("a"), RetType: newType("utf8mb4", "utf8mb4_general_ci")}
+	binConst := &Constant{Value: types.NewStringDatum("a"), RetType: newType("utf8mb4", "utf8mb4_bin")}
+	latin1Col := &Column{RetType: newType("latin1", "latin1_swedish_ci")}
+	asciiCol := &Column{RetType: newType("ascii", "ascii_general_ci")}
+
+	funcNames := []string{ast.EQ, ast.NE, ast.LT, ast.LE, ast.GT, ast.GE, ast.NullEQ, ast.Strcmp, ast.Like, ast.Regexp}
+	for _, funcName := range funcNames {
+		_, err := NewFunction(funcName, types.NewFieldType(mysql.TypeLonglong),
+			newArgs(funcName, collate(ciConst, "utf8mb4_general_ci"), collate(binConst, "utf8mb4_bin"))...)
+		c.Assert(err, NotNil, Commentf("%s", funcName))
+		c.Assert(err.Error(), Equals, "[expression:1267]Illegal mix of collations (utf8mb4_general_ci,EXPLICIT) and (utf8mb4_bin,EXPLICIT) for operation '"+funcName+"'")
+		_, err = NewFunction(funcName, types.NewFieldType(mysql.TypeLonglong), newArgs(funcName, latin1Col, asciiCol)...)
+		c.Assert(terror.ErrorEqual(err, errCantAggregateCollations), IsTrue, Commentf("%s", funcName))
+
+		// An explicit collation wins over the implicit one and a constant is coerced to it.
+		f, err := NewFunction(funcName, types.NewFieldType(mysql.TypeLonglong),
+			newArgs(funcName, collate(ciConst, "utf8mb4_general_ci"), binConst)...)
+		c.Assert(err, IsNil, Commentf("%s", funcName))
+		_, err = f.Eval(nil, s.ctx)
+		c.Assert(err, IsNil, Commentf("%s", funcName))
+		// A number doesn't take part in the coercion.
+		_, err = NewFunction(funcName, types.NewFieldType(mysql.TypeLonglong),
+			newArgs(funcName, collate(ciConst, "utf8mb4_general_ci"), &Constant{Value: types.NewIntDatum(1)})...)
+		c.Assert(err, IsNil, Commentf("%s", funcName))
+	}
+}
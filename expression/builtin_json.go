@@ -0,0 +1,1134 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// TiDB has no JSON column type yet, the JSON functions work on JSON documents in text.
+// A parsed document is made of nil, bool, json.Number, string, []interface{} and map[string]interface{}.
+
+// parseJSON parses the JSON text of the argument argIdx (counted from 1) of the function funcName.
+func parseJSON(str string, argIdx int, funcName string) (interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(str))
+	dec.UseNumber()
+	var doc interface{}
+	err := dec.Decode(&doc)
+	if err == nil {
+		// There must be nothing but spaces after the document.
+		var extra interface{}
+		if err = dec.Decode(&extra); err == io.EOF {
+			return doc, nil
+		}
+		if err == nil {
+			err = errors.New("The document root must not be followed by other values.")
+		}
+	}
+	if err == io.EOF {
+		err = errors.New("The document is empty.")
+	}
+	var pos int64
+	if syntaxErr, ok := err.(*json.SyntaxError); ok {
+		pos = syntaxErr.Offset
+	}
+	return nil, errInvalidJSONText.GenByArgs(argIdx, funcName, err.Error(), pos)
+}
+
+// evalJSONArg evaluates the argument idx and parses it as a JSON document.
+func (b *baseBuiltinFunc) evalJSONArg(row []types.Datum, idx int, funcName string) (doc interface{}, isNull bool, err error) {
+	arg, err := b.args[idx].Eval(row, b.ctx)
+	if err != nil || arg.IsNull() {
+		return nil, true, errors.Trace(err)
+	}
+	str, err := arg.ToString()
+	if err != nil {
+		return nil, true, errors.Trace(err)
+	}
+	doc, err = parseJSON(str, idx+1, funcName)
+	return doc, false, errors.Trace(err)
+}
+
+// jsonKeys sorts the keys of an object in the order of MySQL, the shorter keys come first
+// and the keys of the same length are sorted by their bytes.
+type jsonKeys []string
+
+func (s jsonKeys) Len() int      { return len(s) }
+func (s jsonKeys) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s jsonKeys) Less(i, j int) bool {
+	if len(s[i]) != len(s[j]) {
+		return len(s[i]) < len(s[j])
+	}
+	return s[i] < s[j]
+}
+
+func sortedJSONKeys(obj map[string]interface{}) []string {
+	keys := make(jsonKeys, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Sort(keys)
+	return keys
+}
+
+// quoteJSONString writes str as a JSON string literal, the quotes, the backslashes and the control characters are escaped.
+func quoteJSONString(buf *bytes.Buffer, str string) {
+	const hexDigits = "0123456789abcdef"
+	buf.WriteByte('"')
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		switch c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[c>>4])
+				buf.WriteByte(hexDigits[c&0xF])
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// writeJSONScalar writes a JSON value which is neither an array nor an object.
+func writeJSONScalar(buf *bytes.Buffer, v interface{}) {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		if x {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case json.Number:
+		buf.WriteString(x.String())
+	case string:
+		quoteJSONString(buf, x)
+	}
+}
+
+// writePrettyJSON writes the JSON value in the format of JSON_PRETTY, every array element and object member
+// is on its own line and indented by 2 spaces for each nesting level.
+func writePrettyJSON(buf *bytes.Buffer, v interface{}, level int) {
+	newLine := func(level int) {
+		buf.WriteByte('\n')
+		for i := 0; i < level; i++ {
+			buf.WriteString("  ")
+		}
+	}
+	switch x := v.(type) {
+	case []interface{}:
+		if len(x) == 0 {
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteByte('[')
+		for i, elem := range x {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			newLine(level + 1)
+			writePrettyJSON(buf, elem, level+1)
+		}
+		newLine(level)
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		if len(x) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteByte('{')
+		for i, key := range sortedJSONKeys(x) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			newLine(level + 1)
+			quoteJSONString(buf, key)
+			buf.WriteString(": ")
+			writePrettyJSON(buf, x[key], level+1)
+		}
+		newLine(level)
+		buf.WriteByte('}')
+	default:
+		writeJSONScalar(buf, v)
+	}
+}
+
+type jsonPrettyFuncClass struct {
+	baseFuncClass
+}
+
+func (c *jsonPrettyFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONPretty{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinJSONPretty struct {
+	baseBuiltinFunc
+}
+
+// eval returns the JSON document formatted with one array element or object member per line.
+// See https://dev.mysql.com/doc/refman/5.7/en/json-utility-functions.html#function_json-pretty
+func (b *builtinJSONPretty) eval(row []types.Datum) (d types.Datum, err error) {
+	doc, isNull, err := b.evalJSONArg(row, 0, ast.JSONPretty)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	var buf bytes.Buffer
+	writePrettyJSON(&buf, doc, 0)
+	d.SetString(buf.String())
+	return d, nil
+}
+
+// jsonNumberKind classifies a number like MySQL does when it's stored, the integers fitting in int64 or uint64
+// are stored as integers and the other numbers are stored as doubles.
+func jsonNumberKind(n json.Number) (i int64, u uint64, kind byte) {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return i, 0, types.KindInt64
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return 0, u, types.KindUint64
+	}
+	return 0, 0, types.KindFloat64
+}
+
+// jsonValueInlined checks whether the value is stored in its entry of the parent array or object
+// in the MySQL binary JSON format.
+func jsonValueInlined(v interface{}, large bool) bool {
+	switch x := v.(type) {
+	case nil, bool:
+		return true
+	case json.Number:
+		i, u, kind := jsonNumberKind(x)
+		switch kind {
+		case types.KindInt64:
+			return (i >= math.MinInt16 && i <= math.MaxInt16) || (large && i >= math.MinInt32 && i <= math.MaxInt32)
+		case types.KindUint64:
+			return u <= math.MaxUint16 || (large && u <= math.MaxUint32)
+		}
+	}
+	return false
+}
+
+// jsonBinarySize returns the size of the value in the MySQL binary JSON format, without the type byte.
+// See https://github.com/mysql/mysql-server/blob/5.7/sql/json_binary.h
+func jsonBinarySize(v interface{}) int {
+	switch x := v.(type) {
+	case nil, bool:
+		return 1
+	case json.Number:
+		i, u, kind := jsonNumberKind(x)
+		switch {
+		case kind == types.KindInt64 && i >= math.MinInt16 && i <= math.MaxInt16,
+			kind == types.KindUint64 && u <= math.MaxUint16:
+			return 2
+		case kind == types.KindInt64 && i >= math.MinInt32 && i <= math.MaxInt32,
+			kind == types.KindUint64 && u <= math.MaxUint32:
+			return 4
+		}
+		return 8
+	case string:
+		// The length is stored in a variable length integer, 7 bits in each byte.
+		n := 1
+		for l := len(x); l >= 1<<7; l >>= 7 {
+			n++
+		}
+		return n + len(x)
+	case []interface{}:
+		if size := jsonContainerSize(nil, x, false); size <= math.MaxUint16 {
+			return size
+		}
+		return jsonContainerSize(nil, x, true)
+	case map[string]interface{}:
+		keys := sortedJSONKeys(x)
+		values := make([]interface{}, 0, len(keys))
+		for _, key := range keys {
+			values = append(values, x[key])
+		}
+		if size := jsonContainerSize(keys, values, false); size <= math.MaxUint16 {
+			return size
+		}
+		return jsonContainerSize(keys, values, true)
+	}
+	return 0
+}
+
+// jsonContainerSize returns the size of an array or an object in the small or the large format.
+// An array or an object has a header of the element count and the size, the key entries if it's an object,
+// the value entries, then the keys and the values which are not inlined.
+func jsonContainerSize(keys []string, values []interface{}, large bool) int {
+	offsetSize := 2
+	if large {
+		offsetSize = 4
+	}
+	size := 2 * offsetSize
+	for _, key := range keys {
+		// The key entry has the offset and the 2 bytes length of the key.
+		size += offsetSize + 2 + len(key)
+	}
+	for _, v := range values {
+		size += 1 + offsetSize
+		if !jsonValueInlined(v, large) {
+			size += jsonBinarySize(v)
+		}
+	}
+	return size
+}
+
+type jsonStorageSizeFuncClass struct {
+	baseFuncClass
+}
+
+func (c *jsonStorageSizeFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONStorageSize{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinJSONStorageSize struct {
+	baseBuiltinFunc
+}
+
+// eval returns the number of bytes to store the JSON document in the binary format of MySQL.
+// See https://dev.mysql.com/doc/refman/5.7/en/json-utility-functions.html#function_json-storage-size
+func (b *builtinJSONStorageSize) eval(row []types.Datum) (d types.Datum, err error) {
+	doc, isNull, err := b.evalJSONArg(row, 0, ast.JSONStorageSize)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	// The type byte of the document comes first.
+	d.SetInt64(int64(1 + jsonBinarySize(doc)))
+	return d, nil
+}
+
+type jsonStorageFreeFuncClass struct {
+	baseFuncClass
+}
+
+func (c *jsonStorageFreeFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONStorageFree{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinJSONStorageFree struct {
+	baseBuiltinFunc
+}
+
+// eval returns the number of bytes freed by the partial updates of the JSON document.
+// The documents are never updated in place, so it's always 0 for a valid document.
+// See https://dev.mysql.com/doc/refman/8.0/en/json-utility-functions.html#function_json-storage-free
+func (b *builtinJSONStorageFree) eval(row []types.Datum) (d types.Datum, err error) {
+	_, isNull, err := b.evalJSONArg(row, 0, ast.JSONStorageFree)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	d.SetInt64(0)
+	return d, nil
+}
+
+type jsonQuoteFuncClass struct {
+	baseFuncClass
+}
+
+func (c *jsonQuoteFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONQuote{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinJSONQuote struct {
+	baseBuiltinFunc
+}
+
+// eval quotes the string as a JSON string literal.
+// See https://dev.mysql.com/doc/refman/5.7/en/json-creation-functions.html#function_json-quote
+func (b *builtinJSONQuote) eval(row []types.Datum) (d types.Datum, err error) {
+	arg, err := b.args[0].Eval(row, b.ctx)
+	if err != nil || arg.IsNull() {
+		return d, errors.Trace(err)
+	}
+	str, err := arg.ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	var buf bytes.Buffer
+	quoteJSONString(&buf, str)
+	d.SetString(buf.String())
+	return d, nil
+}
+
+// The kinds of the JSON path legs.
+const (
+	jsonPathLegKey = iota
+	jsonPathLegIndex
+	// jsonPathLegAnyKey is '.*', it matches all the members of an object.
+	jsonPathLegAnyKey
+	// jsonPathLegAnyIndex is '[*]', it matches all the elements of an array.
+	jsonPathLegAnyIndex
+	// jsonPathLegAnyPath is '**', it matches all the paths beginning with the prefix and ending with the suffix.
+	jsonPathLegAnyPath
+)
+
+type jsonPathLeg struct {
+	kind  int
+	key   string
+	index int
+}
+
+// jsonPath is a parsed JSON path expression like '$.a[0]."b c"', the legs don't include the leading '$'.
+type jsonPath []jsonPathLeg
+
+func (p jsonPath) hasWildcard() bool {
+	for _, leg := range p {
+		if leg.kind != jsonPathLegKey && leg.kind != jsonPathLegIndex {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats the path like MySQL, a key is quoted if it's not an identifier.
+func (p jsonPath) String() string {
+	var buf bytes.Buffer
+	buf.WriteByte('$')
+	for _, leg := range p {
+		switch leg.kind {
+		case jsonPathLegKey:
+			buf.WriteByte('.')
+			if isJSONPathIdentifier(leg.key) {
+				buf.WriteString(leg.key)
+			} else {
+				quoteJSONString(&buf, leg.key)
+			}
+		case jsonPathLegIndex:
+			buf.WriteByte('[')
+			buf.WriteString(strconv.Itoa(leg.index))
+			buf.WriteByte(']')
+		case jsonPathLegAnyKey:
+			buf.WriteString(".*")
+		case jsonPathLegAnyIndex:
+			buf.WriteString("[*]")
+		case jsonPathLegAnyPath:
+			buf.WriteString("**")
+		}
+	}
+	return buf.String()
+}
+
+func isJSONPathIdentifier(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c == '_' || c == '$' || c >= 0x80 || unicode.IsLetter(rune(c)) || (i > 0 && unicode.IsDigit(rune(c))) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// parseJSONPath parses a JSON path expression.
+// See https://dev.mysql.com/doc/refman/5.7/en/json-path-syntax.html
+func parseJSONPath(str string) (jsonPath, error) {
+	i := 0
+	skipSpaces := func() {
+		for i < len(str) && unicode.IsSpace(rune(str[i])) {
+			i++
+		}
+	}
+	skipSpaces()
+	if i == len(str) || str[i] != '$' {
+		return nil, errInvalidJSONPath.GenByArgs(i)
+	}
+	i++
+	var path jsonPath
+	for skipSpaces(); i < len(str); skipSpaces() {
+		switch {
+		case str[i] == '.':
+			i++
+			skipSpaces()
+			if i == len(str) {
+				return nil, errInvalidJSONPath.GenByArgs(i)
+			}
+			if str[i] == '*' {
+				i++
+				path = append(path, jsonPathLeg{kind: jsonPathLegAnyKey})
+				continue
+			}
+			if str[i] == '"' {
+				end := i + 1
+				for end < len(str) && str[end] != '"' {
+					if str[end] == '\\' {
+						end++
+					}
+					end++
+				}
+				var key string
+				if end >= len(str) || json.Unmarshal([]byte(str[i:end+1]), &key) != nil {
+					return nil, errInvalidJSONPath.GenByArgs(i)
+				}
+				i = end + 1
+				path = append(path, jsonPathLeg{kind: jsonPathLegKey, key: key})
+				continue
+			}
+			end := i
+			for end < len(str) && str[end] != '.' && str[end] != '[' && str[end] != '*' && !unicode.IsSpace(rune(str[end])) {
+				end++
+			}
+			if !isJSONPathIdentifier(str[i:end]) {
+				return nil, errInvalidJSONPath.GenByArgs(i)
+			}
+			path = append(path, jsonPathLeg{kind: jsonPathLegKey, key: str[i:end]})
+			i = end
+		case str[i] == '[':
+			i++
+			skipSpaces()
+			leg := jsonPathLeg{kind: jsonPathLegAnyIndex}
+			if i < len(str) && str[i] == '*' {
+				i++
+			} else {
+				end := i
+				for end < len(str) && str[end] >= '0' && str[end] <= '9' {
+					end++
+				}
+				index, err := strconv.ParseInt(str[i:end], 10, 32)
+				if err != nil {
+					return nil, errInvalidJSONPath.GenByArgs(i)
+				}
+				leg = jsonPathLeg{kind: jsonPathLegIndex, index: int(index)}
+				i = end
+			}
+			skipSpaces()
+			if i == len(str) || str[i] != ']' {
+				return nil, errInvalidJSONPath.GenByArgs(i)
+			}
+			i++
+			path = append(path, leg)
+		case strings.HasPrefix(str[i:], "**"):
+			i += 2
+			path = append(path, jsonPathLeg{kind: jsonPathLegAnyPath})
+		default:
+			return nil, errInvalidJSONPath.GenByArgs(i)
+		}
+	}
+	// '**' must be followed by another leg.
+	if n := len(path); n > 0 && path[n-1].kind == jsonPathLegAnyPath {
+		return nil, errInvalidJSONPath.GenByArgs(len(str))
+	}
+	return path, nil
+}
+
+// walkJSON calls fn with every value in v matching the path, and the path to the value without wildcards,
+// prefix is the path to v. The walking stops if fn returns false, and walkJSON returns false too.
+func walkJSON(v interface{}, path jsonPath, prefix jsonPath, fn func(v interface{}, path jsonPath) bool) bool {
+	if len(path) == 0 {
+		return fn(v, prefix)
+	}
+	child := func(leg jsonPathLeg, v interface{}, rest jsonPath) bool {
+		p := make(jsonPath, len(prefix), len(prefix)+1)
+		copy(p, prefix)
+		return walkJSON(v, rest, append(p, leg), fn)
+	}
+	leg, rest := path[0], path[1:]
+	switch leg.kind {
+	case jsonPathLegKey:
+		if obj, ok := v.(map[string]interface{}); ok {
+			if elem, ok := obj[leg.key]; ok {
+				return child(leg, elem, rest)
+			}
+		}
+	case jsonPathLegIndex:
+		if arr, ok := v.([]interface{}); ok && leg.index < len(arr) {
+			return child(leg, arr[leg.index], rest)
+		}
+	case jsonPathLegAnyKey, jsonPathLegAnyIndex, jsonPathLegAnyPath:
+		if leg.kind == jsonPathLegAnyPath && !walkJSON(v, rest, prefix, fn) {
+			return false
+		}
+		if leg.kind == jsonPathLegAnyPath {
+			// '**' is kept to match the descendants.
+			rest = path
+		}
+		switch x := v.(type) {
+		case []interface{}:
+			if leg.kind == jsonPathLegAnyKey {
+				break
+			}
+			for i, elem := range x {
+				if !child(jsonPathLeg{kind: jsonPathLegIndex, index: i}, elem, rest) {
+					return false
+				}
+			}
+		case map[string]interface{}:
+			if leg.kind == jsonPathLegAnyIndex {
+				break
+			}
+			for _, key := range sortedJSONKeys(x) {
+				if !child(jsonPathLeg{kind: jsonPathLegKey, key: key}, x[key], rest) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// writeJSON writes the JSON value in the format of MySQL, like '{"a": [1, 2]}'.
+func writeJSON(buf *bytes.Buffer, v interface{}) {
+	switch x := v.(type) {
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, elem := range x {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			writeJSON(buf, elem)
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		buf.WriteByte('{')
+		for i, key := range sortedJSONKeys(x) {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			quoteJSONString(buf, key)
+			buf.WriteString(": ")
+			writeJSON(buf, x[key])
+		}
+		buf.WriteByte('}')
+	default:
+		writeJSONScalar(buf, v)
+	}
+}
+
+type jsonSearchFuncClass struct {
+	baseFuncClass
+}
+
+func (c *jsonSearchFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONSearch{newBaseBuiltinFunc(args, ctx), newConstJSONPaths(args, 4, 1)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinJSONSearch struct {
+	baseBuiltinFunc
+	constPaths constJSONPaths
+}
+
+// eval returns the paths to the strings in the document matching the search string, which may have the wildcards of LIKE.
+// 'one' returns the first path found, 'all' returns all the paths in an array, or the path if there is only one.
+// It returns NULL if nothing is found, or the document, the search string or any path is NULL.
+// See https://dev.mysql.com/doc/refman/5.7/en/json-search-functions.html#function_json-search
+func (b *builtinJSONSearch) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	for i, arg := range args {
+		// The escape character is '\' if it's NULL.
+		if arg.IsNull() && i != 3 {
+			return d, nil
+		}
+	}
+	docStr, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	doc, err := parseJSON(docStr, 1, ast.JSONSearch)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	oneOrAll, err := args[1].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	oneOrAll = strings.ToLower(oneOrAll)
+	if oneOrAll != "one" && oneOrAll != "all" {
+		return d, errJSONBadOneOrAllArg.GenByArgs(ast.JSONSearch)
+	}
+	search, err := args[2].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	escape := int('\\')
+	if len(args) > 3 && !args[3].IsNull() {
+		escapeStr, err := args[3].ToString()
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		if len(escapeStr) > 1 {
+			return d, errIncorrectArgs.GenByArgs("ESCAPE")
+		}
+		if len(escapeStr) == 1 {
+			escape = int(escapeStr[0])
+		}
+	}
+	ci, err := LikeCaseInsensitive(b.args[2], b.args[2])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	patChars, patTypes := compilePattern(search, escape)
+	matcher := newLikeMatcher(patChars, patTypes, ci)
+
+	paths := []jsonPath{nil}
+	if len(args) > 4 {
+		paths = paths[:0]
+		for i, arg := range args[4:] {
+			if path, ok := b.constPaths[i+4]; ok {
+				paths = append(paths, path)
+				continue
+			}
+			pathStr, err := arg.ToString()
+			if err != nil {
+				return d, errors.Trace(err)
+			}
+			path, err := parseJSONPath(pathStr)
+			if err != nil {
+				return d, errors.Trace(err)
+			}
+			paths = append(paths, path)
+		}
+	}
+	var found []interface{}
+	seen := make(map[string]bool)
+	// Every string under the values matching the paths is searched.
+	for _, path := range paths {
+		complete := walkJSON(doc, path, nil, func(v interface{}, path jsonPath) bool {
+			return searchJSONStrings(v, path, func(str string, path jsonPath) bool {
+				if !matcher.match(str) {
+					return true
+				}
+				pathStr := path.String()
+				if !seen[pathStr] {
+					seen[pathStr] = true
+					found = append(found, pathStr)
+				}
+				return oneOrAll == "all"
+			})
+		})
+		if !complete {
+			break
+		}
+	}
+	var buf bytes.Buffer
+	switch len(found) {
+	case 0:
+		return d, nil
+	case 1:
+		writeJSON(&buf, found[0])
+	default:
+		writeJSON(&buf, found)
+	}
+	d.SetString(buf.String())
+	return d, nil
+}
+
+// searchJSONStrings calls fn with every string in v and the path to it in document order, prefix is the path to v.
+// The searching stops if fn returns false, and searchJSONStrings returns false too.
+func searchJSONStrings(v interface{}, prefix jsonPath, fn func(str string, path jsonPath) bool) bool {
+	child := func(leg jsonPathLeg, v interface{}) bool {
+		p := make(jsonPath, len(prefix), len(prefix)+1)
+		copy(p, prefix)
+		return searchJSONStrings(v, append(p, leg), fn)
+	}
+	switch x := v.(type) {
+	case string:
+		return fn(x, prefix)
+	case []interface{}:
+		for i, elem := range x {
+			if !child(jsonPathLeg{kind: jsonPathLegIndex, index: i}, elem) {
+				return false
+			}
+		}
+	case map[string]interface{}:
+		for _, key := range sortedJSONKeys(x) {
+			if !child(jsonPathLeg{kind: jsonPathLegKey, key: key}, x[key]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// constJSONPaths holds the paths parsed from the constant path arguments of a JSON function when it's built,
+// keyed by the index of the argument, so they aren't parsed again for every row.
+// A NULL or invalid constant path isn't held, it's handled when the function is evaluated.
+type constJSONPaths map[int]jsonPath
+
+// newConstJSONPaths parses the constant arguments among the path arguments args[start], args[start+step], ...
+func newConstJSONPaths(args []Expression, start, step int) constJSONPaths {
+	paths := make(constJSONPaths)
+	for i := start; i < len(args); i += step {
+		con, ok := args[i].(*Constant)
+		if !ok || con.Value.IsNull() {
+			continue
+		}
+		str, err := con.Value.ToString()
+		if err != nil {
+			continue
+		}
+		if path, err := parseJSONPath(str); err == nil {
+			paths[i] = path
+		}
+	}
+	return paths
+}
+
+// evalJSONPath evaluates and parses the path argument idx, the path in constPaths is used if it's a constant.
+// isNull is true if the path is NULL.
+func (b *baseBuiltinFunc) evalJSONPath(row []types.Datum, idx int, constPaths constJSONPaths) (path jsonPath, isNull bool, err error) {
+	if path, ok := constPaths[idx]; ok {
+		return path, false, nil
+	}
+	d, err := b.args[idx].Eval(row, b.ctx)
+	if err != nil || d.IsNull() {
+		return nil, true, errors.Trace(err)
+	}
+	str, err := d.ToString()
+	if err != nil {
+		return nil, true, errors.Trace(err)
+	}
+	path, err = parseJSONPath(str)
+	return path, false, errors.Trace(err)
+}
+
+// evalJSONPaths evaluates and parses the path arguments starting from the argument idx,
+// isNull is true if any of them is NULL. The paths may not contain wildcards if noWildcard is true.
+func (b *baseBuiltinFunc) evalJSONPaths(row []types.Datum, idx int, constPaths constJSONPaths, noWildcard bool) (paths []jsonPath, isNull bool, err error) {
+	for i := idx; i < len(b.args); i++ {
+		path, isNull, err := b.evalJSONPath(row, i, constPaths)
+		if err != nil || isNull {
+			return nil, true, errors.Trace(err)
+		}
+		if noWildcard && path.hasWildcard() {
+			return nil, true, errInvalidJSONPathWildcard
+		}
+		paths = append(paths, path)
+	}
+	return paths, false, nil
+}
+
+// removeJSON removes the value at the path without wildcards from v and returns the new v,
+// nothing is removed if the path doesn't exist.
+func removeJSON(v interface{}, path jsonPath) interface{} {
+	leg := path[0]
+	switch x := v.(type) {
+	case []interface{}:
+		if leg.kind != jsonPathLegIndex || leg.index >= len(x) {
+			break
+		}
+		if len(path) == 1 {
+			return append(x[:leg.index], x[leg.index+1:]...)
+		}
+		x[leg.index] = removeJSON(x[leg.index], path[1:])
+	case map[string]interface{}:
+		elem, ok := x[leg.key]
+		if leg.kind != jsonPathLegKey || !ok {
+			break
+		}
+		if len(path) == 1 {
+			delete(x, leg.key)
+		} else {
+			x[leg.key] = removeJSON(elem, path[1:])
+		}
+	}
+	return v
+}
+
+type jsonRemoveFuncClass struct {
+	baseFuncClass
+}
+
+func (c *jsonRemoveFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONRemove{newBaseBuiltinFunc(args, ctx), newConstJSONPaths(args, 1, 1)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinJSONRemove struct {
+	baseBuiltinFunc
+	constPaths constJSONPaths
+}
+
+// eval removes the values at the paths from the document, the paths are evaluated from left to right,
+// so a path is applied to the document with the values at the former paths removed.
+// See https://dev.mysql.com/doc/refman/5.7/en/json-modification-functions.html#function_json-remove
+func (b *builtinJSONRemove) eval(row []types.Datum) (d types.Datum, err error) {
+	doc, isNull, err := b.evalJSONArg(row, 0, ast.JSONRemove)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	paths, isNull, err := b.evalJSONPaths(row, 1, b.constPaths, true)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	for _, path := range paths {
+		if len(path) == 0 {
+			return d, errJSONVacuousPath
+		}
+		doc = removeJSON(doc, path)
+	}
+	var buf bytes.Buffer
+	writeJSON(&buf, doc)
+	d.SetString(buf.String())
+	return d, nil
+}
+
+// datumToJSON converts a datum to a JSON value, a string is converted to a JSON string instead of being parsed.
+func datumToJSON(d types.Datum) (interface{}, error) {
+	switch d.Kind() {
+	case types.KindNull:
+		return nil, nil
+	case types.KindInt64, types.KindUint64, types.KindFloat32, types.KindFloat64, types.KindMysqlDecimal:
+		str, err := d.ToString()
+		return json.Number(str), errors.Trace(err)
+	default:
+		str, err := d.ToString()
+		return str, errors.Trace(err)
+	}
+}
+
+// updateJSON replaces the value at the path without wildcards in v with the result of fn and returns the new v,
+// nothing is changed if the path doesn't exist.
+func updateJSON(v interface{}, path jsonPath, fn func(v interface{}) interface{}) interface{} {
+	if len(path) == 0 {
+		return fn(v)
+	}
+	leg := path[0]
+	switch x := v.(type) {
+	case []interface{}:
+		if leg.kind == jsonPathLegIndex && leg.index < len(x) {
+			x[leg.index] = updateJSON(x[leg.index], path[1:], fn)
+		}
+	case map[string]interface{}:
+		if elem, ok := x[leg.key]; ok && leg.kind == jsonPathLegKey {
+			x[leg.key] = updateJSON(elem, path[1:], fn)
+		}
+	}
+	return v
+}
+
+// jsonPathValueFuncClass is the function class of the functions taking a document followed by pairs of path and value.
+type jsonPathValueFuncClass struct {
+	baseFuncClass
+}
+
+func (c *jsonPathValueFuncClass) checkValid(args []Expression) error {
+	if err := c.baseFuncClass.checkValid(args); err != nil {
+		return errors.Trace(err)
+	}
+	if len(args)%2 == 0 {
+		return errIncorrectParameterCount.GenByArgs(c.funcName)
+	}
+	return nil
+}
+
+// evalJSONPathValues evaluates the document and the pairs of path and value of the function funcName,
+// isNull is true if the document or any path is NULL.
+func (b *baseBuiltinFunc) evalJSONPathValues(row []types.Datum, funcName string, constPaths constJSONPaths) (doc interface{}, paths []jsonPath, values []interface{}, isNull bool, err error) {
+	doc, isNull, err = b.evalJSONArg(row, 0, funcName)
+	if err != nil || isNull {
+		return nil, nil, nil, true, errors.Trace(err)
+	}
+	for i := 1; i < len(b.args); i += 2 {
+		path, isNull, err := b.evalJSONPath(row, i, constPaths)
+		if err != nil || isNull {
+			return nil, nil, nil, true, errors.Trace(err)
+		}
+		if path.hasWildcard() {
+			return nil, nil, nil, true, errInvalidJSONPathWildcard
+		}
+		d, err := b.args[i+1].Eval(row, b.ctx)
+		if err != nil {
+			return nil, nil, nil, true, errors.Trace(err)
+		}
+		value, err := datumToJSON(d)
+		if err != nil {
+			return nil, nil, nil, true, errors.Trace(err)
+		}
+		paths = append(paths, path)
+		values = append(values, value)
+	}
+	return doc, paths, values, false, nil
+}
+
+type jsonArrayAppendFuncClass struct {
+	jsonPathValueFuncClass
+}
+
+func (c *jsonArrayAppendFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONArrayAppend{newBaseBuiltinFunc(args, ctx), newConstJSONPaths(args, 1, 2)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinJSONArrayAppend struct {
+	baseBuiltinFunc
+	constPaths constJSONPaths
+}
+
+// eval appends the values to the end of the arrays at the paths, a value which is not an array
+// is wrapped into an array before appending. The pairs of path and value are evaluated from left to right.
+// See https://dev.mysql.com/doc/refman/5.7/en/json-modification-functions.html#function_json-array-append
+func (b *builtinJSONArrayAppend) eval(row []types.Datum) (d types.Datum, err error) {
+	doc, paths, values, isNull, err := b.evalJSONPathValues(row, ast.JSONArrayAppend, b.constPaths)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	for i, path := range paths {
+		doc = updateJSON(doc, path, func(v interface{}) interface{} {
+			if arr, ok := v.([]interface{}); ok {
+				return append(arr, values[i])
+			}
+			return []interface{}{v, values[i]}
+		})
+	}
+	var buf bytes.Buffer
+	writeJSON(&buf, doc)
+	d.SetString(buf.String())
+	return d, nil
+}
+
+type jsonArrayInsertFuncClass struct {
+	jsonPathValueFuncClass
+}
+
+func (c *jsonArrayInsertFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONArrayInsert{newBaseBuiltinFunc(args, ctx), newConstJSONPaths(args, 1, 2)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinJSONArrayInsert struct {
+	baseBuiltinFunc
+	constPaths constJSONPaths
+}
+
+// eval inserts the values into the arrays at the positions of the paths, the following elements are shifted right.
+// A value is appended if the position is beyond the end of the array, and nothing is inserted if the path
+// doesn't point into an array. The pairs of path and value are evaluated from left to right.
+// See https://dev.mysql.com/doc/refman/5.7/en/json-modification-functions.html#function_json-array-insert
+func (b *builtinJSONArrayInsert) eval(row []types.Datum) (d types.Datum, err error) {
+	doc, paths, values, isNull, err := b.evalJSONPathValues(row, ast.JSONArrayInsert, b.constPaths)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	for _, path := range paths {
+		if len(path) == 0 || path[len(path)-1].kind != jsonPathLegIndex {
+			return d, errInvalidJSONPathArrayCell
+		}
+	}
+	for i, path := range paths {
+		index := path[len(path)-1].index
+		doc = updateJSON(doc, path[:len(path)-1], func(v interface{}) interface{} {
+			arr, ok := v.([]interface{})
+			if !ok {
+				return v
+			}
+			if index > len(arr) {
+				index = len(arr)
+			}
+			arr = append(arr, nil)
+			copy(arr[index+1:], arr[index:])
+			arr[index] = values[i]
+			return arr
+		})
+	}
+	var buf bytes.Buffer
+	writeJSON(&buf, doc)
+	d.SetString(buf.String())
+	return d, nil
+}
+
+// builtinCastToJSON converts the value to a JSON document, a string is parsed as JSON text,
+// a number is converted to a JSON number, and a temporal value is converted to a JSON string.
+// TiDB has no boolean type, so TRUE and FALSE are converted to the numbers 1 and 0.
+// See https://dev.mysql.com/doc/refman/5.7/en/json-creation-functions.html
+func builtinCastToJSON(args []types.Datum, _ context.Context) (d types.Datum, err error) {
+	var v interface{}
+	switch x := args[0]; x.Kind() {
+	case types.KindNull:
+		return d, nil
+	case types.KindString, types.KindBytes:
+		v, err = parseJSON(x.GetString(), 1, "cast_as_json")
+	case types.KindMysqlTime:
+		t := x.GetMysqlTime()
+		if t.Type != mysql.TypeDate {
+			t.Fsp = types.MaxFsp
+		}
+		v = t.String()
+	case types.KindMysqlDuration:
+		dur := x.GetMysqlDuration()
+		dur.Fsp = types.MaxFsp
+		v = dur.String()
+	default:
+		v, err = datumToJSON(x)
+	}
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	var buf bytes.Buffer
+	writeJSON(&buf, v)
+	d.SetString(buf.String())
+	return d, nil
+}
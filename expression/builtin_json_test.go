@@ -0,0 +1,389 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/testutil"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func (s *testEvaluatorSuite) TestJSONPretty(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		doc interface{}
+		ret interface{}
+	}{
+		{`{"a":"10","b":"15","x":{"p":1,"q":2}}`, "{\n  \"a\": \"10\",\n  \"b\": \"15\",\n  \"x\": {\n    \"p\": 1,\n    \"q\": 2\n  }\n}"},
+		{`[1,3,5]`, "[\n  1,\n  3,\n  5\n]"},
+		{`{"ab": [1, {"c": null}], "b": [], "a": {}}`, "{\n  \"a\": {},\n  \"b\": [],\n  \"ab\": [\n    1,\n    {\n      \"c\": null\n    }\n  ]\n}"},
+		{` "a\"b\n" `, `"a\"b\n"`},
+		{`true`, `true`},
+		{`-1.5e3`, `-1.5e3`},
+		{nil, nil},
+	}
+	for _, t := range tbl {
+		d, err := evalFuncClass(ast.JSONPretty, types.MakeDatums(t.doc), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v", t.doc))
+	}
+
+	for _, doc := range []string{"", "{", `{"a": 1} 2`, "[1,]", "abc"} {
+		_, err := evalFuncClass(ast.JSONPretty, types.MakeDatums(doc), s.ctx)
+		c.Assert(terror.ErrorEqual(err, errInvalidJSONText), IsTrue, Commentf("%s", doc))
+	}
+}
+
+func (s *testEvaluatorSuite) TestJSONStorageSize(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		doc  interface{}
+		size interface{}
+	}{
+		// The examples in the MySQL reference manual.
+		{`[100, "sakila", [1, 3, 5], 425.05]`, int64(45)},
+		{`{"a": 1000, "b": "wxyz", "c": "[1, 3, 5, 7]"}`, int64(47)},
+		{`"Hello World!"`, int64(14)},
+		{`null`, int64(2)},
+		{`true`, int64(2)},
+		{`1`, int64(3)},
+		{`-100000`, int64(5)},
+		{`-2147483648`, int64(5)},
+		{`4294967295`, int64(9)},
+		{`18446744073709551615`, int64(9)},
+		{`1.5`, int64(9)},
+		{`[]`, int64(5)},
+		{`{}`, int64(5)},
+		// Only the 16 bits integers are inlined in a small array.
+		{`[100000]`, int64(12)},
+		{nil, nil},
+	}
+	for _, t := range tbl {
+		d, err := evalFuncClass(ast.JSONStorageSize, types.MakeDatums(t.doc), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.size), Commentf("%v", t.doc))
+		d, err = evalFuncClass(ast.JSONStorageFree, types.MakeDatums(t.doc), s.ctx)
+		c.Assert(err, IsNil)
+		if t.doc == nil {
+			c.Assert(d.IsNull(), IsTrue)
+		} else {
+			c.Assert(d, testutil.DatumEquals, types.NewIntDatum(0))
+		}
+	}
+
+	// A string longer than 64KB makes the array use the large format.
+	long := strings.Repeat("a", 1<<16)
+	d, err := evalFuncClass(ast.JSONStorageSize, types.MakeDatums(`["`+long+`", 1]`), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d, testutil.DatumEquals, types.NewIntDatum(1+8+2*5+3+1<<16))
+
+	for _, name := range []string{ast.JSONStorageSize, ast.JSONStorageFree} {
+		_, err := evalFuncClass(name, types.MakeDatums("[1"), s.ctx)
+		c.Assert(terror.ErrorEqual(err, errInvalidJSONText), IsTrue)
+	}
+}
+
+func (s *testEvaluatorSuite) TestJSONQuote(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		str interface{}
+		ret interface{}
+	}{
+		{`null`, `"null"`},
+		{`"null"`, `"\"null\""`},
+		{`[1, 2, 3]`, `"[1, 2, 3]"`},
+		{`a\b`, `"a\\b"`},
+		{"line1\nline2\r\t", `"line1\nline2\r\t"`},
+		{"\x00\x01\x1f\b\f", `"\u0000\u0001\u001f\b\f"`},
+		{"héllo, 世界", `"héllo, 世界"`},
+		{"", `""`},
+		{nil, nil},
+	}
+	for _, t := range tbl {
+		d, err := evalFuncClass(ast.JSONQuote, types.MakeDatums(t.str), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%q", t.str))
+		if t.str == nil {
+			continue
+		}
+		// The result is a valid JSON document of the original string.
+		doc, err := parseJSON(d.GetString(), 1, ast.JSONQuote)
+		c.Assert(err, IsNil)
+		c.Assert(doc, Equals, t.str)
+	}
+}
+
+func (s *testEvaluatorSuite) TestJSONPath(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		path        string
+		str         string
+		hasWildcard bool
+	}{
+		{`$`, `$`, false},
+		{` $ . a [ 1 ] `, `$.a[1]`, false},
+		{`$."a b"[0]."c"`, `$."a b"[0].c`, false},
+		{`$.a1.$_b`, `$.a1.$_b`, false},
+		{`$."1a"`, `$."1a"`, false},
+		{`$.*[*]`, `$.*[*]`, true},
+		{`$**.a`, `$**.a`, true},
+	}
+	for _, t := range tbl {
+		path, err := parseJSONPath(t.path)
+		c.Assert(err, IsNil, Commentf("%s", t.path))
+		c.Assert(path.String(), Equals, t.str)
+		c.Assert(path.hasWildcard(), Equals, t.hasWildcard)
+	}
+	for _, str := range []string{``, `a`, `$.`, `$a`, `$.1a`, `$[a]`, `$[-1]`, `$[1`, `$."a`, `$**`, `$.a b`} {
+		_, err := parseJSONPath(str)
+		c.Assert(terror.ErrorEqual(err, errInvalidJSONPath), IsTrue, Commentf("%s", str))
+	}
+}
+
+func (s *testEvaluatorSuite) TestJSONSearch(c *C) {
+	defer testleak.AfterTest(c)()
+	doc := `["abc", [{"k": "10"}, "def"], {"x": "abc"}, {"y": "bcd"}]`
+	tbl := []struct {
+		args []interface{}
+		ret  interface{}
+	}{
+		{[]interface{}{doc, "one", "abc"}, `"$[0]"`},
+		{[]interface{}{doc, "all", "abc"}, `["$[0]", "$[2].x"]`},
+		{[]interface{}{doc, "ALL", "ghi"}, nil},
+		{[]interface{}{doc, "all", "10"}, `"$[1][0].k"`},
+		{[]interface{}{doc, "all", "%b%"}, `["$[0]", "$[2].x", "$[3].y"]`},
+		{[]interface{}{doc, "one", "%b%"}, `"$[0]"`},
+		{[]interface{}{doc, "all", "_e_"}, `"$[1][1]"`},
+		{[]interface{}{doc, "all", "abc", nil, "$[2]"}, `"$[2].x"`},
+		{[]interface{}{doc, "all", "abc", nil, "$[*]", "$[2].x"}, `["$[0]", "$[2].x"]`},
+		{[]interface{}{doc, "all", "%b%", "", "$**.y"}, `"$[3].y"`},
+		{[]interface{}{doc, "all", "abc", nil, "$[9]"}, nil},
+		{[]interface{}{`{"a_b": "a_b", "ab": "a%b"}`, "all", "a|%b", "|"}, `"$.ab"`},
+		{[]interface{}{`{"a_b": "a_b", "ab": "a%b"}`, "all", `a\_b`}, `"$.a_b"`},
+		{[]interface{}{`"abc"`, "one", "abc"}, `"$"`},
+		{[]interface{}{nil, "one", "abc"}, nil},
+		{[]interface{}{doc, nil, "abc"}, nil},
+		{[]interface{}{doc, "one", nil}, nil},
+		{[]interface{}{doc, "one", "abc", nil, nil}, nil},
+	}
+	for _, t := range tbl {
+		d, err := evalFuncClass(ast.JSONSearch, types.MakeDatums(t.args...), s.ctx)
+		c.Assert(err, IsNil, Commentf("%v", t.args))
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v", t.args))
+	}
+
+	_, err := evalFuncClass(ast.JSONSearch, types.MakeDatums(doc, "two", "abc"), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errJSONBadOneOrAllArg), IsTrue)
+	_, err = evalFuncClass(ast.JSONSearch, types.MakeDatums(doc, "one", "abc", "ab"), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errIncorrectArgs), IsTrue)
+	_, err = evalFuncClass(ast.JSONSearch, types.MakeDatums(doc, "one", "abc", nil, "$."), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errInvalidJSONPath), IsTrue)
+	_, err = evalFuncClass(ast.JSONSearch, types.MakeDatums(`[`, "one", "abc"), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errInvalidJSONText), IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestJSONRemove(c *C) {
+	defer testleak.AfterTest(c)()
+	doc := `{"a": [1, 2, 3], "b": {"c": "d"}, "e": null}`
+	tbl := []struct {
+		args []interface{}
+		ret  interface{}
+	}{
+		{[]interface{}{doc, "$.b"}, `{"a": [1, 2, 3], "e": null}`},
+		{[]interface{}{doc, "$.b.c", "$.e"}, `{"a": [1, 2, 3], "b": {}}`},
+		{[]interface{}{doc, "$.a[1]"}, `{"a": [1, 3], "b": {"c": "d"}, "e": null}`},
+		// The second path is applied after the first element is removed.
+		{[]interface{}{doc, "$.a[0]", "$.a[0]"}, `{"a": [3], "b": {"c": "d"}, "e": null}`},
+		{[]interface{}{`[1, [2, 3], 4]`, "$[1][0]", "$[2]"}, `[1, [3]]`},
+		// Removing a path which doesn't exist does nothing.
+		{[]interface{}{doc, "$.x", "$.a[3]", "$.b[0]", "$.a.b"}, `{"a": [1, 2, 3], "b": {"c": "d"}, "e": null}`},
+		{[]interface{}{`1`, "$[0]"}, `1`},
+		{[]interface{}{nil, "$.a"}, nil},
+		{[]interface{}{doc, "$.a", nil}, nil},
+	}
+	for _, t := range tbl {
+		d, err := evalFuncClass(ast.JSONRemove, types.MakeDatums(t.args...), s.ctx)
+		c.Assert(err, IsNil, Commentf("%v", t.args))
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v", t.args))
+	}
+
+	_, err := evalFuncClass(ast.JSONRemove, types.MakeDatums(doc, "$.a", "$"), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errJSONVacuousPath), IsTrue)
+	_, err = evalFuncClass(ast.JSONRemove, types.MakeDatums(doc, "$.a[*]"), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errInvalidJSONPathWildcard), IsTrue)
+	_, err = evalFuncClass(ast.JSONRemove, types.MakeDatums(doc, "a"), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errInvalidJSONPath), IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestJSONArrayAppend(c *C) {
+	defer testleak.AfterTest(c)()
+	doc := `["a", ["b", "c"], "d", {"e": 1}]`
+	tbl := []struct {
+		args []interface{}
+		ret  interface{}
+	}{
+		{[]interface{}{doc, "$[1]", 1}, `["a", ["b", "c", 1], "d", {"e": 1}]`},
+		{[]interface{}{doc, "$[1]", 1, "$[1]", 2}, `["a", ["b", "c", 1, 2], "d", {"e": 1}]`},
+		// The values which are not arrays are wrapped.
+		{[]interface{}{doc, "$[0]", "x"}, `[["a", "x"], ["b", "c"], "d", {"e": 1}]`},
+		{[]interface{}{doc, "$[3]", nil}, `["a", ["b", "c"], "d", [{"e": 1}, null]]`},
+		{[]interface{}{doc, "$[3].e", 2.5}, `["a", ["b", "c"], "d", {"e": [1, 2.5]}]`},
+		{[]interface{}{doc, "$", `{"f": 2}`}, `["a", ["b", "c"], "d", {"e": 1}, "{\"f\": 2}"]`},
+		{[]interface{}{`1`, "$", 2}, `[1, 2]`},
+		{[]interface{}{doc, "$[9]", 1, "$.a", 1}, doc},
+		{[]interface{}{nil, "$", 1}, nil},
+		{[]interface{}{doc, nil, 1}, nil},
+	}
+	for _, t := range tbl {
+		d, err := evalFuncClass(ast.JSONArrayAppend, types.MakeDatums(t.args...), s.ctx)
+		c.Assert(err, IsNil, Commentf("%v", t.args))
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v", t.args))
+	}
+
+	_, err := evalFuncClass(ast.JSONArrayAppend, types.MakeDatums(doc, "$", 1, "$"), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errIncorrectParameterCount), IsTrue)
+	_, err = evalFuncClass(ast.JSONArrayAppend, types.MakeDatums(doc, "$[*]", 1), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errInvalidJSONPathWildcard), IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestJSONArrayInsert(c *C) {
+	defer testleak.AfterTest(c)()
+	doc := `["a", {"b": [1, 2]}, [3, 4]]`
+	tbl := []struct {
+		args []interface{}
+		ret  interface{}
+	}{
+		{[]interface{}{doc, "$[1]", "x"}, `["a", "x", {"b": [1, 2]}, [3, 4]]`},
+		{[]interface{}{doc, "$[0]", 0}, `[0, "a", {"b": [1, 2]}, [3, 4]]`},
+		{[]interface{}{doc, "$[100]", "x"}, `["a", {"b": [1, 2]}, [3, 4], "x"]`},
+		{[]interface{}{doc, "$[1].b[0]", "x", "$[2][1]", "y"}, `["a", {"b": ["x", 1, 2]}, [3, "y", 4]]`},
+		// The second path is applied after the first value is inserted.
+		{[]interface{}{doc, "$[0]", "x", "$[2]", "y"}, `["x", "a", "y", {"b": [1, 2]}, [3, 4]]`},
+		// Nothing is inserted if the path doesn't point into an array.
+		{[]interface{}{doc, "$[1].b.c[0]", "x", "$[0][0]", "y", "$[9][0]", "z"}, doc},
+		{[]interface{}{nil, "$[0]", 1}, nil},
+		{[]interface{}{doc, nil, 1}, nil},
+	}
+	for _, t := range tbl {
+		d, err := evalFuncClass(ast.JSONArrayInsert, types.MakeDatums(t.args...), s.ctx)
+		c.Assert(err, IsNil, Commentf("%v", t.args))
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v", t.args))
+	}
+
+	for _, path := range []string{"$", "$[1].b", "$[0].a"} {
+		_, err := evalFuncClass(ast.JSONArrayInsert, types.MakeDatums(doc, "$[0]", 1, path, 2), s.ctx)
+		c.Assert(terror.ErrorEqual(err, errInvalidJSONPathArrayCell), IsTrue, Commentf("%s", path))
+	}
+	_, err := evalFuncClass(ast.JSONArrayInsert, types.MakeDatums(doc, "$[*]", 1), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errInvalidJSONPathWildcard), IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestJSONConstPaths(c *C) {
+	defer testleak.AfterTest(c)()
+	strTp := types.NewFieldType(mysql.TypeVarchar)
+	docCol := &Column{RetType: strTp, Index: 0}
+	pathCol := &Column{RetType: strTp, Index: 1}
+	constPath := &Constant{Value: types.NewStringDatum("$.b"), RetType: strTp}
+
+	// Only the constant path is parsed when the function is built, the path from the column is parsed for each row.
+	f, err := funcs[ast.JSONRemove].getFunction([]Expression{docCol, constPath, pathCol}, s.ctx)
+	c.Assert(err, IsNil)
+	constPaths := f.(*builtinJSONRemove).constPaths
+	c.Assert(constPaths, HasLen, 1)
+	c.Assert(constPaths[1], NotNil)
+	doc := `{"a": 1, "b": 2, "c": 3}`
+	rows := []struct {
+		row []interface{}
+		ret interface{}
+	}{
+		{[]interface{}{doc, "$.a"}, `{"c": 3}`},
+		{[]interface{}{doc, "$.c"}, `{"a": 1}`},
+		{[]interface{}{`{"b": [1, 2]}`, "$.x"}, `{}`},
+		{[]interface{}{doc, nil}, nil},
+	}
+	for _, t := range rows {
+		d, err := f.eval(types.MakeDatums(t.row...))
+		c.Assert(err, IsNil, Commentf("%v", t.row))
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v", t.row))
+	}
+	_, err = f.eval(types.MakeDatums(doc, "a"))
+	c.Assert(terror.ErrorEqual(err, errInvalidJSONPath), IsTrue)
+
+	f, err = funcs[ast.JSONArrayAppend].getFunction([]Expression{docCol, pathCol, constPath, constPath, pathCol}, s.ctx)
+	c.Assert(err, IsNil)
+	// The constant at index 2 is a value, only the one at index 3 is a path.
+	constPaths = f.(*builtinJSONArrayAppend).constPaths
+	c.Assert(constPaths, HasLen, 1)
+	c.Assert(constPaths[3], NotNil)
+	d, err := f.eval(types.MakeDatums(`{"a": [1], "b": [2]}`, "$.a"))
+	c.Assert(err, IsNil)
+	c.Assert(d, testutil.DatumEquals, types.NewDatum(`{"a": [1, "$.b"], "b": [2, "$.a"]}`))
+
+	// An invalid or NULL constant path isn't parsed in advance, it fails or returns NULL for each row.
+	invalidPath := &Constant{Value: types.NewStringDatum("a"), RetType: strTp}
+	nullPath := &Constant{Value: types.Datum{}, RetType: strTp}
+	for _, path := range []Expression{invalidPath, nullPath} {
+		f, err = funcs[ast.JSONSearch].getFunction([]Expression{docCol, pathCol, pathCol, nullPath, path}, s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(f.(*builtinJSONSearch).constPaths, HasLen, 0)
+	}
+	f, err = funcs[ast.JSONSearch].getFunction([]Expression{docCol, pathCol, pathCol, nullPath, invalidPath}, s.ctx)
+	c.Assert(err, IsNil)
+	_, err = f.eval(types.MakeDatums(`["one"]`, "one"))
+	c.Assert(terror.ErrorEqual(err, errInvalidJSONPath), IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestCastToJSON(c *C) {
+	defer testleak.AfterTest(c)()
+	f, err := CastFuncFactory(types.NewFieldType(mysql.TypeJSON))
+	c.Assert(err, IsNil)
+	dt := types.Time{Time: types.FromDate(2017, 1, 2, 3, 4, 5, 0), Type: mysql.TypeDatetime}
+	date := types.Time{Time: types.FromDate(2017, 1, 2, 0, 0, 0, 0), Type: mysql.TypeDate}
+	tbl := []struct {
+		arg interface{}
+		ret interface{}
+	}{
+		{`{"b": 1, "a": [true, null]}`, `{"a": [true, null], "b": 1}`},
+		{` "str" `, `"str"`},
+		{`true`, `true`},
+		{[]byte(`[1,2]`), `[1, 2]`},
+		// Casting the result of a JSON cast does nothing.
+		{`{"a": [true, null], "b": 1}`, `{"a": [true, null], "b": 1}`},
+		{int64(-1), `-1`},
+		{uint64(18446744073709551615), `18446744073709551615`},
+		{1.5, `1.5`},
+		{types.NewDecFromStringForTest("3.140"), `3.140`},
+		{dt, `"2017-01-02 03:04:05.000000"`},
+		{date, `"2017-01-02"`},
+		{types.Duration{Duration: time.Hour}, `"01:00:00.000000"`},
+		{nil, nil},
+	}
+	for _, t := range tbl {
+		d, err := f(types.MakeDatums(t.arg), s.ctx)
+		c.Assert(err, IsNil, Commentf("%v", t.arg))
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v", t.arg))
+	}
+
+	for _, str := range []string{`[1`, ``, `abc`, `1 2`} {
+		_, err = f(types.MakeDatums(str), s.ctx)
+		c.Assert(terror.ErrorEqual(err, errInvalidJSONText), IsTrue, Commentf("%s", str))
+	}
+}
@@ -0,0 +1,354 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// userLock is a named advisory lock acquired by GET_LOCK().
+// A session can acquire the same lock multiple times, it is freed after being released as many times.
+type userLock struct {
+	owner *variable.SessionVars
+	count int
+	// released is closed when the lock is freed, to wake up the sessions waiting for it.
+	released chan struct{}
+}
+
+// userLockManager holds the user locks of all the sessions of the server.
+// The locks are local to the tidb-server, they are not shared with the other servers of the cluster.
+type userLockManager struct {
+	mu    sync.Mutex
+	locks map[string]*userLock
+	// waiters counts the sessions waiting for each lock.
+	waiters map[string]int
+}
+
+var userLocks = &userLockManager{locks: make(map[string]*userLock), waiters: make(map[string]int)}
+
+// maxUserLockNameLen is the maximum length in characters of a lock name, the same as MySQL.
+const maxUserLockNameLen = 64
+
+// acquire tries to acquire the lock for the session until the timeout, a negative timeout means waiting forever.
+// isKilled is true if the waiting is interrupted by killing the query.
+func (m *userLockManager) acquire(name string, sessVars *variable.SessionVars, timeout time.Duration) (acquired, isKilled bool) {
+	var timeoutCh <-chan time.Time
+	if timeout >= 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+	ticker := time.NewTicker(sleepCheckInterval)
+	defer ticker.Stop()
+	for {
+		m.mu.Lock()
+		lock, ok := m.locks[name]
+		if !ok {
+			m.locks[name] = &userLock{owner: sessVars, count: 1, released: make(chan struct{})}
+			m.mu.Unlock()
+			return true, false
+		}
+		if lock.owner == sessVars {
+			lock.count++
+			m.mu.Unlock()
+			return true, false
+		}
+		released := lock.released
+		m.waiters[name]++
+		m.mu.Unlock()
+
+		timedOut, killed := false, false
+		select {
+		case <-released:
+		case <-timeoutCh:
+			timedOut = true
+		case <-ticker.C:
+			killed = atomic.LoadUint32(&sessVars.Killed) == 1
+		}
+		m.mu.Lock()
+		if m.waiters[name]--; m.waiters[name] == 0 {
+			delete(m.waiters, name)
+		}
+		m.mu.Unlock()
+		if timedOut || killed {
+			return false, killed
+		}
+	}
+}
+
+// waiting returns the number of sessions waiting for the lock.
+func (m *userLockManager) waiting(name string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.waiters[name]
+}
+
+// release releases the lock once, exists is false if nobody holds the lock.
+func (m *userLockManager) release(name string, sessVars *variable.SessionVars) (released, exists bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	lock, ok := m.locks[name]
+	if !ok {
+		return false, false
+	}
+	if lock.owner != sessVars {
+		return false, true
+	}
+	lock.count--
+	if lock.count == 0 {
+		delete(m.locks, name)
+		close(lock.released)
+	}
+	return true, true
+}
+
+// releaseAll releases all the locks held by the session, it returns the number of times they were acquired.
+func (m *userLockManager) releaseAll(sessVars *variable.SessionVars) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for name, lock := range m.locks {
+		if lock.owner != sessVars {
+			continue
+		}
+		count += lock.count
+		delete(m.locks, name)
+		close(lock.released)
+	}
+	return count
+}
+
+// owner returns the connection ID of the session holding the lock, used is false if the lock is free.
+func (m *userLockManager) owner(name string) (connID uint64, used bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	lock, ok := m.locks[name]
+	if !ok {
+		return 0, false
+	}
+	return lock.owner.ConnectionID, true
+}
+
+// ReleaseUserLocks releases all the locks acquired by GET_LOCK() in the session, it's called when the session is closed.
+func ReleaseUserLocks(sessVars *variable.SessionVars) {
+	userLocks.releaseAll(sessVars)
+}
+
+// evalLockName evaluates the lock name argument, lock names are case insensitive.
+// An empty name or a name longer than maxUserLockNameLen characters is an error.
+func (b *baseBuiltinFunc) evalLockName(row []types.Datum) (name string, isNull bool, err error) {
+	arg, err := b.args[0].Eval(row, b.ctx)
+	if err != nil || arg.IsNull() {
+		return "", true, errors.Trace(err)
+	}
+	name, err = arg.ToString()
+	if err != nil {
+		return "", true, errors.Trace(err)
+	}
+	if name == "" || utf8.RuneCountInString(name) > maxUserLockNameLen {
+		return "", true, errUserLockWrongName.GenByArgs(name)
+	}
+	return strings.ToLower(name), false, nil
+}
+
+type getLockFuncClass struct {
+	baseFuncClass
+}
+
+func (c *getLockFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinGetLock{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinGetLock struct {
+	baseBuiltinFunc
+}
+
+// IsDeterministic implements builtinFunc interface, the result depends on the locks held by other sessions.
+func (b *builtinGetLock) IsDeterministic() bool {
+	return false
+}
+
+// eval returns 1 if the lock is acquired, 0 if it's timed out and NULL if the name is NULL or the query is killed.
+// See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_get-lock
+func (b *builtinGetLock) eval(row []types.Datum) (d types.Datum, err error) {
+	name, isNull, err := b.evalLockName(row)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	arg, err := b.args[1].Eval(row, b.ctx)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	var secs float64
+	if !arg.IsNull() {
+		secs, err = arg.ToFloat64(b.ctx.GetSessionVars().StmtCtx)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+	}
+	timeout := time.Duration(-1)
+	if secs >= 0 {
+		timeout = time.Duration(secs * float64(time.Second))
+	}
+	acquired, isKilled := userLocks.acquire(name, b.ctx.GetSessionVars(), timeout)
+	if isKilled {
+		return d, nil
+	}
+	d.SetInt64(boolToInt64(acquired))
+	return d, nil
+}
+
+type releaseLockFuncClass struct {
+	baseFuncClass
+}
+
+func (c *releaseLockFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinReleaseLock{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinReleaseLock struct {
+	baseBuiltinFunc
+}
+
+// IsDeterministic implements builtinFunc interface, the result depends on the locks held by sessions.
+func (b *builtinReleaseLock) IsDeterministic() bool {
+	return false
+}
+
+// eval returns 1 if the lock is released, 0 if it's held by another session and NULL if it's not held by anyone.
+// See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_release-lock
+func (b *builtinReleaseLock) eval(row []types.Datum) (d types.Datum, err error) {
+	name, isNull, err := b.evalLockName(row)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	released, exists := userLocks.release(name, b.ctx.GetSessionVars())
+	if exists {
+		d.SetInt64(boolToInt64(released))
+	}
+	return d, nil
+}
+
+type releaseAllLocksFuncClass struct {
+	baseFuncClass
+}
+
+func (c *releaseAllLocksFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinReleaseAllLocks{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinReleaseAllLocks struct {
+	baseBuiltinFunc
+}
+
+// IsDeterministic implements builtinFunc interface, the result depends on the locks held by the session.
+func (b *builtinReleaseAllLocks) IsDeterministic() bool {
+	return false
+}
+
+// eval releases all the locks held by the session and returns the number of locks released,
+// a lock acquired multiple times is counted as many times.
+// See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_release-all-locks
+func (b *builtinReleaseAllLocks) eval(_ []types.Datum) (d types.Datum, err error) {
+	d.SetInt64(int64(userLocks.releaseAll(b.ctx.GetSessionVars())))
+	return d, nil
+}
+
+type isFreeLockFuncClass struct {
+	baseFuncClass
+}
+
+func (c *isFreeLockFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinIsFreeLock{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinIsFreeLock struct {
+	baseBuiltinFunc
+}
+
+// IsDeterministic implements builtinFunc interface, the result depends on the locks held by sessions.
+func (b *builtinIsFreeLock) IsDeterministic() bool {
+	return false
+}
+
+// eval returns 1 if the lock is free and 0 if it's in use.
+// See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_is-free-lock
+func (b *builtinIsFreeLock) eval(row []types.Datum) (d types.Datum, err error) {
+	name, isNull, err := b.evalLockName(row)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	_, used := userLocks.owner(name)
+	d.SetInt64(boolToInt64(!used))
+	return d, nil
+}
+
+type isUsedLockFuncClass struct {
+	baseFuncClass
+}
+
+func (c *isUsedLockFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinIsUsedLock{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinIsUsedLock struct {
+	baseBuiltinFunc
+}
+
+// IsDeterministic implements builtinFunc interface, the result depends on the locks held by sessions.
+func (b *builtinIsUsedLock) IsDeterministic() bool {
+	return false
+}
+
+// eval returns the connection ID of the session holding the lock, or NULL if the lock is free.
+// See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_is-used-lock
+func (b *builtinIsUsedLock) eval(row []types.Datum) (d types.Datum, err error) {
+	name, isNull, err := b.evalLockName(row)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	if connID, used := userLocks.owner(name); used {
+		d.SetUint64(connID)
+	}
+	return d, nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// waitForLockWaiter blocks until a session is waiting for the lock.
+func waitForLockWaiter(name string) {
+	for userLocks.waiting(name) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (s *testEvaluatorSuite) TestLock(c *C) {
+	defer testleak.AfterTest(c)()
+	ctx1, ctx2 := mock.NewContext(), mock.NewContext()
+	ctx1.GetSessionVars().ConnectionID = 1
+	ctx2.GetSessionVars().ConnectionID = 2
+	defer ReleaseUserLocks(ctx1.GetSessionVars())
+	defer ReleaseUserLocks(ctx2.GetSessionVars())
+
+	call := func(ctx context.Context, name string, args ...interface{}) types.Datum {
+		f, err := funcs[name].getFunction(datumsToConstants(types.MakeDatums(args...)), ctx)
+		c.Assert(err, IsNil)
+		c.Assert(f.IsDeterministic(), IsFalse)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		return d
+	}
+
+	c.Assert(call(ctx1, ast.IsFreeLock, "l1"), DeepEquals, types.NewIntDatum(1))
+	c.Assert(call(ctx1, ast.IsUsedLock, "l1"), DeepEquals, types.Datum{})
+	c.Assert(call(ctx1, ast.ReleaseLock, "l1"), DeepEquals, types.Datum{})
+	c.Assert(call(ctx1, ast.GetLock, nil, 1), DeepEquals, types.Datum{})
+
+	// The lock is reentrant and the lock names are case insensitive.
+	c.Assert(call(ctx1, ast.GetLock, "l1", 1), DeepEquals, types.NewIntDatum(1))
+	c.Assert(call(ctx1, ast.GetLock, "L1", 1), DeepEquals, types.NewIntDatum(1))
+	c.Assert(call(ctx2, ast.IsFreeLock, "l1"), DeepEquals, types.NewIntDatum(0))
+	c.Assert(call(ctx2, ast.IsUsedLock, "l1"), DeepEquals, types.NewUintDatum(1))
+	c.Assert(call(ctx2, ast.GetLock, "l1", 0), DeepEquals, types.NewIntDatum(0))
+	c.Assert(call(ctx2, ast.ReleaseLock, "l1"), DeepEquals, types.NewIntDatum(0))
+	c.Assert(call(ctx1, ast.ReleaseLock, "l1"), DeepEquals, types.NewIntDatum(1))
+	c.Assert(call(ctx2, ast.IsUsedLock, "l1"), DeepEquals, types.NewUintDatum(1))
+	c.Assert(call(ctx1, ast.ReleaseLock, "l1"), DeepEquals, types.NewIntDatum(1))
+	c.Assert(call(ctx2, ast.IsFreeLock, "l1"), DeepEquals, types.NewIntDatum(1))
+
+	// A session waiting for the lock gets it once the lock is released.
+	c.Assert(call(ctx1, ast.GetLock, "l2", 0), DeepEquals, types.NewIntDatum(1))
+	ch := make(chan types.Datum)
+	go func() {
+		ch <- call(ctx2, ast.GetLock, "l2", -1)
+	}()
+	waitForLockWaiter("l2")
+	c.Assert(call(ctx1, ast.ReleaseLock, "l2"), DeepEquals, types.NewIntDatum(1))
+	c.Assert(<-ch, DeepEquals, types.NewIntDatum(1))
+	c.Assert(call(ctx1, ast.IsUsedLock, "l2"), DeepEquals, types.NewUintDatum(2))
+
+	// Killing the waiting query returns NULL.
+	go func() {
+		ch <- call(ctx1, ast.GetLock, "l2", -1)
+	}()
+	waitForLockWaiter("l2")
+	atomic.StoreUint32(&ctx1.GetSessionVars().Killed, 1)
+	c.Assert(<-ch, DeepEquals, types.Datum{})
+	// The flag is kept until the next statement starts.
+	c.Assert(atomic.LoadUint32(&ctx1.GetSessionVars().Killed), Equals, uint32(1))
+	atomic.StoreUint32(&ctx1.GetSessionVars().Killed, 0)
+	c.Assert(userLocks.waiting("l2"), Equals, 0)
+
+	// Closing the session releases its locks.
+	ReleaseUserLocks(ctx2.GetSessionVars())
+	c.Assert(call(ctx1, ast.IsFreeLock, "l2"), DeepEquals, types.NewIntDatum(1))
+}
+
+func (s *testEvaluatorSuite) TestLockName(c *C) {
+	defer testleak.AfterTest(c)()
+	ctx := mock.NewContext()
+	defer ReleaseUserLocks(ctx.GetSessionVars())
+
+	eval := func(name string, args ...interface{}) (types.Datum, error) {
+		f, err := funcs[name].getFunction(datumsToConstants(types.MakeDatums(args...)), ctx)
+		c.Assert(err, IsNil)
+		return f.eval(nil)
+	}
+
+	// A lock name can have up to 64 characters.
+	name := strings.Repeat("锁", maxUserLockNameLen)
+	d, err := eval(ast.GetLock, name, 0)
+	c.Assert(err, IsNil)
+	c.Assert(d, DeepEquals, types.NewIntDatum(1))
+	d, err = eval(ast.ReleaseLock, name)
+	c.Assert(err, IsNil)
+	c.Assert(d, DeepEquals, types.NewIntDatum(1))
+
+	for _, name := range []string{"", name + "x"} {
+		_, err = eval(ast.GetLock, name, 0)
+		c.Assert(terror.ErrorEqual(err, errUserLockWrongName), IsTrue, Commentf("%s", name))
+		for _, fn := range []string{ast.ReleaseLock, ast.IsFreeLock, ast.IsUsedLock} {
+			_, err = eval(fn, name)
+			c.Assert(terror.ErrorEqual(err, errUserLockWrongName), IsTrue, Commentf("%s(%s)", fn, name))
+		}
+	}
+}
+
+func (s *testEvaluatorSuite) TestReleaseAllLocks(c *C) {
+	defer testleak.AfterTest(c)()
+	ctx1, ctx2 := mock.NewContext(), mock.NewContext()
+	defer ReleaseUserLocks(ctx2.GetSessionVars())
+
+	call := func(ctx context.Context, name string, args ...interface{}) types.Datum {
+		f, err := funcs[name].getFunction(datumsToConstants(types.MakeDatums(args...)), ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		return d
+	}
+
+	c.Assert(call(ctx1, ast.ReleaseAllLocks), DeepEquals, types.NewIntDatum(0))
+	for _, name := range []string{"l1", "l2", "l2", "l3"} {
+		c.Assert(call(ctx1, ast.GetLock, name, 0), DeepEquals, types.NewIntDatum(1))
+	}
+	c.Assert(call(ctx2, ast.GetLock, "l4", 0), DeepEquals, types.NewIntDatum(1))
+	c.Assert(call(ctx1, ast.ReleaseAllLocks), DeepEquals, types.NewIntDatum(4))
+	for _, name := range []string{"l1", "l2", "l3"} {
+		c.Assert(call(ctx1, ast.IsUsedLock, name), DeepEquals, types.Datum{})
+	}
+	c.Assert(call(ctx1, ast.IsFreeLock, "l4"), DeepEquals, types.NewIntDatum(0))
+	c.Assert(call(ctx1, ast.ReleaseAllLocks), DeepEquals, types.NewIntDatum(0))
+
+	// Releasing all the locks wakes up the sessions waiting for them.
+	ch := make(chan types.Datum)
+	go func() {
+		ch <- call(ctx1, ast.GetLock, "l4", -1)
+	}()
+	waitForLockWaiter("l4")
+	c.Assert(call(ctx2, ast.ReleaseAllLocks), DeepEquals, types.NewIntDatum(1))
+	c.Assert(<-ch, DeepEquals, types.NewIntDatum(1))
+	c.Assert(call(ctx1, ast.ReleaseAllLocks), DeepEquals, types.NewIntDatum(1))
+}
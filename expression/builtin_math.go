@@ -18,13 +18,19 @@
 package expression
 
 import (
+	"fmt"
 	"hash/crc32"
 	"math"
 	"math/rand"
+	"strconv"
+	"strings"
 
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -42,12 +48,28 @@ func builtinAbs(args []types.Datum, ctx context.Context) (d types.Datum, err err
 			d.SetInt64(iv)
 			return d, nil
 		}
+		// The absolute value of the min int64 can't be represented by BIGINT.
+		if iv == math.MinInt64 {
+			return dataOutOfRange(ctx.GetSessionVars().StmtCtx, "BIGINT", ast.Abs, args)
+		}
 		d.SetInt64(-iv)
 		return d, nil
+	case types.KindMysqlDecimal:
+		// Negate the decimal directly, converting it to float64 may lose its precision.
+		dec := d.GetMysqlDecimal()
+		if !dec.IsNegative() {
+			return d, nil
+		}
+		to := new(types.MyDecimal)
+		if err = types.DecimalSub(new(types.MyDecimal), dec, to); err != nil {
+			return d, errors.Trace(err)
+		}
+		d.SetMysqlDecimal(to)
+		return d, nil
 	default:
 		// we will try to convert other types to float
 		// TODO: if time has no precision, it will be a integer
-		f, err := d.ToFloat64(ctx.GetSessionVars().StmtCtx)
+		f, err := argToFloat64(ctx.GetSessionVars().StmtCtx, d)
 		d.SetFloat64(math.Abs(f))
 		return d, errors.Trace(err)
 	}
@@ -59,8 +81,20 @@ func builtinCeil(args []types.Datum, ctx context.Context) (d types.Datum, err er
 		args[0].Kind() == types.KindUint64 || args[0].Kind() == types.KindInt64 {
 		return args[0], nil
 	}
+	if idx, ok := enumOrSetIndex(args[0]); ok {
+		d.SetUint64(idx)
+		return d, nil
+	}
+	if args[0].Kind() == types.KindMysqlDecimal {
+		dec, err := roundDecimalToInt(args[0].GetMysqlDecimal(), 1)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		d.SetMysqlDecimal(dec)
+		return d, nil
+	}
 
-	f, err := args[0].ToFloat64(ctx.GetSessionVars().StmtCtx)
+	f, err := argToFloat64(ctx.GetSessionVars().StmtCtx, args[0])
 	if err != nil {
 		return d, errors.Trace(err)
 	}
@@ -68,13 +102,96 @@ func builtinCeil(args []types.Datum, ctx context.Context) (d types.Datum, err er
 	return
 }
 
+// See http://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_floor
+func builtinFloor(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+	if args[0].IsNull() ||
+		args[0].Kind() == types.KindUint64 || args[0].Kind() == types.KindInt64 {
+		return args[0], nil
+	}
+	if idx, ok := enumOrSetIndex(args[0]); ok {
+		d.SetUint64(idx)
+		return d, nil
+	}
+	if args[0].Kind() == types.KindMysqlDecimal {
+		dec, err := roundDecimalToInt(args[0].GetMysqlDecimal(), -1)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		d.SetMysqlDecimal(dec)
+		return d, nil
+	}
+
+	f, err := argToFloat64(ctx.GetSessionVars().StmtCtx, args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	d.SetFloat64(math.Floor(f))
+	return
+}
+
+// absInt64 returns the absolute value of i, it doesn't overflow for the min int64.
+func absInt64(i int64) uint64 {
+	if i < 0 {
+		return uint64(-i)
+	}
+	return uint64(i)
+}
+
+// roundUint64 rounds u half away from zero to a multiple of 10^digits, overflow is true if the result exceeds uint64.
+// If halfEven is true, a value exactly halfway between is rounded to the even multiple.
+func roundUint64(u uint64, digits int, halfEven bool) (res uint64, overflow bool) {
+	// 10^20 is greater than the max uint64, every uint64 rounds to 0.
+	if digits >= 20 {
+		return 0, false
+	}
+	shift := uint64(1)
+	for i := 0; i < digits; i++ {
+		shift *= 10
+	}
+	q, r := u/shift, u%shift
+	if r > shift-r || (r == shift-r && (!halfEven || q%2 == 1)) {
+		q++
+	}
+	if q > math.MaxUint64/shift {
+		return 0, true
+	}
+	return q * shift, false
+}
+
+// enumOrSetIndex returns the numeric index of an ENUM or SET value, which is used in numeric context instead of the label.
+func enumOrSetIndex(d types.Datum) (uint64, bool) {
+	switch d.Kind() {
+	case types.KindMysqlEnum:
+		return d.GetMysqlEnum().Value, true
+	case types.KindMysqlSet:
+		return d.GetMysqlSet().Value, true
+	}
+	return 0, false
+}
+
+// roundDecimalToInt rounds the decimal to a scale 0 decimal,
+// towards positive infinity if direction is 1 and towards negative infinity if direction is -1.
+func roundDecimalToInt(dec *types.MyDecimal, direction int64) (*types.MyDecimal, error) {
+	res := new(types.MyDecimal)
+	if err := dec.Round(res, 0); err != nil {
+		return nil, errors.Trace(err)
+	}
+	// Round rounds half away from zero, adjust the result if it's on the wrong side of the argument.
+	if cmp := res.Compare(dec); cmp != 0 && int64(cmp) != direction {
+		if err := types.DecimalAdd(res, types.NewDecFromInt(direction), res); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return res, nil
+}
+
 // See http://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_log
 func builtinLog(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
 	sc := ctx.GetSessionVars().StmtCtx
 
 	switch len(args) {
 	case 1:
-		x, err := args[0].ToFloat64(sc)
+		x, err := argToFloat64(sc, args[0])
 		if err != nil {
 			return d, errors.Trace(err)
 		}
@@ -83,25 +200,24 @@ func builtinLog(args []types.Datum, ctx context.Context) (d types.Datum, err err
 			return d, nil
 		}
 
-		d.SetFloat64(math.Log(x))
-		return d, nil
+		return floatResult(sc, math.Log(x), ast.Log, args)
 	case 2:
-		b, err := args[0].ToFloat64(sc)
+		b, err := argToFloat64(sc, args[0])
 		if err != nil {
 			return d, errors.Trace(err)
 		}
 
-		x, err := args[1].ToFloat64(sc)
+		x, err := argToFloat64(sc, args[1])
 		if err != nil {
 			return d, errors.Trace(err)
 		}
 
-		if b <= 1 || x <= 0 {
+		// The base can be in (0, 1), which results in a negative slope, but LOG with base 1 is undefined.
+		if b <= 0 || b == 1 || x <= 0 {
 			return d, nil
 		}
 
-		d.SetFloat64(math.Log(x) / math.Log(b))
-		return d, nil
+		return floatResult(sc, math.Log(x)/math.Log(b), ast.Log, args)
 	}
 	return
 }
@@ -109,7 +225,7 @@ func builtinLog(args []types.Datum, ctx context.Context) (d types.Datum, err err
 // See http://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_log2
 func builtinLog2(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
 	sc := ctx.GetSessionVars().StmtCtx
-	x, err := args[0].ToFloat64(sc)
+	x, err := argToFloat64(sc, args[0])
 	if err != nil {
 		return d, errors.Trace(err)
 	}
@@ -118,14 +234,13 @@ func builtinLog2(args []types.Datum, ctx context.Context) (d types.Datum, err er
 		return
 	}
 
-	d.SetFloat64(math.Log2(x))
-	return
+	return floatResult(sc, math.Log2(x), ast.Log2, args)
 }
 
 // See http://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_log10
 func builtinLog10(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
 	sc := ctx.GetSessionVars().StmtCtx
-	x, err := args[0].ToFloat64(sc)
+	x, err := argToFloat64(sc, args[0])
 	if err != nil {
 		return d, errors.Trace(err)
 	}
@@ -134,9 +249,7 @@ func builtinLog10(args []types.Datum, ctx context.Context) (d types.Datum, err e
 		return
 	}
 
-	d.SetFloat64(math.Log10(x))
-	return
-
+	return floatResult(sc, math.Log10(x), ast.Log10, args)
 }
 
 // See http://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_rand
@@ -155,59 +268,362 @@ func builtinRand(args []types.Datum, ctx context.Context) (d types.Datum, err er
 // See http://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_pow
 func builtinPow(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
 	sc := ctx.GetSessionVars().StmtCtx
-	x, err := args[0].ToFloat64(sc)
+	x, err := argToFloat64(sc, args[0])
 	if err != nil {
 		return d, errors.Trace(err)
 	}
 
-	y, err := args[1].ToFloat64(sc)
+	y, err := argToFloat64(sc, args[1])
 	if err != nil {
 		return d, errors.Trace(err)
 	}
-	d.SetFloat64(math.Pow(x, y))
-	return d, nil
+	return floatResult(sc, math.Pow(x, y), ast.Pow, args)
 }
 
-// See http://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_round
-func builtinRound(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+// See http://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_exp
+func builtinExp(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+	if args[0].IsNull() {
+		return d, nil
+	}
+	sc := ctx.GetSessionVars().StmtCtx
+	x, err := argToFloat64(sc, args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	return floatResult(sc, math.Exp(x), ast.Exp, args)
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_sqrt
+func builtinSqrt(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+	if args[0].IsNull() {
+		return d, nil
+	}
 	sc := ctx.GetSessionVars().StmtCtx
-	x, err := args[0].ToFloat64(sc)
+	x, err := argToFloat64(sc, args[0])
 	if err != nil {
 		return d, errors.Trace(err)
 	}
+	// The square root of a negative number is NaN, which results in NULL.
+	return floatResult(sc, math.Sqrt(x), ast.Sqrt, args)
+}
 
+// See http://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_round
+func builtinRound(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+	if args[0].IsNull() {
+		return d, nil
+	}
+	sc := ctx.GetSessionVars().StmtCtx
 	dec := 0
 	if len(args) == 2 {
+		if args[1].IsNull() {
+			return d, nil
+		}
 		y, err1 := args[1].ToInt64(sc)
 		if err1 != nil {
 			return d, errors.Trace(err1)
 		}
 		dec = int(y)
 	}
-	d.SetFloat64(types.Round(x, dec))
+	// MySQL rounds half away from zero, the session can choose the round half to even instead.
+	halfEven := ctx.GetSessionVars().RoundHalfEven
+	if idx, ok := enumOrSetIndex(args[0]); ok && dec >= 0 {
+		d.SetUint64(idx)
+		return d, nil
+	}
+	// An integer is rounded exactly, a BIGINT UNSIGNED above 2^53 can't be represented by a float64.
+	switch args[0].Kind() {
+	case types.KindInt64:
+		iv := args[0].GetInt64()
+		if dec >= 0 {
+			d.SetInt64(iv)
+			return d, nil
+		}
+		uv, overflow := roundUint64(absInt64(iv), -dec, halfEven)
+		if overflow || (iv >= 0 && uv > math.MaxInt64) || (iv < 0 && uv > -math.MinInt64) {
+			return dataOutOfRange(sc, "BIGINT", ast.Round, args)
+		}
+		if iv < 0 {
+			d.SetInt64(int64(-uv))
+		} else {
+			d.SetInt64(int64(uv))
+		}
+		return d, nil
+	case types.KindUint64:
+		uv := args[0].GetUint64()
+		if dec >= 0 {
+			d.SetUint64(uv)
+			return d, nil
+		}
+		uv, overflow := roundUint64(uv, -dec, halfEven)
+		if overflow {
+			return dataOutOfRange(sc, "BIGINT UNSIGNED", ast.Round, args)
+		}
+		d.SetUint64(uv)
+		return d, nil
+	}
+	// A TIME or DATETIME value is rounded on its fractional seconds part,
+	// it's converted to a number like other values when the decimals are out of the fsp range.
+	if dec >= types.MinFsp && dec <= types.MaxFsp {
+		switch args[0].Kind() {
+		case types.KindMysqlTime:
+			t, err := args[0].GetMysqlTime().RoundFrac(dec)
+			if err != nil {
+				return d, errors.Trace(err)
+			}
+			d.SetMysqlTime(t)
+			return d, nil
+		case types.KindMysqlDuration:
+			dur, err := args[0].GetMysqlDuration().RoundFrac(dec)
+			if err != nil {
+				return d, errors.Trace(err)
+			}
+			d.SetMysqlDuration(dur)
+			return d, nil
+		}
+	}
+
+	x, err := argToFloat64(sc, args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if halfEven {
+		d.SetFloat64(types.RoundHalfEven(x, dec))
+	} else {
+		d.SetFloat64(types.Round(x, dec))
+	}
 	return d, nil
 }
 
+// builtinConv converts the number from from_base to to_base, the bases are between 2 and 36 or between -36 and -2.
+// The number is parsed as an unsigned 64-bit value for a positive from_base, a negative number is converted to
+// its 64-bit two's complement, and as a signed 64-bit value for a negative from_base. The value is clamped if it
+// overflows. It's formatted as unsigned for a positive to_base and as signed for a negative to_base.
+// The result is NULL if any of the bases is out of range.
 // See http://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_conv
 func builtinConv(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-	//TODO  implement
-	return d, errors.New("Function unimplement")
+	for _, arg := range args {
+		if arg.IsNull() {
+			return d, nil
+		}
+	}
+	sc := ctx.GetSessionVars().StmtCtx
+	fromBase, err := args[1].ToInt64(sc)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	toBase, err := args[2].ToInt64(sc)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if !isValidConvBase(fromBase) || !isValidConvBase(toBase) {
+		return d, nil
+	}
+	var u uint64
+	if args[0].Kind() == types.KindMysqlBit {
+		u = args[0].GetMysqlBit().Value
+	} else {
+		str, err := args[0].ToString()
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		u = parseConvNumber(str, int(absInt64(fromBase)), fromBase < 0)
+	}
+	var res string
+	if toBase < 0 && int64(u) < 0 {
+		res = "-" + strconv.FormatUint(-u, int(-toBase))
+	} else {
+		res = strconv.FormatUint(u, int(absInt64(toBase)))
+	}
+	d.SetString(strings.ToUpper(res))
+	return d, nil
 }
 
-//　See http://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_crc32
-func builtinCRC32(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+func isValidConvBase(base int64) bool {
+	return (base >= 2 && base <= 36) || (base >= -36 && base <= -2)
+}
+
+// parseConvNumber parses the longest prefix of str which is a number in base, leading spaces are skipped
+// and a number without any digit is 0. The number is clamped to the range of uint64, or int64 if signed is true,
+// and a negative number is returned in its 64-bit two's complement.
+func parseConvNumber(str string, base int, signed bool) uint64 {
+	str = strings.TrimLeft(str, spaceChars)
+	neg := false
+	if len(str) > 0 && (str[0] == '-' || str[0] == '+') {
+		neg = str[0] == '-'
+		str = str[1:]
+	}
+	var u uint64
+	overflow := false
+	for i := 0; i < len(str); i++ {
+		digit := uint64(base)
+		switch c := str[i]; {
+		case c >= '0' && c <= '9':
+			digit = uint64(c - '0')
+		case c >= 'a' && c <= 'z':
+			digit = uint64(c-'a') + 10
+		case c >= 'A' && c <= 'Z':
+			digit = uint64(c-'A') + 10
+		}
+		if digit >= uint64(base) {
+			break
+		}
+		if u > (math.MaxUint64-digit)/uint64(base) {
+			overflow = true
+			break
+		}
+		u = u*uint64(base) + digit
+	}
+	switch {
+	case !signed && overflow:
+		return math.MaxUint64
+	case !signed && neg:
+		return -u
+	case signed && neg && (overflow || u > -math.MinInt64):
+		// The two's complement of the min int64 is itself.
+		return 1 << 63
+	case signed && neg:
+		return -u
+	case signed && (overflow || u > math.MaxInt64):
+		return math.MaxInt64
+	}
+	return u
+}
+
+type crc32FuncClass struct {
+	baseFuncClass
+}
+
+func (c *crc32FuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinCRC32{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinCRC32 struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinCRC32.
+// See http://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_crc32
+func (b *builtinCRC32) eval(row []types.Datum) (d types.Datum, err error) {
+	bs, isNull, err := b.evalBytesArg(row)
+	if isNull || err != nil {
+		return d, errors.Trace(err)
+	}
+	d.SetUint64(uint64(crc32.ChecksumIEEE(bs)))
+	return d, nil
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_sin
+func builtinSin(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+	return trigFunc(args, ctx, ast.Sin, math.Sin)
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_cos
+func builtinCos(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+	return trigFunc(args, ctx, ast.Cos, math.Cos)
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_tan
+// The tangent near PI/2 is a large finite value since PI/2 can't be represented exactly.
+func builtinTan(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+	return trigFunc(args, ctx, ast.Tan, math.Tan)
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_cot
+// COT(0) is infinite, so it results in an out of range error like MySQL does.
+func builtinCot(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+	return trigFunc(args, ctx, ast.Cot, func(x float64) float64 {
+		return 1 / math.Tan(x)
+	})
+}
+
+// trigFunc evaluates the trigonometric function fn named name on the first argument.
+// The result is rounded to 15 significant digits if the session sets RoundTrigResult.
+func trigFunc(args []types.Datum, ctx context.Context, name string, fn func(float64) float64) (d types.Datum, err error) {
 	if args[0].IsNull() {
 		return d, nil
 	}
-	x, err := args[0].ToString()
+	sc := ctx.GetSessionVars().StmtCtx
+	x, err := argToFloat64(sc, args[0])
 	if err != nil {
 		return d, errors.Trace(err)
 	}
-	r := crc32.ChecksumIEEE([]byte(x))
-	d.SetUint64(uint64(r))
+	f := fn(x)
+	if ctx.GetSessionVars().RoundTrigResult && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		f = roundSignificant(f, trigResultDigits)
+	}
+	return floatResult(sc, f, name, args)
+}
+
+// trigResultDigits is the number of significant decimal digits a float64 can hold without loss, i.e. DBL_DIG.
+const trigResultDigits = 15
+
+// roundSignificant rounds the finite f to digits significant decimal digits.
+func roundSignificant(f float64, digits int) float64 {
+	r, err := strconv.ParseFloat(strconv.FormatFloat(f, 'g', digits, 64), 64)
+	if err != nil {
+		return f
+	}
+	return r
+}
+
+// argToFloat64 converts the argument of the math function to float64. A string argument with a non-numeric part
+// is truncated to its numeric prefix with a "Truncated incorrect DOUBLE value" warning like MySQL does,
+// even if the statement ignores the truncation, but it's still an error for an INSERT, UPDATE or DELETE in strict mode.
+func argToFloat64(sc *variable.StatementContext, arg types.Datum) (float64, error) {
+	if arg.Kind() != types.KindString && arg.Kind() != types.KindBytes {
+		return arg.ToFloat64(sc)
+	}
+	s := arg.GetString()
+	// The zero statement context reports the truncation as an error, it's turned into a warning below.
+	f, err := types.StrToFloat(&variable.StatementContext{}, s)
+	if !terror.ErrorEqual(err, types.ErrTruncated) {
+		return f, errors.Trace(err)
+	}
+	// The strict mode error is the truncation itself, the warning tells the value.
+	if handleTruncateError(sc, errTruncatedWrongValue.GenByArgs("DOUBLE", s)) != nil {
+		return f, errors.Trace(err)
+	}
+	return f, nil
+}
+
+// floatResult checks the float result of the math function named name. NaN means the arguments are out of the domain
+// of the function and results in NULL, while Inf is out of the DOUBLE range and results in an error like MySQL does.
+// The out of range error is a warning and the result is NULL when the statement treats truncation as warning,
+// i.e. an INSERT, UPDATE or DELETE in non-strict mode.
+func floatResult(sc *variable.StatementContext, f float64, name string, args []types.Datum) (d types.Datum, err error) {
+	if math.IsNaN(f) {
+		return d, nil
+	}
+	if math.IsInf(f, 0) {
+		return dataOutOfRange(sc, "DOUBLE", name, args)
+	}
+	d.SetFloat64(f)
 	return d, nil
 }
 
+// dataOutOfRange reports the result of the math function named name is out of the range of tp.
+// It's a warning and the result is NULL when the statement treats truncation as warning.
+func dataOutOfRange(sc *variable.StatementContext, tp string, name string, args []types.Datum) (d types.Datum, err error) {
+	strs := make([]string, 0, len(args))
+	for _, arg := range args {
+		str, err1 := arg.ToString()
+		if err1 != nil {
+			str = "?"
+		}
+		strs = append(strs, str)
+	}
+	err = errDataOutOfRange.GenByArgs(tp, fmt.Sprintf("%s(%s)", name, strings.Join(strs, ",")))
+	// Unlike a truncation, the out of range value is an error even if the statement ignores truncation, e.g. a SELECT.
+	if sc.IgnoreTruncate {
+		return d, errors.Trace(err)
+	}
+	return d, errors.Trace(handleTruncateError(sc, err))
+}
+
 func arithmeticFuncFactory(op opcode.Op) BuiltinFunc {
 	return func(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
 		sc := ctx.GetSessionVars().StmtCtx
@@ -229,20 +645,424 @@ func arithmeticFuncFactory(op opcode.Op) BuiltinFunc {
 		}
 
 		switch op {
-		case opcode.Plus:
-			return types.ComputePlus(a, b)
-		case opcode.Minus:
-			return types.ComputeMinus(a, b)
-		case opcode.Mul:
-			return types.ComputeMul(a, b)
-		case opcode.Div:
-			return types.ComputeDiv(sc, a, b)
 		case opcode.Mod:
 			return types.ComputeMod(sc, a, b)
-		case opcode.IntDiv:
-			return types.ComputeIntDiv(sc, a, b)
 		default:
 			return d, errInvalidOperation.Gen("invalid op %v in arithmetic operation", op)
 		}
 	}
 }
+
+type plusFuncClass struct {
+	baseFuncClass
+}
+
+func (c *plusFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinPlus{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinPlus struct {
+	baseBuiltinFunc
+}
+
+// eval evaluates x + y.
+// See https://dev.mysql.com/doc/refman/5.7/en/arithmetic-functions.html#operator_plus
+func (b *builtinPlus) eval(row []types.Datum) (d types.Datum, err error) {
+	return b.evalArithmetic(row, opcode.Plus, ast.Plus)
+}
+
+type minusFuncClass struct {
+	baseFuncClass
+}
+
+func (c *minusFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinMinus{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinMinus struct {
+	baseBuiltinFunc
+}
+
+// eval evaluates x - y.
+// See https://dev.mysql.com/doc/refman/5.7/en/arithmetic-functions.html#operator_minus
+func (b *builtinMinus) eval(row []types.Datum) (d types.Datum, err error) {
+	return b.evalArithmetic(row, opcode.Minus, ast.Minus)
+}
+
+type mulFuncClass struct {
+	baseFuncClass
+}
+
+func (c *mulFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinMul{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinMul struct {
+	baseBuiltinFunc
+}
+
+// eval evaluates x * y.
+// See https://dev.mysql.com/doc/refman/5.7/en/arithmetic-functions.html#operator_times
+func (b *builtinMul) eval(row []types.Datum) (d types.Datum, err error) {
+	return b.evalArithmetic(row, opcode.Mul, ast.Mul)
+}
+
+type divFuncClass struct {
+	baseFuncClass
+}
+
+func (c *divFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinDiv{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinDiv struct {
+	baseBuiltinFunc
+}
+
+// eval evaluates x / y, the quotient of integers is a decimal.
+// See https://dev.mysql.com/doc/refman/5.7/en/arithmetic-functions.html#operator_divide
+func (b *builtinDiv) eval(row []types.Datum) (d types.Datum, err error) {
+	return b.evalArithmetic(row, opcode.Div, ast.Div)
+}
+
+// evalArithmetic evaluates the arithmetic operator op named name. Strings are converted to DOUBLE, and the operands
+// are then computed as DOUBLE if any of them is a float, as DECIMAL if any of them is a decimal, otherwise as BIGINT,
+// which is unsigned if any of them is unsigned. The sum and the difference of decimals keep the larger scale of the
+// operands and the product has the sum of their scales, while the quotient has div_precision_increment more digits
+// than the dividend. A result out of the range of its type is an error, and the division by zero results in NULL.
+func (b *baseBuiltinFunc) evalArithmetic(row []types.Datum, op opcode.Op, name string) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() || args[1].IsNull() {
+		return d, nil
+	}
+	sc := b.ctx.GetSessionVars().StmtCtx
+	x, err := types.CoerceArithmetic(sc, args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	y, err := types.CoerceArithmetic(sc, args[1])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	x, y, err = types.CoerceDatum(sc, x, y)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if op == opcode.Div && isIntegerDatum(x) {
+		// The integers are divided as decimals.
+		xDec, err := x.ToDecimal(sc)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		yDec, err := y.ToDecimal(sc)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		x.SetMysqlDecimal(xDec)
+		y.SetMysqlDecimal(yDec)
+	}
+	switch x.Kind() {
+	case types.KindFloat64:
+		var f float64
+		switch op {
+		case opcode.Plus:
+			f = x.GetFloat64() + y.GetFloat64()
+		case opcode.Minus:
+			f = x.GetFloat64() - y.GetFloat64()
+		case opcode.Mul:
+			f = x.GetFloat64() * y.GetFloat64()
+		case opcode.Div:
+			if y.GetFloat64() == 0 {
+				return divisionByZero(b.ctx)
+			}
+			f = x.GetFloat64() / y.GetFloat64()
+		}
+		if math.IsInf(f, 0) {
+			return dataOutOfRange(sc, "DOUBLE", name, args)
+		}
+		d.SetFloat64(f)
+		return d, nil
+	case types.KindMysqlDecimal:
+		to := new(types.MyDecimal)
+		switch op {
+		case opcode.Plus:
+			err = types.DecimalAdd(x.GetMysqlDecimal(), y.GetMysqlDecimal(), to)
+		case opcode.Minus:
+			err = types.DecimalSub(x.GetMysqlDecimal(), y.GetMysqlDecimal(), to)
+		case opcode.Mul:
+			err = types.DecimalMul(x.GetMysqlDecimal(), y.GetMysqlDecimal(), to)
+		case opcode.Div:
+			err = types.DecimalDiv(x.GetMysqlDecimal(), y.GetMysqlDecimal(), to, types.DivFracIncr)
+		}
+		switch err {
+		case types.ErrDivByZero:
+			return divisionByZero(b.ctx)
+		case types.ErrOverflow:
+			return dataOutOfRange(sc, "DECIMAL", name, args)
+		case nil, types.ErrTruncated:
+			// The digits beyond the max scale of the decimal are rounded.
+			d.SetMysqlDecimal(to)
+			return d, nil
+		}
+		return d, errors.Trace(err)
+	}
+	unsigned := x.Kind() == types.KindUint64 || y.Kind() == types.KindUint64
+	if op == opcode.Minus && unsigned && b.ctx.GetSessionVars().NoUnsignedSubtraction {
+		// The difference is signed, so the unsigned operands are subtracted as decimals.
+		return signedSubtraction(sc, x, y, args)
+	}
+	switch op {
+	case opcode.Plus:
+		d, err = types.ComputePlus(x, y)
+	case opcode.Minus:
+		d, err = types.ComputeMinus(x, y)
+	case opcode.Mul:
+		d, err = types.ComputeMul(x, y)
+	}
+	if terror.ErrorEqual(err, types.ErrArithOverflow) {
+		tp := "BIGINT"
+		if unsigned {
+			tp = "BIGINT UNSIGNED"
+		}
+		return dataOutOfRange(sc, tp, name, args)
+	}
+	return d, errors.Trace(err)
+}
+
+// signedSubtraction computes x - y as a signed integer for the NO_UNSIGNED_SUBTRACTION sql_mode,
+// both x and y are integers and any of them is unsigned.
+func signedSubtraction(sc *variable.StatementContext, x, y types.Datum, args []types.Datum) (d types.Datum, err error) {
+	xDec, err := x.ToDecimal(sc)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	yDec, err := y.ToDecimal(sc)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	to := new(types.MyDecimal)
+	if err = types.DecimalSub(xDec, yDec, to); err != nil {
+		return d, errors.Trace(err)
+	}
+	i, err := to.ToInt()
+	if err == types.ErrOverflow {
+		return dataOutOfRange(sc, "BIGINT", ast.Minus, args)
+	}
+	d.SetInt64(i)
+	return d, nil
+}
+
+type unaryMinusFuncClass struct {
+	baseFuncClass
+}
+
+func (c *unaryMinusFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinUnaryMinus{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinUnaryMinus struct {
+	baseBuiltinFunc
+}
+
+// eval evaluates -x. The negation of an integer is a signed BIGINT, which is out of range for the min int64 or an
+// unsigned integer larger than 9223372036854775808, except that the negation of such an unsigned constant is a
+// decimal like MySQL does, e.g. -18446744073709551615.
+// NO_UNSIGNED_SUBTRACTION doesn't change it: MySQL only applies the sql_mode to the subtraction of two operands,
+// the negation of an unsigned integer is already signed, so the mode is honored by builtinMinus instead.
+// See https://dev.mysql.com/doc/refman/5.7/en/arithmetic-functions.html#operator_unary-minus
+func (b *builtinUnaryMinus) eval(row []types.Datum) (d types.Datum, err error) {
+	arg, err := b.args[0].Eval(row, b.ctx)
+	if err != nil || arg.IsNull() {
+		return d, errors.Trace(err)
+	}
+	sc := b.ctx.GetSessionVars().StmtCtx
+	switch arg.Kind() {
+	case types.KindInt64:
+		if arg.GetInt64() == math.MinInt64 {
+			return dataOutOfRange(sc, "BIGINT", "-", []types.Datum{arg})
+		}
+		d.SetInt64(-arg.GetInt64())
+	case types.KindUint64:
+		u := arg.GetUint64()
+		if u <= -math.MinInt64 {
+			// The negation of 9223372036854775808 wraps around to the min int64 itself.
+			d.SetInt64(-int64(u))
+			return d, nil
+		}
+		if _, ok := b.args[0].(*Constant); !ok {
+			return dataOutOfRange(sc, "BIGINT", "-", []types.Datum{arg})
+		}
+		dec := new(types.MyDecimal).FromUint(u)
+		to := new(types.MyDecimal)
+		err = types.DecimalSub(new(types.MyDecimal), dec, to)
+		d.SetMysqlDecimal(to)
+	case types.KindFloat32:
+		d.SetFloat32(-arg.GetFloat32())
+	case types.KindFloat64:
+		d.SetFloat64(-arg.GetFloat64())
+	case types.KindString, types.KindBytes:
+		var f float64
+		f, err = argToFloat64(sc, arg)
+		d.SetFloat64(-f)
+	case types.KindMysqlDecimal, types.KindMysqlTime, types.KindMysqlDuration:
+		dec, err := arg.ToDecimal(sc)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		to := new(types.MyDecimal)
+		err = types.DecimalSub(new(types.MyDecimal), dec, to)
+		d.SetMysqlDecimal(to)
+		return d, errors.Trace(err)
+	default:
+		// ENUM, SET, BIT and hexadecimal values are negated as floats.
+		var f float64
+		f, err = arg.ToFloat64(sc)
+		d.SetFloat64(-f)
+	}
+	return d, errors.Trace(err)
+}
+
+type intDivFuncClass struct {
+	baseFuncClass
+}
+
+func (c *intDivFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinIntDiv{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinIntDiv struct {
+	baseBuiltinFunc
+}
+
+// eval evaluates the integer division x DIV y, whose quotient is truncated toward zero. Integers are divided as
+// integers, other operands are converted to decimal instead of float so that no precision is lost. The result is
+// unsigned if any operand is unsigned, and the division by zero results in NULL.
+// See https://dev.mysql.com/doc/refman/5.7/en/arithmetic-functions.html#operator_div
+func (b *builtinIntDiv) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	x, y := args[0], args[1]
+	if x.IsNull() || y.IsNull() {
+		return d, nil
+	}
+	sc := b.ctx.GetSessionVars().StmtCtx
+	unsigned := x.Kind() == types.KindUint64 || y.Kind() == types.KindUint64
+	tp := "BIGINT"
+	if unsigned {
+		tp = "BIGINT UNSIGNED"
+	}
+	if isIntegerDatum(x) && isIntegerDatum(y) {
+		if (y.Kind() == types.KindInt64 && y.GetInt64() == 0) || (y.Kind() == types.KindUint64 && y.GetUint64() == 0) {
+			return divisionByZero(b.ctx)
+		}
+		d, err = types.ComputeIntDiv(sc, x, y)
+		if terror.ErrorEqual(err, types.ErrArithOverflow) {
+			return dataOutOfRange(sc, tp, ast.IntDiv, args)
+		}
+		return d, errors.Trace(err)
+	}
+	xDec, err := argToDecimal(sc, x)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	yDec, err := argToDecimal(sc, y)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	quo := new(types.MyDecimal)
+	err = types.DecimalDiv(xDec, yDec, quo, types.DivFracIncr)
+	if err == types.ErrDivByZero {
+		return divisionByZero(b.ctx)
+	}
+	if err != nil && err != types.ErrTruncated {
+		return d, errors.Trace(err)
+	}
+	// The fraction of the quotient is truncated, so ErrTruncated is ignored.
+	if unsigned && !quo.IsNegative() {
+		u, err := quo.ToUint()
+		if err == types.ErrOverflow {
+			return dataOutOfRange(sc, tp, ast.IntDiv, args)
+		}
+		d.SetUint64(u)
+		return d, nil
+	}
+	i, err := quo.ToInt()
+	if err == types.ErrOverflow || (unsigned && i < 0) {
+		return dataOutOfRange(sc, tp, ast.IntDiv, args)
+	}
+	if unsigned {
+		d.SetUint64(uint64(i))
+	} else {
+		d.SetInt64(i)
+	}
+	return d, nil
+}
+
+// isIntegerDatum returns whether the datum is a signed or an unsigned integer.
+func isIntegerDatum(d types.Datum) bool {
+	return d.Kind() == types.KindInt64 || d.Kind() == types.KindUint64
+}
+
+// argToDecimal converts the argument of the arithmetic operator to decimal. A string argument with a non-numeric
+// part is truncated to its numeric prefix with a "Truncated incorrect DECIMAL value" warning like argToFloat64 does.
+func argToDecimal(sc *variable.StatementContext, arg types.Datum) (*types.MyDecimal, error) {
+	if arg.Kind() != types.KindString && arg.Kind() != types.KindBytes {
+		dec, err := arg.ToDecimal(sc)
+		return dec, errors.Trace(err)
+	}
+	s := arg.GetString()
+	// The float parsing only tells whether the string is numeric, the decimal keeps all the digits of the string.
+	_, err := types.StrToFloat(&variable.StatementContext{}, s)
+	if err != nil && !terror.ErrorEqual(err, types.ErrTruncated) {
+		return nil, errors.Trace(err)
+	}
+	if err != nil && handleTruncateError(sc, errTruncatedWrongValue.GenByArgs("DECIMAL", s)) != nil {
+		return nil, errors.Trace(err)
+	}
+	dec := new(types.MyDecimal)
+	if dec.FromString([]byte(s)) != nil {
+		// There is no numeric prefix at all.
+		dec.FromInt(0)
+	}
+	return dec, nil
+}
+
+// divisionByZero handles the division by zero, whose result is NULL. It's reported only if the sql_mode has
+// ERROR_FOR_DIVISION_BY_ZERO, as an error for an INSERT, UPDATE or DELETE in strict mode, otherwise as a warning.
+func divisionByZero(ctx context.Context) (d types.Datum, err error) {
+	vars := ctx.GetSessionVars()
+	if !vars.ErrorForDivisionByZero {
+		return d, nil
+	}
+	err = handleTruncateError(vars.StmtCtx, errDivisionByZero.GenByArgs())
+	return d, errors.Trace(err)
+}
@@ -14,7 +14,17 @@
 package expression
 
 import (
+	"hash/crc32"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
 	"github.com/pingcap/tidb/util/types"
@@ -41,6 +51,28 @@ func (s *testEvaluatorSuite) TestAbs(c *C) {
 		c.Assert(err, IsNil)
 		c.Assert(v, testutil.DatumEquals, t["Ret"][0])
 	}
+
+	_, err := builtinAbs(types.MakeDatums(int64(math.MinInt64)), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errDataOutOfRange), IsTrue)
+
+	// ABS of a decimal returns a decimal keeping the scale and all the digits.
+	decTbl := []struct {
+		arg string
+		ret string
+	}{
+		{"-1.23", "1.23"},
+		{"1.23", "1.23"},
+		{"-0.00", "0.00"},
+		{"-12345678901234567890.123456789012345678", "12345678901234567890.123456789012345678"},
+		{"-0.000000000000000000000000000001", "0.000000000000000000000000000001"},
+		{"-99999999999999999999999999999999999999999999999999999999999999999", "99999999999999999999999999999999999999999999999999999999999999999"},
+	}
+	for _, t := range decTbl {
+		v, err := builtinAbs(types.MakeDatums(types.NewDecFromStringForTest(t.arg)), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.Kind(), Equals, types.KindMysqlDecimal, Commentf("arg:%v", t.arg))
+		c.Assert(v.GetMysqlDecimal().String(), Equals, t.ret, Commentf("arg:%v", t.arg))
+	}
 }
 
 func (s *testEvaluatorSuite) TestCeil(c *C) {
@@ -64,6 +96,77 @@ func (s *testEvaluatorSuite) TestCeil(c *C) {
 		c.Assert(err, IsNil)
 		c.Assert(v, DeepEquals, t["Ret"][0], Commentf("arg:%v", t["Arg"]))
 	}
+
+	// CEIL of a decimal returns a decimal with scale 0.
+	decTbl := []struct {
+		arg string
+		ret string
+	}{
+		{"1.23", "2"},
+		{"-1.23", "-1"},
+		{"1.5", "2"},
+		{"-1.5", "-1"},
+		{"2.00", "2"},
+		{"0.1", "1"},
+		{"-0.1", "0"},
+		{"99999999999999999999.001", "100000000000000000000"},
+		{"12345678901234567890.000000000000000001", "12345678901234567891"},
+		{"-12345678901234567890.999999999999999999", "-12345678901234567890"},
+	}
+	for _, t := range decTbl {
+		v, err := builtinCeil(types.MakeDatums(types.NewDecFromStringForTest(t.arg)), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.Kind(), Equals, types.KindMysqlDecimal, Commentf("arg:%v", t.arg))
+		c.Assert(v.GetMysqlDecimal().String(), Equals, t.ret, Commentf("arg:%v", t.arg))
+		_, frac := v.GetMysqlDecimal().PrecisionAndFrac()
+		c.Assert(frac, Equals, 0)
+	}
+}
+
+func (s *testEvaluatorSuite) TestFloor(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		Arg interface{}
+		Ret interface{}
+	}{
+		{nil, nil},
+		{int64(1), int64(1)},
+		{uint64(1), uint64(1)},
+		{float64(1.23), float64(1)},
+		{float64(-1.23), float64(-2)},
+		{"1.23", float64(1)},
+		{"-1.23", float64(-2)},
+	}
+
+	Dtbl := tblToDtbl(tbl)
+
+	for _, t := range Dtbl {
+		v, err := builtinFloor(t["Arg"], s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, DeepEquals, t["Ret"][0], Commentf("arg:%v", t["Arg"]))
+	}
+
+	// FLOOR of a decimal returns a decimal with scale 0.
+	decTbl := []struct {
+		arg string
+		ret string
+	}{
+		{"1.23", "1"},
+		{"-1.23", "-2"},
+		{"1.5", "1"},
+		{"-1.5", "-2"},
+		{"-2.00", "-2"},
+		{"0.1", "0"},
+		{"-0.1", "-1"},
+		{"12345678901234567890.999999999999999999", "12345678901234567890"},
+		{"-12345678901234567890.000000000000000001", "-12345678901234567891"},
+	}
+	for _, t := range decTbl {
+		v, err := builtinFloor(types.MakeDatums(types.NewDecFromStringForTest(t.arg)), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.Kind(), Equals, types.KindMysqlDecimal, Commentf("arg:%v", t.arg))
+		c.Assert(v.GetMysqlDecimal().String(), Equals, t.ret, Commentf("arg:%v", t.arg))
+	}
 }
 
 func (s *testEvaluatorSuite) TestLog(c *C) {
@@ -77,6 +180,10 @@ func (s *testEvaluatorSuite) TestLog(c *C) {
 
 		{[]interface{}{int64(2), int64(65536)}, float64(16)},
 		{[]interface{}{int64(10), int64(100)}, float64(2)},
+		{[]interface{}{int64(2), float64(0.5)}, float64(-1)},
+		{[]interface{}{float64(0.5), int64(4)}, float64(-2)},
+		{[]interface{}{float64(0.25), float64(0.5)}, float64(0.5)},
+		{[]interface{}{float64(0.999), float64(0.999)}, float64(1)},
 	}
 
 	Dtbl := tblToDtbl(tbl)
@@ -92,6 +199,10 @@ func (s *testEvaluatorSuite) TestLog(c *C) {
 	}{
 		{[]interface{}{int64(-2)}},
 		{[]interface{}{int64(1), int64(100)}},
+		{[]interface{}{int64(0), int64(100)}},
+		{[]interface{}{float64(-0.5), int64(100)}},
+		{[]interface{}{float64(0.5), int64(0)}},
+		{[]interface{}{int64(2), int64(-1)}},
 	}
 
 	nullDtbl := tblToDtbl(nullTbl)
@@ -161,6 +272,11 @@ func (s *testEvaluatorSuite) TestRound(c *C) {
 		{[]interface{}{1.298}, 1},
 		{[]interface{}{1.298, 0}, 1},
 		{[]interface{}{23.298, -1}, 20},
+		// A float is rounded as the decimal it's written as.
+		{[]interface{}{2.675, 2}, 2.68},
+		{[]interface{}{0.285, 2}, 0.29},
+		{[]interface{}{"2.675", 2}, 2.68},
+		{[]interface{}{types.NewDecFromStringForTest("0.285"), 2}, 0.29},
 	}
 
 	Dtbl := tblToDtbl(tbl)
@@ -170,6 +286,167 @@ func (s *testEvaluatorSuite) TestRound(c *C) {
 		c.Assert(err, IsNil)
 		c.Assert(v, testutil.DatumEquals, t["Ret"][0])
 	}
+
+	v, err := builtinRound(types.MakeDatums(nil), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.IsNull(), IsTrue)
+	v, err = builtinRound(types.MakeDatums(1.298, nil), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.IsNull(), IsTrue)
+
+	// Integers are rounded exactly.
+	intTbl := []struct {
+		Arg []interface{}
+		Ret interface{}
+	}{
+		{[]interface{}{uint64(18446744073709551615)}, uint64(18446744073709551615)},
+		{[]interface{}{uint64(18446744073709551615), 2}, uint64(18446744073709551615)},
+		{[]interface{}{uint64(9007199254740993), 0}, uint64(9007199254740993)},
+		{[]interface{}{uint64(9007199254740993), -1}, uint64(9007199254740990)},
+		{[]interface{}{uint64(9007199254740995), -1}, uint64(9007199254741000)},
+		{[]interface{}{uint64(18446744073709551615), -20}, uint64(0)},
+		{[]interface{}{int64(9223372036854775807), 3}, int64(9223372036854775807)},
+		{[]interface{}{int64(-9007199254740993), -1}, int64(-9007199254740990)},
+		{[]interface{}{int64(-9007199254740995), -1}, int64(-9007199254741000)},
+		{[]interface{}{int64(-9223372036854775808), -2}, int64(-9223372036854775800)},
+		{[]interface{}{int64(1234), -2}, int64(1200)},
+		{[]interface{}{int64(1250), -2}, int64(1300)},
+	}
+	for _, t := range intTbl {
+		v, err := builtinRound(types.MakeDatums(t.Arg...), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, testutil.DatumEquals, types.NewDatum(t.Ret), Commentf("%v", t.Arg))
+	}
+	for _, arg := range []interface{}{uint64(18446744073709551615), int64(9223372036854775807), int64(-9223372036854775808)} {
+		_, err = builtinRound(types.MakeDatums(arg, -1), s.ctx)
+		c.Assert(terror.ErrorEqual(err, errDataOutOfRange), IsTrue)
+	}
+
+	// A string is rounded as a DOUBLE, a malformed one is truncated with a warning.
+	sc := s.ctx.GetSessionVars().StmtCtx
+	oldTruncateAsWarning := sc.TruncateAsWarning
+	defer func() {
+		sc.TruncateAsWarning = oldTruncateAsWarning
+	}()
+	sc.TruncateAsWarning = true
+	strTbl := []struct {
+		arg  []interface{}
+		ret  float64
+		warn bool
+	}{
+		{[]interface{}{"3.14159", 2}, 3.14, false},
+		{[]interface{}{"12.5"}, 13, false},
+		{[]interface{}{"9x", 0}, 9, true},
+	}
+	for _, t := range strTbl {
+		warnCnt := len(sc.GetWarnings())
+		v, err = builtinRound(types.MakeDatums(t.arg...), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.Kind(), Equals, types.KindFloat64)
+		c.Assert(v.GetFloat64(), Equals, t.ret)
+		if t.warn {
+			c.Assert(sc.GetWarnings(), HasLen, warnCnt+1)
+			c.Assert(terror.ErrorEqual(sc.GetWarnings()[warnCnt], errTruncatedWrongValue), IsTrue)
+		} else {
+			c.Assert(sc.GetWarnings(), HasLen, warnCnt)
+		}
+	}
+}
+
+func (s *testEvaluatorSuite) TestRoundHalfEven(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		arg      []interface{}
+		halfUp   interface{}
+		halfEven interface{}
+	}{
+		{[]interface{}{2.5}, float64(3), float64(2)},
+		{[]interface{}{3.5}, float64(4), float64(4)},
+		{[]interface{}{-2.5}, float64(-3), float64(-2)},
+		{[]interface{}{-3.5}, float64(-4), float64(-4)},
+		{[]interface{}{2.51}, float64(3), float64(3)},
+		{[]interface{}{0.125, 2}, 0.13, 0.12},
+		{[]interface{}{250, -2}, float64(300), float64(200)},
+		{[]interface{}{int64(250), -2}, int64(300), int64(200)},
+		{[]interface{}{int64(-350), -2}, int64(-400), int64(-400)},
+		{[]interface{}{uint64(2500), -3}, uint64(3000), uint64(2000)},
+		{[]interface{}{int64(25), 1}, int64(25), int64(25)},
+	}
+	sessVars := s.ctx.GetSessionVars()
+	defer func() {
+		sessVars.RoundHalfEven = false
+	}()
+	for _, t := range tbl {
+		sessVars.RoundHalfEven = false
+		v, err := builtinRound(types.MakeDatums(t.arg...), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, testutil.DatumEquals, types.NewDatum(t.halfUp), Commentf("%v", t.arg))
+
+		sessVars.RoundHalfEven = true
+		v, err = builtinRound(types.MakeDatums(t.arg...), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, testutil.DatumEquals, types.NewDatum(t.halfEven), Commentf("%v", t.arg))
+	}
+}
+
+func (s *testEvaluatorSuite) TestRoundTemporal(c *C) {
+	defer testleak.AfterTest(c)()
+	sc := s.ctx.GetSessionVars().StmtCtx
+	dt, err := types.ParseTime("2017-01-02 03:04:05.123456", mysql.TypeDatetime, types.MaxFsp)
+	c.Assert(err, IsNil)
+	dur, err := types.ParseDuration("10:20:30.987654", types.MaxFsp)
+	c.Assert(err, IsNil)
+
+	tbl := []struct {
+		arg interface{}
+		dec int64
+		ret string
+	}{
+		{dt, 3, "2017-01-02 03:04:05.123"},
+		{dt, 0, "2017-01-02 03:04:05"},
+		{dt, 6, "2017-01-02 03:04:05.123456"},
+		{dur, 3, "10:20:30.988"},
+		{dur, 0, "10:20:31"},
+	}
+	for _, t := range tbl {
+		v, err := builtinRound(types.MakeDatums(t.arg, t.dec), s.ctx)
+		c.Assert(err, IsNil)
+		str, err := v.ToString()
+		c.Assert(err, IsNil)
+		c.Assert(str, Equals, t.ret)
+	}
+
+	// The decimals out of the fsp range falls back to the numeric conversion.
+	v, err := builtinRound(types.MakeDatums(dt, 7), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.Kind(), Equals, types.KindFloat64)
+	dtDatum := types.NewDatum(dt)
+	f, err := dtDatum.ToFloat64(sc)
+	c.Assert(err, IsNil)
+	c.Assert(v.GetFloat64(), Equals, f)
+	v, err = builtinRound(types.MakeDatums(dt, -2), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.GetFloat64(), Equals, float64(20170102030400))
+}
+
+func (s *testEvaluatorSuite) TestEnumAndSetInNumericFuncs(c *C) {
+	defer testleak.AfterTest(c)()
+	// The numeric index is used rather than the label.
+	enum := types.Enum{Name: "7.5", Value: 2}
+	set := types.Set{Name: "a,b", Value: 3}
+	fns := []BuiltinFunc{builtinCeil, builtinFloor, builtinRound}
+	for _, fn := range fns {
+		v, err := fn(types.MakeDatums(enum), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, DeepEquals, types.NewUintDatum(2))
+		v, err = fn(types.MakeDatums(set), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, DeepEquals, types.NewUintDatum(3))
+	}
+
+	v, err := builtinRound(types.MakeDatums(types.Enum{Name: "a", Value: 16}, -1), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v, DeepEquals, types.NewFloat64Datum(20))
 }
 
 func (s *testEvaluatorSuite) TestCRC32(c *C) {
@@ -181,13 +458,672 @@ func (s *testEvaluatorSuite) TestCRC32(c *C) {
 		{[]interface{}{"mysql"}, 2501908538},
 		{[]interface{}{"MySQL"}, 3259397556},
 		{[]interface{}{"hello"}, 907060870},
+		{[]interface{}{[]byte("a\x00b")}, uint64(crc32.ChecksumIEEE([]byte("a\x00b")))},
+		{[]interface{}{[]byte{0xff, 0x00, 0xfe}}, uint64(crc32.ChecksumIEEE([]byte{0xff, 0x00, 0xfe}))},
+		{[]interface{}{int64(123)}, uint64(crc32.ChecksumIEEE([]byte("123")))},
+		{[]interface{}{uint64(18446744073709551615)}, uint64(crc32.ChecksumIEEE([]byte("18446744073709551615")))},
+		{[]interface{}{1.5}, uint64(crc32.ChecksumIEEE([]byte("1.5")))},
+		{[]interface{}{types.NewDecFromStringForTest("-1.50")}, uint64(crc32.ChecksumIEEE([]byte("-1.50")))},
 	}
 
 	Dtbl := tblToDtbl(tbl)
 
 	for _, t := range Dtbl {
-		v, err := builtinCRC32(t["Arg"], s.ctx)
+		v, err := evalFuncClass(ast.CRC32, t["Arg"], s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(v, testutil.DatumEquals, t["Ret"][0])
 	}
+
+	v, err := evalFuncClass(ast.CRC32, []types.Datum{types.NewDatum(nil)}, s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.IsNull(), IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestConv(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		args []interface{}
+		ret  interface{}
+	}{
+		{[]interface{}{"a", 16, 2}, "1010"},
+		{[]interface{}{"6E", 18, 8}, "172"},
+		{[]interface{}{"zz", 36, 10}, "1295"},
+		{[]interface{}{10, 2, 10}, "2"},
+		{[]interface{}{"  12abc", 10, 10}, "12"},
+		{[]interface{}{"", 10, 10}, "0"},
+		{[]interface{}{"-", 10, 10}, "0"},
+		{[]interface{}{"ffffffffffffffff", 16, 10}, "18446744073709551615"},
+		// A negative number is converted to its 64-bit two's complement for a positive from_base.
+		{[]interface{}{"-1", 10, 16}, "FFFFFFFFFFFFFFFF"},
+		{[]interface{}{"-17", 10, 10}, "18446744073709551599"},
+		{[]interface{}{"-1", 10, -16}, "-1"},
+		{[]interface{}{"-17", -10, -16}, "-11"},
+		{[]interface{}{"ffffffffffffffff", 16, -10}, "-1"},
+		// The value is clamped if it overflows.
+		{[]interface{}{"10000000000000000000000", 10, 10}, "18446744073709551615"},
+		{[]interface{}{"-10000000000000000000000", 10, 10}, "18446744073709551615"},
+		{[]interface{}{"fffffffffffffffff", 16, 16}, "FFFFFFFFFFFFFFFF"},
+		{[]interface{}{"99999999999999999999", -10, -10}, "9223372036854775807"},
+		{[]interface{}{"-99999999999999999999", -10, -10}, "-9223372036854775808"},
+		{[]interface{}{"-9223372036854775808", -10, 10}, "9223372036854775808"},
+		{[]interface{}{"1", 1, 10}, nil},
+		{[]interface{}{"1", 10, 37}, nil},
+		{[]interface{}{"1", -37, 10}, nil},
+		{[]interface{}{nil, 10, 10}, nil},
+		{[]interface{}{"1", nil, 10}, nil},
+		{[]interface{}{"1", 10, nil}, nil},
+	}
+	for _, t := range tbl {
+		d, err := builtinConv(types.MakeDatums(t.args...), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v", t.args))
+	}
+}
+
+func (s *testEvaluatorSuite) TestTrigonometric(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		fn  BuiltinFunc
+		arg interface{}
+		ret interface{}
+	}{
+		{builtinSin, nil, nil},
+		{builtinSin, 0, float64(0)},
+		{builtinSin, math.Pi / 2, float64(1)},
+		{builtinCos, 0, float64(1)},
+		{builtinCos, math.Pi, float64(-1)},
+		{builtinTan, nil, nil},
+		{builtinTan, 0, float64(0)},
+		{builtinTan, "1", math.Tan(1)},
+		{builtinCot, nil, nil},
+		{builtinCot, 1, 1 / math.Tan(1)},
+		{builtinCot, math.Pi / 2, 1 / math.Tan(math.Pi/2)},
+		{builtinCot, math.Pi, 1 / math.Tan(math.Pi)},
+	}
+	for _, t := range tbl {
+		v, err := t.fn(types.MakeDatums(t.arg), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("arg:%v", t.arg))
+	}
+
+	// PI/2 can't be represented exactly, so TAN(PI/2) is a large finite value rather than an error.
+	d, err := builtinTan(types.MakeDatums(math.Pi/2), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetFloat64(), Greater, 1.6e16)
+
+	_, err = builtinCot(types.MakeDatums(0), s.ctx)
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*DOUBLE value is out of range in 'cot\\(0\\)'")
+}
+
+func (s *testEvaluatorSuite) TestRoundTrigResult(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		fn      BuiltinFunc
+		arg     interface{}
+		ret     float64
+		rounded float64
+	}{
+		{builtinSin, 0.5235987755982988, 0.49999999999999994, 0.5},
+		{builtinCos, 1.0471975511965976, 0.5000000000000001, 0.5},
+		{builtinSin, 3.141592653589793, 1.2246467991473515e-16, 1.22464679914735e-16},
+		{builtinCos, 1.5707963267948966, 6.123233995736757e-17, 6.12323399573676e-17},
+		{builtinSin, 1, 0.8414709848078965, 0.841470984807897},
+		{builtinTan, 1, 1.557407724654902, 1.5574077246549},
+	}
+	sessVars := s.ctx.GetSessionVars()
+	defer func() {
+		sessVars.RoundTrigResult = false
+	}()
+	for _, t := range tbl {
+		sessVars.RoundTrigResult = false
+		v, err := t.fn(types.MakeDatums(t.arg), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.GetFloat64(), Equals, t.ret, Commentf("arg:%v", t.arg))
+
+		sessVars.RoundTrigResult = true
+		v, err = t.fn(types.MakeDatums(t.arg), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.GetFloat64(), Equals, t.rounded, Commentf("arg:%v", t.arg))
+	}
+
+	// NULL and the out of range results are not affected.
+	v, err := builtinSin(types.MakeDatums(nil), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.IsNull(), IsTrue)
+	_, err = builtinCot(types.MakeDatums(0), s.ctx)
+	c.Assert(err, NotNil)
+}
+
+func (s *testEvaluatorSuite) TestFloatResult(c *C) {
+	defer testleak.AfterTest(c)()
+	// The cube root of a negative number is NaN, which is out of the domain of POW.
+	d, err := builtinPow(types.MakeDatums(-8, float64(1)/3), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d.IsNull(), IsTrue)
+
+	_, err = builtinPow(types.MakeDatums(10, 400), s.ctx)
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*DOUBLE value is out of range in 'pow\\(10,400\\)'")
+
+	sc := s.ctx.GetSessionVars().StmtCtx
+	d, err = floatResult(sc, math.NaN(), ast.Tan, nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.IsNull(), IsTrue)
+	_, err = floatResult(sc, math.Inf(-1), ast.Log, types.MakeDatums(0))
+	c.Assert(err, NotNil)
+	d, err = floatResult(sc, math.MaxFloat64, ast.Pow, nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetFloat64(), Equals, math.MaxFloat64)
+
+	// The overflow is a warning when truncation is treated as warning, i.e. in non-strict mode.
+	oldTruncateAsWarning := sc.TruncateAsWarning
+	defer func() {
+		sc.TruncateAsWarning = oldTruncateAsWarning
+	}()
+	sc.TruncateAsWarning = true
+	warnCnt := len(sc.GetWarnings())
+	d, err = builtinPow(types.MakeDatums(10, 400), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d.IsNull(), IsTrue)
+	c.Assert(sc.GetWarnings(), HasLen, warnCnt+1)
+	d, err = builtinExp(types.MakeDatums(1000), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d.IsNull(), IsTrue)
+	c.Assert(sc.GetWarnings(), HasLen, warnCnt+2)
+}
+
+func (s *testEvaluatorSuite) TestExp(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		Arg interface{}
+		Ret interface{}
+	}{
+		{nil, nil},
+		{int64(0), float64(1)},
+		{int64(1), math.E},
+		{float64(-1), 1 / math.E},
+		{"2", math.Exp(2)},
+	}
+
+	Dtbl := tblToDtbl(tbl)
+
+	for _, t := range Dtbl {
+		v, err := builtinExp(t["Arg"], s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, DeepEquals, t["Ret"][0], Commentf("arg:%v", t["Arg"]))
+	}
+
+	_, err := builtinExp(types.MakeDatums(1000), s.ctx)
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*DOUBLE value is out of range in 'exp\\(1000\\)'")
+}
+
+func (s *testEvaluatorSuite) TestSqrt(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		Arg interface{}
+		Ret interface{}
+	}{
+		{nil, nil},
+		{int64(0), float64(0)},
+		{int64(4), float64(2)},
+		{uint64(16), float64(4)},
+		{float64(2.25), float64(1.5)},
+		{"20", math.Sqrt(20)},
+		{int64(-16), nil},
+	}
+
+	Dtbl := tblToDtbl(tbl)
+
+	for _, t := range Dtbl {
+		v, err := builtinSqrt(t["Arg"], s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, DeepEquals, t["Ret"][0], Commentf("arg:%v", t["Arg"]))
+	}
+}
+
+func (s *testEvaluatorSuite) TestRealResultForIntegerArgs(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		fn   func([]types.Datum, context.Context) (types.Datum, error)
+		args []interface{}
+		ret  float64
+	}{
+		{builtinPow, []interface{}{2, 3}, 8},
+		{builtinPow, []interface{}{uint64(2), int64(-1)}, 0.5},
+		{builtinSqrt, []interface{}{9}, 3},
+		{builtinExp, []interface{}{0}, 1},
+	}
+	for _, t := range tbl {
+		v, err := t.fn(types.MakeDatums(t.args...), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.Kind(), Equals, types.KindFloat64, Commentf("%v", t.args))
+		c.Assert(v.GetFloat64(), Equals, t.ret)
+	}
+}
+
+func (s *testEvaluatorSuite) TestTruncatedStringArgs(c *C) {
+	defer testleak.AfterTest(c)()
+	sc := s.ctx.GetSessionVars().StmtCtx
+	oldIgnoreTruncate, oldTruncateAsWarning := sc.IgnoreTruncate, sc.TruncateAsWarning
+	defer func() {
+		sc.IgnoreTruncate, sc.TruncateAsWarning = oldIgnoreTruncate, oldTruncateAsWarning
+	}()
+
+	tbl := []struct {
+		fn   BuiltinFunc
+		args []interface{}
+		ret  interface{}
+		warn string
+	}{
+		{builtinAbs, []interface{}{"-12abc"}, float64(12), "-12abc"},
+		{builtinPow, []interface{}{"3x", 2}, float64(9), "3x"},
+		{builtinPow, []interface{}{2, "3x"}, float64(8), "3x"},
+		{builtinRound, []interface{}{"1.5x"}, float64(2), "1.5x"},
+		{builtinLog, []interface{}{"1y"}, float64(0), "1y"},
+		{builtinLog, []interface{}{"x", 1}, nil, "x"},
+	}
+	// The truncation is a warning whether the statement ignores it like a SELECT, or treats it as warning like
+	// an INSERT in non-strict mode.
+	for _, mode := range []struct{ ignore, asWarning bool }{{true, false}, {false, true}} {
+		sc.IgnoreTruncate, sc.TruncateAsWarning = mode.ignore, mode.asWarning
+		for _, t := range tbl {
+			warnCnt := len(sc.GetWarnings())
+			d, err := t.fn(types.MakeDatums(t.args...), s.ctx)
+			c.Assert(err, IsNil)
+			c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v", t.args))
+			warnings := sc.GetWarnings()
+			c.Assert(warnings, HasLen, warnCnt+1, Commentf("%v", t.args))
+			c.Assert(terror.ErrorEqual(warnings[warnCnt], errTruncatedWrongValue), IsTrue)
+			c.Assert(warnings[warnCnt].Error(), Matches, ".*Truncated incorrect DOUBLE value: '"+t.warn+"'")
+		}
+	}
+
+	// A valid number has no warning.
+	warnCnt := len(sc.GetWarnings())
+	_, err := builtinAbs(types.MakeDatums(" -12 "), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(sc.GetWarnings(), HasLen, warnCnt)
+
+	// The truncation is still an error in strict mode.
+	sc.IgnoreTruncate, sc.TruncateAsWarning = false, false
+	_, err = builtinAbs(types.MakeDatums("12abc"), s.ctx)
+	c.Assert(terror.ErrorEqual(err, types.ErrTruncated), IsTrue)
+}
+
+// mathBoundaryArgs are the boundary arguments of the math functions, all their combinations are checked.
+var mathBoundaryArgs = []types.Datum{
+	types.NewIntDatum(0),
+	types.NewIntDatum(1),
+	types.NewIntDatum(-1),
+	types.NewIntDatum(math.MaxInt64),
+	types.NewIntDatum(math.MinInt64),
+	types.NewUintDatum(math.MaxUint64),
+	types.NewFloat64Datum(0),
+	types.NewFloat64Datum(math.Copysign(0, -1)),
+	types.NewFloat64Datum(0.5),
+	types.NewFloat64Datum(-0.5),
+	types.NewFloat64Datum(math.MaxFloat64),
+	types.NewFloat64Datum(-math.MaxFloat64),
+	types.NewFloat64Datum(math.SmallestNonzeroFloat64),
+	types.NewDecimalDatum(types.NewDecFromStringForTest("-12345678901234567890.123456789")),
+	types.NewStringDatum("1e308"),
+	types.NewStringDatum("-1e309"),
+	types.NewStringDatum("abc"),
+	types.NewStringDatum(""),
+	types.NewStringDatum("12abc"),
+}
+
+// randMathArg generates a random argument of the math functions.
+func randMathArg(r *rand.Rand) types.Datum {
+	f := r.NormFloat64() * math.Pow(10, float64(r.Intn(616)-308))
+	switch r.Intn(6) {
+	case 0:
+		return types.NewIntDatum(r.Int63() - r.Int63())
+	case 1:
+		return types.NewUintDatum(uint64(r.Int63()) << uint(r.Intn(2)))
+	case 2:
+		return types.NewFloat64Datum(f)
+	case 3:
+		return types.NewDecimalDatum(types.NewDecFromFloatForTest(r.NormFloat64() * 1e10))
+	case 4:
+		return types.NewStringDatum(strconv.FormatFloat(f, 'g', -1, 64))
+	default:
+		return mathBoundaryArgs[r.Intn(len(mathBoundaryArgs))]
+	}
+}
+
+// TestMathFuncsRandomArgs feeds the boundary and random arguments to the math functions,
+// the functions may return errors but they must not panic or return NaN or Inf, and a NULL argument results in NULL.
+func (s *testEvaluatorSuite) TestMathFuncsRandomArgs(c *C) {
+	defer testleak.AfterTest(c)()
+	names := []string{ast.Abs, ast.Ceil, ast.Ceiling, ast.Floor, ast.Round, ast.Pow, ast.Power, ast.Exp, ast.Ln, ast.Log, ast.Log2, ast.Log10, ast.Sqrt}
+	// The seed is fixed so the failures are reproducible.
+	r := rand.New(rand.NewSource(1))
+	check := func(name string, args []types.Datum) {
+		defer func() {
+			if p := recover(); p != nil {
+				c.Fatalf("%s%v panics: %v", name, args, p)
+			}
+		}()
+		d, err := Funcs[name].F(args, s.ctx)
+		if err != nil {
+			return
+		}
+		for _, arg := range args {
+			if arg.IsNull() {
+				c.Assert(d.IsNull(), IsTrue, Commentf("%s%v", name, args))
+			}
+		}
+		if d.Kind() == types.KindFloat64 {
+			f := d.GetFloat64()
+			c.Assert(math.IsNaN(f) || math.IsInf(f, 0), IsFalse, Commentf("%s%v", name, args))
+		}
+		if name == ast.Abs && d.Kind() == types.KindInt64 {
+			c.Assert(d.GetInt64(), GreaterEqual, int64(0), Commentf("%s%v", name, args))
+		}
+	}
+
+	boundaryArgs := append([]types.Datum{{}}, mathBoundaryArgs...)
+	for _, name := range names {
+		f := Funcs[name]
+		for argc := f.MinArgs; argc <= f.MaxArgs; argc++ {
+			switch argc {
+			case 1:
+				for _, x := range boundaryArgs {
+					check(name, []types.Datum{x})
+				}
+			case 2:
+				for _, x := range boundaryArgs {
+					for _, y := range boundaryArgs {
+						check(name, []types.Datum{x, y})
+					}
+				}
+			}
+			for i := 0; i < 1000; i++ {
+				args := make([]types.Datum, 0, argc)
+				for j := 0; j < argc; j++ {
+					args = append(args, randMathArg(r))
+				}
+				check(name, args)
+			}
+		}
+	}
+}
+
+func (s *testEvaluatorSuite) TestArithmeticOperators(c *C) {
+	defer testleak.AfterTest(c)()
+	sc := s.ctx.GetSessionVars().StmtCtx
+	tbl := []struct {
+		lhs interface{}
+		op  string
+		rhs interface{}
+		ret interface{}
+	}{
+		{1, ast.Plus, 2, int64(3)},
+		{1, ast.Plus, uint64(2), uint64(3)},
+		{uint64(1), ast.Minus, -2, uint64(3)},
+		{3, ast.Mul, -2, int64(-6)},
+		{1, ast.Plus, 0.5, 1.5},
+		{"1.5", ast.Mul, 2, float64(3)},
+		{nil, ast.Plus, 1, nil},
+		{1, ast.Div, nil, nil},
+		// The quotient of integers is a decimal.
+		{1, ast.Div, 3, types.NewDecFromStringForTest("0.3333")},
+		{4, ast.Div, 2, types.NewDecFromStringForTest("2.0000")},
+		{-7, ast.Div, uint64(2), types.NewDecFromStringForTest("-3.5000")},
+		{1.0, ast.Div, 4.0, 0.25},
+		// The scale of the decimal result follows the scales of the operands.
+		{types.NewDecFromStringForTest("1.10"), ast.Plus, types.NewDecFromStringForTest("2.205"), types.NewDecFromStringForTest("3.305")},
+		{types.NewDecFromStringForTest("1.10"), ast.Minus, 1, types.NewDecFromStringForTest("0.10")},
+		{types.NewDecFromStringForTest("1.10"), ast.Mul, types.NewDecFromStringForTest("2.00"), types.NewDecFromStringForTest("2.2000")},
+		{types.NewDecFromStringForTest("1.00"), ast.Div, 3, types.NewDecFromStringForTest("0.333333")},
+		{types.NewDecFromStringForTest("12345678901234567890.12345678901234567890"), ast.Plus, 1, types.NewDecFromStringForTest("12345678901234567891.12345678901234567890")},
+		// The integer result at the boundary of BIGINT.
+		{int64(math.MaxInt64), ast.Plus, 0, int64(math.MaxInt64)},
+		{int64(math.MinInt64), ast.Minus, 0, int64(math.MinInt64)},
+		{uint64(math.MaxUint64), ast.Minus, uint64(1), uint64(math.MaxUint64 - 1)},
+		// The division by zero results in NULL.
+		{1, ast.Div, 0, nil},
+		{1.5, ast.Div, 0.0, nil},
+		{types.NewDecFromStringForTest("1.5"), ast.Div, types.NewDecFromStringForTest("0.00"), nil},
+		{"1", ast.Div, "0", nil},
+	}
+	for _, t := range tbl {
+		d, err := evalFuncClass(t.op, types.MakeDatums(t.lhs, t.rhs), s.ctx)
+		c.Assert(err, IsNil, Commentf("%v %s %v", t.lhs, t.op, t.rhs))
+		if dec, ok := t.ret.(*types.MyDecimal); ok {
+			c.Assert(d.Kind(), Equals, types.KindMysqlDecimal, Commentf("%v %s %v", t.lhs, t.op, t.rhs))
+			c.Assert(d.GetMysqlDecimal().String(), Equals, dec.String(), Commentf("%v %s %v", t.lhs, t.op, t.rhs))
+			continue
+		}
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v %s %v", t.lhs, t.op, t.rhs))
+	}
+
+	// The result out of the range of its type is an error, or a warning in non-strict mode.
+	outOfRangeTbl := []struct {
+		lhs interface{}
+		op  string
+		rhs interface{}
+	}{
+		{int64(math.MaxInt64), ast.Plus, 1},
+		{int64(math.MinInt64), ast.Minus, 1},
+		{int64(math.MinInt64), ast.Mul, -1},
+		{uint64(math.MaxUint64), ast.Plus, 1},
+		{uint64(0), ast.Minus, 1},
+		{1, ast.Minus, uint64(2)},
+		{uint64(math.MaxUint64), ast.Mul, 2},
+		{math.MaxFloat64, ast.Mul, 2.0},
+		{math.MaxFloat64, ast.Div, 0.5},
+		{types.NewDecFromStringForTest(strings.Repeat("9", 81)), ast.Plus, 1},
+	}
+	defer func() {
+		sc.IgnoreTruncate, sc.TruncateAsWarning = false, false
+		sc.SetWarnings(nil)
+	}()
+	for _, t := range outOfRangeTbl {
+		sc.TruncateAsWarning = false
+		_, err := evalFuncClass(t.op, types.MakeDatums(t.lhs, t.rhs), s.ctx)
+		c.Assert(terror.ErrorEqual(err, errDataOutOfRange), IsTrue, Commentf("%v %s %v", t.lhs, t.op, t.rhs))
+		sc.TruncateAsWarning = true
+		sc.SetWarnings(nil)
+		d, err := evalFuncClass(t.op, types.MakeDatums(t.lhs, t.rhs), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(d.IsNull(), IsTrue)
+		c.Assert(sc.GetWarnings(), HasLen, 1)
+	}
+
+	// The division by zero is an error for an INSERT, UPDATE or DELETE in the strict ERROR_FOR_DIVISION_BY_ZERO mode.
+	vars := s.ctx.GetSessionVars()
+	vars.ErrorForDivisionByZero = true
+	defer func() { vars.ErrorForDivisionByZero = false }()
+	sc.TruncateAsWarning = false
+	_, err := evalFuncClass(ast.Div, types.MakeDatums(1, 0), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errDivisionByZero), IsTrue)
+	sc.IgnoreTruncate = true
+	sc.SetWarnings(nil)
+	d, err := evalFuncClass(ast.Div, types.MakeDatums(1, 0), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d.IsNull(), IsTrue)
+	c.Assert(sc.GetWarnings(), HasLen, 1)
+}
+
+func (s *testEvaluatorSuite) TestUnaryMinus(c *C) {
+	defer testleak.AfterTest(c)()
+	vars := s.ctx.GetSessionVars()
+	defer func() { vars.NoUnsignedSubtraction = false }()
+	// Like MySQL, the negation of an unsigned integer is signed whether NO_UNSIGNED_SUBTRACTION is set or not,
+	// so the same results are checked under both sql_modes.
+	for _, mode := range []bool{false, true} {
+		vars.NoUnsignedSubtraction = mode
+		tbl := []struct {
+			arg interface{}
+			ret interface{}
+		}{
+			{int64(math.MaxInt64), int64(-math.MaxInt64)},
+			{int64(math.MinInt64 + 1), int64(math.MaxInt64)},
+			{uint64(1), int64(-1)},
+			{uint64(math.MaxInt64), int64(-math.MaxInt64)},
+			{uint64(math.MaxInt64 + 1), int64(math.MinInt64)},
+			// The negation of a large unsigned constant is a decimal.
+			{uint64(math.MaxUint64), types.NewDecFromStringForTest("-18446744073709551615")},
+			{types.NewDecFromStringForTest("1.50"), types.NewDecFromStringForTest("-1.50")},
+			{types.NewDecFromStringForTest("-12345678901234567890.123456789"), types.NewDecFromStringForTest("12345678901234567890.123456789")},
+		}
+		for _, t := range tbl {
+			d, err := evalFuncClass(ast.UnaryMinus, types.MakeDatums(t.arg), s.ctx)
+			c.Assert(err, IsNil)
+			if dec, ok := t.ret.(*types.MyDecimal); ok {
+				c.Assert(d.Kind(), Equals, types.KindMysqlDecimal, Commentf("-%v", t.arg))
+				c.Assert(d.GetMysqlDecimal().String(), Equals, dec.String(), Commentf("-%v", t.arg))
+				continue
+			}
+			c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("-%v", t.arg))
+		}
+
+		// The negation of the min int64 is out of range.
+		_, err := evalFuncClass(ast.UnaryMinus, types.MakeDatums(int64(math.MinInt64)), s.ctx)
+		c.Assert(terror.ErrorEqual(err, errDataOutOfRange), IsTrue)
+		c.Assert(err.Error(), Equals, "[expression:1690]BIGINT value is out of range in '-(-9223372036854775808)'")
+		// So is the negation of a large unsigned integer which isn't a constant.
+		minus, err := funcs[ast.UnaryMinus].getFunction([]Expression{&Column{Index: 0}}, s.ctx)
+		c.Assert(err, IsNil)
+		_, err = minus.eval(types.MakeDatums(uint64(math.MaxUint64)))
+		c.Assert(terror.ErrorEqual(err, errDataOutOfRange), IsTrue)
+		d, err := minus.eval(types.MakeDatums(uint64(2)))
+		c.Assert(err, IsNil)
+		c.Assert(d.GetInt64(), Equals, int64(-2))
+	}
+}
+
+func (s *testEvaluatorSuite) TestMinusNoUnsignedSubtraction(c *C) {
+	defer testleak.AfterTest(c)()
+	vars := s.ctx.GetSessionVars()
+	defer func() { vars.NoUnsignedSubtraction = false }()
+
+	// NO_UNSIGNED_SUBTRACTION makes the difference with an unsigned operand signed.
+	vars.NoUnsignedSubtraction = false
+	_, err := evalFuncClass(ast.Minus, types.MakeDatums(uint64(1), 2), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errDataOutOfRange), IsTrue)
+	vars.NoUnsignedSubtraction = true
+	d, err := evalFuncClass(ast.Minus, types.MakeDatums(uint64(1), 2), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d, testutil.DatumEquals, types.NewIntDatum(-1))
+	d, err = evalFuncClass(ast.Minus, types.MakeDatums(uint64(math.MaxUint64), uint64(math.MaxUint64-1)), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d, testutil.DatumEquals, types.NewIntDatum(1))
+	_, err = evalFuncClass(ast.Minus, types.MakeDatums(uint64(math.MaxUint64), 1), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errDataOutOfRange), IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestIntDiv(c *C) {
+	defer testleak.AfterTest(c)()
+	sc := s.ctx.GetSessionVars().StmtCtx
+	tbl := []struct {
+		lhs interface{}
+		rhs interface{}
+		ret interface{}
+	}{
+		{1, 2, int64(0)},
+		{1, uint64(2), uint64(0)},
+		{1, 0, nil},
+		{1, uint64(0), nil},
+		{uint64(1), 2, uint64(0)},
+		{uint64(1), uint64(2), uint64(0)},
+		{uint64(1), 0, nil},
+		{uint64(1), uint64(0), nil},
+		{1.0, 2.0, int64(0)},
+		{1.0, 0, nil},
+		{nil, 1, nil},
+		{1, nil, nil},
+		// The quotient is truncated toward zero.
+		{7, 2, int64(3)},
+		{-7, 2, int64(-3)},
+		{7, -2, int64(-3)},
+		{-7, -2, int64(3)},
+		{-1, uint64(2), uint64(0)},
+		{7.9, 2, int64(3)},
+		{-7.9, 2, int64(-3)},
+		{types.NewDecFromStringForTest("-7.5"), types.NewDecFromStringForTest("2.5"), int64(-3)},
+		{"7.5", "2.5", int64(3)},
+		{"-7.9", 2, int64(-3)},
+		// Large operands don't lose precision.
+		{int64(math.MaxInt64), 1, int64(math.MaxInt64)},
+		{int64(math.MinInt64), 1, int64(math.MinInt64)},
+		{uint64(math.MaxUint64), 1, uint64(math.MaxUint64)},
+		{uint64(math.MaxUint64), uint64(math.MaxUint64), uint64(1)},
+		{"9223372036854775807", 1, int64(math.MaxInt64)},
+		{"9223372036854775806.9", 1, int64(math.MaxInt64 - 1)},
+		{types.NewDecFromStringForTest("18446744073709551615"), uint64(1), uint64(math.MaxUint64)},
+		{types.NewDecFromStringForTest("123456789012345678901234567890"), types.NewDecFromStringForTest("1000000000000"), int64(123456789012345678)},
+		{types.NewDecFromStringForTest("0.000000000000000001"), types.NewDecFromStringForTest("0.000000000000000001"), int64(1)},
+	}
+	for _, t := range tbl {
+		d, err := evalFuncClass(ast.IntDiv, types.MakeDatums(t.lhs, t.rhs), s.ctx)
+		c.Assert(err, IsNil, Commentf("%v DIV %v", t.lhs, t.rhs))
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v DIV %v", t.lhs, t.rhs))
+	}
+
+	// The quotient out of the range of BIGINT is an error.
+	outOfRangeTbl := []struct {
+		lhs interface{}
+		rhs interface{}
+	}{
+		{int64(math.MinInt64), -1},
+		{uint64(1), -1},
+		{-2, uint64(1)},
+		{types.NewDecFromStringForTest("-2.5"), uint64(2)},
+		{types.NewDecFromStringForTest("9223372036854775808"), 1},
+		{types.NewDecFromStringForTest("18446744073709551616"), uint64(1)},
+	}
+	for _, t := range outOfRangeTbl {
+		_, err := evalFuncClass(ast.IntDiv, types.MakeDatums(t.lhs, t.rhs), s.ctx)
+		c.Assert(terror.ErrorEqual(err, errDataOutOfRange), IsTrue, Commentf("%v DIV %v", t.lhs, t.rhs))
+	}
+
+	// The division by zero is reported only in the ERROR_FOR_DIVISION_BY_ZERO sql_mode.
+	vars := s.ctx.GetSessionVars()
+	defer func() {
+		vars.ErrorForDivisionByZero = false
+		sc.IgnoreTruncate, sc.TruncateAsWarning = false, false
+		sc.SetWarnings(nil)
+	}()
+	for _, mode := range []bool{false, true} {
+		vars.ErrorForDivisionByZero = mode
+		for _, rhs := range []interface{}{0, uint64(0), 0.0, "0", types.NewDecFromInt(0)} {
+			// A SELECT always treats it as a warning.
+			sc.IgnoreTruncate, sc.TruncateAsWarning = true, false
+			sc.SetWarnings(nil)
+			d, err := evalFuncClass(ast.IntDiv, types.MakeDatums(1, rhs), s.ctx)
+			c.Assert(err, IsNil)
+			c.Assert(d.IsNull(), IsTrue)
+			c.Assert(len(sc.GetWarnings()), Equals, int(boolToInt64(mode)), Commentf("1 DIV %v", rhs))
+			// An INSERT, UPDATE or DELETE in strict mode fails.
+			sc.IgnoreTruncate, sc.TruncateAsWarning = false, false
+			sc.SetWarnings(nil)
+			d, err = evalFuncClass(ast.IntDiv, types.MakeDatums(1, rhs), s.ctx)
+			if mode {
+				c.Assert(terror.ErrorEqual(err, errDivisionByZero), IsTrue, Commentf("1 DIV %v", rhs))
+			} else {
+				c.Assert(err, IsNil)
+				c.Assert(d.IsNull(), IsTrue)
+			}
+			// An INSERT, UPDATE or DELETE in non-strict mode reports a warning.
+			sc.IgnoreTruncate, sc.TruncateAsWarning = false, true
+			sc.SetWarnings(nil)
+			d, err = evalFuncClass(ast.IntDiv, types.MakeDatums(1, rhs), s.ctx)
+			c.Assert(err, IsNil)
+			c.Assert(d.IsNull(), IsTrue)
+			c.Assert(len(sc.GetWarnings()), Equals, int(boolToInt64(mode)), Commentf("1 DIV %v", rhs))
+		}
+	}
+
+	// A non-numeric string is truncated with a warning.
+	sc.IgnoreTruncate, sc.TruncateAsWarning = true, false
+	sc.SetWarnings(nil)
+	d, err := evalFuncClass(ast.IntDiv, types.MakeDatums("7abc", 2), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetInt64(), Equals, int64(3))
+	c.Assert(sc.GetWarnings(), HasLen, 1)
 }
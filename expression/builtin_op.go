@@ -16,149 +16,170 @@ package expression
 import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/types"
 )
 
-func builtinAndAnd(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-	leftDatum := args[0]
-	rightDatum := args[1]
-	sc := ctx.GetSessionVars().StmtCtx
-	if !leftDatum.IsNull() {
-		var x int64
-		x, err = leftDatum.ToBool(sc)
-		if err != nil {
-			return d, errors.Trace(err)
-		} else if x == 0 {
-			// false && any other types is false
-			d.SetInt64(x)
-			return
-		}
-	}
-	if !rightDatum.IsNull() {
-		var y int64
-		y, err = rightDatum.ToBool(sc)
-		if err != nil {
-			return d, errors.Trace(err)
-		} else if y == 0 {
-			d.SetInt64(y)
-			return
-		}
-	}
-	if leftDatum.IsNull() || rightDatum.IsNull() {
-		return
+type andFuncClass struct {
+	baseFuncClass
+}
+
+func (c *andFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
 	}
-	d.SetInt64(int64(1))
-	return
+	sig := &builtinAndAnd{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
 }
 
-func builtinOrOr(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-	sc := ctx.GetSessionVars().StmtCtx
-	leftDatum := args[0]
-	rightDatum := args[1]
-	if !leftDatum.IsNull() {
-		var x int64
-		x, err = leftDatum.ToBool(sc)
-		if err != nil {
-			return d, errors.Trace(err)
-		} else if x == 1 {
-			// false && any other types is false
-			d.SetInt64(x)
-			return
-		}
+type builtinAndAnd struct {
+	baseBuiltinFunc
+}
+
+// eval returns 0 if either operand is false even if the other one is NULL, so the second operand isn't evaluated
+// if the first one is false. Otherwise it returns NULL if either operand is NULL, or 1.
+// See https://dev.mysql.com/doc/refman/5.7/en/logical-operators.html#operator_and
+func (b *builtinAndAnd) eval(row []types.Datum) (d types.Datum, err error) {
+	x, xIsNull, err := b.evalBool(row, 0)
+	if err != nil {
+		return d, errors.Trace(err)
 	}
-	if !rightDatum.IsNull() {
-		var y int64
-		y, err = rightDatum.ToBool(sc)
-		if err != nil {
-			return d, errors.Trace(err)
-		} else if y == 1 {
-			d.SetInt64(y)
-			return
-		}
+	if !xIsNull && !x {
+		d.SetInt64(0)
+		return d, nil
 	}
-	if leftDatum.IsNull() || rightDatum.IsNull() {
-		return
+	y, yIsNull, err := b.evalBool(row, 1)
+	if err != nil {
+		return d, errors.Trace(err)
 	}
-	d.SetInt64(int64(0))
-	return
+	if !yIsNull && !y {
+		d.SetInt64(0)
+	} else if !xIsNull && !yIsNull {
+		d.SetInt64(1)
+	}
+	return d, nil
 }
 
-func builtinLogicXor(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-	leftDatum := args[0]
-	righDatum := args[1]
-	if leftDatum.IsNull() || righDatum.IsNull() {
-		return
+type orFuncClass struct {
+	baseFuncClass
+}
+
+func (c *orFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
 	}
-	sc := ctx.GetSessionVars().StmtCtx
-	x, err := leftDatum.ToBool(sc)
+	sig := &builtinOrOr{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinOrOr struct {
+	baseBuiltinFunc
+}
+
+// eval returns 1 if either operand is true even if the other one is NULL, so the second operand isn't evaluated
+// if the first one is true. Otherwise it returns NULL if either operand is NULL, or 0.
+// See https://dev.mysql.com/doc/refman/5.7/en/logical-operators.html#operator_or
+func (b *builtinOrOr) eval(row []types.Datum) (d types.Datum, err error) {
+	x, xIsNull, err := b.evalBool(row, 0)
 	if err != nil {
 		return d, errors.Trace(err)
 	}
-
-	y, err := righDatum.ToBool(sc)
+	if !xIsNull && x {
+		d.SetInt64(1)
+		return d, nil
+	}
+	y, yIsNull, err := b.evalBool(row, 1)
 	if err != nil {
 		return d, errors.Trace(err)
 	}
-	if x == y {
-		d.SetInt64(zeroI64)
-	} else {
-		d.SetInt64(oneI64)
+	if !yIsNull && y {
+		d.SetInt64(1)
+	} else if !xIsNull && !yIsNull {
+		d.SetInt64(0)
 	}
-	return
+	return d, nil
 }
 
-func compareFuncFactory(op opcode.Op) BuiltinFunc {
-	return func(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-		sc := ctx.GetSessionVars().StmtCtx
-		var a, b = args[0], args[1]
-		if op != opcode.NullEQ {
-			a, b, err = types.CoerceDatum(sc, a, b)
-			if err != nil {
-				return d, errors.Trace(err)
-			}
-		}
-		if a.IsNull() || b.IsNull() {
-			// for <=>, if a and b are both nil, return true.
-			// if a or b is nil, return false.
-			if op == opcode.NullEQ {
-				if a.IsNull() && b.IsNull() {
-					d.SetInt64(oneI64)
-				} else {
-					d.SetInt64(zeroI64)
-				}
-			}
-			return
-		}
+type xorFuncClass struct {
+	baseFuncClass
+}
 
-		n, err := a.CompareDatum(sc, b)
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-		var result bool
-		switch op {
-		case opcode.LT:
-			result = n < 0
-		case opcode.LE:
-			result = n <= 0
-		case opcode.EQ, opcode.NullEQ:
-			result = n == 0
-		case opcode.GT:
-			result = n > 0
-		case opcode.GE:
-			result = n >= 0
-		case opcode.NE:
-			result = n != 0
-		default:
-			return d, errInvalidOperation.Gen("invalid op %v in comparison operation", op)
-		}
-		if result {
-			d.SetInt64(oneI64)
-		} else {
-			d.SetInt64(zeroI64)
-		}
-		return
+func (c *xorFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinLogicXor{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinLogicXor struct {
+	baseBuiltinFunc
+}
+
+// eval returns NULL if either operand is NULL, so the second operand isn't evaluated if the first one is NULL.
+// Otherwise it returns 1 if exactly one of the operands is true, or 0.
+// See https://dev.mysql.com/doc/refman/5.7/en/logical-operators.html#operator_xor
+func (b *builtinLogicXor) eval(row []types.Datum) (d types.Datum, err error) {
+	x, isNull, err := b.evalBool(row, 0)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	y, isNull, err := b.evalBool(row, 1)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
 	}
+	d.SetInt64(boolToInt64(x != y))
+	return d, nil
+}
+
+type notFuncClass struct {
+	baseFuncClass
+}
+
+func (c *notFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinUnaryNot{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinUnaryNot struct {
+	baseBuiltinFunc
+}
+
+// eval returns 1 if the operand is false, 0 if it's true and NULL if it's NULL.
+// See https://dev.mysql.com/doc/refman/5.7/en/logical-operators.html#operator_not
+func (b *builtinUnaryNot) eval(row []types.Datum) (d types.Datum, err error) {
+	x, isNull, err := b.evalBool(row, 0)
+	if err != nil || isNull {
+		return d, errors.Trace(err)
+	}
+	d.SetInt64(boolToInt64(!x))
+	return d, nil
+}
+
+// evalBool evaluates the i-th argument as an operand of the logical operators, which is true if it's non-zero.
+// Strings are converted to numbers, so '0.1' is true. isNull is true if the argument is NULL.
+func (b *baseBuiltinFunc) evalBool(row []types.Datum, i int) (val bool, isNull bool, err error) {
+	arg, err := b.args[i].Eval(row, b.ctx)
+	if err != nil || arg.IsNull() {
+		return false, true, errors.Trace(err)
+	}
+	sc := b.ctx.GetSessionVars().StmtCtx
+	switch arg.Kind() {
+	case types.KindFloat32, types.KindFloat64:
+		return arg.GetFloat64() != 0, false, nil
+	case types.KindString, types.KindBytes:
+		f, err := argToFloat64(sc, arg)
+		return f != 0, false, errors.Trace(err)
+	case types.KindMysqlDecimal:
+		return arg.GetMysqlDecimal().Compare(new(types.MyDecimal)) != 0, false, nil
+	}
+	n, err := arg.ToBool(sc)
+	return n != 0, false, errors.Trace(err)
 }
 
 func bitOpFactory(op opcode.Op) BuiltinFunc {
@@ -231,16 +252,6 @@ func unaryOpFactory(op opcode.Op) BuiltinFunc {
 		}
 		sc := ctx.GetSessionVars().StmtCtx
 		switch op {
-		case opcode.Not:
-			var n int64
-			n, err = aDatum.ToBool(sc)
-			if err != nil {
-				err = errors.Trace(err)
-			} else if n == 0 {
-				d.SetInt64(1)
-			} else {
-				d.SetInt64(0)
-			}
 		case opcode.BitNeg:
 			var n int64
 			// for bit operation, we will use int64 first, then return uint64
@@ -268,46 +279,273 @@ func unaryOpFactory(op opcode.Op) BuiltinFunc {
 			default:
 				return d, errInvalidOperation.Gen("Unsupported type %v for op.Plus", aDatum.Kind())
 			}
-		case opcode.Minus:
-			switch aDatum.Kind() {
-			case types.KindInt64:
-				d.SetInt64(-aDatum.GetInt64())
-			case types.KindUint64:
-				d.SetInt64(-int64(aDatum.GetUint64()))
-			case types.KindFloat64:
-				d.SetFloat64(-aDatum.GetFloat64())
-			case types.KindFloat32:
-				d.SetFloat32(-aDatum.GetFloat32())
-			case types.KindMysqlDuration:
-				dec := new(types.MyDecimal)
-				err = types.DecimalSub(new(types.MyDecimal), aDatum.GetMysqlDuration().ToNumber(), dec)
-				d.SetMysqlDecimal(dec)
-			case types.KindMysqlTime:
-				dec := new(types.MyDecimal)
-				err = types.DecimalSub(new(types.MyDecimal), aDatum.GetMysqlTime().ToNumber(), dec)
-				d.SetMysqlDecimal(dec)
-			case types.KindString, types.KindBytes:
-				f, err1 := types.StrToFloat(sc, aDatum.GetString())
-				err = errors.Trace(err1)
-				d.SetFloat64(-f)
-			case types.KindMysqlDecimal:
-				dec := new(types.MyDecimal)
-				err = types.DecimalSub(new(types.MyDecimal), aDatum.GetMysqlDecimal(), dec)
-				d.SetMysqlDecimal(dec)
-			case types.KindMysqlHex:
-				d.SetFloat64(-aDatum.GetMysqlHex().ToNumber())
-			case types.KindMysqlBit:
-				d.SetFloat64(-aDatum.GetMysqlBit().ToNumber())
-			case types.KindMysqlEnum:
-				d.SetFloat64(-aDatum.GetMysqlEnum().ToNumber())
-			case types.KindMysqlSet:
-				d.SetFloat64(-aDatum.GetMysqlSet().ToNumber())
-			default:
-				return d, errInvalidOperation.Gen("Unsupported type %v for op.Minus", aDatum.Kind())
-			}
 		default:
 			return d, errInvalidOperation.Gen("Unsupported op %v for unary op", op)
 		}
 		return
 	}
 }
+
+type betweenFuncClass struct {
+	baseFuncClass
+	not bool
+}
+
+func (c *betweenFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinBetween{newBaseBuiltinFunc(args, ctx), c.not}
+	return sig.setSelf(sig), nil
+}
+
+type builtinBetween struct {
+	baseBuiltinFunc
+	not bool
+}
+
+// eval evaluates expr BETWEEN min AND max, which is min <= expr AND expr <= max, or NOT BETWEEN.
+// The result is NULL if expr is NULL, or if a bound is NULL and the other bound doesn't decide the result.
+// See https://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#operator_between
+func (b *builtinBetween) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	sc := b.ctx.GetSessionVars().StmtCtx
+	args, err = coerceCompareArgs(sc, args, b.args)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	// geMin and leMax are -1 if the bound is NULL.
+	geMin, leMax := int64(-1), int64(-1)
+	if !args[1].IsNull() {
+		cmp, err := args[0].CompareDatum(sc, args[1])
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		geMin = boolToInt64(cmp >= 0)
+	}
+	if !args[2].IsNull() {
+		cmp, err := args[0].CompareDatum(sc, args[2])
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		leMax = boolToInt64(cmp <= 0)
+	}
+	if geMin == 0 || leMax == 0 {
+		d.SetInt64(boolToInt64(b.not))
+	} else if geMin == 1 && leMax == 1 {
+		d.SetInt64(boolToInt64(!b.not))
+	}
+	return d, nil
+}
+
+type nullEqFuncClass struct {
+	baseFuncClass
+}
+
+// checkValid implements functionClass interface, the operands must be coerced to a common collation.
+func (c *nullEqFuncClass) checkValid(args []Expression) error {
+	if err := c.baseFuncClass.checkValid(args); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(checkCollations(c.funcName, args))
+}
+
+func (c *nullEqFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinNullEQ{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinNullEQ struct {
+	baseBuiltinFunc
+}
+
+// eval evaluates expr1 <=> expr2, which is like expr1 = expr2 but never returns NULL:
+// it returns 1 if both operands are NULL and 0 if only one of them is NULL.
+// See https://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#operator_equal-to
+func (b *builtinNullEQ) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() || args[1].IsNull() {
+		d.SetInt64(boolToInt64(args[0].IsNull() && args[1].IsNull()))
+		return d, nil
+	}
+	sc := b.ctx.GetSessionVars().StmtCtx
+	args, err = coerceCompareArgs(sc, args, b.args)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	cmp, err := args[0].CompareDatum(sc, args[1])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	d.SetInt64(boolToInt64(cmp == 0))
+	return d, nil
+}
+
+type compareFuncClass struct {
+	baseFuncClass
+	op opcode.Op
+}
+
+// checkValid implements functionClass interface, the operands must be coerced to a common collation.
+func (c *compareFuncClass) checkValid(args []Expression) error {
+	if err := c.baseFuncClass.checkValid(args); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(checkCollations(c.funcName, args))
+}
+
+func (c *compareFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinCompare{newBaseBuiltinFunc(args, ctx), c.op}
+	return sig.setSelf(sig), nil
+}
+
+type builtinCompare struct {
+	baseBuiltinFunc
+	op opcode.Op
+}
+
+// eval evaluates the comparison operators =, <>, <, <=, > and >=, which return NULL if either operand is NULL.
+// The operands are converted to a common type by coerceCompareArgs before they're compared.
+// See https://dev.mysql.com/doc/refman/5.7/en/type-conversion.html
+func (b *builtinCompare) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() || args[1].IsNull() {
+		return d, nil
+	}
+	sc := b.ctx.GetSessionVars().StmtCtx
+	args, err = coerceCompareArgs(sc, args, b.args)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	cmp, err := args[0].CompareDatum(sc, args[1])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	var result bool
+	switch b.op {
+	case opcode.EQ:
+		result = cmp == 0
+	case opcode.NE:
+		result = cmp != 0
+	case opcode.LT:
+		result = cmp < 0
+	case opcode.LE:
+		result = cmp <= 0
+	case opcode.GT:
+		result = cmp > 0
+	case opcode.GE:
+		result = cmp >= 0
+	default:
+		return d, errInvalidOperation.Gen("invalid op %v in comparison operation", b.op)
+	}
+	d.SetInt64(boolToInt64(result))
+	return d, nil
+}
+
+// coerceCompareArgs converts the non-NULL arguments to a common type to compare them with each other:
+// DATETIME if any of them is a DATE or DATETIME, TIME if any of them is a TIME, string if all of them are strings,
+// DOUBLE if strings are mixed with numbers or any of them is a float, DECIMAL if any of them is a decimal,
+// otherwise they are integers. The type of a NULL argument is taken from its expression.
+// An argument which isn't a valid temporal value is compared as the zero value with a warning like MySQL does.
+func coerceCompareArgs(sc *variable.StatementContext, args []types.Datum, exprs []Expression) ([]types.Datum, error) {
+	var hasInt, hasTime, hasDuration, hasString, hasFloat, hasDecimal, hasOther bool
+	for i, arg := range args {
+		switch arg.Kind() {
+		case types.KindNull:
+			if tp := exprs[i].GetType(); tp != nil {
+				switch tp.Tp {
+				case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp:
+					hasTime = true
+				case mysql.TypeDuration:
+					hasDuration = true
+				}
+			}
+		case types.KindInt64, types.KindUint64:
+			hasInt = true
+		case types.KindMysqlTime:
+			hasTime = true
+		case types.KindMysqlDuration:
+			hasDuration = true
+		case types.KindString, types.KindBytes:
+			hasString = true
+		case types.KindFloat32, types.KindFloat64:
+			hasFloat = true
+		case types.KindMysqlDecimal:
+			hasDecimal = true
+		default:
+			hasOther = true
+		}
+	}
+	var target *types.FieldType
+	switch {
+	case hasOther:
+		// ENUM, SET, BIT and hexadecimal values are compared by their own rules.
+		return args, nil
+	case hasTime:
+		target = types.NewFieldType(mysql.TypeDatetime)
+	case hasDuration:
+		target = types.NewFieldType(mysql.TypeDuration)
+	case hasString && !hasInt && !hasFloat && !hasDecimal:
+		return args, nil
+	case hasString || hasFloat:
+		target = types.NewFieldType(mysql.TypeDouble)
+	case hasDecimal:
+		target = types.NewFieldType(mysql.TypeNewDecimal)
+	default:
+		return args, nil
+	}
+	if target.Tp == mysql.TypeDatetime || target.Tp == mysql.TypeDuration {
+		target.Decimal = types.MaxFsp
+	}
+	coerced := make([]types.Datum, len(args))
+	for i, arg := range args {
+		if arg.IsNull() {
+			continue
+		}
+		var err error
+		switch target.Tp {
+		case mysql.TypeDouble:
+			var f float64
+			f, err = argToFloat64(sc, arg)
+			coerced[i].SetFloat64(f)
+		case mysql.TypeNewDecimal:
+			var dec *types.MyDecimal
+			dec, err = arg.ToDecimal(sc)
+			coerced[i].SetMysqlDecimal(dec)
+		case mysql.TypeDatetime:
+			coerced[i], err = arg.ConvertTo(sc, target)
+			if err != nil {
+				coerced[i].SetMysqlTime(types.ZeroDatetime)
+				err = handleInvalidTimeArg(sc, "datetime", arg, err)
+			}
+		case mysql.TypeDuration:
+			coerced[i], err = arg.ConvertTo(sc, target)
+			if err != nil {
+				coerced[i].SetMysqlDuration(types.ZeroDuration)
+				err = handleInvalidTimeArg(sc, "time", arg, err)
+			}
+		}
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return coerced, nil
+}
@@ -14,19 +14,52 @@
 package expression
 
 import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/twinj/uuid"
 )
 
+type sleepFuncClass struct {
+	baseFuncClass
+}
+
+func (c *sleepFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinSleep{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinSleep struct {
+	baseBuiltinFunc
+}
+
+// IsDeterministic implements builtinFunc interface, sleep() has side effect so it must not be constant folded.
+func (b *builtinSleep) IsDeterministic() bool {
+	return false
+}
+
 // See http://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_sleep
-func builtinSleep(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-	sessVars := ctx.GetSessionVars()
+func (b *builtinSleep) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	sessVars := b.ctx.GetSessionVars()
 	if args[0].IsNull() {
 		if sessVars.StrictSQLMode {
 			return d, errors.New("incorrect arguments to sleep")
@@ -49,32 +82,96 @@ func builtinSleep(args []types.Datum, ctx context.Context) (d types.Datum, err e
 		return
 	}
 
-	// TODO: consider it's interrupted using KILL QUERY from other session, or
-	// interrupted by time out.
-	duration := time.Duration(args[0].GetFloat64() * float64(time.Second.Nanoseconds()))
-	time.Sleep(duration)
+	secs, err := args[0].ToFloat64(sc)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if doSleep(secs, sessVars) {
+		// Like MySQL, returns 1 when the sleep is interrupted.
+		d.SetInt64(1)
+		return
+	}
 	d.SetInt64(0)
 	return
 }
 
-// See http://dev.mysql.com/doc/refman/5.7/en/any-in-some-subqueries.html
-func builtinIn(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-	if args[0].IsNull() {
-		return
+// sleepCheckInterval is the interval to check whether the query is killed during sleeping.
+const sleepCheckInterval = 10 * time.Millisecond
+
+// doSleep sleeps for secs seconds, it returns true if the sleep is interrupted by killing the query.
+func doSleep(secs float64, sessVars *variable.SessionVars) (isKilled bool) {
+	if secs <= 0 {
+		return false
+	}
+	timer := time.NewTimer(time.Duration(secs * float64(time.Second.Nanoseconds())))
+	defer timer.Stop()
+	ticker := time.NewTicker(sleepCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if atomic.LoadUint32(&sessVars.Killed) == 1 {
+				return true
+			}
+		case <-timer.C:
+			return false
+		}
+	}
+}
+
+type inFuncClass struct {
+	baseFuncClass
+}
+
+func (c *inFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinIn{baseBuiltinFunc: newBaseBuiltinFunc(args, ctx)}
+	sig.set = newInConstantSet(args[1:])
+	return sig.setSelf(sig), nil
+}
+
+type builtinIn struct {
+	baseBuiltinFunc
+	// set is the hash set of the list if all the items are integer or string constants, otherwise it's nil.
+	set *inConstantSet
+}
+
+// eval returns 1 if the first argument equals any item of the list, NULL if it's NULL or if there is no match
+// but the list has a NULL item, and 0 otherwise.
+// See http://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_in
+func (b *builtinIn) eval(row []types.Datum) (d types.Datum, err error) {
+	arg, err := b.args[0].Eval(row, b.ctx)
+	if err != nil || arg.IsNull() {
+		return d, errors.Trace(err)
+	}
+	if b.set != nil {
+		if found, ok := b.set.contains(arg); ok {
+			if found {
+				d.SetInt64(1)
+			} else if !b.set.hasNull {
+				d.SetInt64(0)
+			}
+			return d, nil
+		}
 	}
-	sc := ctx.GetSessionVars().StmtCtx
+	sc := b.ctx.GetSessionVars().StmtCtx
 	var hasNull bool
-	for _, v := range args[1:] {
+	for _, item := range b.args[1:] {
+		v, err := item.Eval(row, b.ctx)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
 		if v.IsNull() {
 			hasNull = true
 			continue
 		}
-
-		a, b, err := types.CoerceDatum(sc, args[0], v)
+		x, y, err := types.CoerceDatum(sc, arg, v)
 		if err != nil {
 			return d, errors.Trace(err)
 		}
-		ret, err := a.CompareDatum(sc, b)
+		ret, err := x.CompareDatum(sc, y)
 		if err != nil {
 			return d, errors.Trace(err)
 		}
@@ -83,14 +180,83 @@ func builtinIn(args []types.Datum, ctx context.Context) (d types.Datum, err erro
 			return d, nil
 		}
 	}
+	// If it's no matched but we get null in In, returns null.
+	// e.g 1 in (null, 2, 3) returns null.
+	if !hasNull {
+		d.SetInt64(0)
+	}
+	return d, nil
+}
 
-	if hasNull {
-		// If it's no matched but we get null in In, returns null.
-		// e.g 1 in (null, 2, 3) returns null.
-		return
+// inConstantSet is the hash set of the constant list of IN, which is either all integers or all strings except NULL.
+type inConstantSet struct {
+	ints    map[int64]struct{}
+	uints   map[uint64]struct{} // the unsigned integers out of the int64 range
+	strs    map[string]struct{}
+	hasNull bool
+}
+
+// newInConstantSet builds the hash set of the list, it returns nil if the list can't be looked up by hash.
+func newInConstantSet(list []Expression) *inConstantSet {
+	set := &inConstantSet{}
+	for _, item := range list {
+		con, ok := item.(*Constant)
+		if !ok {
+			return nil
+		}
+		v := con.Value
+		switch v.Kind() {
+		case types.KindNull:
+			set.hasNull = true
+		case types.KindInt64, types.KindUint64:
+			if set.strs != nil {
+				return nil
+			}
+			if set.ints == nil {
+				set.ints, set.uints = make(map[int64]struct{}), make(map[uint64]struct{})
+			}
+			if v.Kind() == types.KindUint64 && v.GetUint64() > math.MaxInt64 {
+				set.uints[v.GetUint64()] = struct{}{}
+			} else {
+				set.ints[v.GetInt64()] = struct{}{}
+			}
+		case types.KindString, types.KindBytes:
+			if set.ints != nil {
+				return nil
+			}
+			if set.strs == nil {
+				set.strs = make(map[string]struct{})
+			}
+			set.strs[v.GetString()] = struct{}{}
+		default:
+			return nil
+		}
 	}
-	d.SetInt64(0)
-	return
+	return set
+}
+
+// contains looks up the value in the set, ok is false if the value can't be compared with the set by hash,
+// then the list should be scanned.
+func (s *inConstantSet) contains(v types.Datum) (found, ok bool) {
+	switch v.Kind() {
+	case types.KindInt64, types.KindUint64:
+		if s.strs != nil {
+			return false, false
+		}
+		if v.Kind() == types.KindUint64 && v.GetUint64() > math.MaxInt64 {
+			_, found = s.uints[v.GetUint64()]
+		} else {
+			_, found = s.ints[v.GetInt64()]
+		}
+		return found, true
+	case types.KindString, types.KindBytes:
+		if s.ints != nil {
+			return false, false
+		}
+		_, found = s.strs[v.GetString()]
+		return found, true
+	}
+	return false, false
 }
 
 func builtinRow(row []types.Datum, _ context.Context) (d types.Datum, err error) {
@@ -104,7 +270,7 @@ func CastFuncFactory(tp *types.FieldType) (BuiltinFunc, error) {
 	switch tp.Tp {
 	// Parser has restricted this.
 	case mysql.TypeString, mysql.TypeDuration, mysql.TypeDatetime,
-		mysql.TypeDate, mysql.TypeLonglong, mysql.TypeNewDecimal:
+		mysql.TypeDate, mysql.TypeLonglong:
 		return func(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
 			d = args[0]
 			if d.IsNull() {
@@ -112,10 +278,63 @@ func CastFuncFactory(tp *types.FieldType) (BuiltinFunc, error) {
 			}
 			return d.ConvertTo(ctx.GetSessionVars().StmtCtx, tp)
 		}, nil
+	case mysql.TypeNewDecimal:
+		return func(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+			if args[0].IsNull() {
+				return
+			}
+			return castToDecimal(ctx.GetSessionVars().StmtCtx, args[0], tp)
+		}, nil
+	case mysql.TypeJSON:
+		return builtinCastToJSON, nil
 	}
 	return nil, errors.Errorf("unknown cast type - %v", tp)
 }
 
+// castToDecimal converts arg to the DECIMAL(M,D) of tp, the value is rounded to D decimals. A value whose integer part
+// has more than M-D digits is out of range, it's clamped to the maximum or minimum DECIMAL(M,D) with a warning.
+// Both the out of range value and the rounding are errors for an INSERT, UPDATE or DELETE in strict mode.
+func castToDecimal(sc *variable.StatementContext, arg types.Datum, tp *types.FieldType) (d types.Datum, err error) {
+	dec, err := argToDecimal(sc, arg)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	flen, frac := tp.Flen, tp.Decimal
+	if flen == types.UnspecifiedLength {
+		flen = mysql.GetDefaultFieldLength(mysql.TypeNewDecimal)
+	}
+	if frac == types.UnspecifiedLength {
+		// DECIMAL(M) is DECIMAL(M,0).
+		frac = 0
+	}
+	result := new(types.MyDecimal)
+	if err = dec.Round(result, frac); err != nil {
+		return d, errors.Trace(err)
+	}
+	// The integer part is checked after rounding, which may carry a digit into it.
+	if prec, decFrac := result.PrecisionAndFrac(); prec-decFrac > flen-frac {
+		str, err1 := arg.ToString()
+		if err1 != nil {
+			str = "?"
+		}
+		// The row is not tracked, it's always 1 like in a SELECT of MySQL.
+		err = handleTruncateError(sc, errWarnDataOutOfRange.GenByArgs(fmt.Sprintf("cast(%s as decimal(%d,%d))", str, flen, frac), 1))
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		result = types.NewMaxOrMinDec(result.IsNegative(), flen, frac)
+	} else if _, decFrac := dec.PrecisionAndFrac(); decFrac > frac && !sc.IgnoreTruncate {
+		// The dropped fraction digits are only reported by an INSERT, UPDATE or DELETE.
+		if err = handleTruncateError(sc, types.ErrTruncated); err != nil {
+			return d, errors.Trace(err)
+		}
+	}
+	d.SetMysqlDecimal(result)
+	d.SetLength(flen)
+	d.SetFrac(frac)
+	return d, nil
+}
+
 func builtinSetVar(args []types.Datum, ctx context.Context) (types.Datum, error) {
 	sessionVars := ctx.GetSessionVars()
 	varName, _ := args[0].ToString()
@@ -138,20 +357,6 @@ func builtinGetVar(args []types.Datum, ctx context.Context) (types.Datum, error)
 	return types.Datum{}, nil
 }
 
-// The lock function will do nothing.
-// Warning: get_lock() function is parsed but ignored.
-func builtinLock(args []types.Datum, _ context.Context) (d types.Datum, err error) {
-	d.SetInt64(1)
-	return d, nil
-}
-
-// The release lock function will do nothing.
-// Warning: release_lock() function is parsed but ignored.
-func builtinReleaseLock(args []types.Datum, _ context.Context) (d types.Datum, err error) {
-	d.SetInt64(1)
-	return d, nil
-}
-
 // BuildinValuesFactory generates values builtin function.
 func BuildinValuesFactory(v *ast.ValuesExpr) BuiltinFunc {
 	return func(_ []types.Datum, ctx context.Context) (d types.Datum, err error) {
@@ -169,3 +374,477 @@ func BuildinValuesFactory(v *ast.ValuesExpr) BuiltinFunc {
 		return
 	}
 }
+
+type defaultFuncClass struct {
+	baseFuncClass
+
+	col *model.ColumnInfo
+}
+
+func (c *defaultFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinDefault{newBaseBuiltinFunc(args, ctx), c.col}
+	return sig.setSelf(sig), nil
+}
+
+type builtinDefault struct {
+	baseBuiltinFunc
+
+	col *model.ColumnInfo
+}
+
+// eval returns the default value of the column. A column without an explicit default value
+// gets NULL if it is nullable, or the implicit default value of its type in non-strict mode.
+// See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_default
+func (b *builtinDefault) eval(_ []types.Datum) (d types.Datum, err error) {
+	d, _, err = GetColDefaultValue(b.ctx, b.col)
+	return d, errors.Trace(err)
+}
+
+type benchmarkFuncClass struct {
+	baseFuncClass
+}
+
+func (c *benchmarkFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinBenchmark{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinBenchmark struct {
+	baseBuiltinFunc
+}
+
+// IsDeterministic implements builtinFunc interface, benchmark() must not be constant folded
+// because the expression has to be evaluated repeatedly.
+func (b *builtinBenchmark) IsDeterministic() bool {
+	return false
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/information-functions.html#function_benchmark
+func (b *builtinBenchmark) eval(row []types.Datum) (d types.Datum, err error) {
+	arg, err := b.args[0].Eval(row, b.ctx)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if arg.IsNull() {
+		return d, nil
+	}
+	count, err := arg.ToInt64(b.ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if count < 0 {
+		return d, nil
+	}
+	// The expression is evaluated by itself instead of evalArgs, so that it is evaluated count times.
+	for i := int64(0); i < count; i++ {
+		if _, err = b.args[1].Eval(row, b.ctx); err != nil {
+			return d, errors.Trace(err)
+		}
+	}
+	d.SetInt64(0)
+	return d, nil
+}
+
+type anyValueFuncClass struct {
+	baseFuncClass
+}
+
+func (c *anyValueFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinAnyValue{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinAnyValue struct {
+	baseBuiltinFunc
+}
+
+// eval returns the argument unchanged. ANY_VALUE is used to suppress the ONLY_FULL_GROUP_BY check of MySQL for a
+// nonaggregated column, TiDB doesn't reject such columns, so it is only required for the compatibility.
+// See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_any-value
+func (b *builtinAnyValue) eval(row []types.Datum) (d types.Datum, err error) {
+	d, err = b.args[0].Eval(row, b.ctx)
+	return d, errors.Trace(err)
+}
+
+type nameConstFuncClass struct {
+	baseFuncClass
+}
+
+// checkValid implements functionClass interface, both of the arguments of NAME_CONST must be constants,
+// and a negative number is allowed as the value.
+func (c *nameConstFuncClass) checkValid(args []Expression) error {
+	if err := c.baseFuncClass.checkValid(args); err != nil {
+		return errors.Trace(err)
+	}
+	value := args[1]
+	if f, ok := value.(*ScalarFunction); ok && f.FuncName.L == ast.UnaryMinus {
+		value = f.GetArgs()[0]
+	}
+	_, isNameConst := args[0].(*Constant)
+	_, isValueConst := value.(*Constant)
+	if !isNameConst || !isValueConst {
+		return errIncorrectArgs.GenByArgs("NAME_CONST")
+	}
+	return nil
+}
+
+func (c *nameConstFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinNameConst{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinNameConst struct {
+	baseBuiltinFunc
+}
+
+// eval returns the value, the name is used as the column name of the result, see buildProjection of the plan builder.
+// See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_name-const
+func (b *builtinNameConst) eval(row []types.Datum) (d types.Datum, err error) {
+	d, err = b.args[1].Eval(row, b.ctx)
+	return d, errors.Trace(err)
+}
+
+type groupingFuncClass struct {
+	baseFuncClass
+}
+
+func (c *groupingFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinGrouping{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinGrouping struct {
+	baseBuiltinFunc
+}
+
+// IsDeterministic implements builtinFunc interface, the result of grouping() depends on the row being produced.
+func (b *builtinGrouping) IsDeterministic() bool {
+	return false
+}
+
+// eval evals a builtinGrouping.
+// See https://dev.mysql.com/doc/refman/8.0/en/miscellaneous-functions.html#function_grouping
+// The result is 1 if the argument is aggregated away in a super-aggregate row of WITH ROLLUP, 0 otherwise.
+// With several arguments, the result is a bitmask whose lowest bit is for the rightmost argument.
+func (b *builtinGrouping) eval(row []types.Datum) (d types.Datum, err error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	// The items from index aggregated on are all NULL in the row being produced.
+	aggregated := len(sc.RollupGroupBy) - sc.RollupLevel
+	var mask int64
+	for i, arg := range b.args {
+		idx := -1
+		hash := arg.HashCode()
+		for j, item := range sc.RollupGroupBy {
+			if bytes.Equal(item, hash) {
+				idx = j
+				break
+			}
+		}
+		if idx < 0 {
+			return d, errGroupingNotGroupBy.GenByArgs(i + 1)
+		}
+		mask <<= 1
+		if idx >= aggregated {
+			mask |= 1
+		}
+	}
+	d.SetInt64(mask)
+	return d, nil
+}
+
+type uuidFuncClass struct {
+	baseFuncClass
+}
+
+func (c *uuidFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinUUID{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinUUID struct {
+	baseBuiltinFunc
+}
+
+// IsDeterministic implements builtinFunc interface, every call of uuid() returns a new value.
+func (b *builtinUUID) IsDeterministic() bool {
+	return false
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_uuid
+func (b *builtinUUID) eval(_ []types.Datum) (d types.Datum, err error) {
+	d.SetString(uuid.NewV1().String())
+	return d, nil
+}
+
+type uuidToBinFuncClass struct {
+	baseFuncClass
+}
+
+func (c *uuidToBinFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinUUIDToBin{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinUUIDToBin struct {
+	baseBuiltinFunc
+}
+
+// See https://dev.mysql.com/doc/refman/8.0/en/miscellaneous-functions.html#function_uuid-to-bin
+func (b *builtinUUIDToBin) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	str, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	bin, ok := parseUUID(str)
+	if !ok {
+		return d, errWrongValueForType.GenByArgs("string", str, ast.UUIDToBin)
+	}
+	swap, err := getUUIDSwapFlag(args, b.ctx)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if swap {
+		// Move time-high and time-mid before time-low, so the values generated
+		// in the same period of time are stored close to each other.
+		bin = concatBytes(bin[6:8], bin[4:6], bin[0:4], bin[8:])
+	}
+	d.SetString(string(bin))
+	return d, nil
+}
+
+type binToUUIDFuncClass struct {
+	baseFuncClass
+}
+
+func (c *binToUUIDFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinBinToUUID{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinBinToUUID struct {
+	baseBuiltinFunc
+}
+
+// See https://dev.mysql.com/doc/refman/8.0/en/miscellaneous-functions.html#function_bin-to-uuid
+func (b *builtinBinToUUID) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	str, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	bin := []byte(str)
+	if len(bin) != 16 {
+		return d, errWrongValueForType.GenByArgs("string", hex.EncodeToString(bin), ast.BinToUUID)
+	}
+	swap, err := getUUIDSwapFlag(args, b.ctx)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if swap {
+		bin = concatBytes(bin[4:8], bin[2:4], bin[0:2], bin[8:])
+	}
+	d.SetString(formatUUID(bin))
+	return d, nil
+}
+
+// formatUUID formats the 16 bytes uuid as 32 lower case hex digits separated by hyphens.
+func formatUUID(bin []byte) string {
+	str := hex.EncodeToString(bin)
+	return str[0:8] + "-" + str[8:12] + "-" + str[12:16] + "-" + str[16:20] + "-" + str[20:]
+}
+
+// getUUIDSwapFlag gets the optional swap_flag argument of uuid_to_bin() and bin_to_uuid().
+func getUUIDSwapFlag(args []types.Datum, ctx context.Context) (bool, error) {
+	if len(args) < 2 || args[1].IsNull() {
+		return false, nil
+	}
+	flag, err := args[1].ToInt64(ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return flag != 0, nil
+}
+
+func concatBytes(parts ...[]byte) []byte {
+	var l int
+	for _, p := range parts {
+		l += len(p)
+	}
+	b := make([]byte, 0, l)
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+// parseUUID parses a uuid string into 16 bytes, the string can be 32 hex digits optionally
+// separated by hyphens at the standard positions and enclosed by braces.
+func parseUUID(str string) ([]byte, bool) {
+	if len(str) == 38 {
+		if str[0] != '{' || str[37] != '}' {
+			return nil, false
+		}
+		str = str[1:37]
+	}
+	switch len(str) {
+	case 36:
+		if str[8] != '-' || str[13] != '-' || str[18] != '-' || str[23] != '-' {
+			return nil, false
+		}
+		str = str[0:8] + str[9:13] + str[14:18] + str[19:23] + str[24:]
+	case 32:
+	default:
+		return nil, false
+	}
+	bin, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, false
+	}
+	return bin, true
+}
+
+type formatBytesFuncClass struct {
+	baseFuncClass
+}
+
+func (c *formatBytesFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinFormatBytes{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinFormatBytes struct {
+	baseBuiltinFunc
+}
+
+// formatUnit is a unit of FORMAT_BYTES or FORMAT_PICO_TIME, size is the number of bytes or picoseconds in the unit.
+type formatUnit struct {
+	size float64
+	name string
+}
+
+// byteUnits are the units of FORMAT_BYTES from the largest to the smallest.
+var byteUnits = []formatUnit{
+	{1 << 60, "EiB"},
+	{1 << 50, "PiB"},
+	{1 << 40, "TiB"},
+	{1 << 30, "GiB"},
+	{1 << 20, "MiB"},
+	{1 << 10, "KiB"},
+}
+
+// eval converts a byte count to a human readable string with a size unit, like '1.50 KiB'.
+// See https://dev.mysql.com/doc/refman/8.0/en/performance-schema-functions.html#function_format-bytes
+func (b *builtinFormatBytes) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil || args[0].IsNull() {
+		return d, errors.Trace(err)
+	}
+	bytes, err := args[0].ToFloat64(b.ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	for _, unit := range byteUnits {
+		if math.Abs(bytes) >= unit.size {
+			d.SetString(formatUnitValue(bytes/unit.size, unit.name))
+			return d, nil
+		}
+	}
+	d.SetString(fmt.Sprintf("%4d bytes", int64(bytes)))
+	return d, nil
+}
+
+type formatPicoTimeFuncClass struct {
+	baseFuncClass
+}
+
+func (c *formatPicoTimeFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinFormatPicoTime{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinFormatPicoTime struct {
+	baseBuiltinFunc
+}
+
+// picoTimeUnits are the units of FORMAT_PICO_TIME from the largest to the smallest.
+var picoTimeUnits = []formatUnit{
+	{86400e12, "d"},
+	{3600e12, "h"},
+	{60e12, "min"},
+	{1e12, "s"},
+	{1e9, "ms"},
+	{1e6, "us"},
+	{1e3, "ns"},
+}
+
+// eval converts a time in picoseconds to a human readable string with a time unit, like '1.23 ms'.
+// See https://dev.mysql.com/doc/refman/8.0/en/performance-schema-functions.html#function_format-pico-time
+func (b *builtinFormatPicoTime) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil || args[0].IsNull() {
+		return d, errors.Trace(err)
+	}
+	pico, err := args[0].ToFloat64(b.ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	for _, unit := range picoTimeUnits {
+		if math.Abs(pico) >= unit.size {
+			d.SetString(formatUnitValue(pico/unit.size, unit.name))
+			return d, nil
+		}
+	}
+	d.SetString(fmt.Sprintf("%3d ps", int64(pico)))
+	return d, nil
+}
+
+// formatUnitValue formats the value in the unit with 2 decimals, a huge value is formatted in scientific notation.
+func formatUnitValue(value float64, unit string) string {
+	if math.Abs(value) >= 100000 {
+		return fmt.Sprintf("%4.2e %s", value, unit)
+	}
+	return fmt.Sprintf("%4.2f %s", value, unit)
+}
@@ -0,0 +1,418 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/testutil"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func (s *testEvaluatorSuite) TestIn(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		args   []interface{}
+		ret    interface{}
+		hashed bool
+	}{
+		{[]interface{}{1, 1, 2}, int64(1), true},
+		{[]interface{}{3, 1, 2}, int64(0), true},
+		{[]interface{}{3, 1, nil, 2}, nil, true},
+		{[]interface{}{1, nil, 1}, int64(1), true},
+		{[]interface{}{nil, 1, 2}, nil, true},
+		{[]interface{}{1, nil}, nil, true},
+		{[]interface{}{nil, nil}, nil, true},
+		{[]interface{}{uint64(18446744073709551615), -1, uint64(18446744073709551615)}, int64(1), true},
+		{[]interface{}{-1, uint64(18446744073709551615)}, int64(0), true},
+		{[]interface{}{uint64(1), int64(1)}, int64(1), true},
+		{[]interface{}{"a", "b", "a"}, int64(1), true},
+		{[]interface{}{"A", "b", "a"}, int64(0), true},
+		{[]interface{}{"c", "b", nil}, nil, true},
+		// The list isn't looked up by hash if the argument is of another type.
+		{[]interface{}{"1", 1, 2}, int64(1), true},
+		{[]interface{}{1, "1.0", "2"}, int64(1), true},
+		{[]interface{}{1.0, 1, 2}, int64(1), true},
+		// The list isn't hashed if the items are of different types.
+		{[]interface{}{2, 1, "2"}, int64(1), false},
+		{[]interface{}{2.5, 1, 2.5}, int64(1), false},
+	}
+	for _, t := range tbl {
+		args := types.MakeDatums(t.args...)
+		f, err := funcs[ast.In].getFunction(datumsToConstants(args), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(f.(*builtinIn).set != nil, Equals, t.hashed, Commentf("%v", t.args))
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v", t.args))
+
+		// The result is the same if the list is scanned.
+		exprs := datumsToConstants(args)
+		for i := 1; i < len(exprs); i++ {
+			exprs[i] = &countingExpr{Constant: exprs[i].(*Constant)}
+		}
+		f, err = funcs[ast.In].getFunction(exprs, s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(f.(*builtinIn).set, IsNil)
+		d, err = f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v", t.args))
+	}
+}
+
+func (s *testEvaluatorSuite) TestBenchmark(c *C) {
+	defer testleak.AfterTest(c)()
+	fc := funcs[ast.Benchmark]
+	tbl := []struct {
+		Count interface{}
+		Ret   interface{}
+		Times int
+	}{
+		{10, 0, 10},
+		{0, 0, 0},
+		{"3", 0, 3},
+		{-1, nil, 0},
+		{nil, nil, 0},
+	}
+	for _, t := range tbl {
+		expr := &countingExpr{Constant: &Constant{Value: types.NewIntDatum(1)}}
+		f, err := fc.getFunction([]Expression{&Constant{Value: types.NewDatum(t.Count)}, expr}, s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(f.IsDeterministic(), IsFalse)
+		v, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(v, testutil.DatumEquals, types.NewDatum(t.Ret))
+		c.Assert(expr.count, Equals, t.Times, Commentf("count: %v", t.Count))
+	}
+
+	// benchmark() must not be constant folded.
+	benchFunc, err := NewFunction(ast.Benchmark, types.NewFieldType(mysql.TypeLonglong),
+		&Constant{Value: types.NewIntDatum(1)}, &Constant{Value: types.NewIntDatum(1)})
+	c.Assert(err, IsNil)
+	_, ok := FoldConstant(s.ctx, benchFunc).(*ScalarFunction)
+	c.Assert(ok, IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestUUID(c *C) {
+	defer testleak.AfterTest(c)()
+	fc := funcs[ast.UUID]
+	f, err := fc.getFunction(nil, s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(f.IsDeterministic(), IsFalse)
+
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-1[0-9a-f]{3}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	v1, err := f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(pattern.MatchString(v1.GetString()), IsTrue, Commentf("uuid: %s", v1.GetString()))
+	v2, err := f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(v2.GetString(), Not(Equals), v1.GetString())
+
+	// uuid() must not be constant folded.
+	uuidFunc, err := NewFunction(ast.UUID, types.NewFieldType(mysql.TypeVarString))
+	c.Assert(err, IsNil)
+	_, ok := FoldConstant(s.ctx, uuidFunc).(*ScalarFunction)
+	c.Assert(ok, IsTrue)
+
+	_, err = NewFunction(ast.UUID, types.NewFieldType(mysql.TypeVarString), &Constant{Value: types.NewIntDatum(1)})
+	c.Assert(err, NotNil)
+}
+
+func (s *testEvaluatorSuite) TestAnyValue(c *C) {
+	defer testleak.AfterTest(c)()
+	fc := funcs[ast.AnyValue]
+	for _, arg := range types.MakeDatums(nil, int64(-1), uint64(1), 1.5, "abc", []byte{0}) {
+		f, err := fc.getFunction(datumsToConstants([]types.Datum{arg}), s.ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, DeepEquals, arg)
+	}
+
+	_, err := fc.getFunction(nil, s.ctx)
+	c.Assert(err, NotNil)
+}
+
+func (s *testEvaluatorSuite) TestNameConst(c *C) {
+	defer testleak.AfterTest(c)()
+	for _, value := range types.MakeDatums(nil, int64(14), 1.5, "abc") {
+		f, err := NewFunction(ast.NameConst, types.NewFieldType(mysql.TypeUnspecified), datumsToConstants([]types.Datum{types.NewStringDatum("myname"), value})...)
+		c.Assert(err, IsNil)
+		d, err := f.Eval(nil, s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(d, DeepEquals, value)
+	}
+
+	// A negative number is a constant.
+	minus, err := NewFunction(ast.UnaryMinus, types.NewFieldType(mysql.TypeLonglong), &Constant{Value: types.NewIntDatum(1)})
+	c.Assert(err, IsNil)
+	f, err := NewFunction(ast.NameConst, types.NewFieldType(mysql.TypeLonglong), &Constant{Value: types.NewStringDatum("myname")}, minus)
+	c.Assert(err, IsNil)
+	d, err := f.Eval(nil, s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetInt64(), Equals, int64(-1))
+
+	col := &Column{RetType: types.NewFieldType(mysql.TypeLonglong)}
+	_, err = NewFunction(ast.NameConst, types.NewFieldType(mysql.TypeLonglong), &Constant{Value: types.NewStringDatum("myname")}, col)
+	c.Assert(terror.ErrorEqual(err, errIncorrectArgs), IsTrue)
+	_, err = NewFunction(ast.NameConst, types.NewFieldType(mysql.TypeLonglong), col, &Constant{Value: types.NewIntDatum(1)})
+	c.Assert(terror.ErrorEqual(err, errIncorrectArgs), IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestGrouping(c *C) {
+	defer testleak.AfterTest(c)()
+	sc := s.ctx.GetSessionVars().StmtCtx
+	defer func() {
+		sc.RollupGroupBy, sc.RollupLevel = nil, 0
+	}()
+	a, b, other := newColumn("a"), newColumn("b"), newColumn("c")
+	// GROUP BY a, b WITH ROLLUP.
+	sc.RollupGroupBy = [][]byte{a.HashCode(), b.HashCode()}
+	tbl := []struct {
+		args  []Expression
+		level int
+		ret   int64
+	}{
+		{[]Expression{a}, 0, 0},
+		{[]Expression{b}, 0, 0},
+		{[]Expression{a}, 1, 0},
+		{[]Expression{b}, 1, 1},
+		{[]Expression{a}, 2, 1},
+		{[]Expression{b}, 2, 1},
+		{[]Expression{a, b}, 0, 0},
+		{[]Expression{a, b}, 1, 1},
+		{[]Expression{b, a}, 1, 2},
+		{[]Expression{a, b}, 2, 3},
+	}
+	for _, t := range tbl {
+		sc.RollupLevel = t.level
+		f, err := NewFunction(ast.Grouping, types.NewFieldType(mysql.TypeLonglong), t.args...)
+		c.Assert(err, IsNil)
+		d, err := f.Eval(nil, s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(d.GetInt64(), Equals, t.ret, Commentf("%v at level %d", t.args, t.level))
+	}
+
+	f, err := NewFunction(ast.Grouping, types.NewFieldType(mysql.TypeLonglong), a, other)
+	c.Assert(err, IsNil)
+	_, err = f.Eval(nil, s.ctx)
+	c.Assert(terror.ErrorEqual(err, errGroupingNotGroupBy), IsTrue)
+	c.Assert(err.Error(), Equals, "[expression:3580]Argument #2 of GROUPING function is not in GROUP BY")
+
+	// No argument is in GROUP BY without a rollup.
+	sc.RollupGroupBy, sc.RollupLevel = nil, 0
+	_, err = f.Eval(nil, s.ctx)
+	c.Assert(terror.ErrorEqual(err, errGroupingNotGroupBy), IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestUUIDToBin(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		Arg []interface{}
+		Hex string
+	}{
+		{[]interface{}{"6ccd780c-baba-1026-9564-5b8c656024db"}, "6CCD780CBABA102695645B8C656024DB"},
+		{[]interface{}{"6ccd780c-baba-1026-9564-5b8c656024db", 0}, "6CCD780CBABA102695645B8C656024DB"},
+		{[]interface{}{"6ccd780c-baba-1026-9564-5b8c656024db", 1}, "1026BABA6CCD780C95645B8C656024DB"},
+		{[]interface{}{"6CCD780CBABA102695645B8C656024DB", 1}, "1026BABA6CCD780C95645B8C656024DB"},
+		{[]interface{}{"{6ccd780c-baba-1026-9564-5b8c656024db}"}, "6CCD780CBABA102695645B8C656024DB"},
+	}
+	dtbl := tblToDtbl(tbl)
+	toBin := funcs[ast.UUIDToBin]
+	toUUID := funcs[ast.BinToUUID]
+	for _, t := range dtbl {
+		f, err := toBin.getFunction(datumsToConstants(t["Arg"]), s.ctx)
+		c.Assert(err, IsNil)
+		bin, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(strings.ToUpper(hex.EncodeToString([]byte(bin.GetString()))), Equals, t["Hex"][0].GetString())
+
+		// Round trip with the same swap flag.
+		args := []types.Datum{bin}
+		if len(t["Arg"]) > 1 {
+			args = append(args, t["Arg"][1])
+		}
+		f, err = toUUID.getFunction(datumsToConstants(args), s.ctx)
+		c.Assert(err, IsNil)
+		v, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(v.GetString(), Equals, "6ccd780c-baba-1026-9564-5b8c656024db")
+	}
+
+	// NULL returns NULL.
+	f, err := toBin.getFunction(datumsToConstants(types.MakeDatums(nil)), s.ctx)
+	c.Assert(err, IsNil)
+	v, err := f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(v.IsNull(), IsTrue)
+
+	// Malformed uuid strings return an error.
+	for _, str := range []string{"", "6ccd780c", "6ccd780c-baba-1026-9564-5b8c656024dx", "6ccd780cbaba-1026-9564-5b8c656024db-"} {
+		f, err = toBin.getFunction(datumsToConstants(types.MakeDatums(str)), s.ctx)
+		c.Assert(err, IsNil)
+		_, err = f.eval(nil)
+		c.Assert(terror.ErrorEqual(err, errWrongValueForType), IsTrue, Commentf("uuid: %s", str))
+	}
+	f, err = toUUID.getFunction(datumsToConstants(types.MakeDatums("abc")), s.ctx)
+	c.Assert(err, IsNil)
+	_, err = f.eval(nil)
+	c.Assert(terror.ErrorEqual(err, errWrongValueForType), IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestFormatBytes(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		arg interface{}
+		ret interface{}
+	}{
+		{nil, nil},
+		{0, "   0 bytes"},
+		{512, " 512 bytes"},
+		{1023, "1023 bytes"},
+		{-1023, "-1023 bytes"},
+		{1024, "1.00 KiB"},
+		{1536, "1.50 KiB"},
+		{1024*1024 - 1, "1024.00 KiB"},
+		{1024 * 1024, "1.00 MiB"},
+		{1024*1024*1024 - 1, "1024.00 MiB"},
+		{1024 * 1024 * 1024, "1.00 GiB"},
+		{-1024 * 1024 * 1024 * 3 / 2, "-1.50 GiB"},
+		{uint64(1) << 40, "1.00 TiB"},
+		{uint64(1) << 50, "1.00 PiB"},
+		{uint64(1) << 62, "4.00 EiB"},
+		{float64(1<<60) * 100000, "1.00e+05 EiB"},
+		{"2048", "2.00 KiB"},
+	}
+	for _, t := range tbl {
+		f, err := funcs[ast.FormatBytes].getFunction(datumsToConstants(types.MakeDatums(t.arg)), s.ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v", t.arg))
+	}
+}
+
+func (s *testEvaluatorSuite) TestFormatPicoTime(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		arg interface{}
+		ret interface{}
+	}{
+		{nil, nil},
+		{0, "  0 ps"},
+		{999, "999 ps"},
+		{1000, "1.00 ns"},
+		{999999, "1000.00 ns"},
+		{1000000, "1.00 us"},
+		{1230000000, "1.23 ms"},
+		{-1230000000, "-1.23 ms"},
+		{int64(1e12), "1.00 s"},
+		{int64(90e12), "1.50 min"},
+		{int64(3600e12), "1.00 h"},
+		{int64(86400e12), "1.00 d"},
+		{float64(86400e12) * 123456, "1.23e+05 d"},
+	}
+	for _, t := range tbl {
+		f, err := funcs[ast.FormatPicoTime].getFunction(datumsToConstants(types.MakeDatums(t.arg)), s.ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v", t.arg))
+	}
+}
+
+func (s *testEvaluatorSuite) TestCastToDecimal(c *C) {
+	defer testleak.AfterTest(c)()
+	newDecimalType := func(flen, decimal int) *types.FieldType {
+		tp := types.NewFieldType(mysql.TypeNewDecimal)
+		tp.Flen, tp.Decimal = flen, decimal
+		return tp
+	}
+	sc := s.ctx.GetSessionVars().StmtCtx
+	oldIgnoreTruncate := sc.IgnoreTruncate
+	defer func() {
+		sc.IgnoreTruncate = oldIgnoreTruncate
+	}()
+	// The value is rounded to the scale, which is an error in strict mode.
+	f, err := CastFuncFactory(newDecimalType(5, 2))
+	c.Assert(err, IsNil)
+	_, err = f(types.MakeDatums(1.235), s.ctx)
+	c.Assert(terror.ErrorEqual(err, types.ErrTruncated), IsTrue)
+	sc.IgnoreTruncate = true
+	tbl := []struct {
+		arg     interface{}
+		flen    int
+		decimal int
+		result  string
+	}{
+		{1.235, 5, 2, "1.24"},
+		{-1.235, 5, 2, "-1.24"},
+		{"1.5", 5, 2, "1.50"},
+		{types.NewDecFromStringForTest("123.456"), 5, 0, "123"},
+		{types.NewDecFromStringForTest("9.994"), 3, 2, "9.99"},
+		{int64(12345), 5, 0, "12345"},
+		{"2.5", 10, types.UnspecifiedLength, "3"},
+		{types.NewDecFromStringForTest("0.0001"), 5, 3, "0.000"},
+		{nil, 5, 2, ""},
+	}
+	for _, t := range tbl {
+		f, err := CastFuncFactory(newDecimalType(t.flen, t.decimal))
+		c.Assert(err, IsNil)
+		d, err := f(types.MakeDatums(t.arg), s.ctx)
+		c.Assert(err, IsNil)
+		if t.arg == nil {
+			c.Assert(d.IsNull(), IsTrue)
+			continue
+		}
+		str, err := d.ToString()
+		c.Assert(err, IsNil)
+		c.Assert(str, Equals, t.result, Commentf("%v", t.arg))
+	}
+
+	// The integer part out of range, including the digit carried by rounding, is an error in strict mode,
+	// or it's clamped with a warning.
+	overflowTbl := []struct {
+		arg    interface{}
+		result string
+	}{
+		{int64(1000), "999.99"},
+		{-1000.5, "-999.99"},
+		{types.NewDecFromStringForTest("999.995"), "999.99"},
+		{"12345", "999.99"},
+	}
+	sc.IgnoreTruncate = false
+	for _, t := range overflowTbl {
+		_, err = f(types.MakeDatums(t.arg), s.ctx)
+		c.Assert(terror.ErrorEqual(err, errWarnDataOutOfRange), IsTrue, Commentf("%v", t.arg))
+	}
+	sc.IgnoreTruncate = true
+	for _, t := range overflowTbl {
+		warnCnt := len(sc.GetWarnings())
+		d, err := f(types.MakeDatums(t.arg), s.ctx)
+		c.Assert(err, IsNil)
+		str, err := d.ToString()
+		c.Assert(err, IsNil)
+		c.Assert(str, Equals, t.result, Commentf("%v", t.arg))
+		c.Assert(sc.GetWarnings(), HasLen, warnCnt+1)
+		c.Assert(terror.ErrorEqual(sc.GetWarnings()[warnCnt], errWarnDataOutOfRange), IsTrue)
+	}
+	c.Assert(sc.GetWarnings()[len(sc.GetWarnings())-1].Error(), Equals, "[expression:1264]Out of range value for column 'cast(12345 as decimal(5,2))' at row 1")
+}
@@ -19,19 +19,23 @@ package expression
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/hack"
-	"github.com/pingcap/tidb/util/stringutil"
 	"github.com/pingcap/tidb/util/types"
 	"golang.org/x/text/transform"
 )
@@ -70,9 +74,40 @@ func builtinASCII(args []types.Datum, _ context.Context) (d types.Datum, err err
 	}
 }
 
+type concatFuncClass struct {
+	baseFuncClass
+}
+
+// checkValid implements functionClass interface, the collations of the arguments must be aggregated to the one of the result.
+func (c *concatFuncClass) checkValid(args []Expression) error {
+	if err := c.baseFuncClass.checkValid(args); err != nil {
+		return errors.Trace(err)
+	}
+	_, _, err := aggregateCollations(c.funcName, args)
+	return errors.Trace(err)
+}
+
+func (c *concatFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinConcat{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinConcat struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinConcat.
 // See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_concat
-func builtinConcat(args []types.Datum, _ context.Context) (d types.Datum, err error) {
+func (b *builtinConcat) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
 	var s []byte
+	maxAllowedPacket := getUintSysVar(b.ctx, variable.MaxAllowedPacket)
 	for _, a := range args {
 		if a.IsNull() {
 			return d, nil
@@ -82,56 +117,110 @@ func builtinConcat(args []types.Datum, _ context.Context) (d types.Datum, err er
 		if err != nil {
 			return d, errors.Trace(err)
 		}
+		if uint64(len(s)+len(ss)) > maxAllowedPacket {
+			b.ctx.GetSessionVars().StmtCtx.AppendWarning(errAllowedPacketOverflowed.GenByArgs(ast.Concat, maxAllowedPacket))
+			return d, nil
+		}
 		s = append(s, []byte(ss)...)
 	}
 	d.SetBytesAsString(s)
 	return d, nil
 }
 
+type concatWSFuncClass struct {
+	baseFuncClass
+}
+
+func (c *concatWSFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinConcatWS{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinConcatWS struct {
+	baseBuiltinFunc
+}
+
+// eval joins the non-NULL arguments after the first one with the first one as the separator.
+// The result is NULL if the separator is NULL, and the empty string if there is no non-NULL value to join.
+// A numeric separator or value is joined in its string form.
 // See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_concat-ws
-func builtinConcatWS(args []types.Datum, _ context.Context) (d types.Datum, err error) {
-	var sep string
-	s := make([]string, 0, len(args))
-	for i, a := range args {
+func (b *builtinConcatWS) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	sep, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	s := make([]string, 0, len(args)-1)
+	var totalLen int
+	maxAllowedPacket := getUintSysVar(b.ctx, variable.MaxAllowedPacket)
+	for _, a := range args[1:] {
 		if a.IsNull() {
-			if i == 0 {
-				return d, nil
-			}
 			continue
 		}
 		ss, err := a.ToString()
 		if err != nil {
 			return d, errors.Trace(err)
 		}
-
-		if i == 0 {
-			sep = ss
-			continue
+		if len(s) > 0 {
+			totalLen += len(sep)
+		}
+		totalLen += len(ss)
+		if uint64(totalLen) > maxAllowedPacket {
+			b.ctx.GetSessionVars().StmtCtx.AppendWarning(errAllowedPacketOverflowed.GenByArgs(ast.ConcatWS, maxAllowedPacket))
+			return d, nil
 		}
 		s = append(s, ss)
 	}
-
 	d.SetString(strings.Join(s, sep))
 	return d, nil
 }
 
+type leftFuncClass struct {
+	baseFuncClass
+}
+
+func (c *leftFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinLeft{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinLeft struct {
+	baseBuiltinFunc
+}
+
+// eval returns the leftmost len characters of the string.
 // See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_left
-func builtinLeft(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+func (b *builtinLeft) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
 	str, err := args[0].ToString()
 	if err != nil {
 		return d, errors.Trace(err)
 	}
-	length, err := args[1].ToInt64(ctx.GetSessionVars().StmtCtx)
+	length, err := args[1].ToInt64(b.ctx.GetSessionVars().StmtCtx)
 	if err != nil {
 		return d, errors.Trace(err)
 	}
-	l := int(length)
-	if l < 0 {
-		l = 0
-	} else if l > len(str) {
-		l = len(str)
+	cs := strCharset(b.args[0].GetType())
+	end := 0
+	for i := int64(0); i < length && end < len(str); i++ {
+		end += charSize(str[end:], cs)
 	}
-	d.SetString(str[:l])
+	d.SetString(str[:end])
 	return d, nil
 }
 
@@ -158,24 +247,74 @@ func builtinRepeat(args []types.Datum, _ context.Context) (d types.Datum, err er
 	return d, nil
 }
 
+type lowerFuncClass struct {
+	baseFuncClass
+}
+
+func (c *lowerFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinLower{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinLower struct {
+	baseBuiltinFunc
+}
+
+// eval returns the string with all characters changed to lowercase.
+// Binary strings are returned unchanged, as MySQL does not case-fold them.
 // See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_lower
-func builtinLower(args []types.Datum, _ context.Context) (d types.Datum, err error) {
+func (b *builtinLower) eval(row []types.Datum) (d types.Datum, err error) {
+	return b.convertCase(row, strings.ToLower)
+}
+
+// convertCase evaluates the only argument and maps it with the Unicode case mapping conv,
+// leaving NULL and binary strings as they are.
+func (b *baseBuiltinFunc) convertCase(row []types.Datum, conv func(string) string) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
 	x := args[0]
-	switch x.Kind() {
-	case types.KindNull:
-		return d, nil
-	default:
-		s, err := x.ToString()
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-		d.SetString(strings.ToLower(s))
+	if x.IsNull() {
 		return d, nil
 	}
+	s, err := x.ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if !isBinaryStr(b.args[0].GetType()) {
+		s = conv(s)
+	}
+	d.SetString(s)
+	return d, nil
+}
+
+type reverseFuncClass struct {
+	baseFuncClass
+}
+
+func (c *reverseFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinReverse{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinReverse struct {
+	baseBuiltinFunc
 }
 
+// eval returns the string with the order of the characters reversed.
 // See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_reverse
-func builtinReverse(args []types.Datum, _ context.Context) (d types.Datum, err error) {
+func (b *builtinReverse) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
 	x := args[0]
 	switch x.Kind() {
 	case types.KindNull:
@@ -185,7 +324,11 @@ func builtinReverse(args []types.Datum, _ context.Context) (d types.Datum, err e
 		if err != nil {
 			return d, errors.Trace(err)
 		}
-		d.SetString(stringutil.Reverse(s))
+		chars := splitChars(s, strCharset(b.args[0].GetType()))
+		for i, j := 0, len(chars)-1; i < j; i, j = i+1, j-1 {
+			chars[i], chars[j] = chars[j], chars[i]
+		}
+		d.SetString(strings.Join(chars, ""))
 		return d, nil
 	}
 }
@@ -220,24 +363,60 @@ func builtinSpace(args []types.Datum, ctx context.Context) (d types.Datum, err e
 	return d, nil
 }
 
+type upperFuncClass struct {
+	baseFuncClass
+}
+
+func (c *upperFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinUpper{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinUpper struct {
+	baseBuiltinFunc
+}
+
+// eval returns the string with all characters changed to uppercase.
+// Binary strings are returned unchanged, as MySQL does not case-fold them.
 // See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_upper
-func builtinUpper(args []types.Datum, _ context.Context) (d types.Datum, err error) {
-	x := args[0]
-	switch x.Kind() {
-	case types.KindNull:
-		return d, nil
-	default:
-		s, err := x.ToString()
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-		d.SetString(strings.ToUpper(s))
-		return d, nil
+func (b *builtinUpper) eval(row []types.Datum) (d types.Datum, err error) {
+	return b.convertCase(row, strings.ToUpper)
+}
+
+type strcmpFuncClass struct {
+	baseFuncClass
+}
+
+// checkValid implements functionClass interface, the two strings must be coerced to a common collation.
+func (c *strcmpFuncClass) checkValid(args []Expression) error {
+	if err := c.baseFuncClass.checkValid(args); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(checkCollations(c.funcName, args))
+}
+
+func (c *strcmpFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
 	}
+	sig := &builtinStrcmp{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinStrcmp struct {
+	baseBuiltinFunc
 }
 
-// See https://dev.mysql.com/doc/refman/5.7/en/string-comparison-functions.html
-func builtinStrcmp(args []types.Datum, _ context.Context) (d types.Datum, err error) {
+// eval evals a builtinStrcmp.
+// See https://dev.mysql.com/doc/refman/5.7/en/string-comparison-functions.html#function_strcmp
+func (b *builtinStrcmp) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
 	if args[0].IsNull() || args[1].IsNull() {
 		return d, nil
 	}
@@ -311,7 +490,28 @@ func builtinConvert(args []types.Datum, _ context.Context) (d types.Datum, err e
 	return d, nil
 }
 
-func builtinSubstring(args []types.Datum, _ context.Context) (d types.Datum, err error) {
+type substringFuncClass struct {
+	baseFuncClass
+}
+
+func (c *substringFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinSubstring{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinSubstring struct {
+	baseBuiltinFunc
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_substring
+func (b *builtinSubstring) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
 	// The meaning of the elements of args.
 	// arg[0] -> StrExpr
 	// arg[1] -> Pos
@@ -321,89 +521,130 @@ func builtinSubstring(args []types.Datum, _ context.Context) (d types.Datum, err
 		return d, errors.Errorf("Substring invalid args, need string but get %T", args[0].GetValue())
 	}
 
-	if args[1].Kind() != types.KindInt64 {
-		return d, errors.Errorf("Substring invalid pos args, need int but get %T", args[1].GetValue())
+	pos, err := substringIntArg(args[1])
+	if err != nil {
+		return d, errors.Trace(err)
 	}
-	pos := args[1].GetInt64()
 
 	length, hasLen := int64(-1), false
 	if len(args) == 3 {
-		if args[2].Kind() != types.KindInt64 {
-			return d, errors.Errorf("Substring invalid pos args, need int but get %T", args[2].GetValue())
+		if length, err = substringIntArg(args[2]); err != nil {
+			return d, errors.Trace(err)
 		}
-		length, hasLen = args[2].GetInt64(), true
+		hasLen = true
 	}
 	// The forms without a len argument return a substring from string str starting at position pos.
 	// The forms with a len argument return a substring len characters long from string str, starting at position pos.
 	// The forms that use FROM are standard SQL syntax. It is also possible to use a negative value for pos.
 	// In this case, the beginning of the substring is pos characters from the end of the string, rather than the beginning.
 	// A negative value may be used for pos in any of the forms of this function.
+	chars := splitChars(str, strCharset(b.args[0].GetType()))
+	strLen := int64(len(chars))
 	if pos < 0 {
-		pos = int64(len(str)) + pos
+		pos = strLen + pos
 	} else {
 		pos--
 	}
-	if pos > int64(len(str)) || pos < int64(0) {
-		pos = int64(len(str))
+	// The position before the beginning or after the end of the string results in an empty string.
+	if pos > strLen || pos < int64(0) {
+		pos = strLen
 	}
-	if hasLen {
-		if end := pos + length; end < pos {
-			d.SetString("")
-		} else if end > int64(len(str)) {
-			d.SetString(str[pos:])
-		} else {
-			d.SetString(str[pos:end])
+	end := strLen
+	// The length is compared with the remaining characters rather than added to pos, which may overflow.
+	if hasLen && length < strLen-pos {
+		end = pos
+		if length > 0 {
+			end = pos + length
 		}
-	} else {
-		d.SetString(str[pos:])
 	}
+	d.SetString(strings.Join(chars[pos:end], ""))
 	return d, nil
 }
 
+// substringIntArg gets the integer position or length argument of SUBSTRING,
+// an unsigned value larger than the max int64 is clamped as it's beyond any string anyway.
+func substringIntArg(arg types.Datum) (int64, error) {
+	switch arg.Kind() {
+	case types.KindInt64:
+		return arg.GetInt64(), nil
+	case types.KindUint64:
+		if u := arg.GetUint64(); u <= math.MaxInt64 {
+			return int64(u), nil
+		}
+		return math.MaxInt64, nil
+	}
+	return 0, errors.Errorf("Substring invalid pos args, need int but get %T", arg.GetValue())
+}
+
+type substringIndexFuncClass struct {
+	baseFuncClass
+}
+
+func (c *substringIndexFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinSubstringIndex{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinSubstringIndex struct {
+	baseBuiltinFunc
+}
+
+// eval returns the substring from str before count occurrences of the delimiter delim.
+// A count whose magnitude exceeds the number of delimiters returns the whole string.
 // See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_substring-index
-func builtinSubstringIndex(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+func (b *builtinSubstringIndex) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
 	// The meaning of the elements of args.
 	// args[0] -> StrExpr
 	// args[1] -> Delim
 	// args[2] -> Count
+	for _, arg := range args {
+		if arg.IsNull() {
+			return d, nil
+		}
+	}
 	str, err := args[0].ToString()
 	if err != nil {
-		return d, errors.Errorf("Substring_Index invalid args, need string but get %T", args[0].GetValue())
+		return d, errors.Trace(err)
 	}
-
 	delim, err := args[1].ToString()
 	if err != nil {
-		return d, errors.Errorf("Substring_Index invalid delim, need string but get %T", args[1].GetValue())
+		return d, errors.Trace(err)
 	}
-	if len(delim) == 0 {
+	var count int64
+	if args[2].Kind() == types.KindUint64 {
+		// An unsigned count beyond the max int64 is larger than the number of delimiters anyway.
+		count = math.MaxInt64
+		if u := args[2].GetUint64(); u < math.MaxInt64 {
+			count = int64(u)
+		}
+	} else if count, err = args[2].ToInt64(b.ctx.GetSessionVars().StmtCtx); err != nil {
+		return d, errors.Trace(err)
+	}
+	if len(delim) == 0 || count == 0 {
 		d.SetString("")
 		return d, nil
 	}
-
-	c, err := args[2].ToInt64(ctx.GetSessionVars().StmtCtx)
-	if err != nil {
-		return d, errors.Trace(err)
-	}
-	count := int(c)
 	strs := strings.Split(str, delim)
-	var (
-		start = 0
-		end   = len(strs)
-	)
+	n := int64(len(strs))
+	var start, end int64 = 0, n
 	if count > 0 {
 		// If count is positive, everything to the left of the final delimiter (counting from the left) is returned.
-		if count < end {
+		if count < n {
 			end = count
 		}
-	} else {
+	} else if count > -n {
 		// If count is negative, everything to the right of the final delimiter (counting from the right) is returned.
-		count = -count
-		if count < end {
-			start = end - count
-		}
+		// The comparison is done without negating count, which overflows for the min int64.
+		start = n + count
 	}
-	substrs := strs[start:end]
-	d.SetString(strings.Join(substrs, delim))
+	d.SetString(strings.Join(strs[start:end], delim))
 	return d, nil
 }
 
@@ -474,9 +715,11 @@ func builtinHex(args []types.Datum, ctx context.Context) (d types.Datum, err err
 		d.SetString(strings.ToUpper(hex.EncodeToString(hack.Slice(x))))
 		return d, nil
 	case types.KindInt64, types.KindUint64, types.KindMysqlHex, types.KindFloat32, types.KindFloat64, types.KindMysqlDecimal:
-		x, _ := args[0].Cast(ctx.GetSessionVars().StmtCtx, types.NewFieldType(mysql.TypeLonglong))
-		h := fmt.Sprintf("%x", uint64(x.GetInt64()))
-		d.SetString(strings.ToUpper(h))
+		u, err := intArgToUint64(ctx.GetSessionVars().StmtCtx, args[0])
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		d.SetString(strings.ToUpper(strconv.FormatUint(u, 16)))
 		return d, nil
 	default:
 		return d, errors.Errorf("Hex invalid args, need int or string but get %T", args[0].GetValue())
@@ -605,37 +848,102 @@ func trimRight(str, remstr string) string {
 	}
 }
 
+type lpadFuncClass struct {
+	baseFuncClass
+}
+
+func (c *lpadFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinLpad{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinLpad struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinLpad.
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_lpad
+func (b *builtinLpad) eval(row []types.Datum) (d types.Datum, err error) {
+	return b.evalPad(row, ast.Lpad, true)
+}
+
+type rpadFuncClass struct {
+	baseFuncClass
+}
+
+func (c *rpadFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinRpad{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinRpad struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinRpad.
 // See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_rpad
-func builtinRpad(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-	// RPAD(str,len,padstr)
-	// args[0] string, args[1] int, args[2] string
+func (b *builtinRpad) eval(row []types.Datum) (d types.Datum, err error) {
+	return b.evalPad(row, ast.Rpad, false)
+}
+
+// evalPad evaluates LPAD(str,len,padstr) and RPAD(str,len,padstr), which pad str on the left or the right
+// with padstr to len characters, or shorten it to len characters. The result is NULL if len is negative or str can't be padded,
+// and it's NULL with a warning if len is larger than max_allowed_packet, which is checked before padding.
+func (b *baseBuiltinFunc) evalPad(row []types.Datum, funcName string, left bool) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	for _, arg := range args {
+		if arg.IsNull() {
+			return d, nil
+		}
+	}
 	str, err := args[0].ToString()
 	if err != nil {
 		return d, errors.Trace(err)
 	}
-	length, err := args[1].ToInt64(ctx.GetSessionVars().StmtCtx)
+	length, err := args[1].ToInt64(b.ctx.GetSessionVars().StmtCtx)
 	if err != nil {
 		return d, errors.Trace(err)
 	}
-	l := int(length)
-
 	padStr, err := args[2].ToString()
 	if err != nil {
 		return d, errors.Trace(err)
 	}
-
-	if l < 0 || (len(str) < l && padStr == "") {
-		d.SetNull()
+	// The strings are binary if any of them is binary.
+	cs := strCharset(b.args[0].GetType())
+	if isBinaryStr(b.args[2].GetType()) {
+		cs = charset.CharsetBin
+	}
+	chars := splitChars(str, cs)
+	if length < 0 || (int64(len(chars)) < length && padStr == "") {
 		return d, nil
 	}
-
-	tailLen := l - len(str)
-	if tailLen > 0 {
-		repeatCount := tailLen/len(padStr) + 1
-		str = str + strings.Repeat(padStr, repeatCount)
+	maxAllowedPacket := getUintSysVar(b.ctx, variable.MaxAllowedPacket)
+	if uint64(length) > maxAllowedPacket {
+		b.ctx.GetSessionVars().StmtCtx.AppendWarning(errAllowedPacketOverflowed.GenByArgs(funcName, maxAllowedPacket))
+		return d, nil
+	}
+	l := int(length)
+	if l <= len(chars) {
+		d.SetString(strings.Join(chars[:l], ""))
+		return d, nil
+	}
+	padChars := splitChars(padStr, cs)
+	padLen := l - len(chars)
+	pad := strings.Repeat(padStr, padLen/len(padChars)) + strings.Join(padChars[:padLen%len(padChars)], "")
+	if left {
+		d.SetString(pad + str)
+	} else {
+		d.SetString(str + pad)
 	}
-	d.SetString(str[:l])
-
 	return d, nil
 }
 
@@ -741,8 +1049,91 @@ func reverseByteSlice(slice []byte) {
 	}
 }
 
+// isBinaryStr returns whether tp is a binary string type, which has no character boundaries.
+func isBinaryStr(tp *types.FieldType) bool {
+	if tp == nil || tp.Collate != charset.CollationBin {
+		return false
+	}
+	return types.IsTypeChar(tp.Tp) || types.IsTypeBlob(tp.Tp) || tp.Tp == mysql.TypeVarString
+}
+
+// charsetGBK is not a supported charset of the columns, but the strings in it may still be iterated.
+const charsetGBK = "gbk"
+
+// strCharset returns the charset deciding the character boundaries of a string of type tp,
+// a binary string is a sequence of bytes and a string without a charset is taken as utf8.
+func strCharset(tp *types.FieldType) string {
+	if isBinaryStr(tp) {
+		return charset.CharsetBin
+	}
+	if tp == nil || tp.Charset == "" {
+		return charset.CharsetUTF8
+	}
+	return strings.ToLower(tp.Charset)
+}
+
+// charSize returns the number of bytes of the first character of the non-empty string s in the charset cs.
+// An invalid byte sequence is taken as a character of one byte, so the iteration always moves forward.
+func charSize(s string, cs string) int {
+	switch cs {
+	case charset.CharsetBin, "latin1", "ascii":
+		return 1
+	case charsetGBK:
+		// A double-byte character has a leading byte in [0x81, 0xFE] and a trailing byte in [0x40, 0xFE] except 0x7F.
+		if len(s) >= 2 && s[0] >= 0x81 && s[0] <= 0xFE && s[1] >= 0x40 && s[1] <= 0xFE && s[1] != 0x7F {
+			return 2
+		}
+		return 1
+	default:
+		_, size := utf8.DecodeRuneInString(s)
+		return size
+	}
+}
+
+// splitChars splits the string s into the characters of the charset cs.
+func splitChars(s string, cs string) []string {
+	chars := make([]string, 0, len(s))
+	for len(s) > 0 {
+		size := charSize(s, cs)
+		chars = append(chars, s[:size])
+		s = s[size:]
+	}
+	return chars
+}
+
+// charCount returns the number of characters of the string s in the charset cs.
+func charCount(s string, cs string) int {
+	count := 0
+	for len(s) > 0 {
+		s = s[charSize(s, cs):]
+		count++
+	}
+	return count
+}
+
+type charLengthFuncClass struct {
+	baseFuncClass
+}
+
+func (c *charLengthFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinCharLength{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinCharLength struct {
+	baseBuiltinFunc
+}
+
+// eval returns the number of characters, or the number of bytes if the argument is a binary string.
 // See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_char-length
-func builtinCharLength(args []types.Datum, _ context.Context) (d types.Datum, err error) {
+func (b *builtinCharLength) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
 	switch args[0].Kind() {
 	case types.KindNull:
 		return d, nil
@@ -751,8 +1142,417 @@ func builtinCharLength(args []types.Datum, _ context.Context) (d types.Datum, er
 		if err != nil {
 			return d, errors.Trace(err)
 		}
-		r := []rune(s)
-		d.SetInt64(int64(len(r)))
+		d.SetInt64(int64(charCount(s, strCharset(b.args[0].GetType()))))
+		return d, nil
+	}
+}
+
+type weightStringFuncClass struct {
+	baseFuncClass
+}
+
+func (c *weightStringFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinWeightString{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinWeightString struct {
+	baseBuiltinFunc
+}
+
+// eval returns the weight string of args[0] under its collation, the optional args[1] and args[2]
+// are the "CHAR" or "BINARY" type and length of the AS clause.
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_weight-string
+func (b *builtinWeightString) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	str, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	collation := mysql.DefaultCollationName
+	if tp := b.args[0].GetType(); tp != nil && tp.Collate != "" {
+		collation = tp.Collate
+	} else if args[0].Kind() == types.KindBytes {
+		collation = charset.CollationBin
+	}
+	padding, length := "", -1
+	if len(args) == 3 {
+		padding = args[1].GetString()
+		l, err := args[2].ToInt64(b.ctx.GetSessionVars().StmtCtx)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		length = int(l)
+	}
+
+	var weights []byte
+	switch {
+	case padding == "BINARY" || collation == charset.CollationBin:
+		weights = []byte(str)
+		if length >= 0 {
+			weights = padOrTruncateBytes(weights, length)
+		}
+	default:
+		runes := []rune(str)
+		if padding == "CHAR" {
+			if len(runes) > length {
+				runes = runes[:length]
+			}
+			for len(runes) < length {
+				runes = append(runes, ' ')
+			}
+		}
+		weights = runeWeights(runes, strings.HasSuffix(collation, "_ci"))
+	}
+	d.SetString(string(weights))
+	return d, nil
+}
+
+// padOrTruncateBytes truncates b to n bytes, or pads it with 0x00 to n bytes.
+func padOrTruncateBytes(b []byte, n int) []byte {
+	if len(b) >= n {
+		return b[:n]
+	}
+	return append(b, make([]byte, n-len(b))...)
+}
+
+// runeWeights returns the 2 bytes big endian weight of every rune. The weight of a rune is its upper case
+// code point if caseInsensitive is true. Runes outside the Basic Multilingual Plane weigh as U+FFFD.
+func runeWeights(runes []rune, caseInsensitive bool) []byte {
+	weights := make([]byte, 0, 2*len(runes))
+	for _, r := range runes {
+		if caseInsensitive {
+			r = unicode.ToUpper(r)
+		}
+		if r > 0xFFFF {
+			r = unicode.ReplacementChar
+		}
+		weights = append(weights, byte(r>>8), byte(r))
+	}
+	return weights
+}
+
+type toBase64FuncClass struct {
+	baseFuncClass
+}
+
+func (c *toBase64FuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinToBase64{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinToBase64 struct {
+	baseBuiltinFunc
+}
+
+// base64LineLen is the max length of a line in the output of TO_BASE64.
+const base64LineLen = 76
+
+// eval encodes the argument as base64 like MySQL does, a newline is added after every 76 characters.
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_to-base64
+func (b *builtinToBase64) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	str, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	encoded := base64.StdEncoding.EncodeToString([]byte(str))
+	lines := make([]string, 0, len(encoded)/base64LineLen+1)
+	for len(encoded) > base64LineLen {
+		lines = append(lines, encoded[:base64LineLen])
+		encoded = encoded[base64LineLen:]
+	}
+	lines = append(lines, encoded)
+	d.SetString(strings.Join(lines, "\n"))
+	return d, nil
+}
+
+type fromBase64FuncClass struct {
+	baseFuncClass
+}
+
+func (c *fromBase64FuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinFromBase64{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinFromBase64 struct {
+	baseBuiltinFunc
+}
+
+// eval decodes a base64 string, the whitespaces in it are ignored. It returns NULL if the string is not valid base64.
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_from-base64
+func (b *builtinFromBase64) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	str, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	str = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, str)
+	decoded, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
 		return d, nil
 	}
+	d.SetString(string(decoded))
+	return d, nil
+}
+
+// intArgToUint64 converts the integer argument of BIN, OCT, HEX, MAKE_SET and EXPORT_SET to an unsigned 64-bit value,
+// a negative integer is converted to its 64-bit two's complement like MySQL does.
+func intArgToUint64(sc *variable.StatementContext, arg types.Datum) (uint64, error) {
+	if arg.Kind() == types.KindUint64 {
+		return arg.GetUint64(), nil
+	}
+	x, err := arg.ToInt64(sc)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return uint64(x), nil
+}
+
+type makeSetFuncClass struct {
+	baseFuncClass
+}
+
+func (c *makeSetFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinMakeSet{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinMakeSet struct {
+	baseBuiltinFunc
+}
+
+// eval returns the comma separated strings whose corresponding bits are set in the first argument, NULL strings are skipped.
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_make-set
+func (b *builtinMakeSet) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	bits, err := intArgToUint64(b.ctx.GetSessionVars().StmtCtx, args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	strs := make([]string, 0, len(args)-1)
+	for i, arg := range args[1:] {
+		if i >= 64 {
+			break
+		}
+		if bits&(1<<uint(i)) == 0 || arg.IsNull() {
+			continue
+		}
+		str, err := arg.ToString()
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		strs = append(strs, str)
+	}
+	d.SetString(strings.Join(strs, ","))
+	return d, nil
+}
+
+type exportSetFuncClass struct {
+	baseFuncClass
+}
+
+func (c *exportSetFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinExportSet{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinExportSet struct {
+	baseBuiltinFunc
+}
+
+// eval returns the on string for every bit set and the off string for every bit not set in the first argument,
+// from the lowest bit to the number_of_bits (64 at most) bit, joined by the separator.
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_export-set
+func (b *builtinExportSet) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	for _, arg := range args {
+		if arg.IsNull() {
+			return d, nil
+		}
+	}
+	bits, err := intArgToUint64(b.ctx.GetSessionVars().StmtCtx, args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	on, err := args[1].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	off, err := args[2].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	sep := ","
+	if len(args) > 3 {
+		if sep, err = args[3].ToString(); err != nil {
+			return d, errors.Trace(err)
+		}
+	}
+	numberOfBits := int64(64)
+	if len(args) > 4 {
+		n, err := args[4].ToInt64(b.ctx.GetSessionVars().StmtCtx)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		if n >= 0 && n < 64 {
+			numberOfBits = n
+		}
+	}
+	strs := make([]string, 0, numberOfBits)
+	for i := uint(0); i < uint(numberOfBits); i++ {
+		if bits&(1<<i) != 0 {
+			strs = append(strs, on)
+		} else {
+			strs = append(strs, off)
+		}
+	}
+	d.SetString(strings.Join(strs, sep))
+	return d, nil
+}
+
+type binFuncClass struct {
+	baseFuncClass
+}
+
+func (c *binFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinBin{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinBin struct {
+	baseBuiltinFunc
+}
+
+// eval returns the binary representation of the integer argument, a negative integer is represented by
+// its 64-bit two's complement, e.g. BIN(-1) is 64 ones.
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_bin
+func (b *builtinBin) eval(row []types.Datum) (d types.Datum, err error) {
+	return b.evalIntInBase(row, 2)
+}
+
+type octFuncClass struct {
+	baseFuncClass
+}
+
+func (c *octFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinOct{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinOct struct {
+	baseBuiltinFunc
+}
+
+// eval returns the octal representation of the integer argument, a negative integer is represented by
+// its 64-bit two's complement, e.g. OCT(-1) is 1777777777777777777777.
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_oct
+func (b *builtinOct) eval(row []types.Datum) (d types.Datum, err error) {
+	return b.evalIntInBase(row, 8)
+}
+
+// evalIntInBase evaluates the integer argument and formats it in base like HEX does.
+func (b *baseBuiltinFunc) evalIntInBase(row []types.Datum, base int) (d types.Datum, err error) {
+	arg, err := b.args[0].Eval(row, b.ctx)
+	if err != nil || arg.IsNull() {
+		return d, errors.Trace(err)
+	}
+	u, err := intArgToUint64(b.ctx.GetSessionVars().StmtCtx, arg)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	d.SetString(strconv.FormatUint(u, base))
+	return d, nil
+}
+
+type ordFuncClass struct {
+	baseFuncClass
+}
+
+func (c *ordFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinOrd{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinOrd struct {
+	baseBuiltinFunc
+}
+
+// eval returns the code of the leftmost character of the string, the bytes of a multi-byte character in
+// the charset of the argument are composed as (1st byte code * 256) + (2nd byte code) ... like MySQL does.
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_ord
+func (b *builtinOrd) eval(row []types.Datum) (d types.Datum, err error) {
+	arg, err := b.args[0].Eval(row, b.ctx)
+	if err != nil || arg.IsNull() {
+		return d, errors.Trace(err)
+	}
+	s, err := arg.ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	var code int64
+	if len(s) > 0 {
+		for _, c := range []byte(s[:charSize(s, strCharset(b.args[0].GetType()))]) {
+			code = code<<8 | int64(c)
+		}
+	}
+	d.SetInt64(code)
+	return d, nil
 }
@@ -15,12 +15,17 @@ package expression
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"strings"
 	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
 	"github.com/pingcap/tidb/util/types"
@@ -85,67 +90,116 @@ func (s *testEvaluatorSuite) TestConcat(c *C) {
 	defer testleak.AfterTest(c)()
 	args := []interface{}{nil}
 
-	v, err := builtinConcat(types.MakeDatums(args...), s.ctx)
+	v, err := evalFuncClass(ast.Concat, types.MakeDatums(args...), s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.Kind(), Equals, types.KindNull)
 
 	args = []interface{}{"a", "b", "c"}
-	v, err = builtinConcat(types.MakeDatums(args...), s.ctx)
+	v, err = evalFuncClass(ast.Concat, types.MakeDatums(args...), s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.GetString(), Equals, "abc")
 
 	args = []interface{}{"a", "b", nil, "c"}
-	v, err = builtinConcat(types.MakeDatums(args...), s.ctx)
+	v, err = evalFuncClass(ast.Concat, types.MakeDatums(args...), s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.Kind(), Equals, types.KindNull)
 
 	args = []interface{}{errors.New("must error")}
-	_, err = builtinConcat(types.MakeDatums(args...), s.ctx)
+	_, err = evalFuncClass(ast.Concat, types.MakeDatums(args...), s.ctx)
 	c.Assert(err, NotNil)
+
+	// The result larger than max_allowed_packet is NULL with a warning.
+	sessVars := s.ctx.GetSessionVars()
+	defer delete(sessVars.Systems, variable.MaxAllowedPacket)
+	sessVars.Systems[variable.MaxAllowedPacket] = "4"
+	sc := sessVars.StmtCtx
+	warnCnt := len(sc.GetWarnings())
+	v, err = evalFuncClass(ast.Concat, types.MakeDatums("ab", "cd"), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.GetString(), Equals, "abcd")
+	c.Assert(sc.GetWarnings(), HasLen, warnCnt)
+	v, err = evalFuncClass(ast.Concat, types.MakeDatums("ab", "cd", "e"), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.Kind(), Equals, types.KindNull)
+	c.Assert(sc.GetWarnings(), HasLen, warnCnt+1)
+	c.Assert(terror.ErrorEqual(sc.GetWarnings()[warnCnt], errAllowedPacketOverflowed), IsTrue)
 }
 
 func (s *testEvaluatorSuite) TestConcatWS(c *C) {
 	defer testleak.AfterTest(c)()
-	args := types.MakeDatums([]interface{}{nil}...)
+	tbl := []struct {
+		args []interface{}
+		ret  interface{}
+	}{
+		{[]interface{}{"|", "a", nil, "b", "c"}, "a|b|c"},
+		// A NULL separator makes the result NULL, while NULL values are skipped.
+		{[]interface{}{nil, "a", "b"}, nil},
+		{[]interface{}{nil, nil}, nil},
+		{[]interface{}{"|", nil}, ""},
+		{[]interface{}{"|", nil, nil}, ""},
+		{[]interface{}{"|", ""}, ""},
+		{[]interface{}{"|", "", nil, ""}, "|"},
+		// Numbers are joined in their string form.
+		{[]interface{}{1, "a", "b"}, "a1b"},
+		{[]interface{}{0, 1, 2}, "102"},
+		{[]interface{}{1.5, int64(-2), uint64(3)}, "-21.53"},
+		{[]interface{}{types.NewDecFromStringForTest("0.10"), "a", "b"}, "a0.10b"},
+	}
+	for _, t := range tbl {
+		v, err := evalFuncClass(ast.ConcatWS, types.MakeDatums(t.args...), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v", t.args))
+	}
 
-	v, err := builtinConcatWS(args, s.ctx)
-	c.Assert(err, IsNil)
-	c.Assert(v.Kind(), Equals, types.KindNull)
+	_, err := evalFuncClass(ast.ConcatWS, types.MakeDatums("|"), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errIncorrectParameterCount), IsTrue)
 
-	args = types.MakeDatums([]interface{}{"|", "a", nil, "b", "c"}...)
+	_, err = evalFuncClass(ast.ConcatWS, types.MakeDatums(errors.New("must error"), "a"), s.ctx)
+	c.Assert(err, NotNil)
+	_, err = evalFuncClass(ast.ConcatWS, types.MakeDatums("|", errors.New("must error")), s.ctx)
+	c.Assert(err, NotNil)
 
-	v, err = builtinConcatWS(args, s.ctx)
+	// The result larger than max_allowed_packet is NULL with a warning.
+	sessVars := s.ctx.GetSessionVars()
+	defer delete(sessVars.Systems, variable.MaxAllowedPacket)
+	sessVars.Systems[variable.MaxAllowedPacket] = "5"
+	sc := sessVars.StmtCtx
+	warnCnt := len(sc.GetWarnings())
+	v, err := evalFuncClass(ast.ConcatWS, types.MakeDatums("|", "ab", nil, "cd"), s.ctx)
 	c.Assert(err, IsNil)
-	c.Assert(v.GetString(), Equals, "a|b|c")
-
-	args = types.MakeDatums([]interface{}{errors.New("must error")}...)
-	_, err = builtinConcatWS(args, s.ctx)
-	c.Assert(err, NotNil)
+	c.Assert(v.GetString(), Equals, "ab|cd")
+	c.Assert(sc.GetWarnings(), HasLen, warnCnt)
+	v, err = evalFuncClass(ast.ConcatWS, types.MakeDatums("||", "ab", "cd"), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.Kind(), Equals, types.KindNull)
+	c.Assert(sc.GetWarnings(), HasLen, warnCnt+1)
+	c.Assert(terror.ErrorEqual(sc.GetWarnings()[warnCnt], errAllowedPacketOverflowed), IsTrue)
 }
 
 func (s *testEvaluatorSuite) TestLeft(c *C) {
 	defer testleak.AfterTest(c)()
-	args := types.MakeDatums([]interface{}{"abcdefg", int64(2)}...)
-	v, err := builtinLeft(args, s.ctx)
-	c.Assert(err, IsNil)
-	c.Assert(v.GetString(), Equals, "ab")
-
-	args = types.MakeDatums([]interface{}{"abcdefg", int64(-1)}...)
-	v, err = builtinLeft(args, s.ctx)
-	c.Assert(err, IsNil)
-	c.Assert(v.GetString(), Equals, "")
-
-	args = types.MakeDatums([]interface{}{"abcdefg", int64(100)}...)
-	v, err = builtinLeft(args, s.ctx)
-	c.Assert(err, IsNil)
-	c.Assert(v.GetString(), Equals, "abcdefg")
+	tbl := []struct {
+		str    interface{}
+		length interface{}
+		result string
+	}{
+		{"abcdefg", int64(2), "ab"},
+		{"abcdefg", int64(-1), ""},
+		{"abcdefg", int64(100), "abcdefg"},
+		{1, int64(1), "1"},
+		{"数据库", int64(2), "数据"},
+	}
+	for _, t := range tbl {
+		f, err := funcs[ast.Left].getFunction(datumsToConstants(types.MakeDatums(t.str, t.length)), s.ctx)
+		c.Assert(err, IsNil)
+		v, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(v.GetString(), Equals, t.result)
+	}
 
-	args = types.MakeDatums([]interface{}{1, int64(1)}...)
-	_, err = builtinLeft(args, s.ctx)
+	f, err := funcs[ast.Left].getFunction(datumsToConstants(types.MakeDatums("abcdefg", "xxx")), s.ctx)
 	c.Assert(err, IsNil)
-
-	args = types.MakeDatums([]interface{}{"abcdefg", "xxx"}...)
-	_, err = builtinLeft(args, s.ctx)
+	_, err = f.eval(nil)
 	c.Assert(err, NotNil)
 }
 
@@ -179,11 +233,11 @@ func (s *testEvaluatorSuite) TestRepeat(c *C) {
 
 func (s *testEvaluatorSuite) TestLowerAndUpper(c *C) {
 	defer testleak.AfterTest(c)()
-	d, err := builtinLower(types.MakeDatums([]interface{}{nil}...), s.ctx)
+	d, err := evalFuncClass(ast.Lower, types.MakeDatums(nil), s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(d.Kind(), Equals, types.KindNull)
 
-	d, err = builtinUpper(types.MakeDatums([]interface{}{nil}...), s.ctx)
+	d, err = evalFuncClass(ast.Upper, types.MakeDatums(nil), s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(d.Kind(), Equals, types.KindNull)
 
@@ -198,19 +252,48 @@ func (s *testEvaluatorSuite) TestLowerAndUpper(c *C) {
 	dtbl := tblToDtbl(tbl)
 
 	for _, t := range dtbl {
-		d, err = builtinLower(t["Input"], s.ctx)
+		d, err = evalFuncClass(ast.Lower, t["Input"], s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(d, testutil.DatumEquals, t["Expect"][0])
 
-		d, err = builtinUpper(t["Input"], s.ctx)
+		d, err = evalFuncClass(ast.Upper, t["Input"], s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(d.GetString(), Equals, strings.ToUpper(t["Expect"][0].GetString()))
 	}
+
+	convert := func(name string, tp *types.FieldType, str string) string {
+		f, err := funcs[name].getFunction([]Expression{&Constant{Value: types.NewDatum(str), RetType: tp}}, s.ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		return d.GetString()
+	}
+	utf8Type := types.NewFieldType(mysql.TypeVarchar)
+	utf8Type.Charset, utf8Type.Collate = charset.CharsetUTF8MB4, charset.CollationUTF8MB4
+	binType := types.NewFieldType(mysql.TypeVarString)
+	binType.Charset, binType.Collate = charset.CharsetBin, charset.CollationBin
+
+	c.Assert(convert(ast.Lower, utf8Type, "TiDB"), Equals, "tidb")
+	c.Assert(convert(ast.Lcase, utf8Type, "TiDB"), Equals, "tidb")
+	c.Assert(convert(ast.Upper, utf8Type, "TiDB"), Equals, "TIDB")
+	c.Assert(convert(ast.Ucase, utf8Type, "TiDB"), Equals, "TIDB")
+	// Binary strings are not case-folded.
+	c.Assert(convert(ast.Lower, binType, "TiDB"), Equals, "TiDB")
+	c.Assert(convert(ast.Upper, binType, "TiDB"), Equals, "TiDB")
+	c.Assert(convert(ast.Upper, binType, "\xc3\xa4"), Equals, "\xc3\xa4")
+	// Multi-byte characters use the Unicode case mapping.
+	c.Assert(convert(ast.Upper, utf8Type, "straße äöü"), Equals, "STRAßE ÄÖÜ")
+	c.Assert(convert(ast.Lower, utf8Type, "STRAẞE ÄÖÜ"), Equals, "straße äöü")
+	c.Assert(convert(ast.Lower, utf8Type, "İSTANBUL"), Equals, "istanbul")
+	c.Assert(convert(ast.Upper, utf8Type, "ıi"), Equals, "II")
+	c.Assert(convert(ast.Upper, utf8Type, "数据库"), Equals, "数据库")
 }
 
 func (s *testEvaluatorSuite) TestReverse(c *C) {
 	defer testleak.AfterTest(c)()
-	d, err := builtinReverse(types.MakeDatums([]interface{}{nil}...), s.ctx)
+	f, err := funcs[ast.Reverse].getFunction(datumsToConstants(types.MakeDatums(nil)), s.ctx)
+	c.Assert(err, IsNil)
+	d, err := f.eval(nil)
 	c.Assert(err, IsNil)
 	c.Assert(d.Kind(), Equals, types.KindNull)
 
@@ -222,12 +305,15 @@ func (s *testEvaluatorSuite) TestReverse(c *C) {
 		{"LIKE", "EKIL"},
 		{123, "321"},
 		{"", ""},
+		{"数据库", "库据数"},
 	}
 
 	dtbl := tblToDtbl(tbl)
 
 	for _, t := range dtbl {
-		d, err = builtinReverse(t["Input"], s.ctx)
+		f, err = funcs[ast.Reverse].getFunction(datumsToConstants(t["Input"]), s.ctx)
+		c.Assert(err, IsNil)
+		d, err = f.eval(nil)
 		c.Assert(err, IsNil)
 		c.Assert(d, testutil.DatumEquals, t["Expect"][0])
 	}
@@ -256,7 +342,7 @@ func (s *testEvaluatorSuite) TestStrcmp(c *C) {
 
 	dtbl := tblToDtbl(tbl)
 	for _, t := range dtbl {
-		d, err := builtinStrcmp(t["Input"], s.ctx)
+		d, err := evalFuncClass(ast.Strcmp, t["Input"], s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(d, testutil.DatumEquals, t["Expect"][0])
 	}
@@ -288,7 +374,13 @@ func (s *testEvaluatorSuite) TestReplace(c *C) {
 func (s *testEvaluatorSuite) TestSubstring(c *C) {
 	defer testleak.AfterTest(c)()
 
-	d, err := builtinSubstring(types.MakeDatums([]interface{}{"hello", 2, -1}...), s.ctx)
+	substring := func(args ...interface{}) (types.Datum, error) {
+		f, err := funcs[ast.Substring].getFunction(datumsToConstants(types.MakeDatums(args...)), s.ctx)
+		c.Assert(err, IsNil)
+		return f.eval(nil)
+	}
+
+	d, err := substring("hello", 2, -1)
 	c.Assert(err, IsNil)
 	c.Assert(d.GetString(), Equals, "")
 
@@ -317,23 +409,54 @@ func (s *testEvaluatorSuite) TestSubstring(c *C) {
 		{"Sakila", -1000, 3, ""},
 		{"Sakila", 1000, 2, ""},
 		{"", 2, 3, ""},
+		// The position is after the end of the string.
+		{"Sakila", 7, -1, ""},
+		{"Sakila", 7, 1, ""},
+		{"Sakila", math.MaxInt64, -1, ""},
+		{"Sakila", math.MaxInt64, math.MaxInt64, ""},
+		// The negative position is before the beginning of the string.
+		{"Sakila", -7, -1, ""},
+		{"Sakila", -7, 7, ""},
+		{"Sakila", math.MinInt64, -1, ""},
+		{"Sakila", math.MinInt64, math.MaxInt64, ""},
+		// The position 0 is before the beginning of the string.
+		{"Sakila", 0, -1, ""},
+		{"Sakila", 0, 3, ""},
+		// The length exceeds the remaining characters.
+		{"Sakila", 6, 2, "a"},
+		{"Sakila", -1, 2, "a"},
+		{"Sakila", 2, math.MaxInt64, "akila"},
+		{"Sakila", -2, math.MaxInt64, "la"},
+		// The length is not positive.
+		{"Sakila", 1, math.MinInt64, ""},
+		{"Sakila", -1, 0, ""},
+		// The position and the length are counted in characters.
+		{"数据库", 2, -1, "据库"},
+		{"数据库", -2, 1, "据"},
 	}
 	for _, v := range tbl {
-		f := Funcs[ast.Substring]
-		args := types.MakeDatums(v.str, v.pos)
+		args := []interface{}{v.str, v.pos}
 		if v.slen != -1 {
-			args = append(args, types.NewDatum(v.slen))
+			args = append(args, v.slen)
 		}
-		r, err := f.F(args, s.ctx)
+		r, err := substring(args...)
 		c.Assert(err, IsNil)
 		c.Assert(r.Kind(), Equals, types.KindString)
 		c.Assert(r.GetString(), Equals, v.result)
 
-		r1, err := f.F(args, s.ctx)
+		r1, err := substring(args...)
 		c.Assert(err, IsNil)
 		c.Assert(r1.Kind(), Equals, types.KindString)
 		c.Assert(r.GetString(), Equals, r1.GetString())
 	}
+	// An unsigned position or length larger than the max int64 is beyond the end of the string.
+	d, err = substring("Sakila", uint64(math.MaxUint64))
+	c.Assert(err, IsNil)
+	c.Assert(d.GetString(), Equals, "")
+	d, err = substring("Sakila", uint64(2), uint64(math.MaxUint64))
+	c.Assert(err, IsNil)
+	c.Assert(d.GetString(), Equals, "akila")
+
 	errTbl := []struct {
 		str    interface{}
 		pos    interface{}
@@ -344,12 +467,11 @@ func (s *testEvaluatorSuite) TestSubstring(c *C) {
 		{"Quadratically", 5, "6", "ratica"},
 	}
 	for _, v := range errTbl {
-		f := Funcs[ast.Substring]
-		args := types.MakeDatums(v.str, v.pos)
+		args := []interface{}{v.str, v.pos}
 		if v.len != -1 {
-			args = append(args, types.NewDatum(v.len))
+			args = append(args, v.len)
 		}
-		_, err := f.F(args, s.ctx)
+		_, err := substring(args...)
 		c.Assert(err, NotNil)
 	}
 }
@@ -414,15 +536,30 @@ func (s *testEvaluatorSuite) TestSubstringIndex(c *C) {
 		{"www.mysql.com", "", 1, ""},
 		{"www.mysql.com", "", -1, ""},
 		{"www.mysql.com", "", 0, ""},
+		{"", "", 1, ""},
+
+		{"www.mysql.com", ".", math.MaxInt64, "www.mysql.com"},
+		{"www.mysql.com", ".", math.MinInt64, "www.mysql.com"},
+		{"www.mysql.com", ".", math.MinInt64 + 1, "www.mysql.com"},
+		{"a..b", ".", 2, "a."},
+		{"a..b", ".", -2, ".b"},
+		{"数据.库", "据", -1, ".库"},
 	}
 	for _, v := range tbl {
-		f := Funcs[ast.SubstringIndex]
-		r, err := f.F(types.MakeDatums(v.str, v.delim, v.count), s.ctx)
+		r, err := evalFuncClass(ast.SubstringIndex, types.MakeDatums(v.str, v.delim, v.count), s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(r.Kind(), Equals, types.KindString)
-		c.Assert(r.GetString(), Equals, v.result)
+		c.Assert(r.GetString(), Equals, v.result, Commentf("%v", v))
 	}
-	errTbl := []struct {
+
+	r, err := evalFuncClass(ast.SubstringIndex, types.MakeDatums("www.mysql.com", ".", uint64(math.MaxUint64)), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(r.GetString(), Equals, "www.mysql.com")
+	r, err = evalFuncClass(ast.SubstringIndex, types.MakeDatums("www.mysql.com", ".", uint64(1)), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(r.GetString(), Equals, "www")
+
+	nullTbl := []struct {
 		str   interface{}
 		delim interface{}
 		count interface{}
@@ -434,11 +571,11 @@ func (s *testEvaluatorSuite) TestSubstringIndex(c *C) {
 		{"asdf", nil, -2},
 		{"asdf", nil, 0},
 		{"www.mysql.com", ".", nil},
+		{"www.mysql.com", "", nil},
 	}
-	for _, v := range errTbl {
-		f := Funcs[ast.SubstringIndex]
-		r, err := f.F(types.MakeDatums(v.str, v.delim, v.count), s.ctx)
-		c.Assert(err, NotNil)
+	for _, v := range nullTbl {
+		r, err := evalFuncClass(ast.SubstringIndex, types.MakeDatums(v.str, v.delim, v.count), s.ctx)
+		c.Assert(err, IsNil)
 		c.Assert(r.Kind(), Equals, types.KindNull)
 	}
 }
@@ -617,6 +754,9 @@ func (s *testEvaluatorSuite) TestHexFunc(c *C) {
 		{12.5, "D"},
 		{-12.3, "FFFFFFFFFFFFFFF4"},
 		{-12.5, "FFFFFFFFFFFFFFF3"},
+		{-1, "FFFFFFFFFFFFFFFF"},
+		{uint64(18446744073709551615), "FFFFFFFFFFFFFFFF"},
+		{int64(math.MinInt64), "8000000000000000"},
 		{"12", "3132"},
 		{0x12, "12"},
 		{"", ""},
@@ -650,33 +790,59 @@ func (s *testEvaluatorSuite) TestUnhexFunc(c *C) {
 	}
 }
 
-func (s *testEvaluatorSuite) TestRpad(c *C) {
+func (s *testEvaluatorSuite) TestLpadAndRpad(c *C) {
+	defer testleak.AfterTest(c)()
 	tests := []struct {
-		str    string
-		len    int64
-		padStr string
-		expect interface{}
+		str    interface{}
+		len    interface{}
+		padStr interface{}
+		lpad   interface{}
+		rpad   interface{}
 	}{
-		{"hi", 5, "?", "hi???"},
-		{"hi", 1, "?", "h"},
-		{"hi", 0, "?", ""},
-		{"hi", -1, "?", nil},
-		{"hi", 1, "", "h"},
-		{"hi", 5, "", nil},
-		{"hi", 5, "ab", "hiaba"},
-		{"hi", 6, "ab", "hiabab"},
+		{"hi", 5, "?", "???hi", "hi???"},
+		{"hi", 1, "?", "h", "h"},
+		{"hi", 0, "?", "", ""},
+		{"hi", -1, "?", nil, nil},
+		{"hi", 1, "", "h", "h"},
+		{"hi", 5, "", nil, nil},
+		{"hi", 5, "ab", "abahi", "hiaba"},
+		{"hi", 6, "ab", "ababhi", "hiabab"},
+		{"héllo", 2, "x", "hé", "hé"},
+		{"hé", 5, "éa", "éaéhé", "hééaé"},
+		{"hi", "4", 0, "00hi", "hi00"},
+		{123, 5, 0, "00123", "12300"},
+		{nil, 5, "?", nil, nil},
+		{"hi", nil, "?", nil, nil},
+		{"hi", 5, nil, nil, nil},
 	}
 	for _, test := range tests {
-		str := types.NewStringDatum(test.str)
-		length := types.NewIntDatum(test.len)
-		padStr := types.NewStringDatum(test.padStr)
-		result, err := builtinRpad([]types.Datum{str, length, padStr}, s.ctx)
+		args := types.MakeDatums(test.str, test.len, test.padStr)
+		result, err := evalFuncClass(ast.Lpad, args, s.ctx)
 		c.Assert(err, IsNil)
-		if test.expect == nil {
+		c.Assert(result, testutil.DatumEquals, types.NewDatum(test.lpad), Commentf("%v", args))
+		result, err = evalFuncClass(ast.Rpad, args, s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(result, testutil.DatumEquals, types.NewDatum(test.rpad), Commentf("%v", args))
+	}
+
+	// The result larger than max_allowed_packet is NULL with a warning, the padded string is never built.
+	sessVars := s.ctx.GetSessionVars()
+	defer delete(sessVars.Systems, variable.MaxAllowedPacket)
+	sessVars.Systems[variable.MaxAllowedPacket] = "5"
+	sc := sessVars.StmtCtx
+	for _, funcName := range []string{ast.Lpad, ast.Rpad} {
+		warnCnt := len(sc.GetWarnings())
+		result, err := evalFuncClass(funcName, types.MakeDatums("hi", 5, "?"), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(result.GetString(), HasLen, 5)
+		c.Assert(sc.GetWarnings(), HasLen, warnCnt)
+		for _, length := range []int64{6, math.MaxInt64} {
+			result, err = evalFuncClass(funcName, types.MakeDatums("hi", length, "?"), s.ctx)
+			c.Assert(err, IsNil)
 			c.Assert(result.Kind(), Equals, types.KindNull)
-		} else {
-			expect, _ := test.expect.(string)
-			c.Assert(result.GetString(), Equals, expect)
+			c.Assert(sc.GetWarnings(), HasLen, warnCnt+1)
+			c.Assert(sc.GetWarnings()[warnCnt].Error(), Equals, "[expression:1301]Result of "+funcName+"() was larger than max_allowed_packet (5) - truncated")
+			warnCnt++
 		}
 	}
 }
@@ -760,9 +926,302 @@ func (s *testEvaluatorSuite) TestCharLength(c *C) {
 		{nil, nil}, // nil
 	}
 	for _, v := range tbl {
-		f := Funcs[ast.CharLength]
-		r, err := f.F(types.MakeDatums(v.input), s.ctx)
+		f, err := funcs[ast.CharLength].getFunction(datumsToConstants(types.MakeDatums(v.input)), s.ctx)
+		c.Assert(err, IsNil)
+		r, err := f.eval(nil)
 		c.Assert(err, IsNil)
 		c.Assert(r, testutil.DatumEquals, types.NewDatum(v.result))
 	}
+
+	// A binary string has no character boundaries, CHAR_LENGTH returns its byte length.
+	tbl = []struct {
+		input  interface{}
+		result interface{}
+	}{
+		{"你好", 6},
+		{"abc", 3},
+		{"", 0},
+	}
+	for _, v := range tbl {
+		for _, collation := range []string{charset.CollationBin, "utf8_bin"} {
+			tp := types.NewFieldType(mysql.TypeString)
+			tp.Collate = collation
+			f, err := funcs[ast.CharLength].getFunction([]Expression{&Constant{Value: types.NewDatum(v.input), RetType: tp}}, s.ctx)
+			c.Assert(err, IsNil)
+			r, err := f.eval(nil)
+			c.Assert(err, IsNil)
+			if collation == charset.CollationBin {
+				c.Assert(r, testutil.DatumEquals, types.NewDatum(v.result))
+			} else {
+				c.Assert(r, testutil.DatumEquals, types.NewDatum(len([]rune(v.input.(string)))))
+			}
+		}
+	}
+}
+
+func (s *testEvaluatorSuite) TestSplitChars(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		str   string
+		cs    string
+		chars []string
+	}{
+		{"", "utf8mb4", []string{}},
+		{"a数😀", "utf8mb4", []string{"a", "数", "😀"}},
+		{"a数", "utf8", []string{"a", "数"}},
+		// An invalid utf8 byte is a character by itself.
+		{"a\xff数", "utf8", []string{"a", "\xff", "数"}},
+		{"a\xe9", "latin1", []string{"a", "\xe9"}},
+		{"a数", "latin1", []string{"a", "\xe6", "\x95", "\xb0"}},
+		{"a数", charset.CharsetBin, []string{"a", "\xe6", "\x95", "\xb0"}},
+		// "数据" in gbk.
+		{"a\xca\xfd\xbe\xdd", "gbk", []string{"a", "\xca\xfd", "\xbe\xdd"}},
+		// The trailing byte is out of range or missing.
+		{"\x81\x7fb\x81", "gbk", []string{"\x81", "\x7f", "b", "\x81"}},
+	}
+	for _, t := range tbl {
+		c.Assert(splitChars(t.str, t.cs), DeepEquals, t.chars, Commentf("%q in %s", t.str, t.cs))
+		c.Assert(charCount(t.str, t.cs), Equals, len(t.chars))
+	}
+
+	c.Assert(strCharset(nil), Equals, charset.CharsetUTF8)
+	tp := types.NewFieldType(mysql.TypeVarchar)
+	tp.Charset, tp.Collate = "latin1", "latin1_bin"
+	c.Assert(strCharset(tp), Equals, "latin1")
+	tp.Charset, tp.Collate = charset.CharsetBin, charset.CollationBin
+	c.Assert(strCharset(tp), Equals, charset.CharsetBin)
+
+	// The string functions count the characters in the charset of the argument.
+	eval := func(name string, tp *types.FieldType, args ...interface{}) string {
+		exprs := datumsToConstants(types.MakeDatums(args...))
+		exprs[0].(*Constant).RetType = tp
+		f, err := funcs[name].getFunction(exprs, s.ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		return d.GetString()
+	}
+	utf8Type := types.NewFieldType(mysql.TypeVarchar)
+	utf8Type.Charset, utf8Type.Collate = charset.CharsetUTF8MB4, charset.CollationUTF8MB4
+	binType := types.NewFieldType(mysql.TypeVarchar)
+	binType.Charset, binType.Collate = charset.CharsetBin, charset.CollationBin
+	c.Assert(eval(ast.Left, utf8Type, "数据库", 1), Equals, "数")
+	c.Assert(eval(ast.Left, binType, "数据库", 1), Equals, "\xe6")
+	c.Assert(eval(ast.Reverse, utf8Type, "a数"), Equals, "数a")
+	c.Assert(eval(ast.Reverse, binType, "ab"), Equals, "ba")
+	c.Assert(eval(ast.Substring, utf8Type, "数据库", 3), Equals, "库")
+	c.Assert(eval(ast.Substring, binType, "数据库", 7), Equals, "库")
+}
+
+func (s *testEvaluatorSuite) TestWeightString(c *C) {
+	defer testleak.AfterTest(c)()
+	newStr := func(str, collation string) Expression {
+		tp := types.NewFieldType(mysql.TypeVarString)
+		tp.Collate = collation
+		return &Constant{Value: types.NewDatum(str), RetType: tp}
+	}
+	weightString := func(args ...Expression) string {
+		f, err := funcs[ast.WeightString].getFunction(args, s.ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		return d.GetString()
+	}
+
+	// Differently-cased strings have the same weights under a ci collation.
+	ws := weightString(newStr("aBc", "utf8_general_ci"))
+	c.Assert(ws, Equals, "\x00A\x00B\x00C")
+	c.Assert(weightString(newStr("AbC", "utf8_general_ci")), Equals, ws)
+	c.Assert(weightString(newStr("abc", "utf8_general_ci")), Equals, ws)
+	c.Assert(weightString(newStr("abd", "utf8_general_ci")) > ws, IsTrue)
+	c.Assert(weightString(newStr("你", "utf8_general_ci")), Equals, "\x4f\x60")
+
+	// They are different under a bin collation.
+	c.Assert(weightString(newStr("aBc", "utf8_bin")), Equals, "\x00a\x00B\x00c")
+	c.Assert(weightString(newStr("AbC", "utf8_bin")), Not(Equals), weightString(newStr("aBc", "utf8_bin")))
+	c.Assert(weightString(newStr("aBc", "binary")), Equals, "aBc")
+
+	// AS CHAR(n) pads with spaces or truncates to n characters, AS BINARY(n) pads with 0x00 or truncates to n bytes.
+	asClause := func(tp string, n int) []Expression {
+		return datumsToConstants(types.MakeDatums(tp, n))
+	}
+	c.Assert(weightString(append([]Expression{newStr("ab", "utf8_general_ci")}, asClause("CHAR", 3)...)...), Equals, "\x00A\x00B\x00 ")
+	c.Assert(weightString(append([]Expression{newStr("abc", "utf8_general_ci")}, asClause("CHAR", 2)...)...), Equals, "\x00A\x00B")
+	c.Assert(weightString(append([]Expression{newStr("ab", "utf8_general_ci")}, asClause("BINARY", 3)...)...), Equals, "ab\x00")
+	c.Assert(weightString(append([]Expression{newStr("abc", "utf8_general_ci")}, asClause("BINARY", 1)...)...), Equals, "a")
+
+	f, err := funcs[ast.WeightString].getFunction(datumsToConstants(types.MakeDatums(nil)), s.ctx)
+	c.Assert(err, IsNil)
+	d, err := f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.IsNull(), IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestToBase64AndFromBase64(c *C) {
+	defer testleak.AfterTest(c)()
+	evalFunc := func(name string, arg interface{}) types.Datum {
+		f, err := funcs[name].getFunction(datumsToConstants(types.MakeDatums(arg)), s.ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		return d
+	}
+
+	tbl := []struct {
+		input  interface{}
+		result interface{}
+	}{
+		{"", ""},
+		{"abc", "YWJj"},
+		{"abcd", "YWJjZA=="},
+		{"你好", "5L2g5aW9"},
+		{123, "MTIz"},
+		{nil, nil},
+	}
+	for _, t := range tbl {
+		d := evalFunc(ast.ToBase64, t.input)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.result))
+		if t.input == nil {
+			d = evalFunc(ast.FromBase64, d.GetValue())
+			c.Assert(d.IsNull(), IsTrue)
+			continue
+		}
+		d = evalFunc(ast.FromBase64, d.GetValue())
+		input := types.NewDatum(t.input)
+		str, err := input.ToString()
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(str))
+	}
+
+	// The output of TO_BASE64 is wrapped every 76 characters.
+	long := strings.Repeat("abc", 60)
+	d := evalFunc(ast.ToBase64, long)
+	lines := strings.Split(d.GetString(), "\n")
+	c.Assert(lines, HasLen, 4)
+	for _, line := range lines[:3] {
+		c.Assert(line, HasLen, 76)
+	}
+	c.Assert(lines[3], Equals, strings.Repeat("YWJj", 3))
+	c.Assert(evalFunc(ast.FromBase64, d.GetValue()), testutil.DatumEquals, types.NewDatum(long))
+
+	// FROM_BASE64 returns NULL for invalid base64.
+	for _, input := range []string{"YWJ", "YW?j", "YWJj=", "#"} {
+		d = evalFunc(ast.FromBase64, input)
+		c.Assert(d.IsNull(), IsTrue)
+	}
+}
+
+func (s *testEvaluatorSuite) TestMakeSetAndExportSet(c *C) {
+	defer testleak.AfterTest(c)()
+	evalFunc := func(name string, args ...interface{}) types.Datum {
+		f, err := funcs[name].getFunction(datumsToConstants(types.MakeDatums(args...)), s.ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		return d
+	}
+
+	// The 64th string of MAKE_SET is the member of the highest bit.
+	members := make([]interface{}, 0, 65)
+	for i := 0; i < 65; i++ {
+		members = append(members, fmt.Sprintf("m%d", i))
+	}
+	makeSetTbl := []struct {
+		args   []interface{}
+		result interface{}
+	}{
+		{[]interface{}{1, "a", "b", "c"}, "a"},
+		{[]interface{}{1 | 4, "hello", "nice", "world"}, "hello,world"},
+		{[]interface{}{1 | 4, "hello", "nice", nil, "world"}, "hello"},
+		{[]interface{}{0, "a", "b", "c"}, ""},
+		{[]interface{}{nil, "a", "b", "c"}, nil},
+		{append([]interface{}{uint64(1 << 63)}, members...), "m63"},
+		{append([]interface{}{int64(-1) << 62}, members...), "m62,m63"},
+	}
+	for _, t := range makeSetTbl {
+		c.Assert(evalFunc(ast.MakeSet, t.args...), testutil.DatumEquals, types.NewDatum(t.result))
+	}
+
+	exportSetTbl := []struct {
+		args   []interface{}
+		result interface{}
+	}{
+		{[]interface{}{5, "Y", "N", ",", 4}, "Y,N,Y,N"},
+		{[]interface{}{6, "1", "0", "", 10}, "0110000000"},
+		{[]interface{}{6, "1", "0", "", -1}, "011" + strings.Repeat("0", 61)},
+		{[]interface{}{uint64(1<<63 | 1), "1", "0", "", 100}, "1" + strings.Repeat("0", 62) + "1"},
+		{[]interface{}{int64(-1), "1", "0"}, strings.Repeat("1,", 63) + "1"},
+		{[]interface{}{uint64(1 << 63), "1", "0", ""}, strings.Repeat("0", 63) + "1"},
+		{[]interface{}{nil, "1", "0"}, nil},
+		{[]interface{}{5, "1", nil}, nil},
+	}
+	for _, t := range exportSetTbl {
+		c.Assert(evalFunc(ast.ExportSet, t.args...), testutil.DatumEquals, types.NewDatum(t.result))
+	}
+}
+
+func (s *testEvaluatorSuite) TestBinAndOct(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		arg interface{}
+		bin interface{}
+		oct interface{}
+	}{
+		{12, "1100", "14"},
+		{0, "0", "0"},
+		{"12", "1100", "14"},
+		{12.5, "1101", "15"},
+		{uint64(18446744073709551615), strings.Repeat("1", 64), "1777777777777777777777"},
+		// A negative integer is formatted as its 64-bit two's complement.
+		{-1, strings.Repeat("1", 64), "1777777777777777777777"},
+		{-8, strings.Repeat("1", 60) + "1000", "1777777777777777777770"},
+		{int64(math.MinInt64), "1" + strings.Repeat("0", 63), "1000000000000000000000"},
+		{nil, nil, nil},
+	}
+	for _, t := range tbl {
+		d, err := evalFuncClass(ast.Bin, types.MakeDatums(t.arg), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.bin), Commentf("%v", t.arg))
+		d, err = evalFuncClass(ast.Oct, types.MakeDatums(t.arg), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.oct), Commentf("%v", t.arg))
+	}
+}
+
+func (s *testEvaluatorSuite) TestOrd(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		arg interface{}
+		ret interface{}
+	}{
+		{"2", int64(50)},
+		{2, int64(50)},
+		{"abc", int64(97)},
+		{"", int64(0)},
+		{"é", int64(0xc3a9)},
+		{"数据库", int64(0xe695b0)},
+		{"😀", int64(0xf09f9880)},
+		{nil, nil},
+	}
+	for _, t := range tbl {
+		d, err := evalFuncClass(ast.Ord, types.MakeDatums(t.arg), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%v", t.arg))
+	}
+
+	// The character is taken in the charset of the argument.
+	ord := func(str, cs, collation string) int64 {
+		tp := types.NewFieldType(mysql.TypeVarchar)
+		tp.Charset, tp.Collate = cs, collation
+		f, err := funcs[ast.Ord].getFunction([]Expression{&Constant{Value: types.NewDatum(str), RetType: tp}}, s.ctx)
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		return d.GetInt64()
+	}
+	// 'é' is 0xE9 in latin1 and 0xC3A9 in utf8mb4.
+	c.Assert(ord("\xe9", "latin1", "latin1_bin"), Equals, int64(0xe9))
+	c.Assert(ord("é", charset.CharsetUTF8MB4, charset.CollationUTF8MB4), Equals, int64(0xc3a9))
+	c.Assert(ord("é", "latin1", "latin1_bin"), Equals, int64(0xc3))
+	c.Assert(ord("é", charset.CharsetBin, charset.CollationBin), Equals, int64(0xc3))
 }
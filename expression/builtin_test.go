@@ -14,9 +14,16 @@
 package expression
 
 import (
+	"math"
 	"reflect"
+	"time"
 
+	"github.com/juju/errors"
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
 	"github.com/pingcap/tidb/util/types"
@@ -57,17 +64,174 @@ func makeDatums(i interface{}) []types.Datum {
 	return types.MakeDatums(i)
 }
 
+// datumsToConstants converts datums to constant expressions, which are used as function arguments in test.
+func datumsToConstants(datums []types.Datum) []Expression {
+	constants := make([]Expression, 0, len(datums))
+	for _, d := range datums {
+		constants = append(constants, &Constant{Value: d})
+	}
+	return constants
+}
+
+// evalFuncClass evaluates the function class named name with the constant arguments in test.
+func evalFuncClass(name string, args []types.Datum, ctx context.Context) (types.Datum, error) {
+	f, err := funcs[name].getFunction(datumsToConstants(args), ctx)
+	if err != nil {
+		return types.Datum{}, err
+	}
+	return f.eval(nil)
+}
+
+// funcClassEvaluator returns a BuiltinFunc evaluating the function class named name, so evalDtbl can test it.
+func funcClassEvaluator(name string) BuiltinFunc {
+	return func(args []types.Datum, ctx context.Context) (types.Datum, error) {
+		return evalFuncClass(name, args, ctx)
+	}
+}
+
+// evalDtbl evaluates f for each row of a table built by tblToDtbl and returns the results.
+// The arguments of a row are the datums of the columns named argCols, in order.
+func evalDtbl(f BuiltinFunc, dtbl []map[string][]types.Datum, argCols []string, ctx context.Context) ([]types.Datum, error) {
+	results := make([]types.Datum, 0, len(dtbl))
+	for i, row := range dtbl {
+		var args []types.Datum
+		for _, col := range argCols {
+			datums, ok := row[col]
+			if !ok {
+				return nil, errors.Errorf("row %d has no column %s", i, col)
+			}
+			args = append(args, datums...)
+		}
+		d, err := f(args, ctx)
+		if err != nil {
+			return nil, errors.Annotatef(err, "row %d", i)
+		}
+		results = append(results, d)
+	}
+	return results, nil
+}
+
+// countingExpr is a mock expression which records how many times it is evaluated.
+type countingExpr struct {
+	*Constant
+	count int
+}
+
+func (e *countingExpr) Eval(row []types.Datum, ctx context.Context) (types.Datum, error) {
+	e.count++
+	return e.Constant.Eval(row, ctx)
+}
+
+// panicExpr is a mock expression which panics if it is evaluated.
+type panicExpr struct {
+	*Constant
+}
+
+func (e *panicExpr) Eval(row []types.Datum, ctx context.Context) (types.Datum, error) {
+	panic("the expression should not be evaluated")
+}
+
+func (s *testEvaluatorSuite) TestEvalDtbl(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		Arg interface{}
+		Ret interface{}
+	}{
+		{nil, nil},
+		{int64(-1), int64(1)},
+		{uint64(2), uint64(2)},
+		{float64(-3.5), float64(3.5)},
+	}
+	dtbl := tblToDtbl(tbl)
+	results, err := evalDtbl(builtinAbs, dtbl, []string{"Arg"}, s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(results, HasLen, len(tbl))
+	for i, t := range dtbl {
+		c.Assert(results[i], testutil.DatumEquals, t["Ret"][0])
+	}
+
+	// The datums of several columns are the arguments in order.
+	tbl2 := []struct {
+		X   interface{}
+		Y   interface{}
+		Ret interface{}
+	}{
+		{int64(1), int64(2), int64(3)},
+		{int64(-1), nil, nil},
+	}
+	dtbl = tblToDtbl(tbl2)
+	results, err = evalDtbl(funcClassEvaluator(ast.Plus), dtbl, []string{"X", "Y"}, s.ctx)
+	c.Assert(err, IsNil)
+	for i, t := range dtbl {
+		c.Assert(results[i], testutil.DatumEquals, t["Ret"][0])
+	}
+
+	_, err = evalDtbl(builtinAbs, dtbl, []string{"Z"}, s.ctx)
+	c.Assert(err, ErrorMatches, "row 0 has no column Z")
+	_, err = evalDtbl(builtinAbs, tblToDtbl([]struct{ Arg interface{} }{{int64(1)}, {int64(math.MinInt64)}}), []string{"Arg"}, s.ctx)
+	c.Assert(terror.ErrorEqual(errors.Cause(err), errDataOutOfRange), IsTrue)
+	c.Assert(err, ErrorMatches, "row 1: .*")
+}
+
+func (s *testEvaluatorSuite) TestInterval(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		Args []interface{}
+		Ret  int64
+	}{
+		{[]interface{}{23, 1, 15, 17, 30, 44, 200}, 3},
+		{[]interface{}{10, 1, 10, 100, 1000}, 2},
+		{[]interface{}{22, 23, 30, 44, 200}, 0},
+		{[]interface{}{200, 23, 30, 44, 200}, 4},
+		{[]interface{}{201, 23, 30, 44, 200}, 4},
+		{[]interface{}{1.5, 1, 1.5, 2}, 2},
+		{[]interface{}{"10", "1", "9", "11"}, 2},
+		{[]interface{}{nil, 1, 2, 3}, -1},
+		{[]interface{}{nil, nil}, -1},
+		{[]interface{}{3, nil, 2, 4}, 2},
+		{[]interface{}{5, 20, nil, 30}, 0},
+		{[]interface{}{-1, nil, 2}, 0},
+		{[]interface{}{5, 1, nil, 2}, 3},
+	}
+	fc := funcs[ast.Interval]
+	for _, t := range tbl {
+		f, err := fc.getFunction(datumsToConstants(types.MakeDatums(t.Args...)), s.ctx)
+		c.Assert(err, IsNil)
+		v, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(v, testutil.DatumEquals, types.NewDatum(t.Ret))
+	}
+
+	_, err := fc.getFunction(datumsToConstants(types.MakeDatums(1)), s.ctx)
+	c.Assert(err, NotNil)
+}
+
 func (s *testEvaluatorSuite) TestCoalesce(c *C) {
 	defer testleak.AfterTest(c)()
-	args := types.MakeDatums(1, nil)
-	v, err := builtinCoalesce(args, s.ctx)
+	fc := funcs[ast.Coalesce]
+	f, err := fc.getFunction(datumsToConstants(types.MakeDatums(1, nil)), s.ctx)
+	c.Assert(err, IsNil)
+	v, err := f.eval(nil)
 	c.Assert(err, IsNil)
 	c.Assert(v, testutil.DatumEquals, types.NewDatum(1))
 
-	args = types.MakeDatums(nil, nil)
-	v, err = builtinCoalesce(args, s.ctx)
+	f, err = fc.getFunction(datumsToConstants(types.MakeDatums(nil, nil)), s.ctx)
+	c.Assert(err, IsNil)
+	v, err = f.eval(nil)
 	c.Assert(err, IsNil)
 	c.Assert(v, testutil.DatumEquals, types.NewDatum(nil))
+
+	// The arguments after the first non-NULL one are not evaluated.
+	args := datumsToConstants(types.MakeDatums(nil, "a"))
+	args = append(args, &panicExpr{&Constant{Value: types.NewDatum(nil)}})
+	f, err = fc.getFunction(args, s.ctx)
+	c.Assert(err, IsNil)
+	v, err = f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(v, testutil.DatumEquals, types.NewDatum("a"))
+
+	_, err = NewFunction(ast.Coalesce, types.NewFieldType(mysql.TypeLonglong))
+	c.Assert(err, NotNil)
 }
 
 func (s *testEvaluatorSuite) TestGreatestLeastFuncs(c *C) {
@@ -76,71 +240,434 @@ func (s *testEvaluatorSuite) TestGreatestLeastFuncs(c *C) {
 	var datums []types.Datum
 
 	datums = types.MakeDatums(2, 0)
-	v, err := builtinGreatest(datums, s.ctx)
+	v, err := evalFuncClass(ast.Greatest, datums, s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.GetInt64(), Equals, int64(2))
-	v, err = builtinLeast(datums, s.ctx)
+	v, err = evalFuncClass(ast.Least, datums, s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.GetInt64(), Equals, int64(0))
 
 	datums = types.MakeDatums(34.0, 3.0, 5.0, 767.0)
-	v, err = builtinGreatest(datums, s.ctx)
+	v, err = evalFuncClass(ast.Greatest, datums, s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.GetFloat64(), Equals, float64(767.0))
-	v, err = builtinLeast(datums, s.ctx)
+	v, err = evalFuncClass(ast.Least, datums, s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.GetFloat64(), Equals, float64(3.0))
 
 	datums = types.MakeDatums("B", "A", "C")
-	v, err = builtinGreatest(datums, s.ctx)
+	v, err = evalFuncClass(ast.Greatest, datums, s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.GetString(), Equals, "C")
-	v, err = builtinLeast(datums, s.ctx)
+	v, err = evalFuncClass(ast.Least, datums, s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.GetString(), Equals, "A")
 
+	// Mixed signed and unsigned integers are compared by value.
+	datums = types.MakeDatums(uint64(math.MaxUint64), int64(-1))
+	v, err = evalFuncClass(ast.Greatest, datums, s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.Kind(), Equals, types.KindUint64)
+	c.Assert(v.GetUint64(), Equals, uint64(math.MaxUint64))
+	v, err = evalFuncClass(ast.Least, datums, s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.Kind(), Equals, types.KindInt64)
+	c.Assert(v.GetInt64(), Equals, int64(-1))
+
+	datums = types.MakeDatums(int64(math.MinInt64), uint64(math.MaxInt64+1), int64(math.MaxInt64))
+	v, err = evalFuncClass(ast.Greatest, datums, s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.GetUint64(), Equals, uint64(math.MaxInt64+1))
+	v, err = evalFuncClass(ast.Least, datums, s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.GetInt64(), Equals, int64(math.MinInt64))
+
 	// GREATEST() and LEAST() return NULL if any argument is NULL.
 	datums = types.MakeDatums(nil, 1, 2)
-	v, err = builtinGreatest(datums, s.ctx)
+	v, err = evalFuncClass(ast.Greatest, datums, s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.IsNull(), IsTrue)
-	v, err = builtinLeast(datums, s.ctx)
+	v, err = evalFuncClass(ast.Least, datums, s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.IsNull(), IsTrue)
 
 	datums = types.MakeDatums(1, nil, 2)
-	v, err = builtinGreatest(datums, s.ctx)
+	v, err = evalFuncClass(ast.Greatest, datums, s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.IsNull(), IsTrue)
+	v, err = evalFuncClass(ast.Least, datums, s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.IsNull(), IsTrue)
+
+	// NULL arguments are skipped if GreatestLeastIgnoreNull is set.
+	sessVars := s.ctx.GetSessionVars()
+	sessVars.GreatestLeastIgnoreNull = true
+	defer func() {
+		sessVars.GreatestLeastIgnoreNull = false
+	}()
+	for _, datums = range [][]types.Datum{types.MakeDatums(nil, 1, 2), types.MakeDatums(1, nil, 2), types.MakeDatums(1, 2, nil)} {
+		v, err = evalFuncClass(ast.Greatest, datums, s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, testutil.DatumEquals, types.NewDatum(2))
+		v, err = evalFuncClass(ast.Least, datums, s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, testutil.DatumEquals, types.NewDatum(1))
+	}
+
+	datums = types.MakeDatums(nil, nil)
+	v, err = evalFuncClass(ast.Greatest, datums, s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.IsNull(), IsTrue)
+	v, err = evalFuncClass(ast.Least, datums, s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.IsNull(), IsTrue)
+	sessVars.GreatestLeastIgnoreNull = false
+
+	// The result is a DECIMAL with the max scale if the arguments are decimals or integers.
+	decTbl := []struct {
+		args     []interface{}
+		greatest string
+		least    string
+	}{
+		{[]interface{}{"1.10", "1.05", "0.3"}, "1.10", "0.30"},
+		{[]interface{}{"1.50", int64(2)}, "2.00", "1.50"},
+		{[]interface{}{"-0.001", uint64(0)}, "0.000", "-0.001"},
+		{[]interface{}{"9007199254740993.5", int64(9007199254740993)}, "9007199254740993.5", "9007199254740993.0"},
+	}
+	for _, t := range decTbl {
+		datums = make([]types.Datum, 0, len(t.args))
+		for _, arg := range t.args {
+			if str, ok := arg.(string); ok {
+				datums = append(datums, types.NewDecimalDatum(types.NewDecFromStringForTest(str)))
+			} else {
+				datums = append(datums, types.NewDatum(arg))
+			}
+		}
+		v, err = evalFuncClass(ast.Greatest, datums, s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.Kind(), Equals, types.KindMysqlDecimal)
+		c.Assert(v.GetMysqlDecimal().String(), Equals, t.greatest)
+		v, err = evalFuncClass(ast.Least, datums, s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.Kind(), Equals, types.KindMysqlDecimal)
+		c.Assert(v.GetMysqlDecimal().String(), Equals, t.least)
+	}
+	datums = types.MakeDatums(types.NewDecFromStringForTest("1.5"), nil)
+	v, err = evalFuncClass(ast.Greatest, datums, s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.IsNull(), IsTrue)
-	v, err = builtinLeast(datums, s.ctx)
+
+	// Temporal values are compared as strings if they are mixed with strings, the result is a string.
+	date := types.Time{Time: types.FromDate(2017, 3, 1, 0, 0, 0, 0), Type: mysql.TypeDate}
+	datetime := types.Time{Time: types.FromDate(2017, 3, 1, 12, 0, 0, 0), Type: mysql.TypeDatetime}
+	dur := types.Duration{Duration: 10 * time.Hour}
+	strTbl := []struct {
+		args     []interface{}
+		greatest string
+		least    string
+	}{
+		{[]interface{}{date, "abc"}, "abc", "2017-03-01"},
+		{[]interface{}{date, "2017-3-2"}, "2017-3-2", "2017-03-01"},
+		{[]interface{}{date, "2017-03-01 00:00:00"}, "2017-03-01 00:00:00", "2017-03-01"},
+		{[]interface{}{"2017-12-01", date, datetime}, "2017-12-01", "2017-03-01"},
+		{[]interface{}{dur, "9:00:00"}, "9:00:00", "10:00:00"},
+		{[]interface{}{date, "", 20170302}, "20170302", ""},
+	}
+	for _, t := range strTbl {
+		datums = types.MakeDatums(t.args...)
+		v, err = evalFuncClass(ast.Greatest, datums, s.ctx)
+		c.Assert(err, IsNil, Commentf("%v", t.args))
+		c.Assert(v, testutil.DatumEquals, types.NewStringDatum(t.greatest), Commentf("%v", t.args))
+		v, err = evalFuncClass(ast.Least, datums, s.ctx)
+		c.Assert(err, IsNil, Commentf("%v", t.args))
+		c.Assert(v, testutil.DatumEquals, types.NewStringDatum(t.least), Commentf("%v", t.args))
+	}
+	// They're compared as temporal values if all the arguments are temporal.
+	datums = types.MakeDatums(datetime, date)
+	v, err = evalFuncClass(ast.Greatest, datums, s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.GetMysqlTime().String(), Equals, "2017-03-01 12:00:00")
+	v, err = evalFuncClass(ast.Least, datums, s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.GetMysqlTime().String(), Equals, "2017-03-01")
+	datums = types.MakeDatums(date, nil, "abc")
+	v, err = evalFuncClass(ast.Greatest, datums, s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.IsNull(), IsTrue)
+
+	// GREATEST() and LEAST() need at least two arguments.
+	for _, name := range []string{ast.Greatest, ast.Least} {
+		for _, datums = range [][]types.Datum{nil, types.MakeDatums(1)} {
+			_, err = evalFuncClass(name, datums, s.ctx)
+			c.Assert(terror.ErrorEqual(err, errIncorrectParameterCount), IsTrue, Commentf("%s %v", name, datums))
+			_, err = NewFunction(name, nil, datumsToConstants(datums)...)
+			c.Assert(terror.ErrorEqual(err, errIncorrectParameterCount), IsTrue)
+		}
+		_, err = evalFuncClass(name, types.MakeDatums(1, 2, 3, 4, 5), s.ctx)
+		c.Assert(err, IsNil)
+	}
 }
 
 func (s *testEvaluatorSuite) TestIsNullFunc(c *C) {
 	defer testleak.AfterTest(c)()
 
-	v, err := builtinIsNull(types.MakeDatums(1), s.ctx)
+	v, err := evalFuncClass(ast.IsNull, types.MakeDatums(1), s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.GetInt64(), Equals, int64(0))
 
-	v, err = builtinIsNull(types.MakeDatums(nil), s.ctx)
+	v, err = evalFuncClass(ast.IsNull, types.MakeDatums(nil), s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.GetInt64(), Equals, int64(1))
+
+	zeroDate := types.Time{Time: types.ZeroTime, Type: mysql.TypeDate}
+	zeroDatetime := types.Time{Time: types.ZeroTime, Type: mysql.TypeDatetime}
+	tbl := []struct {
+		arg    interface{}
+		result int64
+	}{
+		{nil, 1},
+		{int64(0), 0},
+		{uint64(1), 0},
+		{0.0, 0},
+		{"", 0},
+		{"NULL", 0},
+		{[]byte{}, 0},
+		{types.NewDecFromInt(0), 0},
+		{zeroDate, 0},
+		{zeroDatetime, 0},
+		{types.ZeroDuration, 0},
+	}
+	for _, t := range tbl {
+		v, err = evalFuncClass(ast.IsNull, types.MakeDatums(t.arg), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, DeepEquals, types.NewIntDatum(t.result), Commentf("%v", t.arg))
+	}
 }
 
-func (s *testEvaluatorSuite) TestLock(c *C) {
+func (s *testEvaluatorSuite) TestNullFuncsOnZeroDate(c *C) {
 	defer testleak.AfterTest(c)()
+	sessionVars := s.ctx.GetSessionVars()
+	defer func(strict bool) {
+		sessionVars.StrictSQLMode = strict
+	}(sessionVars.StrictSQLMode)
+
+	// COALESCE, IFNULL and ISNULL agree that a zero date is a value in any sql_mode.
+	for _, strict := range []bool{false, true} {
+		sessionVars.StrictSQLMode = strict
+		for _, tp := range []byte{mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp} {
+			zero := types.NewDatum(types.Time{Time: types.ZeroTime, Type: tp})
+			v, err := evalFuncClass(ast.IsNull, []types.Datum{zero}, s.ctx)
+			c.Assert(err, IsNil)
+			c.Assert(v, DeepEquals, types.NewIntDatum(0))
 
-	v, err := builtinLock(types.MakeDatums(1), s.ctx)
+			v, err = evalFuncClass(ast.Coalesce, []types.Datum{{}, zero, types.NewIntDatum(1)}, s.ctx)
+			c.Assert(err, IsNil)
+			c.Assert(v, testutil.DatumEquals, zero)
+
+			v, err = evalFuncClass(ast.Ifnull, []types.Datum{zero, types.NewIntDatum(1)}, s.ctx)
+			c.Assert(err, IsNil)
+			c.Assert(v, testutil.DatumEquals, zero)
+		}
+	}
+
+	// IFNULL does not evaluate expr2 if expr1 is not NULL.
+	f, err := funcs[ast.Ifnull].getFunction([]Expression{
+		&Constant{Value: types.NewDatum(types.Time{Time: types.ZeroTime, Type: mysql.TypeDate})},
+		&panicExpr{&Constant{Value: types.NewDatum(nil)}},
+	}, s.ctx)
 	c.Assert(err, IsNil)
-	c.Assert(v.GetInt64(), Equals, int64(1))
+	v, err := f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(v.IsNull(), IsFalse)
+}
+
+func (s *testEvaluatorSuite) TestRegisterFunction(c *C) {
+	defer testleak.AfterTest(c)()
+	double := func(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
+		if args[0].IsNull() {
+			return d, nil
+		}
+		x, err := args[0].ToInt64(ctx.GetSessionVars().StmtCtx)
+		if err != nil {
+			return d, err
+		}
+		d.SetInt64(x * 2)
+		return d, nil
+	}
+	err := RegisterFunction("Test_Double", Func{double, 1, 1})
+	c.Assert(err, IsNil)
+	defer unregisterFunction("test_double")
+	err = RegisterFunction("test_double", Func{double, 1, 1})
+	c.Assert(err, NotNil)
+	err = RegisterFunction(ast.Abs, Func{double, 1, 1})
+	c.Assert(err, NotNil)
+	err = RegisterFunction("ANY_VALUE", Func{double, 1, 1})
+	c.Assert(err, NotNil)
 
-	v, err = builtinLock(types.MakeDatums(nil), s.ctx)
+	col := &Column{RetType: types.NewFieldType(mysql.TypeLonglong), Index: 0}
+	f, err := NewFunction("test_double", types.NewFieldType(mysql.TypeLonglong), col)
 	c.Assert(err, IsNil)
-	c.Assert(v.GetInt64(), Equals, int64(1))
+	d, err := f.Eval(types.MakeDatums(21), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d, testutil.DatumEquals, types.NewIntDatum(42))
+	d, err = f.Eval(types.MakeDatums(nil), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d.IsNull(), IsTrue)
 
-	v, err = builtinReleaseLock(types.MakeDatums(1), s.ctx)
+	// A custom function is not constant folded.
+	f, err = NewFunction("test_double", types.NewFieldType(mysql.TypeLonglong), &Constant{Value: types.NewIntDatum(1)})
 	c.Assert(err, IsNil)
-	c.Assert(v.GetInt64(), Equals, int64(1))
+	_, ok := FoldConstant(s.ctx, f).(*ScalarFunction)
+	c.Assert(ok, IsTrue)
+
+	_, err = NewFunction("test_double", types.NewFieldType(mysql.TypeLonglong))
+	c.Assert(err, NotNil)
+	_, err = NewFunction("test_not_registered", types.NewFieldType(mysql.TypeLonglong))
+	c.Assert(err, NotNil)
+}
+
+func (s *testEvaluatorSuite) TestListFunctions(c *C) {
+	defer testleak.AfterTest(c)()
+	infos := ListFunctions()
+	m := make(map[string]FuncInfo, len(infos))
+	for i, info := range infos {
+		if i > 0 {
+			c.Assert(infos[i-1].Name < info.Name, IsTrue)
+		}
+		m[info.Name] = info
+	}
+	c.Assert(m[ast.Abs], Equals, FuncInfo{ast.Abs, 1, 1})
+	c.Assert(m[ast.Log], Equals, FuncInfo{ast.Log, 1, 2})
+	c.Assert(m[ast.Round], Equals, FuncInfo{ast.Round, 1, 2})
+	c.Assert(m[ast.Coalesce], Equals, FuncInfo{ast.Coalesce, 1, -1})
+	c.Assert(m[ast.AnyValue], Equals, FuncInfo{ast.AnyValue, 1, 1})
+	c.Assert(len(m), Equals, len(infos))
+	// The functions registered by the other tests are removed.
+	_, ok := m["test_double"]
+	c.Assert(ok, IsFalse)
+}
+
+func (s *testEvaluatorSuite) TestIncorrectParameterCount(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		name    string
+		argsCnt int
+	}{
+		{ast.Log, 0},
+		{ast.Log, 3},
+		{ast.Round, 0},
+		{ast.Round, 3},
+		{ast.Pow, 1},
+		{ast.Pow, 3},
+		{ast.AnyValue, 2},
+		{ast.Coalesce, 0},
+	}
+	for _, t := range tbl {
+		args := make([]Expression, 0, t.argsCnt)
+		for i := 0; i < t.argsCnt; i++ {
+			args = append(args, &Constant{Value: types.NewIntDatum(1)})
+		}
+		_, err := NewFunction(t.name, types.NewFieldType(mysql.TypeDouble), args...)
+		c.Assert(err, NotNil)
+		c.Assert(errIncorrectParameterCount.Equal(err), IsTrue, Commentf("%v", err))
+		c.Assert(err.Error(), Equals, "[expression:1582]Incorrect parameter count in the call to native function '"+t.name+"'")
+	}
+}
+
+func (s *testEvaluatorSuite) TestIsDeterministic(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		name          string
+		args          []types.Datum
+		deterministic bool
+	}{
+		{ast.Abs, types.MakeDatums(-1), true},
+		{ast.AnyValue, types.MakeDatums(1), true},
+		{ast.Rand, nil, false},
+		{ast.Now, nil, false},
+		{ast.Sysdate, nil, false},
+		{ast.Curdate, nil, false},
+		{ast.UUID, nil, false},
+	}
+	for _, t := range tbl {
+		f, err := NewFunction(t.name, types.NewFieldType(mysql.TypeDouble), datumsToConstants(t.args)...)
+		c.Assert(err, IsNil)
+		c.Assert(f.(*ScalarFunction).IsDeterministic(s.ctx), Equals, t.deterministic, Commentf("%s", t.name))
+		// Only a deterministic function is constant folded.
+		_, isConst := FoldConstant(s.ctx, f).(*Constant)
+		c.Assert(isConst, Equals, t.deterministic, Commentf("%s", t.name))
+	}
+}
+
+func (s *testEvaluatorSuite) TestEvalBuiltin(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		name string
+		args []interface{}
+		ret  interface{}
+	}{
+		{ast.Abs, []interface{}{-3}, int64(3)},
+		{ast.Abs, []interface{}{-1.5}, float64(1.5)},
+		{ast.Abs, []interface{}{nil}, nil},
+		{"ABS", []interface{}{-3}, int64(3)},
+		{ast.Concat, []interface{}{"a", 1, "b"}, "a1b"},
+		{ast.Concat, []interface{}{"a", nil}, nil},
+		{ast.Round, []interface{}{1.298, 1}, float64(1.3)},
+		{ast.Round, []interface{}{-1.58}, float64(-2)},
+		{ast.Round, []interface{}{1.298, nil}, nil},
+		{ast.Left, []interface{}{"abc", 2}, "ab"},
+	}
+	for _, t := range tbl {
+		d, err := EvalBuiltin(t.name, s.ctx, types.MakeDatums(t.args...)...)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.ret), Commentf("%s%v", t.name, t.args))
+	}
+
+	_, err := EvalBuiltin(ast.Abs, s.ctx)
+	c.Assert(errIncorrectParameterCount.Equal(err), IsTrue)
+	_, err = EvalBuiltin("not_a_function", s.ctx)
+	c.Assert(err, NotNil)
+}
+
+func (s *testEvaluatorSuite) TestEvalBatch(c *C) {
+	defer testleak.AfterTest(c)()
+	var rows [][]types.Datum
+	for i := -50; i <= 50; i++ {
+		row := types.MakeDatums(i, float64(i)/4)
+		if i%7 == 0 {
+			row[1] = types.Datum{}
+		}
+		rows = append(rows, row)
+	}
+	col0 := &Column{RetType: types.NewFieldType(mysql.TypeLonglong), Index: 0}
+	col1 := &Column{RetType: types.NewFieldType(mysql.TypeDouble), Index: 1}
+	newFunc := func(name string, args ...Expression) Expression {
+		f, err := NewFunction(name, nil, args...)
+		c.Assert(err, IsNil)
+		return f
+	}
+	two := datumsToConstants(types.MakeDatums(2))[0]
+	exprs := []Expression{
+		col0,
+		two,
+		newFunc(ast.Abs, col0),
+		newFunc(ast.Pow, col0, two),
+		newFunc(ast.Abs, newFunc(ast.Pow, col0, newFunc(ast.Abs, two))),
+		newFunc(ast.Greatest, newFunc(ast.Abs, col0), col1),
+	}
+	for _, expr := range exprs {
+		result := make([]types.Datum, len(rows))
+		err := EvalBatch(expr, rows, s.ctx, result)
+		c.Assert(err, IsNil)
+		for i, row := range rows {
+			d, err := expr.Eval(row, s.ctx)
+			c.Assert(err, IsNil)
+			c.Assert(result[i], testutil.DatumEquals, d, Commentf("%s %v", expr, row))
+		}
+	}
+
+	// The error of any row is returned.
+	rows = append(rows, types.MakeDatums(int64(math.MinInt64), 0))
+	err := EvalBatch(newFunc(ast.Abs, col0), rows, s.ctx, make([]types.Datum, len(rows)))
+	c.Assert(terror.ErrorEqual(err, errDataOutOfRange), IsTrue)
 }
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -74,8 +75,36 @@ func convertToDuration(sc *variable.StatementContext, arg types.Datum, fsp int)
 	return d, nil
 }
 
-func builtinDate(args []types.Datum, ctx context.Context) (types.Datum, error) {
-	return convertToTime(ctx.GetSessionVars().StmtCtx, args[0], mysql.TypeDate)
+type dateFuncClass struct {
+	baseFuncClass
+}
+
+func (c *dateFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinDate{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinDate struct {
+	baseBuiltinFunc
+}
+
+// eval extracts the date part of the argument as a DATE, an invalid argument returns NULL with a warning.
+// A TIME argument is converted to a DATETIME on the current date like MySQL does, so it results in the current date.
+// See http://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_date
+func (b *builtinDate) eval(row []types.Datum) (d types.Datum, err error) {
+	arg, err := b.args[0].Eval(row, b.ctx)
+	if err != nil || arg.IsNull() {
+		return d, errors.Trace(err)
+	}
+	sc := b.ctx.GetSessionVars().StmtCtx
+	d, err = convertToTime(sc, arg, mysql.TypeDate)
+	if err != nil {
+		return d, errors.Trace(handleInvalidTimeArg(sc, "datetime", arg, err))
+	}
+	return d, nil
 }
 
 func convertDatumToTime(sc *variable.StatementContext, d types.Datum) (t types.Time, err error) {
@@ -88,8 +117,34 @@ func convertDatumToTime(sc *variable.StatementContext, d types.Datum) (t types.T
 	return d.GetMysqlTime(), nil
 }
 
-func builtinTimeDiff(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-	sc := ctx.GetSessionVars().StmtCtx
+type timeDiffFuncClass struct {
+	baseFuncClass
+}
+
+func (c *timeDiffFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinTimeDiff{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinTimeDiff struct {
+	baseBuiltinFunc
+}
+
+// eval returns expr1 - expr2 as a TIME. A difference out of the TIME range is clamped to -838:59:59 or 838:59:59
+// with a warning, like MySQL does.
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_timediff
+func (b *builtinTimeDiff) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() || args[1].IsNull() {
+		return d, nil
+	}
+	sc := b.ctx.GetSessionVars().StmtCtx
 	t1, err := convertDatumToTime(sc, args[0])
 	if err != nil {
 		return d, errors.Trace(err)
@@ -99,8 +154,197 @@ func builtinTimeDiff(args []types.Datum, ctx context.Context) (d types.Datum, er
 		return d, errors.Trace(err)
 	}
 
-	t := t1.Sub(&t2)
-	d.SetMysqlDuration(t)
+	dur, err := clampDuration(sc, t1.Sub(&t2))
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	d.SetMysqlDuration(dur)
+	return d, nil
+}
+
+// clampDuration clamps dur to the TIME range, the overflow is handled by handleTruncateError.
+func clampDuration(sc *variable.StatementContext, dur types.Duration) (types.Duration, error) {
+	if dur.Duration <= types.MaxTime && dur.Duration >= types.MinTime {
+		return dur, nil
+	}
+	err := handleTruncateError(sc, errTruncatedWrongValue.GenByArgs("time", dur.String()))
+	return clampDurationSign(dur, dur.Duration < 0), errors.Trace(err)
+}
+
+// clampDurationSign sets dur to -838:59:59 if neg is true, otherwise to 838:59:59.
+func clampDurationSign(dur types.Duration, neg bool) types.Duration {
+	if neg {
+		dur.Duration = types.MinTime
+	} else {
+		dur.Duration = types.MaxTime
+	}
+	return dur
+}
+
+type addTimeFuncClass struct {
+	baseFuncClass
+}
+
+func (c *addTimeFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinAddTime{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinAddTime struct {
+	baseBuiltinFunc
+}
+
+// eval returns expr1 + expr2, expr2 is a TIME, the result has the larger fsp of them. The result is a DATETIME if expr1 is a DATETIME or a string with a date part,
+// otherwise it's a TIME clamped to -838:59:59 or 838:59:59. A DATETIME out of range results in NULL.
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_addtime
+func (b *builtinAddTime) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() || args[1].IsNull() {
+		return d, nil
+	}
+	sc := b.ctx.GetSessionVars().StmtCtx
+	d2, err := convertToDuration(sc, args[1], types.MaxFsp)
+	if err != nil {
+		return d, errors.Trace(handleInvalidTimeArg(sc, "time", args[1], err))
+	}
+	dur2 := d2.GetMysqlDuration()
+	fsp := datumFsp(args[0])
+	if fsp2 := datumFsp(args[1]); fsp2 > fsp {
+		fsp = fsp2
+	}
+
+	if hasDatePart(args[0]) {
+		t, err := convertDatumToTime(sc, args[0])
+		if err != nil {
+			return d, errors.Trace(handleInvalidTimeArg(sc, "datetime", args[0], err))
+		}
+		gt, err := t.Time.GoTime()
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		gt = gt.Add(dur2.Duration)
+		if gt.Year() < 1 || gt.Year() > 9999 {
+			return d, errors.Trace(handleTruncateError(sc, errDatetimeFunctionOverflow.GenByArgs("datetime")))
+		}
+		t.Time = types.FromGoTime(gt)
+		t.Fsp = fsp
+		d.SetMysqlTime(t)
+		return d, nil
+	}
+
+	d1, err := convertToDuration(sc, args[0], types.MaxFsp)
+	if err != nil {
+		return d, errors.Trace(handleInvalidTimeArg(sc, "time", args[0], err))
+	}
+	dur := d1.GetMysqlDuration()
+	dur.Duration += dur2.Duration
+	dur.Fsp = fsp
+	dur, err = clampDuration(sc, dur)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	d.SetMysqlDuration(dur)
+	return d, nil
+}
+
+// hasDatePart checks whether the argument of a time function is a DATE, DATETIME or TIMESTAMP,
+// or a string with a date part, i.e. a '-' after the year.
+func hasDatePart(arg types.Datum) bool {
+	switch arg.Kind() {
+	case types.KindMysqlTime:
+		return true
+	case types.KindString, types.KindBytes:
+		return strings.IndexByte(strings.TrimSpace(arg.GetString()), '-') > 0
+	}
+	return false
+}
+
+// datumFsp returns the fsp of a time computed from the argument d, i.e. its number of decimals up to MaxFsp.
+func datumFsp(d types.Datum) int {
+	fsp := types.MaxFsp
+	switch d.Kind() {
+	case types.KindInt64, types.KindUint64:
+		fsp = types.MinFsp
+	case types.KindMysqlDuration:
+		fsp = d.GetMysqlDuration().Fsp
+	case types.KindMysqlTime:
+		fsp = d.GetMysqlTime().Fsp
+	case types.KindMysqlDecimal:
+		_, fsp = d.GetMysqlDecimal().PrecisionAndFrac()
+	case types.KindString, types.KindBytes:
+		str := strings.TrimSpace(d.GetString())
+		fsp = types.MinFsp
+		if i := strings.LastIndexByte(str, '.'); i >= 0 {
+			fsp = len(str) - i - 1
+		}
+	}
+	if fsp > types.MaxFsp {
+		return types.MaxFsp
+	}
+	return fsp
+}
+
+type secToTimeFuncClass struct {
+	baseFuncClass
+}
+
+func (c *secToTimeFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinSecToTime{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinSecToTime struct {
+	baseBuiltinFunc
+}
+
+// eval converts the number of seconds to a TIME, keeping its fraction up to microseconds.
+// A number out of the TIME range is clamped to -838:59:59 or 838:59:59, the overflow is handled by handleTruncateError.
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_sec-to-time
+func (b *builtinSecToTime) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	sc := b.ctx.GetSessionVars().StmtCtx
+	seconds, err := args[0].ToDecimal(sc)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	dur := types.Duration{Fsp: datumFsp(args[0])}
+
+	maxSeconds := types.NewDecFromInt(int64(types.MaxTime / time.Second))
+	minSeconds := types.NewDecFromInt(int64(types.MinTime / time.Second))
+	if seconds.Compare(maxSeconds) > 0 || seconds.Compare(minSeconds) < 0 {
+		// The warning has the number of seconds like MySQL does.
+		str, err1 := args[0].ToString()
+		if err1 != nil {
+			return d, errors.Trace(err1)
+		}
+		if err = handleTruncateError(sc, errTruncatedWrongValue.GenByArgs("time", str)); err != nil {
+			return d, errors.Trace(err)
+		}
+		d.SetMysqlDuration(clampDurationSign(dur, seconds.IsNegative()))
+		return d, nil
+	}
+
+	micro, err := secondsToMicroseconds(sc, types.NewDecimalDatum(seconds))
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	dur.Duration = time.Duration(micro) * time.Microsecond
+	d.SetMysqlDuration(dur)
 	return d, nil
 }
 
@@ -121,91 +365,216 @@ func builtinDateFormat(args []types.Datum, ctx context.Context) (types.Datum, er
 	return d, nil
 }
 
+// evalDateArg evaluates the argument of the functions extracting a date field. A TIME argument has no date part,
+// so it results in a zero date whose fields are all 0. isNull is true if the argument is NULL or an invalid date.
+func (b *baseBuiltinFunc) evalDateArg(row []types.Datum) (t types.Time, isNull bool, err error) {
+	arg, err := b.args[0].Eval(row, b.ctx)
+	if err != nil || arg.IsNull() {
+		return t, true, errors.Trace(err)
+	}
+	if arg.Kind() == types.KindMysqlDuration {
+		return types.ZeroDate, false, nil
+	}
+	sc := b.ctx.GetSessionVars().StmtCtx
+	d, err := convertToTime(sc, arg, mysql.TypeDate)
+	if err != nil {
+		return t, true, errors.Trace(handleInvalidTimeArg(sc, "datetime", arg, err))
+	}
+	return d.GetMysqlTime(), false, nil
+}
+
+// evalClockArg evaluates the argument of the functions extracting a time field, a DATE argument results in 00:00:00.
+// isNull is true if the argument is NULL or an invalid time.
+func (b *baseBuiltinFunc) evalClockArg(row []types.Datum) (dur types.Duration, isNull bool, err error) {
+	arg, err := b.args[0].Eval(row, b.ctx)
+	if err != nil || arg.IsNull() {
+		return dur, true, errors.Trace(err)
+	}
+	sc := b.ctx.GetSessionVars().StmtCtx
+	d, err := convertToDuration(sc, arg, types.MaxFsp)
+	if err != nil {
+		return dur, true, errors.Trace(handleInvalidTimeArg(sc, "time", arg, err))
+	}
+	return d.GetMysqlDuration(), false, nil
+}
+
+// handleInvalidTimeArg turns the error converting the argument to a tp value into a warning, so the function returns NULL
+// like MySQL does, but it's still an error for an INSERT, UPDATE or DELETE in strict mode.
+func handleInvalidTimeArg(sc *variable.StatementContext, tp string, arg types.Datum, err error) error {
+	str, err1 := arg.ToString()
+	if err1 != nil || handleTruncateError(sc, errTruncatedWrongValue.GenByArgs(tp, str)) != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+type dayFuncClass struct {
+	baseFuncClass
+}
+
+func (c *dayFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinDay{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinDay struct {
+	baseBuiltinFunc
+}
+
+// eval returns the day of the month, DAY is a synonym for DAYOFMONTH.
 // See http://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_day
-// Day is a synonym for DayOfMonth.
-func builtinDay(args []types.Datum, ctx context.Context) (types.Datum, error) {
-	return builtinDayOfMonth(args, ctx)
+func (b *builtinDay) eval(row []types.Datum) (d types.Datum, err error) {
+	return b.evalDayOfMonth(row)
+}
+
+type hourFuncClass struct {
+	baseFuncClass
+}
+
+func (c *hourFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinHour{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinHour struct {
+	baseBuiltinFunc
 }
 
 // See http://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_hour
-func builtinHour(args []types.Datum, ctx context.Context) (types.Datum, error) {
-	d, err := convertToDuration(ctx.GetSessionVars().StmtCtx, args[0], types.MaxFsp)
-	if err != nil || d.IsNull() {
+func (b *builtinHour) eval(row []types.Datum) (d types.Datum, err error) {
+	dur, isNull, err := b.evalClockArg(row)
+	if err != nil || isNull {
 		return d, errors.Trace(err)
 	}
-
-	// No need to check type here.
-	h := int64(d.GetMysqlDuration().Hour())
-	d.SetInt64(h)
+	d.SetInt64(int64(dur.Hour()))
 	return d, nil
 }
 
+type minuteFuncClass struct {
+	baseFuncClass
+}
+
+func (c *minuteFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinMinute{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinMinute struct {
+	baseBuiltinFunc
+}
+
 // See http://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_minute
-func builtinMinute(args []types.Datum, ctx context.Context) (types.Datum, error) {
-	d, err := convertToDuration(ctx.GetSessionVars().StmtCtx, args[0], types.MaxFsp)
-	if err != nil || d.IsNull() {
+func (b *builtinMinute) eval(row []types.Datum) (d types.Datum, err error) {
+	dur, isNull, err := b.evalClockArg(row)
+	if err != nil || isNull {
 		return d, errors.Trace(err)
 	}
-
-	// No need to check type here.
-	m := int64(d.GetMysqlDuration().Minute())
-	d.SetInt64(m)
+	d.SetInt64(int64(dur.Minute()))
 	return d, nil
 }
 
+type secondFuncClass struct {
+	baseFuncClass
+}
+
+func (c *secondFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinSecond{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinSecond struct {
+	baseBuiltinFunc
+}
+
 // See http://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_second
-func builtinSecond(args []types.Datum, ctx context.Context) (types.Datum, error) {
-	d, err := convertToDuration(ctx.GetSessionVars().StmtCtx, args[0], types.MaxFsp)
-	if err != nil || d.IsNull() {
+func (b *builtinSecond) eval(row []types.Datum) (d types.Datum, err error) {
+	dur, isNull, err := b.evalClockArg(row)
+	if err != nil || isNull {
 		return d, errors.Trace(err)
 	}
-
-	// No need to check type here.
-	s := int64(d.GetMysqlDuration().Second())
-	d.SetInt64(s)
+	d.SetInt64(int64(dur.Second()))
 	return d, nil
 }
 
+type microSecondFuncClass struct {
+	baseFuncClass
+}
+
+func (c *microSecondFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinMicroSecond{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinMicroSecond struct {
+	baseBuiltinFunc
+}
+
 // See http://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_microsecond
-func builtinMicroSecond(args []types.Datum, ctx context.Context) (types.Datum, error) {
-	d, err := convertToDuration(ctx.GetSessionVars().StmtCtx, args[0], types.MaxFsp)
-	if err != nil || d.IsNull() {
+func (b *builtinMicroSecond) eval(row []types.Datum) (d types.Datum, err error) {
+	dur, isNull, err := b.evalClockArg(row)
+	if err != nil || isNull {
 		return d, errors.Trace(err)
 	}
-
-	// No need to check type here.
-	m := int64(d.GetMysqlDuration().MicroSecond())
-	d.SetInt64(m)
+	d.SetInt64(int64(dur.MicroSecond()))
 	return d, nil
 }
 
+type monthFuncClass struct {
+	baseFuncClass
+}
+
+func (c *monthFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinMonth{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinMonth struct {
+	baseBuiltinFunc
+}
+
 // See http://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_month
-func builtinMonth(args []types.Datum, ctx context.Context) (types.Datum, error) {
-	d, err := convertToTime(ctx.GetSessionVars().StmtCtx, args[0], mysql.TypeDate)
-	if err != nil || d.IsNull() {
+func (b *builtinMonth) eval(row []types.Datum) (d types.Datum, err error) {
+	t, isNull, err := b.evalDateArg(row)
+	if err != nil || isNull {
 		return d, errors.Trace(err)
 	}
-
-	// No need to check type here.
-	t := d.GetMysqlTime()
-	i := int64(0)
 	if t.IsZero() {
-		d.SetInt64(i)
+		d.SetInt64(0)
 		return d, nil
 	}
-	i = int64(t.Time.Month())
-	d.SetInt64(i)
+	d.SetInt64(int64(t.Time.Month()))
 	return d, nil
 }
 
 // See http://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_monthname
 func builtinMonthName(args []types.Datum, ctx context.Context) (types.Datum, error) {
-	d, err := builtinMonth(args, ctx)
+	d, err := convertToTime(ctx.GetSessionVars().StmtCtx, args[0], mysql.TypeDate)
 	if err != nil || d.IsNull() {
 		return d, errors.Trace(err)
 	}
 
-	mon := int(d.GetInt64())
+	mon := 0
+	if t := d.GetMysqlTime(); !t.IsZero() {
+		mon = int(t.Time.Month())
+	}
 	if mon <= 0 || mon > len(types.MonthNames) {
 		d.SetNull()
 		if mon == 0 {
@@ -262,21 +631,37 @@ func builtinDayName(args []types.Datum, ctx context.Context) (types.Datum, error
 	return d, nil
 }
 
+type dayOfMonthFuncClass struct {
+	baseFuncClass
+}
+
+func (c *dayOfMonthFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinDayOfMonth{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinDayOfMonth struct {
+	baseBuiltinFunc
+}
+
 // See http://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_dayofmonth
-func builtinDayOfMonth(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-	// TODO: some invalid format like 2000-00-00 will return 0 too.
-	d, err = convertToTime(ctx.GetSessionVars().StmtCtx, args[0], mysql.TypeDate)
-	if err != nil || d.IsNull() {
+func (b *builtinDayOfMonth) eval(row []types.Datum) (d types.Datum, err error) {
+	return b.evalDayOfMonth(row)
+}
+
+// evalDayOfMonth returns the day of the month of the argument, it's shared by DAYOFMONTH and DAY.
+func (b *baseBuiltinFunc) evalDayOfMonth(row []types.Datum) (d types.Datum, err error) {
+	t, isNull, err := b.evalDateArg(row)
+	if err != nil || isNull {
 		return d, errors.Trace(err)
 	}
-
-	// No need to check type here.
-	t := d.GetMysqlTime()
 	if t.IsZero() {
-		d.SetInt64(int64(0))
+		d.SetInt64(0)
 		return d, nil
 	}
-
 	d.SetInt64(int64(t.Time.Day()))
 	return d, nil
 }
@@ -335,13 +720,9 @@ func builtinWeek(args []types.Datum, ctx context.Context) (types.Datum, error) {
 		return d, nil
 	}
 
-	var mode int
-	if len(args) > 1 {
-		v, err := args[1].ToInt64(ctx.GetSessionVars().StmtCtx)
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-		mode = int(v)
+	mode, err := weekMode(args, ctx)
+	if err != nil {
+		return d, errors.Trace(err)
 	}
 
 	week := t.Time.Week(mode)
@@ -350,6 +731,15 @@ func builtinWeek(args []types.Datum, ctx context.Context) (types.Datum, error) {
 	return d, nil
 }
 
+// weekMode returns the mode argument of WEEK, it's the default_week_format of the session if the argument is omitted.
+func weekMode(args []types.Datum, ctx context.Context) (int, error) {
+	if len(args) > 1 {
+		mode, err := args[1].ToInt64(ctx.GetSessionVars().StmtCtx)
+		return int(mode), errors.Trace(err)
+	}
+	return int(getUintSysVar(ctx, variable.DefaultWeekFormat)), nil
+}
+
 // See http://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_weekday
 func builtinWeekDay(args []types.Datum, ctx context.Context) (types.Datum, error) {
 	d, err := convertToTime(ctx.GetSessionVars().StmtCtx, args[0], mysql.TypeDate)
@@ -381,20 +771,32 @@ func builtinWeekOfYear(args []types.Datum, ctx context.Context) (types.Datum, er
 	return builtinWeek([]types.Datum{args[0], d}, ctx)
 }
 
+type yearFuncClass struct {
+	baseFuncClass
+}
+
+func (c *yearFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinYear{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinYear struct {
+	baseBuiltinFunc
+}
+
 // See http://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_year
-func builtinYear(args []types.Datum, ctx context.Context) (types.Datum, error) {
-	d, err := convertToTime(ctx.GetSessionVars().StmtCtx, args[0], mysql.TypeDate)
-	if err != nil || d.IsNull() {
+func (b *builtinYear) eval(row []types.Datum) (d types.Datum, err error) {
+	t, isNull, err := b.evalDateArg(row)
+	if err != nil || isNull {
 		return d, errors.Trace(err)
 	}
-
-	// No need to check type here.
-	t := d.GetMysqlTime()
 	if t.IsZero() {
 		d.SetInt64(0)
 		return d, nil
 	}
-
 	d.SetInt64(int64(t.Time.Year()))
 	return d, nil
 }
@@ -414,13 +816,14 @@ func builtinYearWeek(args []types.Datum, ctx context.Context) (types.Datum, erro
 		return d, nil
 	}
 
-	var mode int64
+	// The mode is 0 if it's omitted. Unlike WEEK, YEARWEEK is not influenced by default_week_format in MySQL,
+	// see the YEARWEEK section of the reference manual above.
+	mode := int64(0)
 	if len(args) > 1 {
-		v, err := args[1].ToInt64(ctx.GetSessionVars().StmtCtx)
+		mode, err = args[1].ToInt64(ctx.GetSessionVars().StmtCtx)
 		if err != nil {
 			return d, errors.Trace(err)
 		}
-		mode = v
 	}
 
 	year, week := t.Time.YearWeek(int(mode))
@@ -502,18 +905,24 @@ func builtinFromUnixTime(args []types.Datum, ctx context.Context) (d types.Datum
 }
 
 // See https://dev.mysql.com/doc/refman/5.5/en/date-and-time-functions.html#function_str-to-date
-func builtinStrToDate(args []types.Datum, _ context.Context) (types.Datum, error) {
-	date := args[0].GetString()
-	format := args[1].GetString()
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_str-to-date
+func builtinStrToDate(args []types.Datum, ctx context.Context) (types.Datum, error) {
 	var (
 		d types.Datum
 		t types.Time
 	)
+	if args[0].IsNull() || args[1].IsNull() {
+		return d, nil
+	}
+	date := args[0].GetString()
+	format := args[1].GetString()
 
 	succ := t.StrToDate(date, format)
 	if !succ {
-		d.SetNull()
-		return d, nil
+		// An invalid date is an error in a strict INSERT, UPDATE or DELETE, otherwise the result is NULL with a warning.
+		sc := ctx.GetSessionVars().StmtCtx
+		err := handleTruncateError(sc, errWrongValueForType.GenByArgs("datetime", date, "str_to_date"))
+		return d, errors.Trace(err)
 	}
 
 	d.SetMysqlTime(t)
@@ -551,28 +960,63 @@ func builtinCurrentTime(args []types.Datum, ctx context.Context) (d types.Datum,
 	return convertToDuration(ctx.GetSessionVars().StmtCtx, d, fsp)
 }
 
-// See http://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_time
-func builtinTime(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-	if args[0].IsNull() {
-		return
+type timeFuncClass struct {
+	baseFuncClass
+}
+
+func (c *timeFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
 	}
+	sig := &builtinTime{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
 
-	str, err := args[0].ToString()
-	if err != nil {
+type builtinTime struct {
+	baseBuiltinFunc
+}
+
+// eval extracts the time part of the argument as a TIME, the fractional seconds part is kept.
+// An invalid or out of range argument returns NULL with a warning.
+// See http://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_time
+func (b *builtinTime) eval(row []types.Datum) (d types.Datum, err error) {
+	arg, err := b.args[0].Eval(row, b.ctx)
+	if err != nil || arg.IsNull() {
 		return d, errors.Trace(err)
 	}
-	idx := strings.Index(str, ".")
-	fsp := 0
-	if idx != -1 {
-		fsp = len(str) - idx - 1
+
+	var fsp int
+	switch arg.Kind() {
+	case types.KindMysqlTime:
+		fsp = arg.GetMysqlTime().Fsp
+	case types.KindMysqlDuration:
+		fsp = arg.GetMysqlDuration().Fsp
+		// The value read from a column may not carry the fsp of the column type.
+		if tp := b.args[0].GetType(); tp != nil && tp.Tp == mysql.TypeDuration && tp.Decimal > fsp {
+			fsp = tp.Decimal
+		}
+	default:
+		str, err := arg.ToString()
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		// The fsp is the number of the fractional digits, the extra digits are rounded.
+		if idx := strings.Index(str, "."); idx != -1 {
+			fsp = len(str) - idx - 1
+		}
 	}
-	sc := ctx.GetSessionVars().StmtCtx
-	fspD := types.NewIntDatum(int64(fsp))
-	if fsp, err = checkFsp(sc, fspD); err != nil {
-		return d, errors.Trace(err)
+	if fsp < types.MinFsp {
+		fsp = types.DefaultFsp
+	} else if fsp > types.MaxFsp {
+		fsp = types.MaxFsp
 	}
 
-	return convertToDuration(sc, args[0], fsp)
+	sc := b.ctx.GetSessionVars().StmtCtx
+	d, err = convertToDuration(sc, arg, fsp)
+	if err != nil {
+		return d, errors.Trace(handleInvalidTimeArg(sc, "time", arg, err))
+	}
+	return d, nil
 }
 
 // See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_utc-date
@@ -639,14 +1083,100 @@ func builtinDateArith(args []types.Datum, ctx context.Context) (d types.Datum, e
 	// args[0] -> Op
 	// args[1] -> Date
 	// args[2] -> DateArithInterval
-	// health check for date and interval
-	if args[1].IsNull() {
-		return d, nil
-	}
-	nodeDate := args[1]
+	op := args[0].GetInterface().(ast.DateArithType)
 	nodeInterval := args[2].GetInterface().(ast.DateArithInterval)
-	nodeIntervalIntervalDatum := nodeInterval.Interval.GetDatum()
-	if nodeIntervalIntervalDatum.IsNull() {
+	return dateArith(ctx, op, args[1], nodeInterval.Unit, *nodeInterval.Interval.GetDatum())
+}
+
+type adddateFuncClass struct {
+	baseFuncClass
+}
+
+func (c *adddateFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinAddDate{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinAddDate struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinAddDate.
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_adddate
+func (b *builtinAddDate) eval(row []types.Datum) (d types.Datum, err error) {
+	return b.evalDateArith(row, ast.DateAdd)
+}
+
+type subdateFuncClass struct {
+	baseFuncClass
+}
+
+func (c *subdateFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinSubDate{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinSubDate struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinSubDate.
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_subdate
+func (b *builtinSubDate) eval(row []types.Datum) (d types.Datum, err error) {
+	return b.evalDateArith(row, ast.DateSub)
+}
+
+type timestampAddFuncClass struct {
+	baseFuncClass
+}
+
+func (c *timestampAddFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinTimestampAdd{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinTimestampAdd struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinTimestampAdd, TIMESTAMPADD(unit, interval, datetime) is DATE_ADD(datetime, INTERVAL interval unit).
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_timestampadd
+func (b *builtinTimestampAdd) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	return dateArith(b.ctx, ast.DateAdd, args[2], args[0].GetString(), args[1])
+}
+
+// evalDateArith evaluates ADDDATE and SUBDATE. With an INTERVAL as the second argument they're synonyms
+// of DATE_ADD and DATE_SUB, otherwise the second argument is a number of days.
+func (b *baseBuiltinFunc) evalDateArith(row []types.Datum, op ast.DateArithType) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[1].Kind() == types.KindInterface {
+		interval := args[1].GetInterface().(ast.DateArithInterval)
+		return dateArith(b.ctx, op, args[0], interval.Unit, *interval.Interval.GetDatum())
+	}
+	return dateArith(b.ctx, op, args[0], "day", args[1])
+}
+
+// dateArith adds the interval of the unit to the date, or subtracts it if op is DateSub.
+// It's shared by DATE_ADD, DATE_SUB, ADDDATE, SUBDATE and TIMESTAMPADD, the result is NULL if the date or the interval is NULL.
+func dateArith(ctx context.Context, op ast.DateArithType, nodeDate types.Datum, unit string, interval types.Datum) (d types.Datum, err error) {
+	// health check for date and interval
+	if nodeDate.IsNull() || interval.IsNull() {
 		return d, nil
 	}
 	// parse date
@@ -672,7 +1202,7 @@ func builtinDateArith(args []types.Datum, ctx context.Context) (d types.Datum, e
 		}
 	}
 	sc := ctx.GetSessionVars().StmtCtx
-	if types.IsClockUnit(nodeInterval.Unit) {
+	if types.IsClockUnit(unit) {
 		fieldType = mysql.TypeDatetime
 	}
 	resultField = types.NewFieldType(fieldType)
@@ -689,29 +1219,36 @@ func builtinDateArith(args []types.Datum, ctx context.Context) (d types.Datum, e
 	}
 	result := value.GetMysqlTime()
 	// parse interval
-	var interval string
-	if strings.ToLower(nodeInterval.Unit) == "day" {
-		day, err1 := parseDayInterval(sc, *nodeIntervalIntervalDatum)
+	var intervalStr string
+	if strings.ToLower(unit) == "day" {
+		day, err1 := parseDayInterval(sc, interval)
 		if err1 != nil {
-			return d, errInvalidOperation.Gen("DateArith invalid day interval, need int but got %T", nodeIntervalIntervalDatum.GetString())
+			return d, errInvalidOperation.Gen("DateArith invalid day interval, need int but got %T", interval.GetString())
 		}
-		interval = fmt.Sprintf("%d", day)
+		intervalStr = fmt.Sprintf("%d", day)
+	} else if strings.ToLower(unit) == "second" && interval.Kind() != types.KindInt64 &&
+		interval.Kind() != types.KindUint64 {
+		// A fractional number of seconds keeps its microseconds like MySQL does.
+		micro, err1 := secondsToMicroseconds(sc, interval)
+		if err1 != nil {
+			return d, errors.Trace(err1)
+		}
+		intervalStr, unit = strconv.FormatInt(micro, 10), "MICROSECOND"
 	} else {
-		if nodeIntervalIntervalDatum.Kind() == types.KindString {
-			interval = fmt.Sprintf("%v", nodeIntervalIntervalDatum.GetString())
+		if interval.Kind() == types.KindString {
+			intervalStr = fmt.Sprintf("%v", interval.GetString())
 		} else {
-			ii, err1 := nodeIntervalIntervalDatum.ToInt64(sc)
+			ii, err1 := interval.ToInt64(sc)
 			if err1 != nil {
 				return d, errors.Trace(err1)
 			}
-			interval = fmt.Sprintf("%v", ii)
+			intervalStr = fmt.Sprintf("%v", ii)
 		}
 	}
-	year, month, day, duration, err := types.ExtractTimeValue(nodeInterval.Unit, interval)
+	year, month, day, duration, err := types.ExtractTimeValue(unit, intervalStr)
 	if err != nil {
 		return d, errors.Trace(err)
 	}
-	op := args[0].GetInterface().(ast.DateArithType)
 	if op == ast.DateSub {
 		year, month, day, duration = -year, -month, -day, -duration
 	}
@@ -729,6 +1266,24 @@ func builtinDateArith(args []types.Datum, ctx context.Context) (d types.Datum, e
 	return d, nil
 }
 
+// secondsToMicroseconds converts a number of seconds to microseconds, rounding the fraction to MaxFsp digits.
+func secondsToMicroseconds(sc *variable.StatementContext, seconds types.Datum) (int64, error) {
+	dec, err := seconds.ToDecimal(sc)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	// Round into a new decimal, dec may be the one held by the datum.
+	micro := new(types.MyDecimal)
+	if err = dec.Round(micro, types.MaxFsp); err != nil {
+		return 0, errors.Trace(err)
+	}
+	if err = micro.Shift(types.MaxFsp); err != nil {
+		return 0, errors.Trace(err)
+	}
+	v, err := micro.ToInt()
+	return v, errors.Trace(err)
+}
+
 var reg = regexp.MustCompile(`[\d]+`)
 
 func parseDayInterval(sc *variable.StatementContext, value types.Datum) (int64, error) {
@@ -19,6 +19,10 @@ import (
 	"time"
 
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
@@ -37,7 +41,7 @@ func (s *testEvaluatorSuite) TestDate(c *C) {
 	}
 	dtblDate := tblToDtbl(tblDate)
 	for _, t := range dtblDate {
-		v, err := builtinDate(t["Input"], s.ctx)
+		v, err := evalFuncClass(ast.Date, t["Input"], s.ctx)
 		c.Assert(err, IsNil)
 		if v.Kind() != types.KindMysqlTime {
 			c.Assert(v, testutil.DatumEquals, t["Expect"][0])
@@ -46,6 +50,41 @@ func (s *testEvaluatorSuite) TestDate(c *C) {
 		}
 	}
 
+	// The time part of a DATETIME is zeroed, and a TIME is on the current date.
+	datetime, err := types.ParseTime("2017-03-04 05:06:07.123456", mysql.TypeDatetime, types.MaxFsp)
+	c.Assert(err, IsNil)
+	date, err := types.ParseDate("2017-03-04")
+	c.Assert(err, IsNil)
+	duration, err := types.ParseDuration("05:06:07", 0)
+	c.Assert(err, IsNil)
+	for _, arg := range []types.Datum{types.NewDatum(datetime), types.NewDatum(date)} {
+		v, err := evalFuncClass(ast.Date, []types.Datum{arg}, s.ctx)
+		c.Assert(err, IsNil)
+		t := v.GetMysqlTime()
+		c.Assert(t.Type, Equals, mysql.TypeDate)
+		c.Assert(t.String(), Equals, "2017-03-04")
+	}
+	v, err := evalFuncClass(ast.Date, []types.Datum{types.NewDatum(duration)}, s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.GetMysqlTime().String(), Equals, time.Now().Format("2006-01-02"))
+
+	// An invalid argument returns NULL with a warning, but it's an error in strict mode.
+	_, err = evalFuncClass(ast.Date, types.MakeDatums("2011-13-45"), s.ctx)
+	c.Assert(err, NotNil)
+	sc := s.ctx.GetSessionVars().StmtCtx
+	oldIgnoreTruncate := sc.IgnoreTruncate
+	defer func() {
+		sc.IgnoreTruncate = oldIgnoreTruncate
+	}()
+	sc.IgnoreTruncate = true
+	warnCnt := len(sc.GetWarnings())
+	v, err = evalFuncClass(ast.Date, types.MakeDatums("2011-13-45"), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.IsNull(), IsTrue)
+	c.Assert(sc.GetWarnings(), HasLen, warnCnt+1)
+	c.Assert(sc.GetWarnings()[warnCnt].Error(), Matches, ".*Truncated incorrect datetime value: '2011-13-45'")
+	sc.IgnoreTruncate = oldIgnoreTruncate
+
 	// test year, month and day
 	tbl := []struct {
 		Input      string
@@ -69,11 +108,11 @@ func (s *testEvaluatorSuite) TestDate(c *C) {
 	dtbl := tblToDtbl(tbl)
 	for ith, t := range dtbl {
 		args := t["Input"]
-		v, err := builtinYear(args, s.ctx)
+		v, err := evalFuncClass(ast.Year, args, s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(v, testutil.DatumEquals, t["Year"][0])
 
-		v, err = builtinMonth(args, s.ctx)
+		v, err = evalFuncClass(ast.Month, args, s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(v, testutil.DatumEquals, t["Month"][0])
 
@@ -81,7 +120,7 @@ func (s *testEvaluatorSuite) TestDate(c *C) {
 		c.Assert(err, IsNil)
 		c.Assert(v, testutil.DatumEquals, t["MonthName"][0])
 
-		v, err = builtinDayOfMonth(args, s.ctx)
+		v, err = evalFuncClass(ast.DayOfMonth, args, s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(v, testutil.DatumEquals, t["DayOfMonth"][0])
 
@@ -136,11 +175,11 @@ func (s *testEvaluatorSuite) TestDate(c *C) {
 	dtblNil := tblToDtbl(tblNil)
 	for _, t := range dtblNil {
 		args := t["Input"]
-		v, err := builtinYear(args, s.ctx)
+		v, err := evalFuncClass(ast.Year, args, s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(v, testutil.DatumEquals, t["Year"][0])
 
-		v, err = builtinMonth(args, s.ctx)
+		v, err = evalFuncClass(ast.Month, args, s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(v, testutil.DatumEquals, t["Month"][0])
 
@@ -148,7 +187,7 @@ func (s *testEvaluatorSuite) TestDate(c *C) {
 		c.Assert(err, IsNil)
 		c.Assert(v, testutil.DatumEquals, t["MonthName"][0])
 
-		v, err = builtinDayOfMonth(args, s.ctx)
+		v, err = evalFuncClass(ast.DayOfMonth, args, s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(v, testutil.DatumEquals, t["DayOfMonth"][0])
 
@@ -182,6 +221,47 @@ func (s *testEvaluatorSuite) TestDate(c *C) {
 	}
 }
 
+func (s *testEvaluatorSuite) TestDefaultWeekFormat(c *C) {
+	defer testleak.AfterTest(c)()
+	sessVars := s.ctx.GetSessionVars()
+	defer delete(sessVars.Systems, variable.DefaultWeekFormat)
+
+	tbl := []struct {
+		format string
+		week   int64
+	}{
+		{"0", 7},
+		{"1", 8},
+		{"2", 7},
+		{"3", 8},
+	}
+	for _, t := range tbl {
+		sessVars.Systems[variable.DefaultWeekFormat] = t.format
+		v, err := builtinWeek(types.MakeDatums("2008-02-20"), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.GetInt64(), Equals, t.week, Commentf("default_week_format %s", t.format))
+		// YEARWEEK ignores default_week_format, its mode is 0 if it's omitted.
+		v, err = builtinYearWeek(types.MakeDatums("1987-01-01"), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.GetInt64(), Equals, int64(198652), Commentf("default_week_format %s", t.format))
+		v, err = builtinYearWeek(types.MakeDatums("1987-01-01", 1), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.GetInt64(), Equals, int64(198701))
+
+		// An explicit mode overrides default_week_format.
+		v, err = builtinWeek(types.MakeDatums("2008-02-20", 0), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.GetInt64(), Equals, int64(7))
+		v, err = builtinYearWeek(types.MakeDatums("1987-01-01", 0), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.GetInt64(), Equals, int64(198652))
+		// WEEKOFYEAR always uses mode 3.
+		v, err = builtinWeekOfYear(types.MakeDatums("2008-02-20"), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.GetInt64(), Equals, int64(8))
+	}
+}
+
 func (s *testEvaluatorSuite) TestDateFormat(c *C) {
 	defer testleak.AfterTest(c)()
 
@@ -235,45 +315,45 @@ func (s *testEvaluatorSuite) TestClock(c *C) {
 
 	dtbl := tblToDtbl(tbl)
 	for _, t := range dtbl {
-		v, err := builtinHour(t["Input"], s.ctx)
+		v, err := evalFuncClass(ast.Hour, t["Input"], s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(v, testutil.DatumEquals, t["Hour"][0])
 
-		v, err = builtinMinute(t["Input"], s.ctx)
+		v, err = evalFuncClass(ast.Minute, t["Input"], s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(v, testutil.DatumEquals, t["Minute"][0])
 
-		v, err = builtinSecond(t["Input"], s.ctx)
+		v, err = evalFuncClass(ast.Second, t["Input"], s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(v, testutil.DatumEquals, t["Second"][0])
 
-		v, err = builtinMicroSecond(t["Input"], s.ctx)
+		v, err = evalFuncClass(ast.MicroSecond, t["Input"], s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(v, testutil.DatumEquals, t["MicroSecond"][0])
 
-		v, err = builtinTime(t["Input"], s.ctx)
+		v, err = evalFuncClass(ast.Time, t["Input"], s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(v, testutil.DatumEquals, t["Time"][0])
 	}
 
 	// nil
-	v, err := builtinHour(types.MakeDatums(nil), s.ctx)
+	v, err := evalFuncClass(ast.Hour, types.MakeDatums(nil), s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.Kind(), Equals, types.KindNull)
 
-	v, err = builtinMinute(types.MakeDatums(nil), s.ctx)
+	v, err = evalFuncClass(ast.Minute, types.MakeDatums(nil), s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.Kind(), Equals, types.KindNull)
 
-	v, err = builtinSecond(types.MakeDatums(nil), s.ctx)
+	v, err = evalFuncClass(ast.Second, types.MakeDatums(nil), s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.Kind(), Equals, types.KindNull)
 
-	v, err = builtinMicroSecond(types.MakeDatums(nil), s.ctx)
+	v, err = evalFuncClass(ast.MicroSecond, types.MakeDatums(nil), s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.Kind(), Equals, types.KindNull)
 
-	v, err = builtinTime(types.MakeDatums(nil), s.ctx)
+	v, err = evalFuncClass(ast.Time, types.MakeDatums(nil), s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(v.Kind(), Equals, types.KindNull)
 
@@ -285,23 +365,125 @@ func (s *testEvaluatorSuite) TestClock(c *C) {
 
 	for _, t := range errTbl {
 		td := types.MakeDatums(t)
-		_, err := builtinHour(td, s.ctx)
+		_, err := evalFuncClass(ast.Hour, td, s.ctx)
 		c.Assert(err, NotNil)
 
-		_, err = builtinMinute(td, s.ctx)
+		_, err = evalFuncClass(ast.Minute, td, s.ctx)
 		c.Assert(err, NotNil)
 
-		_, err = builtinSecond(td, s.ctx)
+		_, err = evalFuncClass(ast.Second, td, s.ctx)
 		c.Assert(err, NotNil)
 
-		_, err = builtinMicroSecond(td, s.ctx)
+		_, err = evalFuncClass(ast.MicroSecond, td, s.ctx)
 		c.Assert(err, NotNil)
 
-		_, err = builtinTime(td, s.ctx)
+		_, err = evalFuncClass(ast.Time, td, s.ctx)
 		c.Assert(err, NotNil)
 	}
 }
 
+func (s *testEvaluatorSuite) TestTimeFieldExtractors(c *C) {
+	defer testleak.AfterTest(c)()
+	date, err := types.ParseDate("2017-03-04")
+	c.Assert(err, IsNil)
+	datetime, err := types.ParseTime("2017-03-04 05:06:07.123456", mysql.TypeDatetime, types.MaxFsp)
+	c.Assert(err, IsNil)
+	duration, err := types.ParseDuration("05:06:07.123456", types.MaxFsp)
+	c.Assert(err, IsNil)
+
+	fields := []string{ast.Year, ast.Month, ast.Day, ast.DayOfMonth, ast.Hour, ast.Minute, ast.Second, ast.MicroSecond}
+	tbl := []struct {
+		arg    types.Datum
+		result []int64
+	}{
+		// The time fields of a DATE are 0.
+		{types.NewDatum(date), []int64{2017, 3, 4, 4, 0, 0, 0, 0}},
+		{types.NewDatum(datetime), []int64{2017, 3, 4, 4, 5, 6, 7, 123456}},
+		// The date fields of a TIME are 0.
+		{types.NewDatum(duration), []int64{0, 0, 0, 0, 5, 6, 7, 123456}},
+		{types.NewDatum("2017-03-04 05:06:07"), []int64{2017, 3, 4, 4, 5, 6, 7, 0}},
+	}
+	for _, t := range tbl {
+		for i, name := range fields {
+			v, err := evalFuncClass(name, []types.Datum{t.arg}, s.ctx)
+			c.Assert(err, IsNil)
+			c.Assert(v, testutil.DatumEquals, types.NewDatum(t.result[i]), Commentf("%s(%v)", name, t.arg.GetValue()))
+		}
+	}
+
+	// NULL returns NULL.
+	for _, name := range fields {
+		v, err := evalFuncClass(name, types.MakeDatums(nil), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.IsNull(), IsTrue)
+	}
+
+	// An invalid argument returns NULL with a warning, but it's an error in strict mode.
+	sc := s.ctx.GetSessionVars().StmtCtx
+	oldIgnoreTruncate := sc.IgnoreTruncate
+	defer func() {
+		sc.IgnoreTruncate = oldIgnoreTruncate
+	}()
+	sc.IgnoreTruncate = true
+	for _, name := range fields {
+		warnCnt := len(sc.GetWarnings())
+		v, err := evalFuncClass(name, types.MakeDatums("2011-11-11 10:10:10.11.12"), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.IsNull(), IsTrue, Commentf("%s", name))
+		c.Assert(sc.GetWarnings(), HasLen, warnCnt+1)
+		c.Assert(terror.ErrorEqual(sc.GetWarnings()[warnCnt], errTruncatedWrongValue), IsTrue)
+	}
+	sc.IgnoreTruncate = false
+	_, err = evalFuncClass(ast.Hour, types.MakeDatums("2011-11-11T10:10:10.11"), s.ctx)
+	c.Assert(err, NotNil)
+}
+
+func (s *testEvaluatorSuite) TestTime(c *C) {
+	defer testleak.AfterTest(c)()
+	datetime, err := types.ParseTime("2017-03-04 05:06:07.1234", mysql.TypeDatetime, 4)
+	c.Assert(err, IsNil)
+	duration, err := types.ParseDuration("-05:06:07.12", 2)
+	c.Assert(err, IsNil)
+	tbl := []struct {
+		arg    types.Datum
+		result string
+		fsp    int
+	}{
+		{types.NewDatum(datetime), "05:06:07.1234", 4},
+		{types.NewDatum(duration), "-05:06:07.12", 2},
+		{types.NewDatum("2017-03-04 05:06:07"), "05:06:07", 0},
+		// A TIME can exceed 24 hours.
+		{types.NewDatum("100:00:00"), "100:00:00", 0},
+		{types.NewDatum("838:59:59"), "838:59:59", 0},
+		{types.NewDatum("-100:00:00.25"), "-100:00:00.25", 2},
+		// The extra fractional digits are rounded.
+		{types.NewDatum("12:00:00.12345678"), "12:00:00.123457", types.MaxFsp},
+	}
+	for _, t := range tbl {
+		v, err := evalFuncClass(ast.Time, []types.Datum{t.arg}, s.ctx)
+		c.Assert(err, IsNil, Commentf("%v", t.arg.GetValue()))
+		c.Assert(v.Kind(), Equals, types.KindMysqlDuration)
+		c.Assert(v.GetMysqlDuration().String(), Equals, t.result)
+		c.Assert(v.GetMysqlDuration().Fsp, Equals, t.fsp)
+	}
+
+	// An out of range or invalid argument returns NULL with a warning.
+	sc := s.ctx.GetSessionVars().StmtCtx
+	oldIgnoreTruncate := sc.IgnoreTruncate
+	defer func() {
+		sc.IgnoreTruncate = oldIgnoreTruncate
+	}()
+	sc.IgnoreTruncate = true
+	for _, arg := range []string{"839:00:00", "-900:00:00", "12:00:00 am"} {
+		warnCnt := len(sc.GetWarnings())
+		v, err := evalFuncClass(ast.Time, types.MakeDatums(arg), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v.IsNull(), IsTrue, Commentf("%s", arg))
+		c.Assert(sc.GetWarnings(), HasLen, warnCnt+1)
+		c.Assert(sc.GetWarnings()[warnCnt].Error(), Matches, ".*Truncated incorrect time value: '"+arg+"'")
+	}
+}
+
 func (s *testEvaluatorSuite) TestNow(c *C) {
 	defer testleak.AfterTest(c)()
 	v, err := builtinNow(nil, s.ctx)
@@ -452,8 +634,16 @@ func (s *testEvaluatorSuite) TestStrToDate(c *C) {
 		{"2016 11 22 16 50 22", "%Y%m%d%H%i%s", true, time.Date(2016, 11, 22, 16, 50, 22, 0, time.Local)},
 		{"16-50-22 2016 11 22", "%H-%i-%s%Y%m%d", true, time.Date(2016, 11, 22, 16, 50, 22, 0, time.Local)},
 		{"16-50 2016 11 22", "%H-%i-%s%Y%m%d", false, time.Time{}},
+		{"2016-13-40", "%Y-%m-%d", false, time.Time{}},
 	}
 
+	sc := s.ctx.GetSessionVars().StmtCtx
+	oldIgnoreTruncate, oldTruncateAsWarning := sc.IgnoreTruncate, sc.TruncateAsWarning
+	defer func() {
+		sc.IgnoreTruncate, sc.TruncateAsWarning = oldIgnoreTruncate, oldTruncateAsWarning
+	}()
+	// A SELECT ignores truncation.
+	sc.IgnoreTruncate, sc.TruncateAsWarning = true, false
 	for _, test := range tests {
 		date := types.NewStringDatum(test.Date)
 		format := types.NewStringDatum(test.Format)
@@ -468,6 +658,25 @@ func (s *testEvaluatorSuite) TestStrToDate(c *C) {
 		t1, _ := value.Time.GoTime()
 		c.Assert(t1, Equals, test.Expect)
 	}
+
+	result, err := builtinStrToDate(types.MakeDatums(nil, "%Y-%m-%d"), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(result.IsNull(), IsTrue)
+
+	// An invalid date is a warning in non-strict mode and an error in strict mode.
+	args := types.MakeDatums("2016-13-40", "%Y-%m-%d")
+	sc.IgnoreTruncate, sc.TruncateAsWarning = false, true
+	warnCnt := len(sc.GetWarnings())
+	result, err = builtinStrToDate(args, s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(result.IsNull(), IsTrue)
+	c.Assert(sc.GetWarnings(), HasLen, warnCnt+1)
+	c.Assert(terror.ErrorEqual(sc.GetWarnings()[warnCnt], errWrongValueForType), IsTrue)
+
+	sc.IgnoreTruncate, sc.TruncateAsWarning = false, false
+	_, err = builtinStrToDate(args, s.ctx)
+	c.Assert(terror.ErrorEqual(err, errWrongValueForType), IsTrue)
+	c.Assert(err.Error(), Equals, "[expression:1411]Incorrect datetime value: '2016-13-40' for function str_to_date")
 }
 
 func (s *testEvaluatorSuite) TestTimeDiff(c *C) {
@@ -484,9 +693,204 @@ func (s *testEvaluatorSuite) TestTimeDiff(c *C) {
 	for _, test := range tests {
 		t1 := types.NewStringDatum(test.t1)
 		t2 := types.NewStringDatum(test.t2)
-		result, err := builtinTimeDiff([]types.Datum{t1, t2}, s.ctx)
+		result, err := evalFuncClass(ast.TimeDiff, []types.Datum{t1, t2}, s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(result.GetMysqlDuration().String(), Equals, test.expectStr)
+	}
+
+	result, err := evalFuncClass(ast.TimeDiff, types.MakeDatums(nil, "2000-01-01 00:00:00"), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(result.IsNull(), IsTrue)
+
+	// The difference out of the TIME range is clamped with a warning.
+	sc := s.ctx.GetSessionVars().StmtCtx
+	oldIgnoreTruncate := sc.IgnoreTruncate
+	defer func() {
+		sc.IgnoreTruncate = oldIgnoreTruncate
+	}()
+	sc.IgnoreTruncate = true
+	clampTests := []struct {
+		t1        string
+		t2        string
+		expectStr string
+		warning   string
+	}{
+		{"2010-01-01 00:00:00", "2000-01-01 00:00:00", "838:59:59.000000", "87672:00:00"},
+		{"2000-01-01 00:00:00", "2010-01-01 00:00:00.5", "-838:59:59.000000", "-87672:00:00.5"},
+		{"9999-12-31 23:59:59", "0000-01-01 00:00:00", "838:59:59.000000", "2562047:47:16"},
+		{"2000-02-04 22:59:59", "2000-01-01 00:00:00", "838:59:59.000000", ""},
+	}
+	for _, test := range clampTests {
+		warnCnt := len(sc.GetWarnings())
+		result, err = evalFuncClass(ast.TimeDiff, types.MakeDatums(test.t1, test.t2), s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(result.GetMysqlDuration().String(), Equals, test.expectStr)
+		if test.warning == "" {
+			c.Assert(sc.GetWarnings(), HasLen, warnCnt)
+			continue
+		}
+		c.Assert(sc.GetWarnings(), HasLen, warnCnt+1)
+		c.Assert(terror.ErrorEqual(sc.GetWarnings()[warnCnt], errTruncatedWrongValue), IsTrue)
+		c.Assert(sc.GetWarnings()[warnCnt].Error(), Matches, ".*Truncated incorrect time value: '"+test.warning+".*'")
+	}
+
+	// It's an error in strict mode.
+	sc.IgnoreTruncate = false
+	_, err = evalFuncClass(ast.TimeDiff, types.MakeDatums("2010-01-01 00:00:00", "2000-01-01 00:00:00"), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errTruncatedWrongValue), IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestAddTime(c *C) {
+	defer testleak.AfterTest(c)()
+	// Test cases from https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_addtime
+	tbl := []struct {
+		t1  interface{}
+		t2  interface{}
+		ret string
+	}{
+		{"2007-12-31 23:59:59.999999", "1 1:1:1.000002", "2008-01-02 01:01:01.000001"},
+		{"01:00:00.999999", "02:00:00.999998", "03:00:01.999997"},
+		{"-01:00:00", "00:30:00", "-00:30:00"},
+		{types.Duration{Duration: time.Hour, Fsp: 0}, "-2:00:00.5", "-01:00:00.5"},
+		{types.Time{Time: types.FromDate(2017, 1, 31, 23, 0, 0, 0), Type: mysql.TypeDatetime}, "1:00:00", "2017-02-01 00:00:00"},
+	}
+	for _, t := range tbl {
+		d, err := evalFuncClass(ast.AddTime, types.MakeDatums(t.t1, t.t2), s.ctx)
+		c.Assert(err, IsNil, Commentf("%v", t.t1))
+		str, err := d.ToString()
+		c.Assert(err, IsNil)
+		c.Assert(str, Equals, t.ret, Commentf("%v", t.t1))
+	}
+
+	d, err := evalFuncClass(ast.AddTime, types.MakeDatums(nil, "1:00:00"), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d.IsNull(), IsTrue)
+	d, err = evalFuncClass(ast.AddTime, types.MakeDatums("1:00:00", nil), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d.IsNull(), IsTrue)
+
+	sc := s.ctx.GetSessionVars().StmtCtx
+	oldIgnoreTruncate := sc.IgnoreTruncate
+	defer func() {
+		sc.IgnoreTruncate = oldIgnoreTruncate
+	}()
+	// A TIME out of range is clamped and a DATETIME out of range is NULL, both with a warning.
+	sc.IgnoreTruncate = true
+	warnCnt := len(sc.GetWarnings())
+	d, err = evalFuncClass(ast.AddTime, types.MakeDatums("838:00:00", "1:00:00"), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetMysqlDuration().String(), Equals, "838:59:59")
+	d, err = evalFuncClass(ast.AddTime, types.MakeDatums("9999-12-31 23:00:00", "1:00:00"), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d.IsNull(), IsTrue)
+	warnings := sc.GetWarnings()
+	c.Assert(warnings, HasLen, warnCnt+2)
+	c.Assert(warnings[warnCnt].Error(), Matches, ".*Truncated incorrect time value: '839:00:00'")
+	c.Assert(terror.ErrorEqual(warnings[warnCnt+1], errDatetimeFunctionOverflow), IsTrue)
+
+	// They're errors in strict mode.
+	sc.IgnoreTruncate = false
+	_, err = evalFuncClass(ast.AddTime, types.MakeDatums("838:00:00", "1:00:00"), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errTruncatedWrongValue), IsTrue)
+	_, err = evalFuncClass(ast.AddTime, types.MakeDatums("9999-12-31 23:00:00", "1:00:00"), s.ctx)
+	c.Assert(terror.ErrorEqual(err, errDatetimeFunctionOverflow), IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestSecToTime(c *C) {
+	defer testleak.AfterTest(c)()
+	// Test cases from https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_sec-to-time
+	tbl := []struct {
+		sec interface{}
+		ret string
+	}{
+		{2378, "00:39:38"},
+		{-2378, "-00:39:38"},
+		{uint64(0), "00:00:00"},
+		{types.NewDecFromStringForTest("1.5"), "00:00:01.5"},
+		{1.25, "00:00:01.250000"},
+		{"3020399", "838:59:59"},
+		{"-3020399", "-838:59:59"},
+		{3020398.9999996, "838:59:59.000000"},
+	}
+	for _, t := range tbl {
+		d, err := evalFuncClass(ast.SecToTime, types.MakeDatums(t.sec), s.ctx)
+		c.Assert(err, IsNil, Commentf("%v", t.sec))
+		c.Assert(d.GetMysqlDuration().String(), Equals, t.ret, Commentf("%v", t.sec))
+	}
+	d, err := evalFuncClass(ast.SecToTime, types.MakeDatums(nil), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d.IsNull(), IsTrue)
+
+	sc := s.ctx.GetSessionVars().StmtCtx
+	oldIgnoreTruncate, oldTruncateAsWarning := sc.IgnoreTruncate, sc.TruncateAsWarning
+	defer func() {
+		sc.IgnoreTruncate, sc.TruncateAsWarning = oldIgnoreTruncate, oldTruncateAsWarning
+	}()
+	overflowTbl := []struct {
+		sec interface{}
+		ret string
+	}{
+		{3600000, "838:59:59"},
+		{-3600000, "-838:59:59"},
+		{"3020399.5", "838:59:59.0"},
+		{"-3020399.000001", "-838:59:59.000000"},
+		{1e20, "838:59:59.000000"},
+		{types.NewDecFromStringForTest("-99999999999999999999999.9"), "-838:59:59.0"},
+	}
+	// The overflow is clamped with a warning whether the statement ignores it like a SELECT,
+	// or treats it as warning like an INSERT in non-strict mode.
+	for _, mode := range []struct{ ignore, asWarning bool }{{true, false}, {false, true}} {
+		sc.IgnoreTruncate, sc.TruncateAsWarning = mode.ignore, mode.asWarning
+		for _, t := range overflowTbl {
+			warnCnt := len(sc.GetWarnings())
+			d, err = evalFuncClass(ast.SecToTime, types.MakeDatums(t.sec), s.ctx)
+			c.Assert(err, IsNil, Commentf("%v", t.sec))
+			c.Assert(d.GetMysqlDuration().String(), Equals, t.ret, Commentf("%v", t.sec))
+			warnings := sc.GetWarnings()
+			c.Assert(warnings, HasLen, warnCnt+1, Commentf("%v", t.sec))
+			c.Assert(terror.ErrorEqual(warnings[warnCnt], errTruncatedWrongValue), IsTrue)
+		}
+	}
+	c.Assert(sc.GetWarnings()[len(sc.GetWarnings())-1].Error(), Matches,
+		".*Truncated incorrect time value: '-99999999999999999999999.9'")
+
+	// It's an error for an INSERT, UPDATE or DELETE in strict mode.
+	sc.IgnoreTruncate, sc.TruncateAsWarning = false, false
+	for _, t := range overflowTbl {
+		_, err = evalFuncClass(ast.SecToTime, types.MakeDatums(t.sec), s.ctx)
+		c.Assert(terror.ErrorEqual(err, errTruncatedWrongValue), IsTrue, Commentf("%v", t.sec))
+	}
+	d, err = evalFuncClass(ast.SecToTime, types.MakeDatums(3020399), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetMysqlDuration().String(), Equals, "838:59:59")
+}
+
+func (s *testEvaluatorSuite) TestTimestampAdd(c *C) {
+	defer testleak.AfterTest(c)()
+	// Test cases from https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_timestampadd
+	tbl := []struct {
+		unit     string
+		interval interface{}
+		date     interface{}
+		ret      string
+	}{
+		{"MINUTE", 1, "2003-01-02", "2003-01-02 00:01:00"},
+		{"WEEK", 1, "2003-01-02", "2003-01-09"},
+		{"QUARTER", -1, "2003-01-02", "2002-10-02"},
+		{"MICROSECOND", 500000, "2003-01-02 10:10:10", "2003-01-02 10:10:10.500000"},
+		// A fractional number of seconds keeps its microseconds like DATE_ADD.
+		{"SECOND", 1.5, "2003-01-02 10:10:10", "2003-01-02 10:10:11.500000"},
+		{"second", "-0.25", "2003-01-02", "2003-01-01 23:59:59.750000"},
+	}
+	for _, t := range tbl {
+		d, err := evalFuncClass(ast.TimestampAdd, types.MakeDatums(t.unit, t.interval, t.date), s.ctx)
+		c.Assert(err, IsNil, Commentf("%v", t))
+		c.Assert(d.GetMysqlTime().String(), Equals, t.ret, Commentf("%v", t))
+	}
+	for _, args := range [][]interface{}{{"DAY", nil, "2003-01-02"}, {"DAY", 1, nil}} {
+		d, err := evalFuncClass(ast.TimestampAdd, types.MakeDatums(args...), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(d.IsNull(), IsTrue)
 	}
 }
 
@@ -15,17 +15,24 @@ package expression
 
 import (
 	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/util/types"
 )
 
+// unFoldableFuncs are the deterministic functions whose calls must be kept even if all the arguments are constants,
+// COLLATE gives its argument an explicit coercibility, which a folded constant would lose.
+var unFoldableFuncs = map[string]struct{}{
+	ast.Collate: {},
+}
+
 // FoldConstant does constant folding optimization on an expression.
 func FoldConstant(ctx context.Context, expr Expression) Expression {
 	scalarFunc, ok := expr.(*ScalarFunction)
 	if !ok {
 		return expr
 	}
-	if _, isDynamic := DynamicFuncs[scalarFunc.FuncName.L]; isDynamic {
+	if !scalarFunc.IsDeterministic(ctx) {
 		return expr
 	}
 	args := scalarFunc.GetArgs()
@@ -40,10 +47,18 @@ func FoldConstant(ctx context.Context, expr Expression) Expression {
 			canFold = false
 		}
 	}
-	if !canFold {
+	// The arguments are replaced by the folded ones, so the signature must be rebuilt.
+	scalarFunc.sig = nil
+	if _, ok := unFoldableFuncs[scalarFunc.FuncName.L]; ok || !canFold {
 		return expr
 	}
-	value, err := scalarFunc.Function(datums, ctx)
+	var value types.Datum
+	var err error
+	if scalarFunc.class != nil {
+		value, err = scalarFunc.Eval(nil, ctx)
+	} else {
+		value, err = scalarFunc.Function(datums, ctx)
+	}
 	if err != nil {
 		log.Warnf("There may exist an error during constant folding. The function name is %s, args are %s", scalarFunc.FuncName, args)
 		return expr
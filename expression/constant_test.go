@@ -146,6 +146,10 @@ func (*testExpressionSuite) TestConstantFolding(c *C) {
 			condition: newFunction(ast.EQ, newColumn("a"), newFunction(ast.Rand)),
 			result:    "eq(test.t.a, rand())",
 		},
+		{
+			condition: newFunction(ast.Floor, newFunction(ast.Plus, newLonglong(1), newFunction(ast.Mul, newFunction(ast.Rand), newFunction(ast.Minus, newLonglong(10), newLonglong(1))))),
+			result:    "floor(plus(1, mul(rand(), 9)))",
+		},
 		{
 			condition: newFunction(ast.In, newColumn("a"), newLonglong(1), newLonglong(2), newLonglong(3)),
 			result:    "in(test.t.a, 1, 2, 3)",
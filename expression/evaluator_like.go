@@ -15,9 +15,11 @@ package expression
 
 import (
 	"regexp"
+	"strings"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -27,8 +29,13 @@ const (
 	patAny
 )
 
+// noEscape is the escape argument of LIKE when there is no escape character,
+// which is the default in the NO_BACKSLASH_ESCAPES sql_mode.
+const noEscape = -1
+
 // Handle escapes and wild cards convert pattern characters and pattern types.
-func compilePattern(pattern string, escape byte) (patChars, patTypes []byte) {
+// escape is the escape character or noEscape.
+func compilePattern(pattern string, escape int) (patChars, patTypes []byte) {
 	var lastAny bool
 	patChars = make([]byte, len(pattern))
 	patTypes = make([]byte, len(pattern))
@@ -36,14 +43,14 @@ func compilePattern(pattern string, escape byte) (patChars, patTypes []byte) {
 	for i := 0; i < len(pattern); i++ {
 		var tp byte
 		var c = pattern[i]
-		switch c {
-		case escape:
+		switch {
+		case int(c) == escape:
 			lastAny = false
 			tp = patMatch
 			if i < len(pattern)-1 {
 				i++
 				c = pattern[i]
-				if c == escape || c == '_' || c == '%' {
+				if int(c) == escape || c == '_' || c == '%' {
 					// valid escape.
 				} else {
 					// invalid escape, fall back to escape byte
@@ -53,13 +60,13 @@ func compilePattern(pattern string, escape byte) (patChars, patTypes []byte) {
 					// Following case is correct just for escape \, not for others like +.
 					// TODO: add more checks for other escapes.
 					i--
-					c = escape
+					c = byte(escape)
 				}
 			}
-		case '_':
+		case c == '_':
 			lastAny = false
 			tp = patOne
-		case '%':
+		case c == '%':
 			if lastAny {
 				continue
 			}
@@ -96,12 +103,19 @@ func matchByteCI(a, b byte) bool {
 	return a >= 'A' && a <= 'Z' && a+caseDiff == b
 }
 
-func doMatch(str string, patChars, patTypes []byte) bool {
+func matchByte(a, b byte, caseInsensitive bool) bool {
+	if caseInsensitive {
+		return matchByteCI(a, b)
+	}
+	return a == b
+}
+
+func doMatch(str string, patChars, patTypes []byte, caseInsensitive bool) bool {
 	var sIdx int
 	for i := 0; i < len(patChars); i++ {
 		switch patTypes[i] {
 		case patMatch:
-			if sIdx >= len(str) || !matchByteCI(str[sIdx], patChars[i]) {
+			if sIdx >= len(str) || !matchByte(str[sIdx], patChars[i], caseInsensitive) {
 				return false
 			}
 			sIdx++
@@ -116,7 +130,7 @@ func doMatch(str string, patChars, patTypes []byte) bool {
 				return true
 			}
 			for sIdx < len(str) {
-				if matchByteCI(patChars[i], str[sIdx]) && doMatch(str[sIdx:], patChars[i:], patTypes[i:]) {
+				if matchByte(patChars[i], str[sIdx], caseInsensitive) && doMatch(str[sIdx:], patChars[i:], patTypes[i:], caseInsensitive) {
 					return true
 				}
 				sIdx++
@@ -127,34 +141,216 @@ func doMatch(str string, patChars, patTypes []byte) bool {
 	return sIdx == len(str)
 }
 
-// See http://dev.mysql.com/doc/refman/5.7/en/string-comparison-functions.html
-func builtinLike(args []types.Datum, _ context.Context) (d types.Datum, err error) {
-	if args[0].IsNull() {
-		return
+type likeFuncClass struct {
+	baseFuncClass
+}
+
+// checkValid implements functionClass interface, the string and the pattern must be coerced to a common collation.
+func (c *likeFuncClass) checkValid(args []Expression) error {
+	if err := c.baseFuncClass.checkValid(args); err != nil {
+		return errors.Trace(err)
 	}
+	return errors.Trace(checkCollations(c.funcName, args[:2]))
+}
 
-	valStr, err := args[0].ToString()
+func (c *likeFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinLike{baseBuiltinFunc: newBaseBuiltinFunc(args, ctx)}
+	ci, err := LikeCaseInsensitive(args[0], args[1])
 	if err != nil {
-		return d, errors.Trace(err)
+		return nil, errors.Trace(err)
 	}
+	sig.caseInsensitive = ci
+	// The pattern is compiled only once if it's a constant.
+	pattern, ok1 := args[1].(*Constant)
+	escape, ok2 := args[2].(*Constant)
+	if ok1 && ok2 && !pattern.Value.IsNull() {
+		patternStr, err := pattern.Value.ToString()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		patChars, patTypes := compilePattern(patternStr, int(escape.Value.GetInt64()))
+		sig.matcher = newLikeMatcher(patChars, patTypes, ci)
+	}
+	return sig.setSelf(sig), nil
+}
 
-	// TODO: We don't need to compile pattern if it has been compiled or it is static.
-	if args[1].IsNull() {
-		return
+type builtinLike struct {
+	baseBuiltinFunc
+	caseInsensitive bool
+	// matcher is the precompiled constant pattern, it's nil if the pattern is not a constant.
+	matcher *likeMatcher
+}
+
+// eval matches the first argument with the pattern, the third argument is the escape character or noEscape.
+// See http://dev.mysql.com/doc/refman/5.7/en/string-comparison-functions.html#operator_like
+func (b *builtinLike) eval(row []types.Datum) (d types.Datum, err error) {
+	arg, err := b.args[0].Eval(row, b.ctx)
+	if err != nil || arg.IsNull() {
+		return d, errors.Trace(err)
 	}
-	patternStr, err := args[1].ToString()
+	valStr, err := arg.ToString()
 	if err != nil {
 		return d, errors.Trace(err)
 	}
-	escape := byte(args[2].GetInt64())
-	patChars, patTypes := compilePattern(patternStr, escape)
-	match := doMatch(valStr, patChars, patTypes)
-	d.SetInt64(boolToInt64(match))
-	return
+	if b.matcher != nil {
+		d.SetInt64(boolToInt64(b.matcher.match(valStr)))
+		return d, nil
+	}
+	pattern, err := b.args[1].Eval(row, b.ctx)
+	if err != nil || pattern.IsNull() {
+		return d, errors.Trace(err)
+	}
+	patternStr, err := pattern.ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	escape, err := b.args[2].Eval(row, b.ctx)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	patChars, patTypes := compilePattern(patternStr, int(escape.GetInt64()))
+	d.SetInt64(boolToInt64(doMatch(valStr, patChars, patTypes, b.caseInsensitive)))
+	return d, nil
 }
 
+// The kinds of likeMatcher, a pattern with only literal characters and '%' at the start or the end
+// is matched without backtracking.
+const (
+	likeMatchGeneric = iota
+	likeMatchExact
+	likeMatchPrefix
+	likeMatchSuffix
+	likeMatchContains
+)
+
+// likeMatcher is a compiled LIKE pattern.
+type likeMatcher struct {
+	kind            int
+	literal         string
+	patChars        []byte
+	patTypes        []byte
+	caseInsensitive bool
+}
+
+func newLikeMatcher(patChars, patTypes []byte, caseInsensitive bool) *likeMatcher {
+	m := &likeMatcher{kind: likeMatchGeneric, patChars: patChars, patTypes: patTypes, caseInsensitive: caseInsensitive}
+	start, end := 0, len(patTypes)
+	leadingAny := end > 0 && patTypes[0] == patAny
+	if leadingAny {
+		start++
+	}
+	trailingAny := end > start && patTypes[end-1] == patAny
+	if trailingAny {
+		end--
+	}
+	for _, tp := range patTypes[start:end] {
+		if tp != patMatch {
+			return m
+		}
+	}
+	m.literal = string(patChars[start:end])
+	if caseInsensitive && strings.ToLower(m.literal) == strings.ToUpper(m.literal) {
+		// The literal has no letters, so it can be compared byte by byte.
+		m.caseInsensitive = false
+	}
+	switch {
+	case leadingAny && trailingAny:
+		m.kind = likeMatchContains
+	case leadingAny:
+		m.kind = likeMatchSuffix
+	case trailingAny:
+		m.kind = likeMatchPrefix
+	default:
+		m.kind = likeMatchExact
+	}
+	return m
+}
+
+func (m *likeMatcher) match(str string) bool {
+	n := len(m.literal)
+	switch m.kind {
+	case likeMatchExact:
+		return len(str) == n && m.equal(str)
+	case likeMatchPrefix:
+		return len(str) >= n && m.equal(str[:n])
+	case likeMatchSuffix:
+		return len(str) >= n && m.equal(str[len(str)-n:])
+	case likeMatchContains:
+		if !m.caseInsensitive {
+			return strings.Contains(str, m.literal)
+		}
+		for i := 0; i+n <= len(str); i++ {
+			if m.equal(str[i : i+n]) {
+				return true
+			}
+		}
+		return false
+	}
+	return doMatch(str, m.patChars, m.patTypes, m.caseInsensitive)
+}
+
+// equal compares str with the literal of the same length.
+func (m *likeMatcher) equal(str string) bool {
+	if !m.caseInsensitive {
+		return str == m.literal
+	}
+	for i := 0; i < len(str); i++ {
+		if !matchByteCI(str[i], m.literal[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// LikeCaseInsensitive returns whether LIKE compares the string and the pattern case insensitively.
+// It depends on the collation of the argument with the lower coercibility, the string is used if they are equal.
+// A binary string or a _bin collation is compared case sensitively.
+func LikeCaseInsensitive(str, pattern Expression) (bool, error) {
+	expr := str
+	if deriveCoercibility(pattern) < deriveCoercibility(str) {
+		expr = pattern
+	}
+	_, collation, err := charsetAndCollation(expr)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return collation != charset.CollationBin && !strings.HasSuffix(collation, "_bin"), nil
+}
+
+type regexpFuncClass struct {
+	baseFuncClass
+}
+
+// checkValid implements functionClass interface, the string and the pattern must be coerced to a common collation.
+func (c *regexpFuncClass) checkValid(args []Expression) error {
+	if err := c.baseFuncClass.checkValid(args); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(checkCollations(c.funcName, args))
+}
+
+func (c *regexpFuncClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	if err := c.checkValid(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinRegexp{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinRegexp struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinRegexp.
 // See http://dev.mysql.com/doc/refman/5.7/en/regexp.html#operator_regexp
-func builtinRegexp(args []types.Datum, _ context.Context) (d types.Datum, err error) {
+func (b *builtinRegexp) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
 	// TODO: We don't need to compile pattern if it has been compiled or it is static.
 	if args[0].IsNull() || args[1].IsNull() {
 		return
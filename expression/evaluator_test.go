@@ -14,6 +14,8 @@
 package expression
 
 import (
+	"math/rand"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -24,6 +26,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
@@ -52,34 +55,56 @@ func (s *testEvaluatorSuite) TestSleep(c *C) {
 	ctx := mock.NewContext()
 	sessVars := ctx.GetSessionVars()
 
+	fc := funcs[ast.Sleep]
+	sleep := func(d types.Datum) (types.Datum, error) {
+		f, err := fc.getFunction(datumsToConstants([]types.Datum{d}), ctx)
+		c.Assert(err, IsNil)
+		c.Assert(f.IsDeterministic(), IsFalse)
+		return f.eval(nil)
+	}
+
 	// non-strict model
 	sessVars.StrictSQLMode = false
-	d := make([]types.Datum, 1)
-	ret, err := builtinSleep(d, ctx)
+	ret, err := sleep(types.Datum{})
 	c.Assert(err, IsNil)
 	c.Assert(ret, DeepEquals, types.NewIntDatum(0))
-	d[0].SetInt64(-1)
-	ret, err = builtinSleep(d, ctx)
+	ret, err = sleep(types.NewIntDatum(-1))
 	c.Assert(err, IsNil)
 	c.Assert(ret, DeepEquals, types.NewIntDatum(0))
 
 	// for error case under the strict model
 	sessVars.StrictSQLMode = true
-	d[0].SetNull()
-	_, err = builtinSleep(d, ctx)
+	_, err = sleep(types.Datum{})
 	c.Assert(err, NotNil)
-	d[0].SetFloat64(-2.5)
-	_, err = builtinSleep(d, ctx)
+	_, err = sleep(types.NewFloat64Datum(-2.5))
 	c.Assert(err, NotNil)
 
 	// strict model
-	d[0].SetFloat64(0.5)
 	start := time.Now()
-	ret, err = builtinSleep(d, ctx)
+	ret, err = sleep(types.NewFloat64Datum(0.5))
 	c.Assert(err, IsNil)
 	c.Assert(ret, DeepEquals, types.NewIntDatum(0))
 	sub := time.Since(start)
 	c.Assert(sub.Nanoseconds(), GreaterEqual, int64(0.5*1e9))
+
+	// Killing the query interrupts the sleep, and sleep returns 1.
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		atomic.StoreUint32(&sessVars.Killed, 1)
+	}()
+	start = time.Now()
+	ret, err = sleep(types.NewFloat64Datum(10))
+	c.Assert(err, IsNil)
+	c.Assert(ret, DeepEquals, types.NewIntDatum(1))
+	c.Assert(time.Since(start), Less, 5*time.Second)
+	// The flag stays set until the next statement starts, so later sleeps in the statement stop too.
+	c.Assert(atomic.LoadUint32(&sessVars.Killed), Equals, uint32(1))
+	start = time.Now()
+	ret, err = sleep(types.NewFloat64Datum(10))
+	c.Assert(err, IsNil)
+	c.Assert(ret, DeepEquals, types.NewIntDatum(1))
+	c.Assert(time.Since(start), Less, 5*time.Second)
+	atomic.StoreUint32(&sessVars.Killed, 0)
 }
 
 func (s *testEvaluatorSuite) TestBinopComparison(c *C) {
@@ -120,8 +145,7 @@ func (s *testEvaluatorSuite) TestBinopComparison(c *C) {
 		{1, ast.LE, 1, 1},
 	}
 	for _, t := range tbl {
-		f := Funcs[t.op]
-		v, err := f.F(types.MakeDatums(t.lhs, t.rhs), s.ctx)
+		v, err := evalFuncClass(t.op, types.MakeDatums(t.lhs, t.rhs), s.ctx)
 		c.Assert(err, IsNil)
 		val, err := v.ToBool(s.ctx.GetSessionVars().StmtCtx)
 		c.Assert(err, IsNil)
@@ -149,48 +173,242 @@ func (s *testEvaluatorSuite) TestBinopComparison(c *C) {
 	}
 
 	for _, t := range nilTbl {
-		f := Funcs[t.op]
-		v, err := f.F(types.MakeDatums(t.lhs, t.rhs), s.ctx)
+		v, err := evalFuncClass(t.op, types.MakeDatums(t.lhs, t.rhs), s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(v.Kind(), Equals, types.KindNull)
 	}
 }
 
+func (s *testEvaluatorSuite) TestCompare(c *C) {
+	defer testleak.AfterTest(c)()
+	date, err := types.ParseTime("2017-01-02", mysql.TypeDate, types.MinFsp)
+	c.Assert(err, IsNil)
+	datetime, err := types.ParseTime("2017-01-02 10:20:30", mysql.TypeDatetime, types.MinFsp)
+	c.Assert(err, IsNil)
+	dur, err := types.ParseDuration("10:20:30", types.MinFsp)
+	c.Assert(err, IsNil)
+	tbl := []struct {
+		lhs    interface{}
+		op     string
+		rhs    interface{}
+		result interface{}
+	}{
+		// Strings are compared as numbers with numbers and as strings with strings.
+		{"10", ast.GT, 9, int64(1)},
+		{"10", ast.GT, "9", int64(0)},
+		{"1.0", ast.EQ, 1, int64(1)},
+		{"1.0", ast.EQ, "1", int64(0)},
+		{" 1", ast.EQ, 1, int64(1)},
+		{"1e1", ast.EQ, 10, int64(1)},
+		{"b", ast.GT, "a", int64(1)},
+		{2.5, ast.GT, "2.4", int64(1)},
+		{types.NewDecFromStringForTest("1.10"), ast.EQ, "1.1", int64(1)},
+		{uint64(18446744073709551615), ast.GT, int64(-1), int64(1)},
+		{uint64(18446744073709551615), ast.NE, int64(-1), int64(1)},
+		{int64(-1), ast.LE, uint64(0), int64(1)},
+		// Temporal values are compared as temporal values.
+		{date, ast.EQ, "2017-1-2", int64(1)},
+		{date, ast.LT, "2017-01-10", int64(1)},
+		{date, ast.LT, datetime, int64(1)},
+		{date, ast.EQ, "2017-01-02 00:00:00", int64(1)},
+		{datetime, ast.GE, "2017-01-02 10:20:30", int64(1)},
+		{"2017-01-02 10:20:31", ast.GT, datetime, int64(1)},
+		{dur, ast.EQ, "10:20:30", int64(1)},
+		{dur, ast.LT, "9:00:00", int64(0)},
+		{dur, ast.GT, "9:00:00", int64(1)},
+		// NULL is returned if either operand is NULL.
+		{nil, ast.EQ, 1, nil},
+		{"a", ast.NE, nil, nil},
+		{nil, ast.LT, nil, nil},
+		{date, ast.GE, nil, nil},
+	}
+	for _, t := range tbl {
+		args := types.MakeDatums(t.lhs, t.rhs)
+		v, err := evalFuncClass(t.op, args, s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, testutil.DatumEquals, types.NewDatum(t.result), Commentf("%v %s %v", t.lhs, t.op, t.rhs))
+	}
+
+	// An invalid temporal value is compared as the zero value with a warning, but it's an error in strict mode.
+	_, err = evalFuncClass(ast.EQ, types.MakeDatums(date, "abc"), s.ctx)
+	c.Assert(err, NotNil)
+	sc := s.ctx.GetSessionVars().StmtCtx
+	oldIgnoreTruncate := sc.IgnoreTruncate
+	defer func() {
+		sc.IgnoreTruncate = oldIgnoreTruncate
+	}()
+	sc.IgnoreTruncate = true
+	warnCnt := len(sc.GetWarnings())
+	v, err := evalFuncClass(ast.GT, types.MakeDatums(date, "abc"), s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v, testutil.DatumEquals, types.NewDatum(int64(1)))
+	c.Assert(sc.GetWarnings(), HasLen, warnCnt+1)
+	c.Assert(sc.GetWarnings()[warnCnt].Error(), Matches, ".*Truncated incorrect datetime value: 'abc'")
+}
+
+func (s *testEvaluatorSuite) TestNullEQ(c *C) {
+	defer testleak.AfterTest(c)()
+	date, err := types.ParseTime("2017-01-02", mysql.TypeDate, types.MinFsp)
+	c.Assert(err, IsNil)
+	tbl := []struct {
+		lhs    interface{}
+		rhs    interface{}
+		result int64
+	}{
+		{nil, nil, 1},
+		{nil, 1, 0},
+		{1, nil, 0},
+		{"", nil, 0},
+		{1, 1, 1},
+		{1, 2, 0},
+		{uint64(18446744073709551615), int64(-1), 0},
+		{types.NewDecFromStringForTest("1.10"), 1.1, 1},
+		// The operands are compared in a common type like =.
+		{"1", 1, 1},
+		{"1.0", 1, 1},
+		{"1.0", "1", 0},
+		{date, "2017-01-02 00:00:00", 1},
+		{date, "2017-1-2", 1},
+		{date, "2017-01-03", 0},
+	}
+	for _, t := range tbl {
+		args := types.MakeDatums(t.lhs, t.rhs)
+		v, err := evalFuncClass(ast.NullEQ, args, s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, testutil.DatumEquals, types.NewDatum(t.result), Commentf("%v", args))
+	}
+}
+
+func (s *testEvaluatorSuite) TestBetween(c *C) {
+	defer testleak.AfterTest(c)()
+	date, err := types.ParseTime("2017-01-02", mysql.TypeDate, types.MinFsp)
+	c.Assert(err, IsNil)
+	dur, err := types.ParseDuration("10:20:30", types.MinFsp)
+	c.Assert(err, IsNil)
+	tbl := []struct {
+		expr   interface{}
+		min    interface{}
+		max    interface{}
+		result interface{}
+	}{
+		{2, 1, 3, int64(1)},
+		{1, 1, 1, int64(1)},
+		{4, 1, 3, int64(0)},
+		{2, 3, 1, int64(0)},
+		{uint64(18446744073709551615), 0, uint64(18446744073709551615), int64(1)},
+		{2.5, 2, 3, int64(1)},
+		{types.NewDecFromStringForTest("1.10"), types.NewDecFromStringForTest("1.1"), 2, int64(1)},
+		// Strings are compared as numbers with numbers and as strings with strings.
+		{"10", 9, 11, int64(1)},
+		{"10", "9", "11", int64(0)},
+		{"b", "a", "c", int64(1)},
+		{"2", "1.5", 3, int64(1)},
+		// Temporal values are compared as temporal values.
+		{date, "2017-01-01", "2017-01-03", int64(1)},
+		{date, "2017-1-2", "2017-01-02 00:00:00", int64(1)},
+		{date, "2017-01-03", "2017-02-01", int64(0)},
+		{"2017-01-02 10:00:00", date, "2017-01-03", int64(1)},
+		{dur, "10:00:00", "11:00:00", int64(1)},
+		{dur, "9:00", "10:20:29", int64(0)},
+		// NULL is returned unless a non-NULL bound decides the result.
+		{nil, 1, 3, nil},
+		{2, nil, 3, nil},
+		{2, 1, nil, nil},
+		{4, nil, 3, int64(0)},
+		{0, 1, nil, int64(0)},
+		{nil, nil, nil, nil},
+	}
+	for _, t := range tbl {
+		args := types.MakeDatums(t.expr, t.min, t.max)
+		v, err := evalFuncClass(ast.Between, args, s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, testutil.DatumEquals, types.NewDatum(t.result), Commentf("%v", args))
+		v, err = evalFuncClass(ast.NotBetween, args, s.ctx)
+		c.Assert(err, IsNil)
+		if t.result != nil {
+			t.result = 1 - t.result.(int64)
+		}
+		c.Assert(v, testutil.DatumEquals, types.NewDatum(t.result), Commentf("not %v", args))
+	}
+}
+
 func (s *testEvaluatorSuite) TestBinopLogic(c *C) {
 	defer testleak.AfterTest(c)()
+	// The truth tables of AND, OR and XOR, the operands are true, false or NULL.
 	tbl := []struct {
 		lhs interface{}
-		op  string
 		rhs interface{}
-		ret interface{}
+		and interface{}
+		or  interface{}
+		xor interface{}
 	}{
-		{nil, ast.AndAnd, 1, nil},
-		{nil, ast.AndAnd, 0, 0},
-		{nil, ast.OrOr, 1, 1},
-		{nil, ast.OrOr, 0, nil},
-		{nil, ast.LogicXor, 1, nil},
-		{nil, ast.LogicXor, 0, nil},
-		{1, ast.AndAnd, 0, 0},
-		{1, ast.AndAnd, 1, 1},
-		{1, ast.OrOr, 0, 1},
-		{1, ast.OrOr, 1, 1},
-		{0, ast.OrOr, 0, 0},
-		{1, ast.LogicXor, 0, 1},
-		{1, ast.LogicXor, 1, 0},
-		{0, ast.LogicXor, 0, 0},
-		{0, ast.LogicXor, 1, 1},
+		{1, 1, 1, 1, 0},
+		{1, 0, 0, 1, 1},
+		{1, nil, nil, 1, nil},
+		{0, 1, 0, 1, 1},
+		{0, 0, 0, 0, 0},
+		{0, nil, 0, nil, nil},
+		{nil, 1, nil, 1, nil},
+		{nil, 0, 0, nil, nil},
+		{nil, nil, nil, nil, nil},
 	}
 	for _, t := range tbl {
-		f := Funcs[t.op]
-		v, err := f.F(types.MakeDatums(t.lhs, t.rhs), s.ctx)
-		c.Assert(err, IsNil)
-		switch x := t.ret.(type) {
-		case nil:
-			c.Assert(v.Kind(), Equals, types.KindNull)
-		case int:
-			c.Assert(v, testutil.DatumEquals, types.NewDatum(int64(x)))
+		for op, ret := range map[string]interface{}{ast.AndAnd: t.and, ast.OrOr: t.or, ast.LogicXor: t.xor} {
+			v, err := evalFuncClass(op, types.MakeDatums(t.lhs, t.rhs), s.ctx)
+			c.Assert(err, IsNil)
+			if ret == nil {
+				c.Assert(v.Kind(), Equals, types.KindNull, Commentf("%v %s %v", t.lhs, op, t.rhs))
+			} else {
+				c.Assert(v, testutil.DatumEquals, types.NewDatum(int64(ret.(int))), Commentf("%v %s %v", t.lhs, op, t.rhs))
+			}
 		}
 	}
+
+	// The operands are true if they're non-zero.
+	truthTbl := []struct {
+		arg    interface{}
+		result int64
+	}{
+		{0.1, 1},
+		{-0.4, 1},
+		{0.0, 0},
+		{"0.1", 1},
+		{"1e-3", 1},
+		{"0", 0},
+		{"0.0", 0},
+		{types.NewDecFromStringForTest("0.01"), 1},
+		{types.NewDecFromStringForTest("0.00"), 0},
+		{uint64(18446744073709551615), 1},
+		{types.Hex{Value: 0}, 0},
+	}
+	for _, t := range truthTbl {
+		v, err := evalFuncClass(ast.AndAnd, types.MakeDatums(t.arg, 1), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, testutil.DatumEquals, types.NewDatum(t.result), Commentf("%v", t.arg))
+		v, err = evalFuncClass(ast.UnaryNot, types.MakeDatums(t.arg), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, testutil.DatumEquals, types.NewDatum(1-t.result), Commentf("%v", t.arg))
+	}
+
+	// The second operand isn't evaluated if the first one decides the result.
+	shortCircuitTbl := []struct {
+		op     string
+		lhs    interface{}
+		result interface{}
+	}{
+		{ast.AndAnd, 0, int64(0)},
+		{ast.OrOr, 1, int64(1)},
+		{ast.OrOr, "0.5", int64(1)},
+		{ast.LogicXor, nil, nil},
+	}
+	for _, t := range shortCircuitTbl {
+		args := []Expression{&Constant{Value: types.NewDatum(t.lhs)}, &panicExpr{&Constant{Value: types.NewDatum(nil)}}}
+		f, err := funcs[t.op].getFunction(args, s.ctx)
+		c.Assert(err, IsNil)
+		v, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(v, testutil.DatumEquals, types.NewDatum(t.result), Commentf("%v %s", t.lhs, t.op))
+	}
 }
 
 func (s *testEvaluatorSuite) TestBinopBitop(c *C) {
@@ -279,18 +497,6 @@ func (s *testEvaluatorSuite) TestBinopNumeric(c *C) {
 		{1, ast.Div, 2, 0.5},
 		{1, ast.Div, 0, nil},
 
-		// int div
-		{1, ast.IntDiv, 2, 0},
-		{1, ast.IntDiv, uint64(2), 0},
-		{1, ast.IntDiv, 0, nil},
-		{1, ast.IntDiv, uint64(0), nil},
-		{uint64(1), ast.IntDiv, 2, 0},
-		{uint64(1), ast.IntDiv, uint64(2), 0},
-		{uint64(1), ast.IntDiv, 0, nil},
-		{uint64(1), ast.IntDiv, uint64(0), nil},
-		{1.0, ast.IntDiv, 2.0, 0},
-		{1.0, ast.IntDiv, 0, nil},
-
 		// mod
 		{10, ast.Mod, 2, 0},
 		{10, ast.Mod, uint64(2), 0},
@@ -309,8 +515,7 @@ func (s *testEvaluatorSuite) TestBinopNumeric(c *C) {
 	}
 
 	for _, t := range tbl {
-		f := Funcs[t.op]
-		v, err := f.F(types.MakeDatums(t.lhs, t.rhs), s.ctx)
+		v, err := EvalBuiltin(t.op, s.ctx, types.MakeDatums(t.lhs, t.rhs)...)
 		c.Assert(err, IsNil)
 		switch v.Kind() {
 		case types.KindNull:
@@ -395,7 +600,7 @@ func (s *testEvaluatorSuite) TestLike(c *C) {
 	tbl := []struct {
 		pattern string
 		input   string
-		escape  byte
+		escape  int
 		match   bool
 	}{
 		{"", "a", '\\', false},
@@ -426,29 +631,115 @@ func (s *testEvaluatorSuite) TestLike(c *C) {
 		{`\%a`, `%a`, '+', false},
 		{`++a`, `+a`, '+', true},
 		{`++_a`, `+xa`, '+', true},
+		{`\%a`, `\xa`, noEscape, true},
+		{`\_a`, `_a`, noEscape, false},
+		{`\\a`, `\\a`, noEscape, true},
 	}
 	for _, v := range tbl {
 		patChars, patTypes := compilePattern(v.pattern, v.escape)
-		match := doMatch(v.input, patChars, patTypes)
+		match := doMatch(v.input, patChars, patTypes, true)
 		c.Assert(match, Equals, v.match, Commentf("%v", v))
 	}
+	patChars, patTypes := compilePattern("a%B", '\\')
+	c.Assert(doMatch("AxB", patChars, patTypes, true), IsTrue)
+	c.Assert(doMatch("AxB", patChars, patTypes, false), IsFalse)
+	c.Assert(doMatch("axB", patChars, patTypes, false), IsTrue)
+
 	testCases := []struct {
-		input   string
-		pattern string
-		match   int
+		input   interface{}
+		pattern interface{}
+		escape  int
+		match   interface{}
 	}{
-		{"a", "", 0},
-		{"a", "a", 1},
-		{"a", "b", 0},
-		{"aA", "Aa", 1},
-		{"aAb", "Aa%", 1},
-		{"aAb", "Aa_", 1},
+		{"a", "", '\\', int64(0)},
+		{"a", "a", '\\', int64(1)},
+		{"a", "b", '\\', int64(0)},
+		{"aA", "Aa", '\\', int64(1)},
+		{"aAb", "Aa%", '\\', int64(1)},
+		{"aAb", "Aa_", '\\', int64(1)},
+		{"a_b", "a|_b", '|', int64(1)},
+		{"axb", "a|_b", '|', int64(0)},
+		{`a\b`, `a\_`, noEscape, int64(1)},
+		{nil, "a", '\\', nil},
+		{"a", nil, '\\', nil},
 	}
 	for _, tc := range testCases {
-		f := Funcs[ast.Like]
-		r, err := f.F(types.MakeDatums(tc.input, tc.pattern, 0), s.ctx)
+		r, err := evalFuncClass(ast.Like, types.MakeDatums(tc.input, tc.pattern, tc.escape), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(r, testutil.DatumEquals, types.NewDatum(tc.match), Commentf("%v", tc))
+	}
+
+	// A binary string or a _bin collation is matched case sensitively.
+	for _, collation := range []string{"utf8_general_ci", "utf8_bin", "binary"} {
+		str := &Constant{Value: types.NewStringDatum("aA"), RetType: types.NewFieldType(mysql.TypeVarString)}
+		str.RetType.Charset, str.RetType.Collate = charset.CharsetUTF8, collation
+		if collation == charset.CollationBin {
+			str.RetType.Charset = charset.CharsetBin
+		}
+		args := append([]Expression{str}, datumsToConstants(types.MakeDatums("Aa", '\\'))...)
+		f, err := funcs[ast.Like].getFunction(args, s.ctx)
+		c.Assert(err, IsNil)
+		r, err := f.eval(nil)
 		c.Assert(err, IsNil)
-		c.Assert(r, testutil.DatumEquals, types.NewDatum(tc.match))
+		c.Assert(r, testutil.DatumEquals, types.NewDatum(boolToInt64(collation == "utf8_general_ci")), Commentf("%s", collation))
+	}
+
+	// The constant pattern is compiled only once.
+	col := &Column{RetType: types.NewFieldType(mysql.TypeVarString), Index: 0}
+	args := append([]Expression{col}, datumsToConstants(types.MakeDatums("a%", '\\'))...)
+	f, err := funcs[ast.Like].getFunction(args, s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(f.(*builtinLike).matcher, NotNil)
+	for _, v := range []struct {
+		input string
+		match int64
+	}{{"abc", 1}, {"bc", 0}} {
+		r, err := f.eval(types.MakeDatums(v.input))
+		c.Assert(err, IsNil)
+		c.Assert(r, testutil.DatumEquals, types.NewIntDatum(v.match))
+	}
+}
+
+func (s *testEvaluatorSuite) TestLikeMatcher(c *C) {
+	defer testleak.AfterTest(c)()
+	kinds := map[string]int{
+		"abc":    likeMatchExact,
+		"abc%":   likeMatchPrefix,
+		"%abc":   likeMatchSuffix,
+		"%abc%":  likeMatchContains,
+		"%%":     likeMatchSuffix,
+		`a\%b%`:  likeMatchPrefix,
+		"a_c%":   likeMatchGeneric,
+		"a%c":    likeMatchGeneric,
+		"%a%c%":  likeMatchGeneric,
+		"":       likeMatchExact,
+		`%\_%`:   likeMatchContains,
+		`%\%\_%`: likeMatchContains,
+	}
+	for pattern, kind := range kinds {
+		patChars, patTypes := compilePattern(pattern, '\\')
+		c.Assert(newLikeMatcher(patChars, patTypes, true).kind, Equals, kind, Commentf("%s", pattern))
+	}
+
+	// The matcher must agree with the generic matching on every row.
+	rnd := rand.New(rand.NewSource(1))
+	randStr := func(alphabet string, maxLen int) string {
+		buf := make([]byte, rnd.Intn(maxLen+1))
+		for i := range buf {
+			buf[i] = alphabet[rnd.Intn(len(alphabet))]
+		}
+		return string(buf)
+	}
+	for i := 0; i < 500; i++ {
+		pattern := randStr("aAb%_", 5)
+		patChars, patTypes := compilePattern(pattern, '\\')
+		for _, ci := range []bool{true, false} {
+			m := newLikeMatcher(patChars, patTypes, ci)
+			for j := 0; j < 50; j++ {
+				str := randStr("aAbB", 8)
+				c.Assert(m.match(str), Equals, doMatch(str, patChars, patTypes, ci), Commentf("%q like %q %v", str, pattern, ci))
+			}
+		}
 	}
 }
 
@@ -470,8 +761,7 @@ func (s *testEvaluatorSuite) TestRegexp(c *C) {
 		{".*", "abcd", 1},
 	}
 	for _, v := range tbl {
-		f := Funcs[ast.Regexp]
-		match, err := f.F(types.MakeDatums(v.input, v.pattern), s.ctx)
+		match, err := evalFuncClass(ast.Regexp, types.MakeDatums(v.input, v.pattern), s.ctx)
 		c.Assert(err, IsNil)
 		c.Assert(match, testutil.DatumEquals, types.NewDatum(v.match), Commentf("%v", v))
 	}
@@ -528,8 +818,7 @@ func (s *testEvaluatorSuite) TestUnaryOp(c *C) {
 		{types.Set{Name: "a", Value: 1}, ast.UnaryMinus, -1.0},
 	}
 	for i, t := range tbl {
-		f := Funcs[t.op]
-		result, err := f.F(types.MakeDatums(t.arg), s.ctx)
+		result, err := EvalBuiltin(t.op, s.ctx, types.MakeDatums(t.arg)...)
 		c.Assert(err, IsNil)
 		c.Assert(result, testutil.DatumEquals, types.NewDatum(t.result), Commentf("%d", i))
 	}
@@ -556,8 +845,7 @@ func (s *testEvaluatorSuite) TestUnaryOp(c *C) {
 	}
 
 	for _, t := range tbl {
-		f := Funcs[t.op]
-		result, err := f.F(types.MakeDatums(t.arg), s.ctx)
+		result, err := EvalBuiltin(t.op, s.ctx, types.MakeDatums(t.arg)...)
 		c.Assert(err, IsNil)
 
 		ret, err := result.CompareDatum(s.ctx.GetSessionVars().StmtCtx, types.NewDatum(t.result))
@@ -30,19 +30,66 @@ import (
 
 // Error instances.
 var (
-	errInvalidOperation        = terror.ClassExpression.New(codeInvalidOperation, "invalid operation")
-	errIncorrectParameterCount = terror.ClassExpression.New(codeIncorrectParameterCount, "Incorrect parameter count")
+	errInvalidOperation         = terror.ClassExpression.New(codeInvalidOperation, "invalid operation")
+	errIncorrectParameterCount  = terror.ClassExpression.New(codeIncorrectParameterCount, "Incorrect parameter count in the call to native function '%s'")
+	errWrongValueForType        = terror.ClassExpression.New(codeWrongValueForType, "Incorrect %s value: '%s' for function %s")
+	ErrDeprecatedSyntax         = terror.ClassExpression.New(codeDeprecatedSyntax, "'%s' is deprecated and will be removed in a future release.")
+	errCutValueGroupConcat      = terror.ClassExpression.New(codeCutValueGroupConcat, "Row %d was cut by GROUP_CONCAT()")
+	errDataOutOfRange           = terror.ClassExpression.New(codeDataOutOfRange, "%s value is out of range in '%s'")
+	errWarnDataOutOfRange       = terror.ClassExpression.New(codeWarnDataOutOfRange, "Out of range value for column '%s' at row %d")
+	errAllowedPacketOverflowed  = terror.ClassExpression.New(codeAllowedPacketOverflowed, "Result of %s() was larger than max_allowed_packet (%d) - truncated")
+	errIncorrectArgs            = terror.ClassExpression.New(codeIncorrectArgs, "Incorrect arguments to %s")
+	errTruncatedWrongValue      = terror.ClassExpression.New(codeTruncatedWrongValue, "Truncated incorrect %s value: '%s'")
+	errMalformedGtidSet         = terror.ClassExpression.New(codeMalformedGtidSet, "Malformed GTID set specification '%s'.")
+	errInvalidJSONText          = terror.ClassExpression.New(codeInvalidJSONText, mysql.MySQLErrName[mysql.ErrInvalidJSONTextInParam])
+	errInvalidJSONPath          = terror.ClassExpression.New(codeInvalidJSONPath, mysql.MySQLErrName[mysql.ErrInvalidJSONPath])
+	errInvalidJSONPathWildcard  = terror.ClassExpression.New(codeInvalidJSONPathWildcard, mysql.MySQLErrName[mysql.ErrInvalidJSONPathWildcard])
+	errJSONVacuousPath          = terror.ClassExpression.New(codeJSONVacuousPath, mysql.MySQLErrName[mysql.ErrJSONVacuousPath])
+	errJSONBadOneOrAllArg       = terror.ClassExpression.New(codeJSONBadOneOrAllArg, mysql.MySQLErrName[mysql.ErrJSONBadOneOrAllArg])
+	errInvalidJSONPathArrayCell = terror.ClassExpression.New(codeInvalidJSONPathArrayCell, mysql.MySQLErrName[mysql.ErrInvalidJSONPathArrayCell])
+	errGroupingNotGroupBy       = terror.ClassExpression.New(codeGroupingNotGroupBy, mysql.MySQLErrName[mysql.ErrFieldInGroupingNotGroupBy])
+	errUnknownCollation         = terror.ClassExpression.New(codeUnknownCollation, mysql.MySQLErrName[mysql.ErrUnknownCollation])
+	errCollationCharsetMismatch = terror.ClassExpression.New(codeCollationCharsetMismatch, mysql.MySQLErrName[mysql.ErrCollationCharsetMismatch])
+	errCantAggregateCollations  = terror.ClassExpression.New(codeCantAggregateCollations, mysql.MySQLErrName[mysql.ErrCantAggregate2collations])
+	errDivisionByZero           = terror.ClassExpression.New(codeDivisionByZero, mysql.MySQLErrName[mysql.ErrDivisionByZero])
+	errDatetimeFunctionOverflow = terror.ClassExpression.New(codeDatetimeFunctionOverflow, mysql.MySQLErrName[mysql.ErrDatetimeFunctionOverflow])
+	errUserLockWrongName        = terror.ClassExpression.New(codeUserLockWrongName, mysql.MySQLErrName[mysql.ErrUserLockWrongName])
 )
 
 // Error codes.
 const (
-	codeInvalidOperation        terror.ErrCode = 1
-	codeIncorrectParameterCount                = 1582
+	codeInvalidOperation         terror.ErrCode = 1
+	codeIncorrectParameterCount                 = 1582
+	codeWrongValueForType                       = 1411
+	codeDeprecatedSyntax                        = 1681
+	codeCutValueGroupConcat                     = 1260
+	codeDataOutOfRange                          = 1690
+	codeWarnDataOutOfRange                      = 1264
+	codeAllowedPacketOverflowed                 = 1301
+	codeIncorrectArgs                           = 1210
+	codeTruncatedWrongValue                     = 1292
+	codeMalformedGtidSet                        = 1772
+	codeInvalidJSONText                         = 3141
+	codeInvalidJSONPath                         = 3143
+	codeInvalidJSONPathWildcard                 = 3149
+	codeJSONVacuousPath                         = 3153
+	codeJSONBadOneOrAllArg                      = 3154
+	codeInvalidJSONPathArrayCell                = 3165
+	codeGroupingNotGroupBy                      = 3580
+	codeUnknownCollation                        = 1273
+	codeCollationCharsetMismatch                = 1253
+	codeCantAggregateCollations                 = 1267
+	codeDivisionByZero                          = 1365
+	codeDatetimeFunctionOverflow                = 1441
+	codeUserLockWrongName                       = 3057
 )
 
 // EvalAstExpr evaluates ast expression directly.
 var EvalAstExpr func(expr ast.ExprNode, ctx context.Context) (types.Datum, error)
 
+// GetColDefaultValue gets the default value of the column, it is set by the table package.
+var GetColDefaultValue func(ctx context.Context, col *model.ColumnInfo) (types.Datum, bool, error)
+
 // Expression represents all scalar expression in SQL.
 type Expression interface {
 	fmt.Stringer
@@ -294,9 +341,39 @@ func NewValuesFunc(v *ast.ValuesExpr) *ScalarFunction {
 	}
 }
 
+// NewDefaultFunc creates a new default function which returns the default value of the column.
+// The qualified column name is kept as the argument so that default functions of different columns are not equal.
+func NewDefaultFunc(col *model.ColumnInfo, colName string) *ScalarFunction {
+	fc := &defaultFuncClass{baseFuncClass{ast.Default, 1, 1}, col}
+	f, _ := newClassFunction(ast.Default, fc, &col.FieldType, &Constant{Value: types.NewStringDatum(colName), RetType: types.NewFieldType(mysql.TypeVarString)})
+	return f.(*ScalarFunction)
+}
+
 func init() {
 	expressionMySQLErrCodes := map[terror.ErrCode]uint16{
-		codeIncorrectParameterCount: mysql.ErrWrongParamcountToNativeFct,
+		codeIncorrectParameterCount:  mysql.ErrWrongParamcountToNativeFct,
+		codeWrongValueForType:        mysql.ErrWrongValueForType,
+		codeDeprecatedSyntax:         mysql.ErrWarnDeprecatedSyntaxNoReplacement,
+		codeCutValueGroupConcat:      mysql.ErrCutValueGroupConcat,
+		codeDataOutOfRange:           mysql.ErrDataOutOfRange,
+		codeWarnDataOutOfRange:       mysql.ErrWarnDataOutOfRange,
+		codeAllowedPacketOverflowed:  mysql.ErrWarnAllowedPacketOverflowed,
+		codeIncorrectArgs:            mysql.ErrWrongArguments,
+		codeTruncatedWrongValue:      mysql.ErrTruncatedWrongValue,
+		codeMalformedGtidSet:         mysql.ErrMalformedGtidSetSpecification,
+		codeInvalidJSONText:          mysql.ErrInvalidJSONTextInParam,
+		codeInvalidJSONPath:          mysql.ErrInvalidJSONPath,
+		codeInvalidJSONPathWildcard:  mysql.ErrInvalidJSONPathWildcard,
+		codeJSONVacuousPath:          mysql.ErrJSONVacuousPath,
+		codeJSONBadOneOrAllArg:       mysql.ErrJSONBadOneOrAllArg,
+		codeInvalidJSONPathArrayCell: mysql.ErrInvalidJSONPathArrayCell,
+		codeGroupingNotGroupBy:       mysql.ErrFieldInGroupingNotGroupBy,
+		codeUnknownCollation:         mysql.ErrUnknownCollation,
+		codeCollationCharsetMismatch: mysql.ErrCollationCharsetMismatch,
+		codeCantAggregateCollations:  mysql.ErrCantAggregate2collations,
+		codeDivisionByZero:           mysql.ErrDivisionByZero,
+		codeDatetimeFunctionOverflow: mysql.ErrDatetimeFunctionOverflow,
+		codeUserLockWrongName:        mysql.ErrUserLockWrongName,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassExpression] = expressionMySQLErrCodes
 }
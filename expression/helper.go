@@ -14,6 +14,7 @@
 package expression
 
 import (
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -30,6 +32,29 @@ const (
 	oneI64  int64 = 1
 )
 
+// getUintSysVar gets the value of an unsigned integer system variable of the session,
+// the default value is used if the variable is not set.
+func getUintSysVar(ctx context.Context, name string) uint64 {
+	val := varsutil.GetSystemVar(ctx.GetSessionVars(), name)
+	if !val.IsNull() {
+		if v, err := strconv.ParseUint(val.GetString(), 10, 64); err == nil {
+			return v
+		}
+	}
+	v, _ := strconv.ParseUint(variable.SysVars[name].Value, 10, 64)
+	return v
+}
+
+// handleTruncateError returns err if the statement reports truncation as an error, i.e. an INSERT, UPDATE or DELETE
+// in strict mode, otherwise err is appended as a warning and the function goes on with a truncated or NULL result.
+func handleTruncateError(sc *variable.StatementContext, err error) error {
+	if !sc.IgnoreTruncate && !sc.TruncateAsWarning {
+		return errors.Trace(err)
+	}
+	sc.AppendWarning(err)
+	return nil
+}
+
 func boolToInt64(v bool) int64 {
 	if v {
 		return int64(1)
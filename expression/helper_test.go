@@ -20,7 +20,9 @@ import (
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
@@ -113,3 +115,28 @@ func (s *testExpressionSuite) TestIsCurrentTimeExpr(c *C) {
 	v = IsCurrentTimeExpr(&ast.FuncCallExpr{FnName: model.NewCIStr("CURRENT_TIMESTAMP")})
 	c.Assert(v, IsTrue)
 }
+
+func (s *testExpressionSuite) TestHandleTruncateError(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		ignore    bool
+		asWarning bool
+		isErr     bool
+	}{
+		{false, false, true},
+		{true, false, false},
+		{false, true, false},
+	}
+	for _, t := range tbl {
+		sc := &variable.StatementContext{IgnoreTruncate: t.ignore, TruncateAsWarning: t.asWarning}
+		err := handleTruncateError(sc, types.ErrTruncated)
+		if t.isErr {
+			c.Assert(terror.ErrorEqual(err, types.ErrTruncated), IsTrue)
+			c.Assert(sc.GetWarnings(), HasLen, 0)
+		} else {
+			c.Assert(err, IsNil)
+			c.Assert(sc.GetWarnings(), HasLen, 1)
+			c.Assert(terror.ErrorEqual(sc.GetWarnings()[0], types.ErrTruncated), IsTrue)
+		}
+	}
+}
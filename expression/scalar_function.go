@@ -16,6 +16,7 @@ package expression
 import (
 	"bytes"
 	"fmt"
+	"strings"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
@@ -32,6 +33,12 @@ type ScalarFunction struct {
 	RetType   *types.FieldType
 	Function  BuiltinFunc
 	ArgValues []types.Datum
+	// class is set when the function is registered in funcs, Function is nil then.
+	class functionClass
+	// sig is the function signature built from class, it is bound to the context it was built with.
+	sig builtinFunc
+	// argColumns keeps the arguments evaluated by EvalBatch, they are reused by the following batches.
+	argColumns [][]types.Datum
 }
 
 // GetArgs gets arguments of function.
@@ -60,12 +67,18 @@ func (sf *ScalarFunction) MarshalJSON() ([]byte, error) {
 
 // NewFunction creates a new scalar function or constant.
 func NewFunction(funcName string, retType *types.FieldType, args ...Expression) (Expression, error) {
+	if fc, ok := funcs[funcName]; ok {
+		return newClassFunction(funcName, fc, retType, args...)
+	}
 	f, ok := Funcs[funcName]
 	if !ok {
+		if fc, ok := getCustomFunc(funcName); ok {
+			return newClassFunction(funcName, fc, retType, args...)
+		}
 		return nil, errors.Errorf("Function %s is not implemented.", funcName)
 	}
 	if len(args) < f.MinArgs || (f.MaxArgs != -1 && len(args) > f.MaxArgs) {
-		return nil, errIncorrectParameterCount.Gen("Incorrect parameter count in the call to native function %s", funcName)
+		return nil, errIncorrectParameterCount.GenByArgs(funcName)
 	}
 	funcArgs := make([]Expression, len(args))
 	copy(funcArgs, args)
@@ -77,6 +90,36 @@ func NewFunction(funcName string, retType *types.FieldType, args ...Expression)
 		ArgValues: make([]types.Datum, len(funcArgs))}, nil
 }
 
+// EvalBuiltin evaluates the builtin function named funcName with the constant arguments.
+// It's the way to call a builtin function without building an expression tree, the name is case insensitive.
+func EvalBuiltin(funcName string, ctx context.Context, args ...types.Datum) (types.Datum, error) {
+	constants := make([]Expression, 0, len(args))
+	for _, arg := range args {
+		ft := new(types.FieldType)
+		types.DefaultTypeForValue(arg.GetValue(), ft)
+		constants = append(constants, &Constant{Value: arg, RetType: ft})
+	}
+	f, err := NewFunction(strings.ToLower(funcName), nil, constants...)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	d, err := f.Eval(nil, ctx)
+	return d, errors.Trace(err)
+}
+
+func newClassFunction(funcName string, fc functionClass, retType *types.FieldType, args ...Expression) (Expression, error) {
+	funcArgs := make([]Expression, len(args))
+	copy(funcArgs, args)
+	if err := fc.checkValid(funcArgs); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &ScalarFunction{
+		args:     funcArgs,
+		FuncName: model.NewCIStr(funcName),
+		RetType:  retType,
+		class:    fc}, nil
+}
+
 //ScalarFuncs2Exprs converts []*ScalarFunction to []Expression.
 func ScalarFuncs2Exprs(funcs []*ScalarFunction) []Expression {
 	result := make([]Expression, 0, len(funcs))
@@ -92,7 +135,8 @@ func (sf *ScalarFunction) Clone() Expression {
 		FuncName:  sf.FuncName,
 		Function:  sf.Function,
 		RetType:   sf.RetType,
-		ArgValues: make([]types.Datum, len(sf.args))}
+		ArgValues: make([]types.Datum, len(sf.args)),
+		class:     sf.class}
 	newFunc.args = make([]Expression, 0, len(sf.args))
 	for _, arg := range sf.args {
 		newFunc.args = append(newFunc.args, arg.Clone())
@@ -114,6 +158,10 @@ func (sf *ScalarFunction) Equal(e Expression, ctx context.Context) bool {
 	if sf.FuncName.L != fun.FuncName.L {
 		return false
 	}
+	// Two calls of a non-deterministic function like rand() may return different results.
+	if !sf.IsDeterministic(ctx) {
+		return false
+	}
 	if len(sf.args) != len(fun.args) {
 		return false
 	}
@@ -140,11 +188,20 @@ func (sf *ScalarFunction) Decorrelate(schema Schema) Expression {
 	for i, arg := range sf.GetArgs() {
 		sf.args[i] = arg.Decorrelate(schema)
 	}
+	// The signature may have cached information of the correlated arguments.
+	sf.sig = nil
 	return sf
 }
 
 // Eval implements Expression interface.
 func (sf *ScalarFunction) Eval(row []types.Datum, ctx context.Context) (types.Datum, error) {
+	if sf.class != nil {
+		sig, err := sf.getSig(ctx)
+		if err != nil {
+			return types.Datum{}, errors.Trace(err)
+		}
+		return sig.eval(row)
+	}
 	var err error
 	for i, arg := range sf.GetArgs() {
 		sf.ArgValues[i], err = arg.Eval(row, ctx)
@@ -155,6 +212,80 @@ func (sf *ScalarFunction) Eval(row []types.Datum, ctx context.Context) (types.Da
 	return sf.Function(sf.ArgValues, ctx)
 }
 
+// EvalBatch evaluates the function on every row of rows and stores the results in result, which must be as long as rows.
+// A function built from a functionClass is evaluated by its signature, which may evaluate the rows faster than one by one.
+// The arguments of other functions are evaluated in batch before the function is called on each row.
+func (sf *ScalarFunction) EvalBatch(rows [][]types.Datum, ctx context.Context, result []types.Datum) (err error) {
+	if sf.class != nil {
+		sig, err := sf.getSig(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(sig.evalBatch(rows, result))
+	}
+	if len(sf.argColumns) != len(sf.args) {
+		sf.argColumns = make([][]types.Datum, len(sf.args))
+	}
+	for i, arg := range sf.args {
+		if cap(sf.argColumns[i]) < len(rows) {
+			sf.argColumns[i] = make([]types.Datum, len(rows))
+		}
+		sf.argColumns[i] = sf.argColumns[i][:len(rows)]
+		if err = EvalBatch(arg, rows, ctx, sf.argColumns[i]); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for i := range rows {
+		for j, column := range sf.argColumns {
+			sf.ArgValues[j] = column[i]
+		}
+		result[i], err = sf.Function(sf.ArgValues, ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// EvalBatch evaluates the expression on every row of rows and stores the results in result, which must be as long as rows.
+func EvalBatch(expr Expression, rows [][]types.Datum, ctx context.Context, result []types.Datum) (err error) {
+	if sf, ok := expr.(*ScalarFunction); ok {
+		return errors.Trace(sf.EvalBatch(rows, ctx, result))
+	}
+	for i, row := range rows {
+		result[i], err = expr.Eval(row, ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// getSig gets the function signature of a function built from a functionClass.
+// The signature is built lazily because the context is only known on evaluation.
+func (sf *ScalarFunction) getSig(ctx context.Context) (builtinFunc, error) {
+	if sf.sig != nil && sf.sig.getCtx() == ctx {
+		return sf.sig, nil
+	}
+	sig, err := sf.class.getFunction(sf.args, ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	sf.sig = sig
+	return sig, nil
+}
+
+// IsDeterministic checks if the function returns same results for same inputs.
+// The planner must not constant fold or cache the result of a non-deterministic function.
+func (sf *ScalarFunction) IsDeterministic(ctx context.Context) bool {
+	if sf.class == nil {
+		_, isDynamic := DynamicFuncs[sf.FuncName.L]
+		return !isDynamic
+	}
+	sig, err := sf.getSig(ctx)
+	return err == nil && sig.IsDeterministic()
+}
+
 // HashCode implements Expression interface.
 func (sf *ScalarFunction) HashCode() []byte {
 	var bytes []byte
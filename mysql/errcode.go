@@ -883,3 +883,19 @@ const (
 	ErrRowInWrongPartition                                          = 1863
 	ErrErrorLast                                                    = 1863
 )
+
+// MySQL 5.7 error codes of the JSON functions.
+const (
+	ErrInvalidJSONTextInParam   uint16 = 3141
+	ErrInvalidJSONPath          uint16 = 3143
+	ErrInvalidJSONPathWildcard  uint16 = 3149
+	ErrJSONVacuousPath          uint16 = 3153
+	ErrJSONBadOneOrAllArg       uint16 = 3154
+	ErrInvalidJSONPathArrayCell uint16 = 3165
+)
+
+// ErrUserLockWrongName is the MySQL 5.7 error code of a user-level lock name which is empty or too long.
+const ErrUserLockWrongName uint16 = 3057
+
+// ErrFieldInGroupingNotGroupBy is the MySQL 8.0 error code of an argument of GROUPING() which is not in GROUP BY.
+const ErrFieldInGroupingNotGroupBy uint16 = 3580
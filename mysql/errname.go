@@ -879,4 +879,15 @@ var MySQLErrName = map[uint16]string{
 	ErrAlterOperationNotSupportedReasonNotNull:               "cannot silently convert NULL values, as required in this SQLMODE",
 	ErrMustChangePasswordLogin:                               "Your password has expired. To log in you must change it using a client that supports expired passwords.",
 	ErrRowInWrongPartition:                                   "Found a row in wrong partition %s",
+
+	ErrInvalidJSONTextInParam:   "Invalid JSON text in argument %d to function %s: \"%s\" at position %d.",
+	ErrInvalidJSONPath:          "Invalid JSON path expression. The error is around character position %d.",
+	ErrInvalidJSONPathWildcard:  "In this situation, path expressions may not contain the * and ** tokens.",
+	ErrJSONVacuousPath:          "The path expression '$' is not allowed in this context.",
+	ErrInvalidJSONPathArrayCell: "A path expression is not a path to a cell in an array.",
+	ErrJSONBadOneOrAllArg:       "The oneOrAll argument to %s may take these values: 'one' or 'all'.",
+
+	ErrUserLockWrongName: "Incorrect user-level lock name '%s'.",
+
+	ErrFieldInGroupingNotGroupBy: "Argument #%d of GROUPING function is not in GROUP BY",
 }
@@ -34,6 +34,9 @@ const (
 	TypeBit
 )
 
+// TypeJSON is the type of JSON values. TiDB has no JSON column type yet, it's only the target type of CAST.
+const TypeJSON byte = 0xf5
+
 // TypeUnspecified is an uninitialized type. TypeDecimal is not used in MySQL.
 var TypeUnspecified = TypeDecimal
 
@@ -135,359 +135,417 @@ func init() {
 }
 
 var tokenMap = map[string]int{
-	"ABS":                 abs,
-	"ADD":                 add,
-	"ADDDATE":             addDate,
-	"ADMIN":               admin,
-	"AFTER":               after,
-	"ALL":                 all,
-	"ALTER":               alter,
-	"ANALYZE":             analyze,
-	"AND":                 and,
-	"ANY":                 any,
-	"AS":                  as,
-	"ASC":                 asc,
-	"ASCII":               ascii,
-	"AUTO_INCREMENT":      autoIncrement,
-	"AVG":                 avg,
-	"AVG_ROW_LENGTH":      avgRowLength,
-	"BEGIN":               begin,
-	"BETWEEN":             between,
-	"BINLOG":              binlog,
-	"BOTH":                both,
-	"BTREE":               btree,
-	"BY":                  by,
-	"BYTE":                byteType,
-	"CASE":                caseKwd,
-	"CAST":                cast,
-	"CEIL":                ceil,
-	"CEILING":             ceiling,
-	"CHANGE":              change,
-	"CHARACTER":           character,
-	"CHARSET":             charsetKwd,
-	"CHECK":               check,
-	"CHECKSUM":            checksum,
-	"COALESCE":            coalesce,
-	"COLLATE":             collate,
-	"COLLATION":           collation,
-	"COLUMN":              column,
-	"COLUMNS":             columns,
-	"COMMENT":             comment,
-	"COMMIT":              commit,
-	"COMMITTED":           committed,
-	"COMPACT":             compact,
-	"COMPRESSED":          compressed,
-	"COMPRESSION":         compression,
-	"CONCAT":              concat,
-	"CONCAT_WS":           concatWs,
-	"CONNECTION":          connection,
-	"CONNECTION_ID":       connectionID,
-	"CONSTRAINT":          constraint,
-	"CONSISTENT":          consistent,
-	"CONVERT":             convert,
-	"COUNT":               count,
-	"CREATE":              create,
-	"CROSS":               cross,
-	"CURDATE":             curDate,
-	"UTC_DATE":            utcDate,
-	"CURRENT_DATE":        currentDate,
-	"CURTIME":             curTime,
-	"CURRENT_TIME":        currentTime,
-	"CURRENT_USER":        currentUser,
-	"DATA":                data,
-	"DATABASE":            database,
-	"DATABASES":           databases,
-	"DATE_ADD":            dateAdd,
-	"DATE_FORMAT":         dateFormat,
-	"DATE_SUB":            dateSub,
-	"DAY":                 day,
-	"DAYNAME":             dayname,
-	"DAYOFMONTH":          dayofmonth,
-	"DAYOFWEEK":           dayofweek,
-	"DAYOFYEAR":           dayofyear,
-	"DDL":                 ddl,
-	"DEALLOCATE":          deallocate,
-	"DEFAULT":             defaultKwd,
-	"DELAYED":             delayed,
-	"DELAY_KEY_WRITE":     delayKeyWrite,
-	"DELETE":              deleteKwd,
-	"DESC":                desc,
-	"DESCRIBE":            describe,
-	"DISABLE":             disable,
-	"DISTINCT":            distinct,
-	"DIV":                 div,
-	"DO":                  do,
-	"DROP":                drop,
-	"DUAL":                dual,
-	"DUPLICATE":           duplicate,
-	"DYNAMIC":             dynamic,
-	"ELSE":                elseKwd,
-	"ENABLE":              enable,
-	"ENCLOSED":            enclosed,
-	"END":                 end,
-	"ENGINE":              engine,
-	"ENGINES":             engines,
-	"ENUM":                enum,
-	"ESCAPE":              escape,
-	"ESCAPED":             escaped,
-	"EVENTS":              events,
-	"EXECUTE":             execute,
-	"EXISTS":              exists,
-	"EXPLAIN":             explain,
-	"EXTRACT":             extract,
-	"FALSE":               falseKwd,
-	"FIELDS":              fields,
-	"FIRST":               first,
-	"FIXED":               fixed,
-	"FOREIGN":             foreign,
-	"FOR":                 forKwd,
-	"FORCE":               force,
-	"FOUND_ROWS":          foundRows,
-	"FROM":                from,
-	"FROM_UNIXTIME":       fromUnixTime,
-	"FULL":                full,
-	"FULLTEXT":            fulltext,
-	"FUNCTION":            function,
-	"FLUSH":               flush,
-	"GET_LOCK":            getLock,
-	"GLOBAL":              global,
-	"GRANT":               grant,
-	"GRANTS":              grants,
-	"GREATEST":            greatest,
-	"GROUP":               group,
-	"GROUP_CONCAT":        groupConcat,
-	"HASH":                hash,
-	"HAVING":              having,
-	"HIGH_PRIORITY":       highPriority,
-	"HOUR":                hour,
-	"HEX":                 hex,
-	"UNHEX":               unhex,
-	"IDENTIFIED":          identified,
-	"IGNORE":              ignore,
-	"IF":                  ifKwd,
-	"IFNULL":              ifNull,
-	"IN":                  in,
-	"INDEX":               index,
-	"INDEXES":             indexes,
-	"INFILE":              infile,
-	"INNER":               inner,
-	"INSERT":              insert,
-	"INTERVAL":            interval,
-	"INTO":                into,
-	"IS":                  is,
-	"ISNULL":              isNull,
-	"ISOLATION":           isolation,
-	"JOIN":                join,
-	"KEY":                 key,
-	"KEY_BLOCK_SIZE":      keyBlockSize,
-	"KEYS":                keys,
-	"LAST_INSERT_ID":      lastInsertID,
-	"LEADING":             leading,
-	"LEAST":               least,
-	"LEFT":                left,
-	"LENGTH":              length,
-	"LESS":                less,
-	"LEVEL":               level,
-	"LIKE":                like,
-	"LIMIT":               limit,
-	"LINES":               lines,
-	"LN":                  ln,
-	"LOAD":                load,
-	"LOCAL":               local,
-	"LOCATE":              locate,
-	"LOCK":                lock,
-	"LOG":                 log,
-	"LOG2":                log2,
-	"LOG10":               log10,
-	"LOWER":               lower,
-	"LCASE":               lcase,
-	"LOW_PRIORITY":        lowPriority,
-	"LTRIM":               ltrim,
-	"MAX":                 max,
-	"MAXVALUE":            maxValue,
-	"MAX_ROWS":            maxRows,
-	"MICROSECOND":         microsecond,
-	"MIN":                 min,
-	"MINUTE":              minute,
-	"MIN_ROWS":            minRows,
-	"MOD":                 mod,
-	"MODE":                mode,
-	"MODIFY":              modify,
-	"MONTH":               month,
-	"MONTHNAME":           monthname,
-	"NAMES":               names,
-	"NATIONAL":            national,
-	"NOT":                 not,
-	"NO_WRITE_TO_BINLOG":  noWriteToBinLog,
-	"NULL":                null,
-	"NULLIF":              nullIf,
-	"OFFSET":              offset,
-	"ON":                  on,
-	"ONLY":                only,
-	"OPTION":              option,
-	"OR":                  or,
-	"ORDER":               order,
-	"OUTER":               outer,
-	"PASSWORD":            password,
-	"POW":                 pow,
-	"POWER":               power,
-	"PREPARE":             prepare,
-	"PRIMARY":             primary,
-	"PRIVILEGES":          privileges,
-	"PROCEDURE":           procedure,
-	"PROCESSLIST":         processlist,
-	"QUARTER":             quarter,
-	"QUICK":               quick,
-	"RANGE":               rangeKwd,
-	"RAND":                rand,
-	"READ":                read,
-	"REDUNDANT":           redundant,
-	"REFERENCES":          references,
-	"REGEXP":              regexpKwd,
-	"RELEASE_LOCK":        releaseLock,
-	"REPEAT":              repeat,
-	"REPEATABLE":          repeatable,
-	"REPLACE":             replace,
-	"RIGHT":               right,
-	"RLIKE":               rlike,
-	"ROLLBACK":            rollback,
-	"ROUND":               round,
-	"ROW":                 row,
-	"ROW_FORMAT":          rowFormat,
-	"RTRIM":               rtrim,
-	"REVERSE":             reverse,
-	"SCHEMA":              schema,
-	"SCHEMAS":             schemas,
-	"SECOND":              second,
-	"SELECT":              selectKwd,
-	"SERIALIZABLE":        serializable,
-	"SESSION":             session,
-	"SET":                 set,
-	"SHARE":               share,
-	"SHOW":                show,
-	"SLEEP":               sleep,
-	"SIGNED":              signed,
-	"SNAPSHOT":            snapshot,
-	"SOME":                some,
-	"SPACE":               space,
-	"START":               start,
-	"STARTING":            starting,
-	"STATS_PERSISTENT":    statsPersistent,
-	"STATUS":              status,
-	"SUBDATE":             subDate,
-	"STRCMP":              strcmp,
-	"STR_TO_DATE":         strToDate,
-	"SUBSTR":              substring,
-	"SUBSTRING":           substring,
-	"SUBSTRING_INDEX":     substringIndex,
-	"SUM":                 sum,
-	"SYSDATE":             sysDate,
-	"TABLE":               tableKwd,
-	"TABLES":              tables,
-	"TERMINATED":          terminated,
-	"TIMEDIFF":            timediff,
-	"THAN":                than,
-	"THEN":                then,
-	"TO":                  to,
-	"TRAILING":            trailing,
-	"TRANSACTION":         transaction,
-	"TRIGGERS":            triggers,
-	"TRIM":                trim,
-	"TRUE":                trueKwd,
-	"TRUNCATE":            truncate,
-	"UNCOMMITTED":         uncommitted,
-	"UNKNOWN":             unknown,
-	"UNION":               union,
-	"UNIQUE":              unique,
-	"UNLOCK":              unlock,
-	"UNSIGNED":            unsigned,
-	"UPDATE":              update,
-	"UPPER":               upper,
-	"UCASE":               ucase,
-	"USE":                 use,
-	"USER":                user,
-	"USING":               using,
-	"VALUE":               value,
-	"VALUES":              values,
-	"VARIABLES":           variables,
-	"VERSION":             version,
-	"VIEW":                view,
-	"WARNINGS":            warnings,
-	"WEEK":                week,
-	"WEEKDAY":             weekday,
-	"WEEKOFYEAR":          weekofyear,
-	"WHEN":                when,
-	"WHERE":               where,
-	"WITH":                with,
-	"WRITE":               write,
-	"XOR":                 xor,
-	"YEARWEEK":            yearweek,
-	"ZEROFILL":            zerofill,
-	"SQL_CALC_FOUND_ROWS": calcFoundRows,
-	"SQL_CACHE":           sqlCache,
-	"SQL_NO_CACHE":        sqlNoCache,
-	"CURRENT_TIMESTAMP":   currentTs,
-	"LOCALTIME":           localTime,
-	"LOCALTIMESTAMP":      localTs,
-	"NOW":                 now,
-	"TINY":                tinyIntType,
-	"TINYINT":             tinyIntType,
-	"SMALLINT":            smallIntType,
-	"MEDIUMINT":           mediumIntType,
-	"INT":                 intType,
-	"INTEGER":             integerType,
-	"BIGINT":              bigIntType,
-	"BIT":                 bitType,
-	"DECIMAL":             decimalType,
-	"NUMERIC":             numericType,
-	"FLOAT":               floatType,
-	"DOUBLE":              doubleType,
-	"PRECISION":           precisionType,
-	"REAL":                realType,
-	"DATE":                dateType,
-	"TIME":                timeType,
-	"DATETIME":            datetimeType,
-	"TIMESTAMP":           timestampType,
-	"YEAR":                yearType,
-	"CHAR":                charType,
-	"VARCHAR":             varcharType,
-	"BINARY":              binaryType,
-	"VARBINARY":           varbinaryType,
-	"TINYBLOB":            tinyblobType,
-	"BLOB":                blobType,
-	"MEDIUMBLOB":          mediumblobType,
-	"LONGBLOB":            longblobType,
-	"TINYTEXT":            tinytextType,
-	"TEXT":                textType,
-	"MEDIUMTEXT":          mediumtextType,
-	"LONGTEXT":            longtextType,
-	"BOOL":                boolType,
-	"BOOLEAN":             booleanType,
-	"SECOND_MICROSECOND":  secondMicrosecond,
-	"MINUTE_MICROSECOND":  minuteMicrosecond,
-	"MINUTE_SECOND":       minuteSecond,
-	"HOUR_MICROSECOND":    hourMicrosecond,
-	"HOUR_SECOND":         hourSecond,
-	"HOUR_MINUTE":         hourMinute,
-	"DAY_MICROSECOND":     dayMicrosecond,
-	"DAY_SECOND":          daySecond,
-	"DAY_MINUTE":          dayMinute,
-	"DAY_HOUR":            dayHour,
-	"YEAR_MONTH":          yearMonth,
-	"RESTRICT":            restrict,
-	"CASCADE":             cascade,
-	"NO":                  no,
-	"ACTION":              action,
-	"PARTITION":           partition,
-	"PARTITIONS":          partitions,
-	"RPAD":                rpad,
-	"BIT_LENGTH":          bitLength,
-	"CHAR_FUNC":           charFunc,
-	"CHAR_LENGTH":         charLength,
-	"CHARACTER_LENGTH":    charLength,
-	"CONV":                conv,
-	"BIT_XOR":             bitXor,
-	"CRC32":               crc32,
+	"ABS":                        abs,
+	"ADD":                        add,
+	"ADDDATE":                    addDate,
+	"ADMIN":                      admin,
+	"AFTER":                      after,
+	"ALL":                        all,
+	"ALTER":                      alter,
+	"ANALYZE":                    analyze,
+	"AND":                        and,
+	"ANY":                        any,
+	"AS":                         as,
+	"ASC":                        asc,
+	"ASCII":                      ascii,
+	"AUTO_INCREMENT":             autoIncrement,
+	"AVG":                        avg,
+	"AVG_ROW_LENGTH":             avgRowLength,
+	"BEGIN":                      begin,
+	"BETWEEN":                    between,
+	"BINLOG":                     binlog,
+	"BOTH":                       both,
+	"BTREE":                      btree,
+	"BY":                         by,
+	"BYTE":                       byteType,
+	"CASE":                       caseKwd,
+	"CAST":                       cast,
+	"CEIL":                       ceil,
+	"CEILING":                    ceiling,
+	"CHANGE":                     change,
+	"CHARACTER":                  character,
+	"CHARSET":                    charsetKwd,
+	"CHECK":                      check,
+	"CHECKSUM":                   checksum,
+	"COALESCE":                   coalesce,
+	"COLLATE":                    collate,
+	"COLLATION":                  collation,
+	"COLUMN":                     column,
+	"COLUMNS":                    columns,
+	"COMMENT":                    comment,
+	"COMMIT":                     commit,
+	"COMMITTED":                  committed,
+	"COMPACT":                    compact,
+	"COMPRESSED":                 compressed,
+	"COMPRESSION":                compression,
+	"CONCAT":                     concat,
+	"CONCAT_WS":                  concatWs,
+	"CONNECTION":                 connection,
+	"CONNECTION_ID":              connectionID,
+	"CONSTRAINT":                 constraint,
+	"CONSISTENT":                 consistent,
+	"CONVERT":                    convert,
+	"COUNT":                      count,
+	"CREATE":                     create,
+	"CROSS":                      cross,
+	"CURDATE":                    curDate,
+	"UTC_DATE":                   utcDate,
+	"CURRENT_DATE":               currentDate,
+	"CURTIME":                    curTime,
+	"CURRENT_TIME":               currentTime,
+	"CURRENT_USER":               currentUser,
+	"DATA":                       data,
+	"DATABASE":                   database,
+	"DATABASES":                  databases,
+	"DATE_ADD":                   dateAdd,
+	"DATE_FORMAT":                dateFormat,
+	"DATE_SUB":                   dateSub,
+	"DAY":                        day,
+	"DAYNAME":                    dayname,
+	"DAYOFMONTH":                 dayofmonth,
+	"DAYOFWEEK":                  dayofweek,
+	"DAYOFYEAR":                  dayofyear,
+	"DDL":                        ddl,
+	"DEALLOCATE":                 deallocate,
+	"DEFAULT":                    defaultKwd,
+	"DELAYED":                    delayed,
+	"DELAY_KEY_WRITE":            delayKeyWrite,
+	"DELETE":                     deleteKwd,
+	"DESC":                       desc,
+	"DESCRIBE":                   describe,
+	"DISABLE":                    disable,
+	"DISTINCT":                   distinct,
+	"DIV":                        div,
+	"DO":                         do,
+	"DROP":                       drop,
+	"DUAL":                       dual,
+	"DUPLICATE":                  duplicate,
+	"DYNAMIC":                    dynamic,
+	"ELSE":                       elseKwd,
+	"ENABLE":                     enable,
+	"ENCLOSED":                   enclosed,
+	"END":                        end,
+	"ENGINE":                     engine,
+	"ENGINES":                    engines,
+	"ENUM":                       enum,
+	"ESCAPE":                     escape,
+	"ESCAPED":                    escaped,
+	"EVENTS":                     events,
+	"EXECUTE":                    execute,
+	"EXISTS":                     exists,
+	"EXPLAIN":                    explain,
+	"EXTRACT":                    extract,
+	"FALSE":                      falseKwd,
+	"FIELDS":                     fields,
+	"FIRST":                      first,
+	"FIXED":                      fixed,
+	"FOREIGN":                    foreign,
+	"FOR":                        forKwd,
+	"FORCE":                      force,
+	"FOUND_ROWS":                 foundRows,
+	"FROM":                       from,
+	"FROM_UNIXTIME":              fromUnixTime,
+	"FULL":                       full,
+	"FULLTEXT":                   fulltext,
+	"FUNCTION":                   function,
+	"FLUSH":                      flush,
+	"GET_LOCK":                   getLock,
+	"GLOBAL":                     global,
+	"GRANT":                      grant,
+	"GRANTS":                     grants,
+	"GREATEST":                   greatest,
+	"GROUP":                      group,
+	"GROUP_CONCAT":               groupConcat,
+	"HASH":                       hash,
+	"HAVING":                     having,
+	"HIGH_PRIORITY":              highPriority,
+	"HOUR":                       hour,
+	"HEX":                        hex,
+	"UNHEX":                      unhex,
+	"IDENTIFIED":                 identified,
+	"IGNORE":                     ignore,
+	"IF":                         ifKwd,
+	"IFNULL":                     ifNull,
+	"IN":                         in,
+	"INDEX":                      index,
+	"INDEXES":                    indexes,
+	"INFILE":                     infile,
+	"INNER":                      inner,
+	"INSERT":                     insert,
+	"INTERVAL":                   interval,
+	"INTO":                       into,
+	"IS":                         is,
+	"ISNULL":                     isNull,
+	"ISOLATION":                  isolation,
+	"JOIN":                       join,
+	"JSON":                       jsonType,
+	"KEY":                        key,
+	"KEY_BLOCK_SIZE":             keyBlockSize,
+	"KEYS":                       keys,
+	"LAST_INSERT_ID":             lastInsertID,
+	"LEADING":                    leading,
+	"LEAST":                      least,
+	"LEFT":                       left,
+	"LENGTH":                     length,
+	"LESS":                       less,
+	"LEVEL":                      level,
+	"LIKE":                       like,
+	"LIMIT":                      limit,
+	"LINES":                      lines,
+	"LN":                         ln,
+	"LOAD":                       load,
+	"LOCAL":                      local,
+	"LOCATE":                     locate,
+	"LOCK":                       lock,
+	"LOG":                        log,
+	"LOG2":                       log2,
+	"LOG10":                      log10,
+	"LOWER":                      lower,
+	"LCASE":                      lcase,
+	"LOW_PRIORITY":               lowPriority,
+	"LTRIM":                      ltrim,
+	"MAX":                        max,
+	"MAXVALUE":                   maxValue,
+	"MAX_ROWS":                   maxRows,
+	"MICROSECOND":                microsecond,
+	"MIN":                        min,
+	"MINUTE":                     minute,
+	"MIN_ROWS":                   minRows,
+	"MOD":                        mod,
+	"MODE":                       mode,
+	"MODIFY":                     modify,
+	"MONTH":                      month,
+	"MONTHNAME":                  monthname,
+	"NAMES":                      names,
+	"NATIONAL":                   national,
+	"NOT":                        not,
+	"NO_WRITE_TO_BINLOG":         noWriteToBinLog,
+	"NULL":                       null,
+	"NULLIF":                     nullIf,
+	"OFFSET":                     offset,
+	"ON":                         on,
+	"ONLY":                       only,
+	"OPTION":                     option,
+	"OR":                         or,
+	"ORDER":                      order,
+	"OUTER":                      outer,
+	"PASSWORD":                   password,
+	"POW":                        pow,
+	"POWER":                      power,
+	"PREPARE":                    prepare,
+	"PRIMARY":                    primary,
+	"PRIVILEGES":                 privileges,
+	"PROCEDURE":                  procedure,
+	"PROCESSLIST":                processlist,
+	"QUARTER":                    quarter,
+	"QUICK":                      quick,
+	"RANGE":                      rangeKwd,
+	"RAND":                       rand,
+	"READ":                       read,
+	"REDUNDANT":                  redundant,
+	"REFERENCES":                 references,
+	"REGEXP":                     regexpKwd,
+	"RELEASE_LOCK":               releaseLock,
+	"REPEAT":                     repeat,
+	"REPEATABLE":                 repeatable,
+	"REPLACE":                    replace,
+	"RIGHT":                      right,
+	"RLIKE":                      rlike,
+	"ROLLBACK":                   rollback,
+	"ROUND":                      round,
+	"ROW":                        row,
+	"ROW_FORMAT":                 rowFormat,
+	"RTRIM":                      rtrim,
+	"REVERSE":                    reverse,
+	"SCHEMA":                     schema,
+	"SCHEMAS":                    schemas,
+	"SECOND":                     second,
+	"SELECT":                     selectKwd,
+	"SEPARATOR":                  separator,
+	"SERIALIZABLE":               serializable,
+	"SESSION":                    session,
+	"SET":                        set,
+	"SHARE":                      share,
+	"SHOW":                       show,
+	"SLEEP":                      sleep,
+	"SIGNED":                     signed,
+	"SNAPSHOT":                   snapshot,
+	"SOME":                       some,
+	"SPACE":                      space,
+	"START":                      start,
+	"STARTING":                   starting,
+	"STATS_PERSISTENT":           statsPersistent,
+	"STATUS":                     status,
+	"SUBDATE":                    subDate,
+	"STRCMP":                     strcmp,
+	"STR_TO_DATE":                strToDate,
+	"SUBSTR":                     substring,
+	"SUBSTRING":                  substring,
+	"SUBSTRING_INDEX":            substringIndex,
+	"SUM":                        sum,
+	"SYSDATE":                    sysDate,
+	"TABLE":                      tableKwd,
+	"TABLES":                     tables,
+	"TERMINATED":                 terminated,
+	"TIMEDIFF":                   timediff,
+	"THAN":                       than,
+	"THEN":                       then,
+	"TO":                         to,
+	"TRAILING":                   trailing,
+	"TRANSACTION":                transaction,
+	"TRIGGERS":                   triggers,
+	"TRIM":                       trim,
+	"TRUE":                       trueKwd,
+	"TRUNCATE":                   truncate,
+	"UNCOMMITTED":                uncommitted,
+	"UNKNOWN":                    unknown,
+	"UNION":                      union,
+	"UNIQUE":                     unique,
+	"UNLOCK":                     unlock,
+	"UNSIGNED":                   unsigned,
+	"UPDATE":                     update,
+	"UPPER":                      upper,
+	"UCASE":                      ucase,
+	"USE":                        use,
+	"USER":                       user,
+	"USING":                      using,
+	"VALUE":                      value,
+	"VALUES":                     values,
+	"VARIABLES":                  variables,
+	"VERSION":                    version,
+	"VIEW":                       view,
+	"WARNINGS":                   warnings,
+	"WEEK":                       week,
+	"WEEKDAY":                    weekday,
+	"WEEKOFYEAR":                 weekofyear,
+	"WHEN":                       when,
+	"WHERE":                      where,
+	"WITH":                       with,
+	"WRITE":                      write,
+	"XOR":                        xor,
+	"YEARWEEK":                   yearweek,
+	"ZEROFILL":                   zerofill,
+	"SQL_CALC_FOUND_ROWS":        calcFoundRows,
+	"SQL_CACHE":                  sqlCache,
+	"SQL_NO_CACHE":               sqlNoCache,
+	"CURRENT_TIMESTAMP":          currentTs,
+	"LOCALTIME":                  localTime,
+	"LOCALTIMESTAMP":             localTs,
+	"NOW":                        now,
+	"TINY":                       tinyIntType,
+	"TINYINT":                    tinyIntType,
+	"SMALLINT":                   smallIntType,
+	"MEDIUMINT":                  mediumIntType,
+	"INT":                        intType,
+	"INTEGER":                    integerType,
+	"BIGINT":                     bigIntType,
+	"BIT":                        bitType,
+	"DECIMAL":                    decimalType,
+	"NUMERIC":                    numericType,
+	"FLOAT":                      floatType,
+	"DOUBLE":                     doubleType,
+	"PRECISION":                  precisionType,
+	"REAL":                       realType,
+	"DATE":                       dateType,
+	"TIME":                       timeType,
+	"DATETIME":                   datetimeType,
+	"TIMESTAMP":                  timestampType,
+	"YEAR":                       yearType,
+	"CHAR":                       charType,
+	"VARCHAR":                    varcharType,
+	"BINARY":                     binaryType,
+	"VARBINARY":                  varbinaryType,
+	"TINYBLOB":                   tinyblobType,
+	"BLOB":                       blobType,
+	"MEDIUMBLOB":                 mediumblobType,
+	"LONGBLOB":                   longblobType,
+	"TINYTEXT":                   tinytextType,
+	"TEXT":                       textType,
+	"MEDIUMTEXT":                 mediumtextType,
+	"LONGTEXT":                   longtextType,
+	"BOOL":                       boolType,
+	"BOOLEAN":                    booleanType,
+	"SECOND_MICROSECOND":         secondMicrosecond,
+	"MINUTE_MICROSECOND":         minuteMicrosecond,
+	"MINUTE_SECOND":              minuteSecond,
+	"HOUR_MICROSECOND":           hourMicrosecond,
+	"HOUR_SECOND":                hourSecond,
+	"HOUR_MINUTE":                hourMinute,
+	"DAY_MICROSECOND":            dayMicrosecond,
+	"DAY_SECOND":                 daySecond,
+	"DAY_MINUTE":                 dayMinute,
+	"DAY_HOUR":                   dayHour,
+	"YEAR_MONTH":                 yearMonth,
+	"RESTRICT":                   restrict,
+	"CASCADE":                    cascade,
+	"NO":                         no,
+	"ACTION":                     action,
+	"PARTITION":                  partition,
+	"PARTITIONS":                 partitions,
+	"RPAD":                       rpad,
+	"BIT_LENGTH":                 bitLength,
+	"CHAR_FUNC":                  charFunc,
+	"CHAR_LENGTH":                charLength,
+	"CHARACTER_LENGTH":           charLength,
+	"CONV":                       conv,
+	"BIT_AND":                    bitAnd,
+	"BIT_OR":                     bitOr,
+	"BIT_XOR":                    bitXor,
+	"STD":                        std,
+	"STDDEV":                     stddev,
+	"STDDEV_POP":                 stddevPop,
+	"STDDEV_SAMP":                stddevSamp,
+	"VARIANCE":                   variance,
+	"VAR_POP":                    varPop,
+	"VAR_SAMP":                   varSamp,
+	"CRC32":                      crc32,
+	"UUID":                       uuid,
+	"UUID_TO_BIN":                uuidToBin,
+	"BIN_TO_UUID":                binToUUID,
+	"BENCHMARK":                  benchmark,
+	"WEIGHT_STRING":              weightString,
+	"TO_BASE64":                  toBase64,
+	"FROM_BASE64":                fromBase64,
+	"ENCODE":                     encode,
+	"DECODE":                     decode,
+	"OLD_PASSWORD":               oldPassword,
+	"VALIDATE_PASSWORD_STRENGTH": validatePasswordStrength,
+	"SIN":                        sin,
+	"COS":                        cos,
+	"TAN":                        tan,
+	"COT":                        cot,
+	"IS_FREE_LOCK":               isFreeLock,
+	"IS_USED_LOCK":               isUsedLock,
+	"RELEASE_ALL_LOCKS":          releaseAllLocks,
+	"MAKE_SET":                   makeSet,
+	"EXPORT_SET":                 exportSet,
+	"ANY_VALUE":                  anyValue,
+	"NAME_CONST":                 nameConst,
+	"FLOOR":                      floor,
+	"COERCIBILITY":               coercibility,
+	"EXP":                        exp,
+	"FORMAT_BYTES":               formatBytes,
+	"FORMAT_PICO_TIME":           formatPicoTime,
+	"GTID_SUBSET":                gtidSubset,
+	"GTID_SUBTRACT":              gtidSubtract,
+	"CURRENT_ROLE":               currentRole,
+	"JSON_PRETTY":                jsonPretty,
+	"JSON_STORAGE_SIZE":          jsonStorageSize,
+	"JSON_STORAGE_FREE":          jsonStorageFree,
+	"JSON_QUOTE":                 jsonQuote,
+	"JSON_SEARCH":                jsonSearch,
+	"JSON_REMOVE":                jsonRemove,
+	"JSON_ARRAY_APPEND":          jsonArrayAppend,
+	"JSON_ARRAY_INSERT":          jsonArrayInsert,
+	"BIN":                        bin,
+	"OCT":                        oct,
+	"SQRT":                       sqrt,
+	"GROUPING":                   grouping,
+	"ORD":                        ord,
+	"LPAD":                       lpad,
+	"ADDTIME":                    addTime,
+	"SEC_TO_TIME":                secToTime,
+	"TIMESTAMPADD":               timestampAdd,
 }
 
 func isTokenIdentifier(s string, buf *bytes.Buffer) int {
@@ -293,8 +293,64 @@ import (
 	charLength	"CHAR_LENGTH"
 	characterLength	"CHARACTER_LENGTH"
 	conv		"CONV"
+	bitAnd		"BIT_AND"
+	bitOr		"BIT_OR"
 	bitXor		"BIT_XOR"
+	std		"STD"
+	stddev		"STDDEV"
+	stddevPop	"STDDEV_POP"
+	stddevSamp	"STDDEV_SAMP"
+	variance	"VARIANCE"
+	varPop		"VAR_POP"
+	varSamp		"VAR_SAMP"
 	crc32		"CRC32"
+	uuid		"UUID"
+	uuidToBin	"UUID_TO_BIN"
+	binToUUID	"BIN_TO_UUID"
+	benchmark	"BENCHMARK"
+	weightString	"WEIGHT_STRING"
+	toBase64	"TO_BASE64"
+	fromBase64	"FROM_BASE64"
+	encode		"ENCODE"
+	decode		"DECODE"
+	oldPassword	"OLD_PASSWORD"
+	validatePasswordStrength	"VALIDATE_PASSWORD_STRENGTH"
+	sin		"SIN"
+	cos		"COS"
+	tan		"TAN"
+	cot		"COT"
+	isFreeLock	"IS_FREE_LOCK"
+	isUsedLock	"IS_USED_LOCK"
+	releaseAllLocks	"RELEASE_ALL_LOCKS"
+	makeSet		"MAKE_SET"
+	exportSet	"EXPORT_SET"
+	anyValue	"ANY_VALUE"
+	nameConst	"NAME_CONST"
+	floor		"FLOOR"
+	coercibility	"COERCIBILITY"
+	exp		"EXP"
+	formatBytes	"FORMAT_BYTES"
+	formatPicoTime	"FORMAT_PICO_TIME"
+	gtidSubset	"GTID_SUBSET"
+	gtidSubtract	"GTID_SUBTRACT"
+	currentRole	"CURRENT_ROLE"
+	jsonPretty	"JSON_PRETTY"
+	jsonStorageSize	"JSON_STORAGE_SIZE"
+	jsonStorageFree	"JSON_STORAGE_FREE"
+	jsonQuote	"JSON_QUOTE"
+	jsonSearch	"JSON_SEARCH"
+	jsonRemove	"JSON_REMOVE"
+	jsonArrayAppend	"JSON_ARRAY_APPEND"
+	jsonArrayInsert	"JSON_ARRAY_INSERT"
+	bin		"BIN"
+	oct		"OCT"
+	sqrt		"SQRT"
+	grouping	"GROUPING"
+	ord		"ORD"
+	lpad		"LPAD"
+	addTime		"ADDTIME"
+	secToTime	"SEC_TO_TIME"
+	timestampAdd	"TIMESTAMPADD"
 
 	/* the following tokens belong to UnReservedKeyword*/
 	action		"ACTION"
@@ -349,6 +405,7 @@ import (
 	identified	"IDENTIFIED"
 	isolation	"ISOLATION"
 	indexes		"INDEXES"
+	jsonType	"JSON"
 	keyBlockSize	"KEY_BLOCK_SIZE"
 	local		"LOCAL"
 	less		"LESS"
@@ -374,6 +431,7 @@ import (
 	rollback	"ROLLBACK"
 	row 		"ROW"
 	rowFormat	"ROW_FORMAT"
+	separator	"SEPARATOR"
 	serializable	"SERIALIZABLE"
 	session		"SESSION"
 	share		"SHARE"
@@ -473,7 +531,6 @@ import (
 	CreateTableStmt		"CREATE TABLE statement"
 	CreateUserStmt		"CREATE User statement"
 	DateArithOpt		"Date arith dateadd or datesub option"
-	DateArithMultiFormsOpt	"Date arith adddate or subdate option"
 	DateArithInterval       "Date arith interval part"
 	DBName			"Database Name"
 	DeallocateStmt		"Deallocate prepared statement"
@@ -672,6 +729,7 @@ import (
 	Precision		"Floating-point precision option"
 	OptBinary		"Optional BINARY"
 	OptCharset		"Optional Character setting"
+	OptGConcatSeparator	"optional GROUP_CONCAT SEPARATOR"
 	OptCollate		"Optional Collate setting"
 	NUM			"numbers"
 	LengthNum		"Field length num(uint64)"
@@ -685,9 +743,11 @@ import (
 	DatabaseSym		"DATABASE or SCHEMA"
 	ExplainSym		"EXPLAIN or DESCRIBE or DESC"
 	RegexpSym		"REGEXP or RLIKE"
+	DateArithMultiFormsOpt	"ADDDATE or SUBDATE"
 	IntoOpt			"INTO or EmptyString"
 	ValueSym		"Value or Values"
 	TimeUnit		"Time unit"
+	TimestampUnit		"Time unit of TIMESTAMPADD"
 	DeallocateSym		"Deallocate or drop"
 	OuterOpt		"optional OUTER clause"
 	CrossOpt		"Cross join option"
@@ -1721,7 +1781,7 @@ logAnd:
 "&&" | "AND"
 
 ExpressionList:
-	Expression
+	Expression %prec lowerThanComma
 	{
 		$$ = []ast.ExprNode{$1.(ast.ExprNode)}
 	}
@@ -1839,14 +1899,16 @@ PredicateExpr:
 		if len(escape) > 1 {
 			yylex.Errorf("Incorrect arguments %s to ESCAPE", escape)
 			return 1
-		} else if len(escape) == 0 {
-			escape = "\\"
+		}
+		var escapeChar byte
+		if len(escape) == 1 {
+			escapeChar = escape[0]
 		}
 		$$ = &ast.PatternLikeExpr{
 			Expr:		$1.(ast.ExprNode),
 			Pattern:	$4.(ast.ExprNode),
 			Not: 		$2.(bool),
-			Escape: 	escape[0],
+			Escape: 	escapeChar,
 		}
 	}
 |	PrimaryFactor NotOpt RegexpSym PrimaryExpression
@@ -1861,7 +1923,7 @@ RegexpSym:
 LikeEscapeOpt:
 	%prec lowerThanEscape
 	{
-		$$ = "\\"
+		$$ = ""
 	}
 |	"ESCAPE" stringLit
 	{
@@ -2065,6 +2127,7 @@ UnReservedKeyword:
 | "MIN_ROWS" | "NATIONAL" | "ROW" | "ROW_FORMAT" | "QUARTER" | "GRANTS" | "TRIGGERS" | "DELAY_KEY_WRITE" | "ISOLATION"
 | "REPEATABLE" | "COMMITTED" | "UNCOMMITTED" | "ONLY" | "SERIALIZABLE" | "LEVEL" | "VARIABLES" | "SQL_CACHE" | "INDEXES" | "PROCESSLIST"
 | "SQL_NO_CACHE" | "DISABLE"  | "ENABLE" | "REVERSE" | "SPACE" | "PRIVILEGES" | "NO" | "BINLOG" | "FUNCTION" | "VIEW" | "MODIFY" | "EVENTS" | "PARTITIONS"
+| "SEPARATOR" | "JSON"
 
 ReservedKeyword:
 "ADD" | "ALL" | "ALTER" | "ANALYZE" | "AND" | "AS" | "ASC" | "BETWEEN" | "BIGINT"
@@ -2099,6 +2162,45 @@ NotKeywordToken:
 |	"SECOND" | "SLEEP" | "SQL_CALC_FOUND_ROWS" | "STR_TO_DATE" | "SUBDATE" | "SUBSTRING" %prec lowerThanLeftParen |
 "SUBSTRING_INDEX" | "SUM" | "TRIM" | "RTRIM" | "UCASE" | "UPPER" | "VERSION" | "WEEKDAY" | "WEEKOFYEAR" | "YEARWEEK" | "ROUND"
 |	"STATS_PERSISTENT" | "GET_LOCK" | "RELEASE_LOCK" | "CEIL" | "CEILING" | "FROM_UNIXTIME" | "TIMEDIFF" | "LN" | "LOG" | "LOG2" | "LOG10"
+|	"UUID" | "UUID_TO_BIN" | "BIN_TO_UUID"
+|	"BENCHMARK"
+|	"WEIGHT_STRING"
+|	"TO_BASE64" | "FROM_BASE64"
+|	"ENCODE" | "DECODE"
+|	"BIT_AND" | "BIT_OR" | "BIT_XOR"
+|	"STD" | "STDDEV" | "STDDEV_POP" | "STDDEV_SAMP" | "VARIANCE" | "VAR_POP" | "VAR_SAMP"
+|	"OLD_PASSWORD" | "VALIDATE_PASSWORD_STRENGTH"
+|	"SIN" | "COS" | "TAN" | "COT"
+|	"IS_FREE_LOCK" | "IS_USED_LOCK"
+|	"RELEASE_ALL_LOCKS"
+|	"MAKE_SET" | "EXPORT_SET"
+|	"ANY_VALUE"
+|	"NAME_CONST"
+|	"FLOOR"
+|	"COERCIBILITY"
+|	"EXP"
+|	"FORMAT_BYTES"
+|	"FORMAT_PICO_TIME"
+|	"GTID_SUBSET"
+|	"GTID_SUBTRACT"
+|	"CURRENT_ROLE"
+|	"JSON_PRETTY"
+|	"JSON_STORAGE_SIZE"
+|	"JSON_STORAGE_FREE"
+|	"JSON_QUOTE"
+|	"JSON_SEARCH"
+|	"JSON_REMOVE"
+|	"JSON_ARRAY_APPEND"
+|	"JSON_ARRAY_INSERT"
+|	"BIN"
+|	"OCT"
+|	"SQRT"
+|	"GROUPING"
+|	"ORD"
+|	"LPAD"
+|	"ADDTIME"
+|	"SEC_TO_TIME"
+|	"TIMESTAMPADD"
 
 /************************************************************************************
  *
@@ -2428,8 +2530,10 @@ PrimaryExpression:
 	}
 |	PrimaryExpression "COLLATE" StringName %prec neg
 	{
-		// TODO: Create a builtin function hold expr and collation. When do evaluation, convert expr result using the collation.
-		$$ = $1
+		$$ = &ast.FuncCallExpr{
+			FnName: model.NewCIStr(ast.Collate),
+			Args: []ast.ExprNode{$1.(ast.ExprNode), ast.NewValueExpr($3)},
+		}
 	}
 
 Function:
@@ -2471,6 +2575,12 @@ FunctionCallConflict:
 	{
 		$$ = &ast.BinaryOperationExpr{Op: opcode.Mod, L: $3.(ast.ExprNode), R: $5.(ast.ExprNode)}
 	}
+|	"INTERVAL" '(' Expression ',' ExpressionList ')'
+	{
+		// See https://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_interval
+		args := append([]ast.ExprNode{$3.(ast.ExprNode)}, $5.([]ast.ExprNode)...)
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: args}
+	}
 
 DistinctOpt:
 	{
@@ -2607,6 +2717,14 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
 	}
+|	"CHARSET" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"COLLATION" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
 |	"DAY" '(' Expression ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
@@ -2648,15 +2766,9 @@ FunctionCallNonKeyword:
 	}
 |	DateArithMultiFormsOpt '(' Expression ',' DateArithInterval')'
 	{
-		op := ast.NewValueExpr($1)
-		dateArithInterval := ast.NewValueExpr($5)
 		$$ = &ast.FuncCallExpr{
-			FnName: model.NewCIStr("DATE_ARITH"),
-			Args: []ast.ExprNode{
-				op,
-				$3.(ast.ExprNode),
-				dateArithInterval,
-			},
+			FnName: model.NewCIStr($1),
+			Args: []ast.ExprNode{$3.(ast.ExprNode), $5.(ast.ExprNode)},
 		}
 	}
 |	"DATE_FORMAT" '(' Expression ',' Expression ')'
@@ -2916,6 +3028,24 @@ FunctionCallNonKeyword:
 			Args: []ast.ExprNode{$3.(ast.ExprNode), $5.(ast.ExprNode)},
 		}
 	}
+|	"ADDTIME" '(' Expression ',' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{
+			FnName: model.NewCIStr($1),
+			Args: []ast.ExprNode{$3.(ast.ExprNode), $5.(ast.ExprNode)},
+		}
+	}
+|	"SEC_TO_TIME" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"TIMESTAMPADD" '(' TimestampUnit ',' Expression ',' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{
+			FnName: model.NewCIStr($1),
+			Args: []ast.ExprNode{ast.NewValueExpr($3), $5.(ast.ExprNode), $7.(ast.ExprNode)},
+		}
+	}
 |	"TRIM" '(' Expression ')'
 	{
 		$$ = &ast.FuncCallExpr{
@@ -2983,6 +3113,13 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
 	}
+|	"LPAD" '(' Expression ',' Expression ',' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{
+			FnName: model.NewCIStr($1),
+			Args: []ast.ExprNode{$3.(ast.ExprNode), $5.(ast.ExprNode), $7.(ast.ExprNode)},
+		}
+	}
 |	"RPAD" '(' Expression ',' Expression ',' Expression ')'
 	{
 		$$ = &ast.FuncCallExpr{
@@ -3043,6 +3180,197 @@ FunctionCallNonKeyword:
 			Args: []ast.ExprNode{$3.(ast.ExprNode)},
 		}
 	}
+|	"UUID" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"UUID_TO_BIN" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"BIN_TO_UUID" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"BENCHMARK" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"WEIGHT_STRING" '(' Expression ')'
+	{
+		// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_weight-string
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"WEIGHT_STRING" '(' Expression "AS" "CHAR" FieldLen ')'
+	{
+		$$ = &ast.FuncCallExpr{
+			FnName: model.NewCIStr($1),
+			Args: []ast.ExprNode{$3.(ast.ExprNode), ast.NewValueExpr("CHAR"), ast.NewValueExpr($6)},
+		}
+	}
+|	"WEIGHT_STRING" '(' Expression "AS" "BINARY" FieldLen ')'
+	{
+		$$ = &ast.FuncCallExpr{
+			FnName: model.NewCIStr($1),
+			Args: []ast.ExprNode{$3.(ast.ExprNode), ast.NewValueExpr("BINARY"), ast.NewValueExpr($6)},
+		}
+	}
+|	"TO_BASE64" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"FROM_BASE64" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"ENCODE" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"DECODE" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"PASSWORD" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"OLD_PASSWORD" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"VALIDATE_PASSWORD_STRENGTH" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"SIN" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"COS" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"TAN" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"COT" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"IS_FREE_LOCK" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"IS_USED_LOCK" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"RELEASE_ALL_LOCKS" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"MAKE_SET" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"EXPORT_SET" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"ANY_VALUE" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"NAME_CONST" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"FLOOR" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"COERCIBILITY" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"EXP" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"FORMAT_BYTES" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"FORMAT_PICO_TIME" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"GTID_SUBSET" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"GTID_SUBTRACT" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"CURRENT_ROLE" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_PRETTY" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_STORAGE_SIZE" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_STORAGE_FREE" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_QUOTE" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_SEARCH" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_REMOVE" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_ARRAY_APPEND" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_ARRAY_INSERT" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"BIN" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"OCT" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"SQRT" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"GROUPING" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"ORD" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
 
 
 DateArithOpt:
@@ -3056,26 +3384,14 @@ DateArithOpt:
 	}
 
 DateArithMultiFormsOpt:
-	"ADDDATE"
-	{
-		$$ = ast.DateAdd
-	}
-|	"SUBDATE"
-	{
-		$$ = ast.DateSub
-	}
+	"ADDDATE" | "SUBDATE"
 
+/* The second argument of ADDDATE and SUBDATE is either an interval or a number of days. */
 DateArithInterval:
 	Expression
-	{
-		$$ = ast.DateArithInterval{
-					Unit: "day",
-					Interval: $1.(ast.ExprNode),
-		}
-	}
 |	"INTERVAL" Expression TimeUnit
 	{
-		$$ = ast.DateArithInterval{Unit: $3, Interval: $2.(ast.ExprNode)}
+		$$ = ast.NewValueExpr(ast.DateArithInterval{Unit: $3, Interval: $2.(ast.ExprNode)})
 	}
 
 TrimDirection:
@@ -3106,9 +3422,13 @@ FunctionCallAgg:
 		args := []ast.ExprNode{ast.NewValueExpr(1)}
 		$$ = &ast.AggregateFuncExpr{F: $1, Args: args, Distinct: $3.(bool)}
 	}
-|	"GROUP_CONCAT" '(' DistinctOpt ExpressionList ')'
+|	"GROUP_CONCAT" '(' DistinctOpt ExpressionList OrderByOptional OptGConcatSeparator ')'
 	{
-		$$ = &ast.AggregateFuncExpr{F: $1, Args: $4.([]ast.ExprNode), Distinct: $3.(bool)}
+		agg := &ast.AggregateFuncExpr{F: $1, Args: $4.([]ast.ExprNode), Distinct: $3.(bool), Separator: $6.(string)}
+		if $5 != nil {
+			agg.Order = $5.(*ast.OrderByClause)
+		}
+		$$ = agg
 	}
 |	"MAX" '(' DistinctOpt Expression ')'
 	{
@@ -3122,10 +3442,55 @@ FunctionCallAgg:
 	{
 		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
 	}
+|	"BIT_AND" '(' DistinctOpt Expression ')'
+	{
+		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
+	}
+|	"BIT_OR" '(' DistinctOpt Expression ')'
+	{
+		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
+	}
 |	"BIT_XOR" '(' DistinctOpt Expression ')'
 	{
 		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
 	}
+|	"STD" '(' DistinctOpt Expression ')'
+	{
+		$$ = &ast.AggregateFuncExpr{F: ast.AggFuncStddevPop, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
+	}
+|	"STDDEV" '(' DistinctOpt Expression ')'
+	{
+		$$ = &ast.AggregateFuncExpr{F: ast.AggFuncStddevPop, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
+	}
+|	"STDDEV_POP" '(' DistinctOpt Expression ')'
+	{
+		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
+	}
+|	"STDDEV_SAMP" '(' DistinctOpt Expression ')'
+	{
+		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
+	}
+|	"VARIANCE" '(' DistinctOpt Expression ')'
+	{
+		$$ = &ast.AggregateFuncExpr{F: ast.AggFuncVarPop, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
+	}
+|	"VAR_POP" '(' DistinctOpt Expression ')'
+	{
+		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
+	}
+|	"VAR_SAMP" '(' DistinctOpt Expression ')'
+	{
+		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
+	}
+
+OptGConcatSeparator:
+	{
+		$$ = ","
+	}
+|	"SEPARATOR" stringLit
+	{
+		$$ = $2
+	}
 
 FuncDatetimePrec:
 	{
@@ -3162,6 +3527,18 @@ TimeUnit:
 |	"DAY_HOUR"
 |	"YEAR_MONTH"
 
+/* TIMESTAMPADD only accepts the single units. */
+TimestampUnit:
+	"MICROSECOND"
+|	"SECOND"
+|	"MINUTE"
+|	"HOUR"
+|	"DAY"
+|	"WEEK"
+|	"MONTH"
+|	"QUARTER"
+|	"YEAR"
+
 ExpressionOpt:
 	{
 		$$ = nil
@@ -3259,6 +3636,11 @@ CastType:
 		x.Flag |= mysql.UnsignedFlag
 		$$ = x
 	}
+|	"JSON"
+	{
+		x := types.NewFieldType(mysql.TypeJSON)
+		$$ = x
+	}
 
 
 PrimaryFactor:
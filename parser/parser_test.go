@@ -89,7 +89,11 @@ func (s *testParserSuite) TestSimple(c *C) {
 		"compact", "redundant", "sql_no_cache sql_no_cache", "sql_cache sql_cache", "action", "round",
 		"enable", "disable", "reverse", "space", "privileges", "get_lock", "release_lock", "sleep", "no", "greatest", "least",
 		"binlog", "hex", "unhex", "function", "indexes", "from_unixtime", "processlist", "events", "less", "than", "timediff",
-		"ln", "log", "log2", "log10",
+		"ln", "log", "log2", "log10", "uuid", "uuid_to_bin", "bin_to_uuid",
+		"benchmark", "weight_string", "to_base64", "from_base64",
+		"encode", "decode", "old_password", "validate_password_strength", "separator",
+		"bit_and", "bit_or", "bit_xor", "sin", "cos", "tan", "cot", "std", "stddev", "stddev_pop", "stddev_samp", "variance", "var_pop", "var_samp",
+		"is_free_lock", "is_used_lock", "release_all_locks", "make_set", "export_set", "any_value", "name_const", "floor", "coercibility", "exp", "format_bytes", "format_pico_time", "gtid_subset", "gtid_subtract", "current_role", "json_pretty", "json_storage_size", "json_storage_free", "json_quote", "json_search", "json_remove", "json_array_append", "json_array_insert", "json", "bin", "oct", "sqrt", "grouping", "ord", "lpad", "addtime", "sec_to_time", "timestampadd",
 	}
 	for _, kw := range unreservedKws {
 		src := fmt.Sprintf("SELECT %s FROM tbl;", kw)
@@ -496,6 +500,7 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 	table := []testCase{
 		// For buildin functions
 		{"SELECT POW(1, 2)", true},
+		{"SELECT SIN(1), COS(1), TAN(1), COT(1)", true},
 		{"SELECT POW(1, 0.5)", true},
 		{"SELECT POW(1, -1)", true},
 		{"SELECT POW(-1, 1)", true},
@@ -506,6 +511,21 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{"SELECT ROUND(1.23, 1);", true},
 		{"SELECT CEIL(-1.23);", true},
 		{"SELECT CEILING(1.23);", true},
+		{"SELECT FLOOR(-1.23);", true},
+		{"SELECT EXP(1);", true},
+		{"SELECT SQRT(4);", true},
+		{"SELECT a, GROUPING(a) FROM t GROUP BY a;", true},
+		{"SELECT FORMAT_BYTES(1024), FORMAT_PICO_TIME(1000);", true},
+		{"SELECT GTID_SUBSET('a:1', 'a:1-2'), GTID_SUBTRACT('a:1-2', 'a:1');", true},
+		{"SELECT CURRENT_ROLE();", true},
+		{`SELECT JSON_PRETTY('{"a": 1}');`, true},
+		{`SELECT JSON_STORAGE_SIZE('[1]'), JSON_STORAGE_FREE('[1]');`, true},
+		{`SELECT JSON_QUOTE('a');`, true},
+		{`SELECT JSON_SEARCH('["a"]', 'one', 'a');`, true},
+		{`SELECT JSON_SEARCH('["a"]', 'all', 'a', NULL, '$[0]', '$[1]');`, true},
+		{`SELECT JSON_REMOVE('["a"]', '$[0]', '$[1]');`, true},
+		{`SELECT JSON_ARRAY_APPEND('["a"]', '$', 1, '$[0]', 2);`, true},
+		{`SELECT JSON_ARRAY_INSERT('["a"]', '$[0]', 1);`, true},
 		{"SELECT LN(1);", true},
 		{"SELECT LOG(-2);", true},
 		{"SELECT LOG(2, 65536);", true},
@@ -536,6 +556,9 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{"SELECT CURRENT_USER;", true},
 		{"SELECT CONNECTION_ID();", true},
 		{"SELECT VERSION();", true},
+		{"SELECT COERCIBILITY('abc');", true},
+		{"SELECT CHARSET('abc');", true},
+		{"SELECT COLLATION('abc');", true},
 
 		{"SELECT SUBSTRING_INDEX('www.mysql.com', '.', 2);", true},
 		{"SELECT SUBSTRING_INDEX('www.mysql.com', '.', -2);", true},
@@ -563,6 +586,7 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 
 		// For cast with charset
 		{"SELECT *, CAST(data AS CHAR CHARACTER SET utf8) FROM t;", true},
+		{`SELECT CAST('[1, 2]' AS JSON), CAST(1 AS json);`, true},
 
 		// For last_insert_id
 		{"SELECT last_insert_id();", true},
@@ -580,6 +604,11 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{"select sysdate(), sysdate(6)", true},
 		{"SELECT time('01:02:03');", true},
 		{"SELECT TIMEDIFF('2000:01:01 00:00:00', '2000:01:01 00:00:00.000001');", true},
+		{"SELECT ADDTIME('2007-12-31 23:59:59.999999', '1 1:1:1.000002');", true},
+		{"SELECT SEC_TO_TIME(2378);", true},
+		{"SELECT TIMESTAMPADD(MINUTE, 1, '2003-01-02');", true},
+		{"SELECT TIMESTAMPADD(second, 1.5, '2003-01-02 00:00:00');", true},
+		{"SELECT TIMESTAMPADD(MINUTE_SECOND, 1, '2003-01-02');", false},
 
 		// Select current_time
 		{"select current_time", true},
@@ -662,6 +691,7 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{`SELECT RTRIM(' bar ');`, true},
 
 		{`SELECT RPAD('hi', 6, 'c');`, true},
+		{`SELECT LPAD('hi', 6, 'c');`, true},
 		{`SELECT BIT_LENGTH('hi');`, true},
 		{`SELECT CHAR(65);`, true},
 		{`SELECT CHAR_LENGTH('abc');`, true},
@@ -673,6 +703,46 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		// Sleep
 		{`SELECT SLEEP(10);`, true},
 
+		// UUID
+		{`SELECT UUID();`, true},
+		{`SELECT UUID_TO_BIN('6ccd780c-baba-1026-9564-5b8c656024db');`, true},
+		{`SELECT UUID_TO_BIN('6ccd780c-baba-1026-9564-5b8c656024db', 1);`, true},
+		{`SELECT BIN_TO_UUID(UUID_TO_BIN(UUID()), 1);`, true},
+
+		// Benchmark
+		{`SELECT BENCHMARK(1000000, ABS(-1));`, true},
+		{`SELECT BENCHMARK(10, 1 + 1);`, true},
+
+		// Weight string
+		{`SELECT WEIGHT_STRING('abc');`, true},
+		{`SELECT WEIGHT_STRING('abc' AS CHAR(5));`, true},
+		{`SELECT WEIGHT_STRING('abc' AS BINARY(2));`, true},
+		{`SELECT WEIGHT_STRING('abc' AS CHAR);`, false},
+		{`SELECT WEIGHT_STRING('abc', 'CHAR', 5);`, false},
+
+		// Base64
+		{`SELECT TO_BASE64('abc');`, true},
+		{`SELECT FROM_BASE64(TO_BASE64('abc'));`, true},
+		{`SELECT MAKE_SET(1, 'a', 'b'), EXPORT_SET(5, 'Y', 'N', ',', 4);`, true},
+		{`SELECT BIN(12), OCT(-1);`, true},
+		{`SELECT ORD('a'), ORD(NULL);`, true},
+
+		// Encode and decode
+		{`SELECT ENCODE('abc', 'key');`, true},
+		{`SELECT DECODE(ENCODE('abc', 'key'), 'key');`, true},
+
+		// Password
+		{`SELECT PASSWORD('abc');`, true},
+		{`SET PASSWORD = PASSWORD('abc');`, true},
+		{`SELECT OLD_PASSWORD('abc');`, true},
+		{`SELECT VALIDATE_PASSWORD_STRENGTH('abc');`, true},
+
+		// Interval
+		{`SELECT INTERVAL(23, 1, 15, 17, 30, 44, 200);`, true},
+		{`SELECT INTERVAL(NULL, 1, 2);`, true},
+		{`SELECT INTERVAL(1);`, false},
+		{`SELECT DATE_ADD("2011-11-11", INTERVAL (1 + 1) DAY);`, true},
+
 		// For date_add
 		{`select date_add("2011-11-11 10:10:10.123456", interval 10 microsecond)`, true},
 		{`select date_add("2011-11-11 10:10:10.123456", interval 10 second)`, true},
@@ -776,8 +846,42 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		// For misc functions
 		{`SELECT GET_LOCK('lock1',10);`, true},
 		{`SELECT RELEASE_LOCK('lock1');`, true},
+		{`SELECT IS_FREE_LOCK('lock1'), IS_USED_LOCK('lock1');`, true},
+		{`SELECT RELEASE_ALL_LOCKS();`, true},
+		{`SELECT a, ANY_VALUE(b) FROM t GROUP BY a;`, true},
+		{`SELECT NAME_CONST('myname', 14);`, true},
+
+		// For group_concat
+		{`select group_concat(c) from t`, true},
+		{`select group_concat(distinct c, d) from t`, true},
+		{`select group_concat(c order by d desc, c) from t`, true},
+		{`select group_concat(distinct c order by c separator '|') from t`, true},
+		{`select group_concat(c separator '') from t`, true},
+		{`select group_concat(c separator d) from t`, false},
+		{`select group_concat(c separator ',' order by c) from t`, false},
+
+		// For bit aggregate functions
+		{`select bit_and(c), bit_or(c), bit_xor(c) from t`, true},
+		{`select bit_and(c, d) from t`, false},
+
+		// For standard deviation and variance functions
+		{`select std(c), stddev(c), stddev_pop(c), stddev_samp(c) from t`, true},
+		{`select variance(c), var_pop(c), var_samp(distinct c) from t`, true},
 	}
 	s.RunTest(c, table)
+
+	parser := New()
+	stmt, err := parser.ParseOneStmt("select group_concat(c order by d desc separator ';') from t", "", "")
+	c.Assert(err, IsNil)
+	agg := stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.(*ast.AggregateFuncExpr)
+	c.Assert(agg.Separator, Equals, ";")
+	c.Assert(agg.Order.Items, HasLen, 1)
+	c.Assert(agg.Order.Items[0].Desc, IsTrue)
+	stmt, err = parser.ParseOneStmt("select group_concat(c) from t", "", "")
+	c.Assert(err, IsNil)
+	agg = stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.(*ast.AggregateFuncExpr)
+	c.Assert(agg.Separator, Equals, ",")
+	c.Assert(agg.Order, IsNil)
 }
 
 func (s *testParserSuite) TestIdentifier(c *C) {
@@ -171,7 +171,11 @@ func (pc pbConverter) likeToPBExpr(expr *expression.ScalarFunction) *tipb.Expr {
 	}
 	// Only patterns like 'abc', '%abc', 'abc%', '%abc%' can be converted to *tipb.Expr for now.
 	escape := expr.GetArgs()[2].(*expression.Constant).Value
-	if escape.IsNull() || byte(escape.GetInt64()) != '\\' {
+	if escape.IsNull() || escape.GetInt64() != '\\' {
+		return nil
+	}
+	// The pushed down LIKE is always case insensitive.
+	if ci, err := expression.LikeCaseInsensitive(expr.GetArgs()[0], expr.GetArgs()[1]); err != nil || !ci {
 		return nil
 	}
 	pattern, ok := expr.GetArgs()[1].(*expression.Constant)
@@ -353,6 +357,8 @@ func aggFuncToPBExpr(sc *variable.StatementContext, client kv.Client, aggFunc ex
 		tp = tipb.ExprType_Sum
 	case ast.AggFuncAvg:
 		tp = tipb.ExprType_Avg
+	default:
+		return nil
 	}
 	if !client.SupportRequestType(kv.ReqTypeSelect, int64(tp)) {
 		return nil
@@ -14,6 +14,7 @@
 package plan
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/juju/errors"
@@ -196,6 +197,9 @@ func (er *expressionRewriter) Enter(inNode ast.Node) (ast.Node, bool) {
 	case *ast.ValuesExpr:
 		er.ctxStack = append(er.ctxStack, expression.NewValuesFunc(v))
 		return inNode, true
+	case *ast.DefaultExpr:
+		er.rewriteDefault(v)
+		return inNode, true
 	default:
 		er.asScalar = true
 	}
@@ -429,7 +433,7 @@ func (er *expressionRewriter) Leave(inNode ast.Node) (retNode ast.Node, ok bool)
 
 	switch v := inNode.(type) {
 	case *ast.AggregateFuncExpr, *ast.ColumnNameExpr, *ast.ParenthesesExpr, *ast.WhenClause,
-		*ast.SubqueryExpr, *ast.ExistsSubqueryExpr, *ast.CompareSubqueryExpr, *ast.ValuesExpr:
+		*ast.SubqueryExpr, *ast.ExistsSubqueryExpr, *ast.CompareSubqueryExpr, *ast.ValuesExpr, *ast.DefaultExpr:
 	case *ast.ValueExpr:
 		value := &expression.Constant{Value: v.Datum, RetType: &v.Type}
 		er.ctxStack = append(er.ctxStack, value)
@@ -489,6 +493,21 @@ func datumToConstant(d types.Datum, tp byte) *expression.Constant {
 	return &expression.Constant{Value: d, RetType: types.NewFieldType(tp)}
 }
 
+// rewriteDefault rewrites DEFAULT(col) to a function returning the default value of the column.
+// A bare DEFAULT is only allowed as a value of INSERT, which is handled by the plan builder.
+func (er *expressionRewriter) rewriteDefault(v *ast.DefaultExpr) {
+	if v.Name == nil {
+		er.err = errors.New("Invalid use of DEFAULT")
+		return
+	}
+	if v.Refer == nil || v.Refer.Column == nil || v.Refer.Table == nil {
+		er.err = ErrUnknownColumn.GenByArgs(v.Name.Name.O, "field_list")
+		return
+	}
+	colName := fmt.Sprintf("%s.%s.%s", v.Refer.DBName.L, v.Refer.Table.Name.L, v.Refer.Column.Name.L)
+	er.ctxStack = append(er.ctxStack, expression.NewDefaultFunc(v.Refer.Column, colName))
+}
+
 func (er *expressionRewriter) rewriteVariable(v *ast.VariableExpr) {
 	stkLen := len(er.ctxStack)
 	name := strings.ToLower(v.Name)
@@ -747,8 +766,16 @@ func (er *expressionRewriter) likeToScalarFunc(v *ast.PatternLikeExpr) {
 	if er.err != nil {
 		return
 	}
+	escape := int64(v.Escape)
+	if escape == 0 {
+		// The default escape character is '\\', there is none in the NO_BACKSLASH_ESCAPES sql_mode.
+		escape = '\\'
+		if er.ctx.GetSessionVars().NoBackslashEscapes {
+			escape = -1
+		}
+	}
 	function := er.notToExpression(v.Not, ast.Like, &v.Type,
-		er.ctxStack[l-2], er.ctxStack[l-1], &expression.Constant{Value: types.NewIntDatum(int64(v.Escape))})
+		er.ctxStack[l-2], er.ctxStack[l-1], &expression.Constant{Value: types.NewIntDatum(escape)})
 	er.ctxStack = er.ctxStack[:l-2]
 	er.ctxStack = append(er.ctxStack, function)
 }
@@ -786,6 +813,21 @@ func (er *expressionRewriter) betweenToExpression(v *ast.BetweenExpr) {
 	if er.err != nil {
 		return
 	}
+	// BETWEEN on a column is rewritten to two comparisons, which can be used to build the scan ranges and pushed down.
+	if _, ok := er.ctxStack[stkLen-3].(*expression.Column); !ok {
+		name := ast.Between
+		if v.Not {
+			name = ast.NotBetween
+		}
+		function, err := expression.NewFunction(name, &v.Type, er.ctxStack[stkLen-3:]...)
+		if err != nil {
+			er.err = errors.Trace(err)
+			return
+		}
+		er.ctxStack = er.ctxStack[:stkLen-3]
+		er.ctxStack = append(er.ctxStack, function)
+		return
+	}
 	var op string
 	var l, r expression.Expression
 	l, er.err = expression.NewFunction(ast.GE, &v.Type, er.ctxStack[stkLen-3], er.ctxStack[stkLen-2])
@@ -831,6 +873,10 @@ func (er *expressionRewriter) funcCallToExpression(v *ast.FuncCallExpr) {
 	}
 	var function expression.Expression
 	function, er.err = expression.NewFunction(v.FnName.L, &v.Type, args...)
+	if er.err == nil && v.FnName.L == ast.Password {
+		// The deprecation is reported once when the expression is rewritten, rather than for every row.
+		er.ctx.GetSessionVars().StmtCtx.AppendWarning(expression.ErrDeprecatedSyntax.GenByArgs("PASSWORD"))
+	}
 	er.ctxStack = er.ctxStack[:stackLen-len(v.Args)]
 	er.ctxStack = append(er.ctxStack, function)
 }
@@ -328,6 +328,12 @@ func (s *testExpressionSuite) TestDateArith(c *C) {
 		{"2011-11-11 10:10:10", "11 10", "DAY_HOUR", "2011-11-22 20:10:10", "2011-10-31 00:10:10", false},
 		{"2011-11-11 10:10:10", "11-1", "YEAR_MONTH", "2022-12-11 10:10:10", "2000-10-11 10:10:10", false},
 		{"2011-11-11 10:10:10", "11-11", "YEAR_MONTH", "2023-10-11 10:10:10", "1999-12-11 10:10:10", false},
+		// tests for fractional seconds
+		{"2011-11-11 10:10:10", 500000, "MICROSECOND", "2011-11-11 10:10:10.500000", "2011-11-11 10:10:09.500000", false},
+		{"2011-11-11 10:10:10", 1.5, "SECOND", "2011-11-11 10:10:11.500000", "2011-11-11 10:10:08.500000", false},
+		{"2011-11-11 10:10:10", "1.5", "SECOND", "2011-11-11 10:10:11.500000", "2011-11-11 10:10:08.500000", false},
+		{"2011-11-11", 0.0000015, "SECOND", "2011-11-11 00:00:00.000002", "2011-11-10 23:59:59.999998", false},
+		{"2011-11-11", -1.25, "SECOND", "2011-11-10 23:59:58.750000", "2011-11-11 00:00:01.250000", false},
 		// tests for interval in day forms
 		{"2011-11-11 10:10:10", "20", "DAY", "2011-12-01 10:10:10", "2011-10-22 10:10:10", false},
 		{"2011-11-11 10:10:10", 19.88, "DAY", "2011-12-01 10:10:10", "2011-10-22 10:10:10", false},
@@ -411,3 +417,52 @@ func (s *testExpressionSuite) TestDateArith(c *C) {
 		}
 	}
 }
+
+func (s *testExpressionSuite) TestAddDateAndSubDate(c *C) {
+	defer testleak.AfterTest(c)()
+	interval := func(value interface{}, unit string) ast.ExprNode {
+		return ast.NewValueExpr(ast.DateArithInterval{Unit: unit, Interval: ast.NewValueExpr(value)})
+	}
+	tests := []struct {
+		Date      interface{}
+		Interval  ast.ExprNode
+		AddResult interface{}
+		SubResult interface{}
+	}{
+		// The INTERVAL form is the same as DATE_ADD and DATE_SUB.
+		{"2011-11-11", interval(1, "DAY"), "2011-11-12", "2011-11-10"},
+		{"2011-11-11", interval(10, "HOUR"), "2011-11-11 10:00:00", "2011-11-10 14:00:00"},
+		{"2011-11-11 10:10:10", interval("1.5", "SECOND"), "2011-11-11 10:10:11.500000", "2011-11-11 10:10:08.500000"},
+		{"2011-11-11 10:10:10", interval("11-1", "YEAR_MONTH"), "2022-12-11 10:10:10", "2000-10-11 10:10:10"},
+		{"2011-11-11", interval(nil, "DAY"), nil, nil},
+		// The second argument is a number of days otherwise.
+		{"2011-11-11", ast.NewValueExpr(1), "2011-11-12", "2011-11-10"},
+		{"2011-11-11", ast.NewValueExpr(-31), "2011-10-11", "2011-12-12"},
+		{"2011-11-11 10:10:10", ast.NewValueExpr(20), "2011-12-01 10:10:10", "2011-10-22 10:10:10"},
+		{"2011-11-11 10:10:10", ast.NewValueExpr("19.88"), "2011-11-30 10:10:10", "2011-10-23 10:10:10"},
+		{int64(20111111), ast.NewValueExpr(1), "2011-11-12", "2011-11-10"},
+		{"2011-11-11", ast.NewValueExpr(nil), nil, nil},
+		{nil, ast.NewValueExpr(1), nil, nil},
+	}
+	for _, t := range tests {
+		for _, fnName := range []string{ast.AddDate, ast.SubDate} {
+			expr := &ast.FuncCallExpr{
+				FnName: model.NewCIStr(fnName),
+				Args:   []ast.ExprNode{ast.NewValueExpr(t.Date), t.Interval},
+			}
+			ast.SetFlag(expr)
+			v, err := evalAstExpr(expr, s.ctx)
+			c.Assert(err, IsNil)
+			expect := t.AddResult
+			if fnName == ast.SubDate {
+				expect = t.SubResult
+			}
+			if expect == nil {
+				c.Assert(v.IsNull(), IsTrue)
+				continue
+			}
+			c.Assert(v.Kind(), Equals, types.KindMysqlTime)
+			c.Assert(v.GetMysqlTime().String(), Equals, expect, Commentf("%s(%v, %v)", fnName, t.Date, t.Interval.GetDatum().GetValue()))
+		}
+	}
+}
@@ -64,7 +64,15 @@ func (b *planBuilder) buildAggregation(p LogicalPlan, aggFuncList []*ast.Aggrega
 			p = np
 			newArgList = append(newArgList, newArg)
 		}
-		newFunc := expression.NewAggFunction(aggFunc.F, newArgList, aggFunc.Distinct)
+		var newFunc expression.AggregationFunction
+		if aggFunc.F == ast.AggFuncGroupConcat {
+			newFunc, p = b.buildGroupConcat(p, aggFunc, newArgList)
+			if b.err != nil {
+				return nil, nil
+			}
+		} else {
+			newFunc = expression.NewAggFunction(aggFunc.F, newArgList, aggFunc.Distinct)
+		}
 		combined := false
 		for j, oldFunc := range agg.AggFuncs {
 			if oldFunc.Equal(newFunc, b.ctx) {
@@ -92,6 +100,27 @@ func (b *planBuilder) buildAggregation(p LogicalPlan, aggFuncList []*ast.Aggrega
 	return agg, aggIndexMap
 }
 
+// buildGroupConcat builds group_concat with its ORDER BY items and separator.
+func (b *planBuilder) buildGroupConcat(p LogicalPlan, aggFunc *ast.AggregateFuncExpr, args []expression.Expression) (expression.AggregationFunction, LogicalPlan) {
+	var (
+		orderBy []expression.Expression
+		desc    []bool
+	)
+	if aggFunc.Order != nil {
+		for _, item := range aggFunc.Order.Items {
+			newExpr, np, err := b.rewrite(item.Expr, p, nil, true)
+			if err != nil {
+				b.err = errors.Trace(err)
+				return nil, nil
+			}
+			p = np
+			orderBy = append(orderBy, newExpr)
+			desc = append(desc, item.Desc)
+		}
+	}
+	return expression.NewGroupConcatFunction(args, aggFunc.Distinct, orderBy, desc, aggFunc.Separator), p
+}
+
 func (b *planBuilder) buildResultSetNode(node ast.ResultSetNode) LogicalPlan {
 	switch x := node.(type) {
 	case *ast.Join:
@@ -297,6 +326,8 @@ func (b *planBuilder) buildProjection(p LogicalPlan, fields []*ast.SelectField,
 				innerExpr := getInnerFromParentheses(field.Expr)
 				if _, ok := innerExpr.(*ast.ValueExpr); ok && innerExpr.Text() != "" {
 					colName = model.NewCIStr(innerExpr.Text())
+				} else if name, ok := getNameConstName(innerExpr); ok {
+					colName = model.NewCIStr(name)
 				} else {
 					colName = model.NewCIStr(field.Text())
 				}
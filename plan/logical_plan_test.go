@@ -225,7 +225,7 @@ func mockResolve(node ast.Node) (infoschema.InfoSchema, error) {
 	if err != nil {
 		return nil, err
 	}
-	return is, InferType(ctx.GetSessionVars().StmtCtx, node)
+	return is, InferType(ctx, node)
 }
 
 func supportExpr(exprType tipb.ExprType) bool {
@@ -542,6 +542,11 @@ func (s *testPlanSuite) TestPlanBuilder(c *C) {
 			sql:  "select * from t for update",
 			plan: "DataScan(t)->Lock->Projection",
 		},
+		{
+			// ANY_VALUE of a nonaggregated column is evaluated on the first row of the group like the column itself.
+			sql:  "select a, any_value(b) from t group by a",
+			plan: "DataScan(t)->Aggr(firstrow(test.t.a),firstrow(test.t.b))->Projection",
+		},
 		{
 			sql:  "update t set t.a = t.a * 1.5 where t.a >= 1000 order by t.a desc limit 10",
 			plan: "DataScan(t)->Selection->Sort->Limit->*plan.Update",
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/terror"
 )
 
@@ -31,7 +32,7 @@ var AllowCartesianProduct = true
 // The node must be prepared first.
 func Optimize(ctx context.Context, node ast.Node, is infoschema.InfoSchema) (Plan, error) {
 	// We have to infer type again because after parameter is set, the expression type may change.
-	if err := InferType(ctx.GetSessionVars().StmtCtx, node); err != nil {
+	if err := InferType(ctx, node); err != nil {
 		return nil, errors.Trace(err)
 	}
 	allocator := new(idAllocator)
@@ -133,4 +134,5 @@ func init() {
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassOptimizer] = mySQLErrCodes
 	expression.EvalAstExpr = evalAstExpr
+	expression.GetColDefaultValue = table.GetColDefaultValue
 }
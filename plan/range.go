@@ -296,16 +296,17 @@ func (r *rangeBuilder) newBuildFromPatternLike(expr *expression.ScalarFunction)
 		return []rangePoint{startPoint, endPoint}
 	}
 	lowValue := make([]byte, 0, len(pattern))
-	escape := byte(expr.GetArgs()[2].(*expression.Constant).Value.GetInt64())
+	// escape is negative if there is no escape character.
+	escape := int(expr.GetArgs()[2].(*expression.Constant).Value.GetInt64())
 	var exclude bool
 	isExactMatch := true
 	for i := 0; i < len(pattern); i++ {
-		if pattern[i] == escape {
+		if int(pattern[i]) == escape {
 			i++
 			if i < len(pattern) {
 				lowValue = append(lowValue, pattern[i])
 			} else {
-				lowValue = append(lowValue, escape)
+				lowValue = append(lowValue, byte(escape))
 			}
 			continue
 		}
@@ -393,9 +393,9 @@ func (c *conditionChecker) checkLikeFunc(scalar *expression.ScalarFunction) bool
 	if len(patternStr) == 0 {
 		return true
 	}
-	escape := byte(scalar.GetArgs()[2].(*expression.Constant).Value.GetInt64())
+	escape := int(scalar.GetArgs()[2].(*expression.Constant).Value.GetInt64())
 	for i := 0; i < len(patternStr); i++ {
-		if patternStr[i] == escape {
+		if int(patternStr[i]) == escape {
 			i++
 			if i < len(patternStr)-1 {
 				continue
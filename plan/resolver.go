@@ -245,6 +245,8 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 		nr.handleTableName(v)
 	case *ast.ColumnNameExpr:
 		nr.handleColumnName(v)
+	case *ast.DefaultExpr:
+		nr.handleDefaultExpr(v)
 	case *ast.CreateIndexStmt:
 		nr.popContext()
 	case *ast.CreateTableStmt:
@@ -483,6 +485,16 @@ func (nr *nameResolver) handleColumnName(cn *ast.ColumnNameExpr) {
 	nr.Err = errors.Errorf("unknown column %s", cn.Name.Name.L)
 }
 
+// handleDefaultExpr resolves the column of DEFAULT(col) the same way as a column name expression.
+func (nr *nameResolver) handleDefaultExpr(v *ast.DefaultExpr) {
+	if v.Name == nil {
+		return
+	}
+	cn := &ast.ColumnNameExpr{Name: v.Name}
+	nr.handleColumnName(cn)
+	v.Refer = cn.Refer
+}
+
 // resolveColumnNameInContext looks up and sets ResultField for a column with the ctx.
 func (nr *nameResolver) resolveColumnNameInContext(ctx *resolverContext, cn *ast.ColumnNameExpr) bool {
 	if ctx.inTableRefs {
@@ -716,6 +728,20 @@ func getInnerFromParentheses(expr ast.ExprNode) ast.ExprNode {
 	return expr
 }
 
+// getNameConstName gets the column name given by NAME_CONST(name, value), ok is false if expr is not NAME_CONST.
+func getNameConstName(expr ast.ExprNode) (name string, ok bool) {
+	f, ok := expr.(*ast.FuncCallExpr)
+	if !ok || f.FnName.L != ast.NameConst || len(f.Args) != 2 {
+		return "", false
+	}
+	v, ok := f.Args[0].(*ast.ValueExpr)
+	if !ok {
+		return "", false
+	}
+	name, err := v.Datum.ToString()
+	return name, err == nil
+}
+
 // createResultFields creates result field list for a single select field.
 func (nr *nameResolver) createResultFields(field *ast.SelectField) (rfs []*ast.ResultField) {
 	ctx := nr.currentContext()
@@ -790,7 +816,11 @@ func (nr *nameResolver) createResultFields(field *ast.SelectField) (rfs []*ast.R
 				rf.ColumnAsName = model.NewCIStr(field.Text())
 			}
 		default:
-			rf.ColumnAsName = model.NewCIStr(field.Text())
+			if name, ok := getNameConstName(innerExpr); ok {
+				rf.ColumnAsName = model.NewCIStr(name)
+			} else {
+				rf.ColumnAsName = model.NewCIStr(field.Text())
+			}
 		}
 	}
 	rfs = append(rfs, rf)
@@ -19,6 +19,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser/opcode"
 	"github.com/pingcap/tidb/sessionctx/variable"
@@ -27,9 +28,10 @@ import (
 )
 
 // InferType infers result type for ast.ExprNode.
-func InferType(sc *variable.StatementContext, node ast.Node) error {
+func InferType(ctx context.Context, node ast.Node) error {
 	var inferrer typeInferrer
-	inferrer.sc = sc
+	inferrer.sc = ctx.GetSessionVars().StmtCtx
+	inferrer.noUnsignedSubtraction = ctx.GetSessionVars().NoUnsignedSubtraction
 	// TODO: get the default charset from ctx
 	inferrer.defaultCharset = "utf8"
 	node.Accept(&inferrer)
@@ -40,6 +42,8 @@ type typeInferrer struct {
 	sc             *variable.StatementContext
 	err            error
 	defaultCharset string
+	// noUnsignedSubtraction is true if the sql_mode has NO_UNSIGNED_SUBTRACTION.
+	noUnsignedSubtraction bool
 }
 
 func (v *typeInferrer) Enter(in ast.Node) (out ast.Node, skipChildren bool) {
@@ -106,6 +110,10 @@ func (v *typeInferrer) Leave(in ast.Node) (out ast.Node, ok bool) {
 		v.handleValueExpr(x)
 	case *ast.ValuesExpr:
 		v.handleValuesExpr(x)
+	case *ast.DefaultExpr:
+		if x.Refer != nil && x.Refer.Column != nil {
+			x.SetType(&x.Refer.Column.FieldType)
+		}
 	case *ast.VariableExpr:
 		x.SetType(types.NewFieldType(mysql.TypeVarString))
 		x.Type.Charset = v.defaultCharset
@@ -156,6 +164,18 @@ func (v *typeInferrer) aggregateFunc(x *ast.AggregateFuncExpr) {
 		}
 		ft.Collate = cln
 		x.SetType(ft)
+	case ast.AggFuncStddevPop, ast.AggFuncStddevSamp, ast.AggFuncVarPop, ast.AggFuncVarSamp:
+		ft := types.NewFieldType(mysql.TypeDouble)
+		ft.Charset = charset.CharsetBin
+		ft.Collate = charset.CollationBin
+		x.SetType(ft)
+	case ast.AggFuncBitAnd, ast.AggFuncBitOr, ast.AggFuncBitXor:
+		ft := types.NewFieldType(mysql.TypeLonglong)
+		ft.Flen = 21
+		ft.Flag |= mysql.UnsignedFlag
+		ft.Charset = charset.CharsetBin
+		ft.Collate = charset.CollationBin
+		x.SetType(ft)
 	}
 }
 
@@ -170,14 +190,27 @@ func (v *typeInferrer) binaryOperation(x *ast.BinaryOperationExpr) {
 		x.Type.Flag |= mysql.UnsignedFlag
 	case opcode.IntDiv:
 		x.Type.Init(mysql.TypeLonglong)
+		if x.L.GetType() != nil && x.R.GetType() != nil {
+			// If any operand is unsigned, result is unsigned.
+			x.Type.Flag |= (x.L.GetType().Flag | x.R.GetType().Flag) & mysql.UnsignedFlag
+		}
 	case opcode.Plus, opcode.Minus, opcode.Mul, opcode.Mod:
 		if x.L.GetType() != nil && x.R.GetType() != nil {
 			xTp := mergeArithType(x.L.GetType().Tp, x.R.GetType().Tp)
 			x.Type.Init(xTp)
 			leftUnsigned := x.L.GetType().Flag & mysql.UnsignedFlag
 			rightUnsigned := x.R.GetType().Flag & mysql.UnsignedFlag
-			// If both operands are unsigned, result is unsigned.
-			x.Type.Flag |= (leftUnsigned & rightUnsigned)
+			if x.Op == opcode.Mod {
+				// If both operands are unsigned, result is unsigned.
+				x.Type.Flag |= (leftUnsigned & rightUnsigned)
+			} else if xTp == mysql.TypeLonglong && !(x.Op == opcode.Minus && v.noUnsignedSubtraction) {
+				// If any operand is unsigned, the integer result is unsigned,
+				// unless NO_UNSIGNED_SUBTRACTION makes the difference signed.
+				x.Type.Flag |= (leftUnsigned | rightUnsigned)
+			}
+			if xTp == mysql.TypeNewDecimal && x.Op != opcode.Mod {
+				x.Type.Decimal = arithDecimalScale(x.Op, x.L.GetType(), x.R.GetType())
+			}
 		}
 	case opcode.Div:
 		if x.L.GetType() != nil && x.R.GetType() != nil {
@@ -186,6 +219,9 @@ func (v *typeInferrer) binaryOperation(x *ast.BinaryOperationExpr) {
 				xTp = mysql.TypeNewDecimal
 			}
 			x.Type.Init(xTp)
+			if xTp == mysql.TypeNewDecimal {
+				x.Type.Decimal = arithDecimalScale(x.Op, x.L.GetType(), x.R.GetType())
+			}
 		}
 	}
 	x.Type.Charset = charset.CharsetBin
@@ -207,6 +243,41 @@ func mergeArithType(a, b byte) byte {
 	return mysql.TypeLonglong
 }
 
+// arithDecimalScale returns the scale of the decimal result of the arithmetic operator op, the sum and the difference
+// have the larger scale of the operands, the product has the sum of their scales, and the quotient has
+// div_precision_increment more digits than the dividend. It's unspecified if the scale of an operand is unknown.
+func arithDecimalScale(op opcode.Op, l, r *types.FieldType) int {
+	scale := func(tp *types.FieldType) int {
+		switch tp.Tp {
+		case mysql.TypeNewDecimal:
+			return tp.Decimal
+		case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
+			return 0
+		}
+		return types.UnspecifiedLength
+	}
+	ls, rs := scale(l), scale(r)
+	if ls == types.UnspecifiedLength || rs == types.UnspecifiedLength {
+		return types.UnspecifiedLength
+	}
+	var s int
+	switch op {
+	case opcode.Plus, opcode.Minus:
+		s = ls
+		if rs > s {
+			s = rs
+		}
+	case opcode.Mul:
+		s = ls + rs
+	case opcode.Div:
+		s = ls + types.DivFracIncr
+	}
+	if s > types.MaxFraction {
+		s = types.MaxFraction
+	}
+	return s
+}
+
 func (v *typeInferrer) unaryOperation(x *ast.UnaryOperationExpr) {
 	switch x.Op {
 	case opcode.Not:
@@ -250,6 +321,46 @@ func (v *typeInferrer) getFsp(x *ast.FuncCallExpr) int {
 	return 0
 }
 
+// argFsp returns the fsp of a TIME or DATETIME computed from arg, i.e. its number of decimals up to MaxFsp.
+// An integer has no decimals, while an unknown number of decimals results in MaxFsp.
+func argFsp(arg ast.ExprNode) int {
+	tp := arg.GetType()
+	switch tp.Tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong, mysql.TypeYear:
+		return types.MinFsp
+	}
+	if tp.Decimal < types.MinFsp || tp.Decimal > types.MaxFsp {
+		return types.MaxFsp
+	}
+	return tp.Decimal
+}
+
+// dateArithHasMicroseconds checks whether the interval argument of DATE_ADD, DATE_SUB, ADDDATE or SUBDATE
+// may have microseconds, i.e. its unit has a MICROSECOND part or it's a fractional number of seconds.
+func dateArithHasMicroseconds(arg ast.ExprNode) bool {
+	interval, ok := arg.GetDatum().GetInterface().(ast.DateArithInterval)
+	if !ok {
+		return false
+	}
+	return intervalHasMicroseconds(interval.Unit, interval.Interval)
+}
+
+// intervalHasMicroseconds checks whether an interval of the unit may have microseconds.
+func intervalHasMicroseconds(unit string, interval ast.ExprNode) bool {
+	unit = strings.ToUpper(unit)
+	if strings.HasSuffix(unit, "MICROSECOND") {
+		return true
+	}
+	if unit != "SECOND" {
+		return false
+	}
+	switch interval.GetDatum().Kind() {
+	case types.KindFloat32, types.KindFloat64, types.KindMysqlDecimal, types.KindString:
+		return true
+	}
+	return false
+}
+
 func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 	var (
 		tp  *types.FieldType
@@ -264,37 +375,100 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 		}
 	case "greatest", "least":
 		for _, arg := range x.Args {
-			InferType(v.sc, arg)
+			arg.Accept(v)
 		}
 		if len(x.Args) > 0 {
 			tp = x.Args[0].GetType()
 			for i := 1; i < len(x.Args); i++ {
 				mergeArithType(tp.Tp, x.Args[i].GetType().Tp)
 			}
+			// Mixing signed and unsigned integers may yield either a negative value
+			// or one larger than MaxInt64, so the result is a DECIMAL as in MySQL.
+			if hasMixedSignedIntegers(x.Args) {
+				tp = types.NewFieldType(mysql.TypeNewDecimal)
+				tp.Decimal = 0
+				tp.Charset = charset.CharsetBin
+				tp.Collate = charset.CollationBin
+			}
+			// Temporal values mixed with strings are compared as strings, so the result is a string.
+			if hasTemporalAndString(x.Args) {
+				tp = types.NewFieldType(mysql.TypeVarString)
+				chs = v.defaultCharset
+			}
 		}
-	case "ceil", "ceiling":
+	case "ceil", "ceiling", "floor":
 		t := x.Args[0].GetType().Tp
-		if t == mysql.TypeNull || t == mysql.TypeFloat || t == mysql.TypeDouble || t == mysql.TypeVarchar ||
+		if t == mysql.TypeNewDecimal {
+			tp = types.NewFieldType(mysql.TypeNewDecimal)
+			tp.Decimal = 0
+		} else if t == mysql.TypeNull || t == mysql.TypeFloat || t == mysql.TypeDouble || t == mysql.TypeVarchar ||
 			t == mysql.TypeTinyBlob || t == mysql.TypeMediumBlob || t == mysql.TypeLongBlob ||
 			t == mysql.TypeBlob || t == mysql.TypeVarString || t == mysql.TypeString {
 			tp = types.NewFieldType(mysql.TypeDouble)
 		} else {
 			tp = types.NewFieldType(mysql.TypeLonglong)
 		}
-	case "ln", "log", "log2", "log10":
+	case "round":
+		// An integer or a temporal value keeps its type, any other argument including a string yields a DOUBLE.
+		argTp := x.Args[0].GetType()
+		switch argTp.Tp {
+		case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong, mysql.TypeYear:
+			tp = types.NewFieldType(mysql.TypeLonglong)
+			tp.Flag |= argTp.Flag & mysql.UnsignedFlag
+		case mysql.TypeEnum, mysql.TypeSet:
+			tp = types.NewFieldType(mysql.TypeLonglong)
+			tp.Flag |= mysql.UnsignedFlag
+		case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp, mysql.TypeDuration:
+			tp = types.NewFieldType(argTp.Tp)
+			tp.Decimal = argTp.Decimal
+		default:
+			tp = types.NewFieldType(mysql.TypeDouble)
+		}
+	case "ln", "log", "log2", "log10", "sin", "cos", "tan", "cot":
 		tp = types.NewFieldType(mysql.TypeDouble)
-	case "pow", "power", "rand":
+	case "pow", "power", "exp", "sqrt", "rand":
 		tp = types.NewFieldType(mysql.TypeDouble)
 	case "curdate", "current_date", "date":
 		tp = types.NewFieldType(mysql.TypeDate)
 	case "curtime", "current_time", "timediff":
 		tp = types.NewFieldType(mysql.TypeDuration)
 		tp.Decimal = v.getFsp(x)
-	case "current_timestamp", "date_arith":
+	case "sec_to_time":
+		tp = types.NewFieldType(mysql.TypeDuration)
+		tp.Decimal = argFsp(x.Args[0])
+	case "addtime":
+		// The result is a DATETIME or a TIME depending on the first argument, it's a string if it's unknown.
+		switch x.Args[0].GetType().Tp {
+		case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp:
+			tp = types.NewFieldType(mysql.TypeDatetime)
+		case mysql.TypeDuration, mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong,
+			mysql.TypeNewDecimal, mysql.TypeFloat, mysql.TypeDouble:
+			tp = types.NewFieldType(mysql.TypeDuration)
+		default:
+			tp = types.NewFieldType(mysql.TypeVarString)
+			chs = v.defaultCharset
+		}
+		if tp.Tp != mysql.TypeVarString {
+			tp.Decimal = argFsp(x.Args[0])
+			if fsp := argFsp(x.Args[1]); fsp > tp.Decimal {
+				tp.Decimal = fsp
+			}
+		}
+	case "current_timestamp":
+		tp = types.NewFieldType(mysql.TypeDatetime)
+	case "date_arith", "adddate", "subdate":
 		tp = types.NewFieldType(mysql.TypeDatetime)
+		if dateArithHasMicroseconds(x.Args[len(x.Args)-1]) {
+			tp.Decimal = types.MaxFsp
+		}
+	case "timestampadd":
+		tp = types.NewFieldType(mysql.TypeDatetime)
+		if intervalHasMicroseconds(x.Args[0].GetDatum().GetString(), x.Args[1]) {
+			tp.Decimal = types.MaxFsp
+		}
 	case "microsecond", "second", "minute", "hour", "day", "week", "month", "year",
 		"dayofweek", "dayofmonth", "dayofyear", "weekday", "weekofyear", "yearweek",
-		"found_rows", "length", "extract", "locate":
+		"found_rows", "length", "extract", "locate", "interval", "coercibility":
 		tp = types.NewFieldType(mysql.TypeLonglong)
 	case "now", "sysdate":
 		tp = types.NewFieldType(mysql.TypeDatetime)
@@ -308,13 +482,14 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 		}
 	case "str_to_date":
 		tp = types.NewFieldType(mysql.TypeDatetime)
-	case "dayname", "version", "database", "user", "current_user", "schema",
+	case "dayname", "version", "database", "user", "current_user", "current_role", "schema", "charset", "collation",
 		"concat", "concat_ws", "left", "lcase", "lower", "repeat",
 		"replace", "ucase", "upper", "convert", "substring",
-		"substring_index", "trim", "ltrim", "rtrim", "reverse", "hex", "unhex", "date_format", "rpad", "char_func":
+		"substring_index", "trim", "ltrim", "rtrim", "reverse", "hex", "unhex", "date_format", "lpad", "rpad", "char_func",
+		"to_base64", "password", "old_password", "make_set", "export_set", "bin", "oct", "conv":
 		tp = types.NewFieldType(mysql.TypeVarString)
 		chs = v.defaultCharset
-	case "strcmp", "isnull", "bit_length", "char_length", "character_length", "crc32":
+	case "strcmp", "isnull", "bit_length", "char_length", "character_length", "crc32", "ord":
 		tp = types.NewFieldType(mysql.TypeLonglong)
 	case "connection_id":
 		tp = types.NewFieldType(mysql.TypeLonglong)
@@ -328,8 +503,31 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 		// expr2 or expr3 returns a floating-point value	floating-point
 		// expr2 or expr3 returns an integer	integer
 		tp = x.Args[1].GetType()
-	case "get_lock", "release_lock":
+	case "any_value":
+		argTp := *x.Args[0].GetType()
+		tp = &argTp
+	case ast.Collate:
+		argTp := *x.Args[0].GetType()
+		tp = &argTp
+		// An invalid collation is reported when the function is built.
+		if co, err := charset.GetCollationByName(x.Args[1].GetDatum().GetString()); err == nil {
+			tp.Charset, tp.Collate = co.CharsetName, co.Name
+		}
+	case "name_const":
+		argTp := *x.Args[1].GetType()
+		tp = &argTp
+	case "get_lock", "release_lock", "is_free_lock", "release_all_locks", "benchmark", "validate_password_strength", "gtid_subset",
+		"json_storage_size", "json_storage_free", "grouping":
+		tp = types.NewFieldType(mysql.TypeLonglong)
+	case "is_used_lock":
 		tp = types.NewFieldType(mysql.TypeLonglong)
+		tp.Flag |= mysql.UnsignedFlag
+	case "uuid", "bin_to_uuid", "format_bytes", "format_pico_time", "gtid_subtract", "json_pretty", "json_quote",
+		"json_search", "json_remove", "json_array_append", "json_array_insert":
+		tp = types.NewFieldType(mysql.TypeVarString)
+		chs = v.defaultCharset
+	case "uuid_to_bin", "weight_string", "from_base64", "encode", "decode":
+		tp = types.NewFieldType(mysql.TypeVarString)
 	default:
 		tp = types.NewFieldType(mysql.TypeUnspecified)
 	}
@@ -388,12 +586,65 @@ func (v *typeInferrer) handleCaseExpr(x *ast.CaseExpr) {
 }
 
 // like expression expects the target expression and pattern to be a string, if it's not, we add a cast function.
+// A binary string is kept as it is to be matched case sensitively.
 func (v *typeInferrer) handleLikeExpr(x *ast.PatternLikeExpr) {
 	x.SetType(types.NewFieldType(mysql.TypeLonglong))
 	x.Type.Charset = charset.CharsetBin
 	x.Type.Collate = charset.CollationBin
-	x.Expr = v.addCastToString(x.Expr)
-	x.Pattern = v.addCastToString(x.Pattern)
+	if !isBinaryString(x.Expr.GetType()) {
+		x.Expr = v.addCastToString(x.Expr)
+	}
+	if !isBinaryString(x.Pattern.GetType()) {
+		x.Pattern = v.addCastToString(x.Pattern)
+	}
+}
+
+// hasMixedSignedIntegers checks whether args are all integers and contain both
+// signed and unsigned ones.
+func hasMixedSignedIntegers(args []ast.ExprNode) bool {
+	var signed, unsigned bool
+	for _, arg := range args {
+		ft := arg.GetType()
+		switch ft.Tp {
+		case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
+		default:
+			return false
+		}
+		if mysql.HasUnsignedFlag(ft.Flag) {
+			unsigned = true
+		} else {
+			signed = true
+		}
+	}
+	return signed && unsigned
+}
+
+// hasTemporalAndString checks whether args mix temporal values and strings.
+func hasTemporalAndString(args []ast.ExprNode) bool {
+	var hasTemporal, hasString bool
+	for _, arg := range args {
+		switch tp := arg.GetType().Tp; tp {
+		case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp, mysql.TypeDuration:
+			hasTemporal = true
+		default:
+			if types.IsTypeChar(tp) || types.IsTypeBlob(tp) || tp == mysql.TypeVarString {
+				hasString = true
+			}
+		}
+	}
+	return hasTemporal && hasString
+}
+
+func isBinaryString(ft *types.FieldType) bool {
+	if ft.Charset != charset.CharsetBin {
+		return false
+	}
+	switch ft.Tp {
+	case mysql.TypeString, mysql.TypeVarchar, mysql.TypeVarString,
+		mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeBlob, mysql.TypeLongBlob:
+		return true
+	}
+	return false
 }
 
 // regexp expression expects the target expression and pattern to be a string, if it's not, we add a cast function.
@@ -14,6 +14,8 @@
 package plan_test
 
 import (
+	"fmt"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/ast"
@@ -56,6 +58,7 @@ func (ts *testTypeInferrerSuite) TestInferType(c *C) {
 		{"c2 is null", mysql.TypeLonglong, charset.CharsetBin},
 		{"isnull(1/0)", mysql.TypeLonglong, charset.CharsetBin},
 		{"cast(1 as decimal)", mysql.TypeNewDecimal, charset.CharsetBin},
+		{"cast(1 as json)", mysql.TypeJSON, charset.CharsetUTF8MB4},
 
 		{"1 and 1", mysql.TypeLonglong, charset.CharsetBin},
 		{"1 or 1", mysql.TypeLonglong, charset.CharsetBin},
@@ -82,8 +85,18 @@ func (ts *testTypeInferrerSuite) TestInferType(c *C) {
 		// Functions
 		{"version()", mysql.TypeVarString, "utf8"},
 		{"count(c1)", mysql.TypeLonglong, charset.CharsetBin},
+		{"bit_and(c1)", mysql.TypeLonglong, charset.CharsetBin},
+		{"cot(c1)", mysql.TypeDouble, charset.CharsetBin},
+		{"bit_or(c1)", mysql.TypeLonglong, charset.CharsetBin},
+		{"bit_xor(c1)", mysql.TypeLonglong, charset.CharsetBin},
+		{"std(c1)", mysql.TypeDouble, charset.CharsetBin},
+		{"var_samp(c1)", mysql.TypeDouble, charset.CharsetBin},
 		{"abs(1)", mysql.TypeLonglong, charset.CharsetBin},
 		{"abs(1.1)", mysql.TypeNewDecimal, charset.CharsetBin},
+		{"ceil(1.1)", mysql.TypeNewDecimal, charset.CharsetBin},
+		{"floor(1.1)", mysql.TypeNewDecimal, charset.CharsetBin},
+		{"floor(c1)", mysql.TypeLonglong, charset.CharsetBin},
+		{"floor(c2)", mysql.TypeDouble, charset.CharsetBin},
 		{"abs(cast(\"20150817015609\" as DATETIME))", mysql.TypeDouble, charset.CharsetBin},
 		{"IF(1>2,2,3)", mysql.TypeLonglong, charset.CharsetBin},
 		{"IFNULL(1,0)", mysql.TypeLonglong, charset.CharsetBin},
@@ -95,6 +108,12 @@ func (ts *testTypeInferrerSuite) TestInferType(c *C) {
 		{"LOG2(3)", mysql.TypeDouble, charset.CharsetBin},
 		{"LOG10(3)", mysql.TypeDouble, charset.CharsetBin},
 		{"rand()", mysql.TypeDouble, charset.CharsetBin},
+		{"pow(2, 3)", mysql.TypeDouble, charset.CharsetBin},
+		{"exp(1)", mysql.TypeDouble, charset.CharsetBin},
+		{"sqrt(4)", mysql.TypeDouble, charset.CharsetBin},
+		{"round(1.5)", mysql.TypeDouble, charset.CharsetBin},
+		{"round('3.14159', 2)", mysql.TypeDouble, charset.CharsetBin},
+		{"round(c1, -1)", mysql.TypeLonglong, charset.CharsetBin},
 		{"curdate()", mysql.TypeDate, charset.CharsetBin},
 		{"current_date()", mysql.TypeDate, charset.CharsetBin},
 		{"DATE('2003-12-31 01:02:03')", mysql.TypeDate, charset.CharsetBin},
@@ -102,6 +121,13 @@ func (ts *testTypeInferrerSuite) TestInferType(c *C) {
 		{"current_time()", mysql.TypeDuration, charset.CharsetBin},
 		{"curtime()", mysql.TypeDuration, charset.CharsetBin},
 		{"current_timestamp()", mysql.TypeDatetime, charset.CharsetBin},
+		{"sec_to_time(c1)", mysql.TypeDuration, charset.CharsetBin},
+		{"timestampadd(minute, 1, c3)", mysql.TypeDatetime, charset.CharsetBin},
+		{"greatest(current_timestamp(), c3)", mysql.TypeVarString, "utf8"},
+		{"least(curdate(), 'abc')", mysql.TypeVarString, "utf8"},
+		{"addtime(sec_to_time(c1), '1:00:00')", mysql.TypeDuration, charset.CharsetBin},
+		{"addtime(current_timestamp(), '1:00:00')", mysql.TypeDatetime, charset.CharsetBin},
+		{"addtime('2017-01-01 00:00:00', '1:00:00')", mysql.TypeVarString, "utf8"},
 		{"microsecond('2009-12-31 23:59:59.000010')", mysql.TypeLonglong, charset.CharsetBin},
 		{"second('2009-12-31 23:59:59.000010')", mysql.TypeLonglong, charset.CharsetBin},
 		{"minute('2009-12-31 23:59:59.000010')", mysql.TypeLonglong, charset.CharsetBin},
@@ -145,6 +171,7 @@ func (ts *testTypeInferrerSuite) TestInferType(c *C) {
 		{"case c1 when null then 2 when 2 then 1.1 else 1 END", mysql.TypeNewDecimal, charset.CharsetBin},
 		{"case c1 when null then 2 when 2 then 'tidb' else 1.1 END", mysql.TypeVarchar, "utf8"},
 		{"greatest(1, 2, 3)", mysql.TypeLonglong, charset.CharsetBin},
+		{"interval(1, 2, 3)", mysql.TypeLonglong, charset.CharsetBin},
 		{"greatest('TiDB', 'D', 'd')", mysql.TypeVarString, "utf8"},
 		{"greatest(1.1, 2.2)", mysql.TypeNewDecimal, charset.CharsetBin},
 		{"greatest('TiDB', 3)", mysql.TypeVarString, "utf8"},
@@ -152,8 +179,16 @@ func (ts *testTypeInferrerSuite) TestInferType(c *C) {
 		{"least('TiDB', 'D', 'd')", mysql.TypeVarString, "utf8"},
 		{"least(1.1, 2.2)", mysql.TypeNewDecimal, charset.CharsetBin},
 		{"least('TiDB', 3)", mysql.TypeVarString, "utf8"},
+		{"greatest(18446744073709551615, -1)", mysql.TypeNewDecimal, charset.CharsetBin},
+		{"least(18446744073709551615, 1)", mysql.TypeNewDecimal, charset.CharsetBin},
+		{"'TiDB' collate utf8_general_ci", mysql.TypeVarString, "utf8"},
+		{"concat(c3 collate utf8_bin, 'TiDB')", mysql.TypeVarString, "utf8"},
 		{"hex('TiDB')", mysql.TypeVarString, "utf8"},
 		{"hex(12)", mysql.TypeVarString, "utf8"},
+		{"bin(12)", mysql.TypeVarString, "utf8"},
+		{"oct(12)", mysql.TypeVarString, "utf8"},
+		{"ord('a')", mysql.TypeLonglong, "binary"},
+		{"conv('a', 16, 10)", mysql.TypeVarString, "utf8"},
 		{"unhex('TiDB')", mysql.TypeVarString, "utf8"},
 		{"unhex(12)", mysql.TypeVarString, "utf8"},
 		{"DATE_FORMAT('2009-10-04 22:23:00', '%W %M %Y')", mysql.TypeVarString, "utf8"},
@@ -163,6 +198,34 @@ func (ts *testTypeInferrerSuite) TestInferType(c *C) {
 		{"char_length('TiDB')", mysql.TypeLonglong, charset.CharsetBin},
 		{"character_length('TiDB')", mysql.TypeLonglong, charset.CharsetBin},
 		{"crc32('TiDB')", mysql.TypeLonglong, charset.CharsetBin},
+		{"uuid()", mysql.TypeVarString, charset.CharsetUTF8},
+		{"any_value(c1)", mysql.TypeLong, charset.CharsetBin},
+		{"any_value(c3)", mysql.TypeBlob, charset.CharsetUTF8},
+		{"uuid_to_bin(uuid())", mysql.TypeVarString, charset.CharsetBin},
+		{"weight_string('a')", mysql.TypeVarString, charset.CharsetBin},
+		{"to_base64('a')", mysql.TypeVarString, charset.CharsetUTF8},
+		{"from_base64('YQ==')", mysql.TypeVarString, charset.CharsetBin},
+		{"encode('a', 'key')", mysql.TypeVarString, charset.CharsetBin},
+		{"decode('a', 'key')", mysql.TypeVarString, charset.CharsetBin},
+		{"password('abc')", mysql.TypeVarString, charset.CharsetUTF8},
+		{"old_password('abc')", mysql.TypeVarString, charset.CharsetUTF8},
+		{"validate_password_strength('abc')", mysql.TypeLonglong, charset.CharsetBin},
+		{"weight_string('a' as char(3))", mysql.TypeVarString, charset.CharsetBin},
+		{"bin_to_uuid(uuid_to_bin(uuid()))", mysql.TypeVarString, charset.CharsetUTF8},
+		{"benchmark(10, 1)", mysql.TypeLonglong, charset.CharsetBin},
+		{"format_bytes(1024)", mysql.TypeVarString, charset.CharsetUTF8},
+		{"format_pico_time(1000)", mysql.TypeVarString, charset.CharsetUTF8},
+		{"gtid_subset('', '')", mysql.TypeLonglong, charset.CharsetBin},
+		{"gtid_subtract('', '')", mysql.TypeVarString, charset.CharsetUTF8},
+		{"current_role()", mysql.TypeVarString, charset.CharsetUTF8},
+		{"json_pretty('[]')", mysql.TypeVarString, charset.CharsetUTF8},
+		{"json_storage_size('[]')", mysql.TypeLonglong, charset.CharsetBin},
+		{"json_storage_free('[]')", mysql.TypeLonglong, charset.CharsetBin},
+		{"json_quote('a')", mysql.TypeVarString, charset.CharsetUTF8},
+		{`json_search('["a"]', 'one', 'a')`, mysql.TypeVarString, charset.CharsetUTF8},
+		{`json_remove('["a"]', '$[0]')`, mysql.TypeVarString, charset.CharsetUTF8},
+		{`json_array_append('["a"]', '$', 1)`, mysql.TypeVarString, charset.CharsetUTF8},
+		{`json_array_insert('["a"]', '$[0]', 1)`, mysql.TypeVarString, charset.CharsetUTF8},
 	}
 	for _, ca := range cases {
 		ctx := testKit.Se.(context.Context)
@@ -173,7 +236,7 @@ func (ts *testTypeInferrerSuite) TestInferType(c *C) {
 		is := sessionctx.GetDomain(ctx).InfoSchema()
 		err = plan.ResolveName(stmt, is, ctx)
 		c.Assert(err, IsNil)
-		plan.InferType(ctx.GetSessionVars().StmtCtx, stmt)
+		plan.InferType(ctx, stmt)
 		tp := stmt.GetResultFields()[0].Column.Tp
 		chs := stmt.GetResultFields()[0].Column.Charset
 		c.Assert(tp, Equals, ca.tp, Commentf("Tp for %s", ca.expr))
@@ -181,6 +244,42 @@ func (ts *testTypeInferrerSuite) TestInferType(c *C) {
 	}
 }
 
+func (ts *testTypeInferrerSuite) TestInferUnsignedFlag(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	testKit := testkit.NewTestKit(c, store)
+	testKit.MustExec("use test")
+	testKit.MustExec("create table t (a int, b int unsigned)")
+	cases := []struct {
+		sqlMode  string
+		expr     string
+		unsigned bool
+	}{
+		{"", "b - 3", true},
+		{"", "b + 3", true},
+		{"NO_UNSIGNED_SUBTRACTION", "b - 3", false},
+		{"NO_UNSIGNED_SUBTRACTION", "3 - b", false},
+		{"NO_UNSIGNED_SUBTRACTION", "b + 3", true},
+		{"NO_UNSIGNED_SUBTRACTION", "a - 3", false},
+	}
+	for _, ca := range cases {
+		testKit.MustExec(fmt.Sprintf("set sql_mode = '%s'", ca.sqlMode))
+		ctx := testKit.Se.(context.Context)
+		stmts, err := tidb.Parse(ctx, "select "+ca.expr+" from t")
+		c.Assert(err, IsNil)
+		stmt := stmts[0].(*ast.SelectStmt)
+		is := sessionctx.GetDomain(ctx).InfoSchema()
+		err = plan.ResolveName(stmt, is, ctx)
+		c.Assert(err, IsNil)
+		err = plan.InferType(ctx, stmt)
+		c.Assert(err, IsNil)
+		flag := stmt.GetResultFields()[0].Column.Flag
+		c.Assert(mysql.HasUnsignedFlag(flag), Equals, ca.unsigned, Commentf("%s with sql_mode '%s'", ca.expr, ca.sqlMode))
+	}
+}
+
 func (s *testTypeInferrerSuite) TestColumnInfoModified(c *C) {
 	defer testleak.AfterTest(c)()
 	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
@@ -31,6 +31,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/mysql"
@@ -644,6 +645,7 @@ func (s *session) ClearValue(key fmt.Stringer) {
 
 // Close function does some clean work when session end.
 func (s *session) Close() error {
+	expression.ReleaseUserLocks(s.sessionVars)
 	return s.RollbackTxn()
 }
 
@@ -823,6 +825,7 @@ func finishBootstrap(store kv.Storage) {
 const loadCommonGlobalVarsSQL = "select * from mysql.global_variables where variable_name in ('" +
 	variable.AutocommitVar + "', '" +
 	variable.SQLModeVar + "', '" +
+	variable.MaxAllowedPacket + "', '" +
 	variable.DistSQLJoinConcurrencyVar + "', '" +
 	variable.DistSQLScanConcurrencyVar + "')"
 
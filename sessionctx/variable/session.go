@@ -109,12 +109,25 @@ type SessionVars struct {
 	// Current user
 	User string
 
+	// ActiveRoles is the active roles of the session, each one is formatted like `role`@`host`.
+	ActiveRoles []string
+
 	// Current DB
 	CurrentDB string
 
 	// Strict SQL mode
 	StrictSQLMode bool
 
+	// NoBackslashEscapes is true if the sql_mode has NO_BACKSLASH_ESCAPES, then LIKE has no default escape character.
+	NoBackslashEscapes bool
+
+	// ErrorForDivisionByZero is true if the sql_mode has ERROR_FOR_DIVISION_BY_ZERO, then division by zero is reported.
+	ErrorForDivisionByZero bool
+
+	// NoUnsignedSubtraction is true if the sql_mode has NO_UNSIGNED_SUBTRACTION, then the subtraction with an unsigned
+	// operand results in a signed integer.
+	NoUnsignedSubtraction bool
+
 	// CommonGlobalLoaded indicates if common global variable has been loaded for this session.
 	CommonGlobalLoaded bool
 
@@ -135,6 +148,18 @@ type SessionVars struct {
 	// Then if there are multiple TiDB servers, the new table may not be available for other TiDB servers.
 	SkipDDLWait bool
 
+	// GreatestLeastIgnoreNull makes GREATEST() and LEAST() ignore NULL arguments instead of returning NULL,
+	// they only return NULL when all the arguments are NULL.
+	GreatestLeastIgnoreNull bool
+
+	// RoundTrigResult rounds the results of the trigonometric functions to 15 significant digits,
+	// so the last digit differences caused by the math libraries don't show up when comparing the results with MySQL.
+	RoundTrigResult bool
+
+	// RoundHalfEven makes ROUND() round a value exactly halfway between to the even neighbor,
+	// instead of rounding it away from zero like MySQL.
+	RoundHalfEven bool
+
 	// GlobalAccessor is used to set and get global variables.
 	GlobalVarsAccessor GlobalVarAccessor
 
@@ -144,6 +169,11 @@ type SessionVars struct {
 	// CurrInsertValues is used to record current ValuesExpr's values.
 	// See http://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_values
 	CurrInsertValues interface{}
+
+	// Killed is a flag to indicate that the current query is killed, it is accessed atomically
+	// and reset when a statement starts.
+	// Only sleep() and get_lock() check it to stop early.
+	Killed uint32
 }
 
 // NewSessionVars creates a session vars object.
@@ -224,6 +254,8 @@ const (
 	SQLModeVar          = "sql_mode"
 	AutocommitVar       = "autocommit"
 	CharacterSetResults = "character_set_results"
+	MaxAllowedPacket    = "max_allowed_packet"
+	DefaultWeekFormat   = "default_week_format"
 )
 
 // GetTiDBSystemVar gets variable value for name.
@@ -252,6 +284,12 @@ type StatementContext struct {
 	TruncateAsWarning bool
 
 	/* Variables that changes during execution. */
+	// RollupGroupBy holds the hash codes of the GROUP BY items of a WITH ROLLUP aggregation,
+	// RollupLevel is the number of trailing items aggregated away in the row being produced.
+	// They are read by GROUPING().
+	RollupGroupBy [][]byte
+	RollupLevel   int
+
 	mu struct {
 		sync.Mutex
 		affectedRows uint64
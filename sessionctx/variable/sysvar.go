@@ -85,6 +85,9 @@ func init() {
 	tidbSysVars[TiDBSnapshot] = true
 	tidbSysVars[TiDBSkipConstraintCheck] = true
 	tidbSysVars[TiDBSkipDDLWait] = true
+	tidbSysVars[TiDBGreatestLeastIgnoreNull] = true
+	tidbSysVars[TiDBRoundTrigResult] = true
+	tidbSysVars[TiDBRoundHalfEven] = true
 }
 
 // we only support MySQL now
@@ -110,7 +113,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal | ScopeSession, "sql_select_limit", "18446744073709551615"},
 	{ScopeGlobal, "ndb_show_foreign_key_mock_tables", ""},
 	{ScopeNone, "multi_range_count", "256"},
-	{ScopeGlobal | ScopeSession, "default_week_format", "0"},
+	{ScopeGlobal | ScopeSession, DefaultWeekFormat, "0"},
 	{ScopeGlobal | ScopeSession, "binlog_error_action", "IGNORE_ERROR"},
 	{ScopeGlobal, "slave_transaction_retries", "10"},
 	{ScopeGlobal | ScopeSession, "default_storage_engine", "InnoDB"},
@@ -561,7 +564,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal | ScopeSession, "ndbinfo_show_hidden", ""},
 	{ScopeGlobal | ScopeSession, "net_read_timeout", "30"},
 	{ScopeNone, "innodb_page_size", "16384"},
-	{ScopeGlobal, "max_allowed_packet", "4194304"},
+	{ScopeGlobal, MaxAllowedPacket, "4194304"},
 	{ScopeNone, "innodb_log_file_size", "50331648"},
 	{ScopeGlobal, "sync_relay_log_info", "10000"},
 	{ScopeGlobal | ScopeSession, "optimizer_trace_limit", "1"},
@@ -593,15 +596,21 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal | ScopeSession, DistSQLJoinConcurrencyVar, "5"},
 	{ScopeSession, TiDBSkipConstraintCheck, "0"},
 	{ScopeSession, TiDBSkipDDLWait, "0"},
+	{ScopeSession, TiDBGreatestLeastIgnoreNull, "0"},
+	{ScopeSession, TiDBRoundTrigResult, "0"},
+	{ScopeSession, TiDBRoundHalfEven, "0"},
 }
 
 // TiDB system variables
 const (
-	TiDBSnapshot              = "tidb_snapshot"
-	DistSQLScanConcurrencyVar = "tidb_distsql_scan_concurrency"
-	DistSQLJoinConcurrencyVar = "tidb_distsql_join_concurrency"
-	TiDBSkipConstraintCheck   = "tidb_skip_constraint_check"
-	TiDBSkipDDLWait           = "tidb_skip_ddl_wait"
+	TiDBSnapshot                = "tidb_snapshot"
+	DistSQLScanConcurrencyVar   = "tidb_distsql_scan_concurrency"
+	DistSQLJoinConcurrencyVar   = "tidb_distsql_join_concurrency"
+	TiDBSkipConstraintCheck     = "tidb_skip_constraint_check"
+	TiDBSkipDDLWait             = "tidb_skip_ddl_wait"
+	TiDBGreatestLeastIgnoreNull = "tidb_greatest_least_ignore_null"
+	TiDBRoundTrigResult         = "tidb_round_trig_result"
+	TiDBRoundHalfEven           = "tidb_round_half_even"
 )
 
 // SetNamesVariables is the system variable names related to set names statements.
@@ -36,6 +36,12 @@ func GetSystemVar(s *variable.SessionVars, key string) types.Datum {
 			d.SetString(variable.SysVars[variable.TiDBSkipConstraintCheck].Value)
 		} else if key == variable.TiDBSkipDDLWait {
 			d.SetString(variable.SysVars[variable.TiDBSkipDDLWait].Value)
+		} else if key == variable.TiDBGreatestLeastIgnoreNull {
+			d.SetString(variable.SysVars[variable.TiDBGreatestLeastIgnoreNull].Value)
+		} else if key == variable.TiDBRoundTrigResult {
+			d.SetString(variable.SysVars[variable.TiDBRoundTrigResult].Value)
+		} else if key == variable.TiDBRoundHalfEven {
+			d.SetString(variable.SysVars[variable.TiDBRoundHalfEven].Value)
 		}
 	}
 	return d
@@ -66,6 +72,9 @@ func SetSystemVar(vars *variable.SessionVars, name string, value types.Datum) er
 		} else {
 			vars.StrictSQLMode = false
 		}
+		vars.NoBackslashEscapes = strings.Contains(sVal, "NO_BACKSLASH_ESCAPES")
+		vars.ErrorForDivisionByZero = strings.Contains(sVal, "ERROR_FOR_DIVISION_BY_ZERO")
+		vars.NoUnsignedSubtraction = strings.Contains(sVal, "NO_UNSIGNED_SUBTRACTION")
 	case variable.TiDBSnapshot:
 		err = setSnapshotTS(vars, sVal)
 		if err != nil {
@@ -81,6 +90,12 @@ func SetSystemVar(vars *variable.SessionVars, name string, value types.Datum) er
 		vars.SkipConstraintCheck = (sVal == "1")
 	case variable.TiDBSkipDDLWait:
 		vars.SkipDDLWait = (sVal == "1")
+	case variable.TiDBGreatestLeastIgnoreNull:
+		vars.GreatestLeastIgnoreNull = (sVal == "1")
+	case variable.TiDBRoundTrigResult:
+		vars.RoundTrigResult = (sVal == "1")
+	case variable.TiDBRoundHalfEven:
+		vars.RoundHalfEven = (sVal == "1")
 	}
 	vars.Systems[name] = sVal
 	return nil
@@ -46,6 +46,21 @@ func (s *testVarsutilSuite) TestVarsutil(c *C) {
 	c.Assert(v.StrictSQLMode, IsTrue)
 	SetSystemVar(v, "sql_mode", types.NewStringDatum(""))
 	c.Assert(v.StrictSQLMode, IsFalse)
+	c.Assert(v.NoBackslashEscapes, IsFalse)
+	SetSystemVar(v, "sql_mode", types.NewStringDatum("no_backslash_escapes"))
+	c.Assert(v.NoBackslashEscapes, IsTrue)
+	SetSystemVar(v, "sql_mode", types.NewStringDatum(""))
+	c.Assert(v.NoBackslashEscapes, IsFalse)
+	c.Assert(v.ErrorForDivisionByZero, IsFalse)
+	SetSystemVar(v, "sql_mode", types.NewStringDatum("strict_trans_tables,error_for_division_by_zero"))
+	c.Assert(v.ErrorForDivisionByZero, IsTrue)
+	SetSystemVar(v, "sql_mode", types.NewStringDatum(""))
+	c.Assert(v.ErrorForDivisionByZero, IsFalse)
+	c.Assert(v.NoUnsignedSubtraction, IsFalse)
+	SetSystemVar(v, "sql_mode", types.NewStringDatum("no_unsigned_subtraction"))
+	c.Assert(v.NoUnsignedSubtraction, IsTrue)
+	SetSystemVar(v, "sql_mode", types.NewStringDatum(""))
+	c.Assert(v.NoUnsignedSubtraction, IsFalse)
 
 	SetSystemVar(v, "character_set_connection", types.NewStringDatum("utf8"))
 	SetSystemVar(v, "collation_connection", types.NewStringDatum("utf8_general_ci"))
@@ -83,4 +98,37 @@ func (s *testVarsutilSuite) TestVarsutil(c *C) {
 	c.Assert(v.SkipDDLWait, IsTrue)
 	d = GetSystemVar(v, variable.TiDBSkipDDLWait)
 	c.Assert(d.GetString(), Equals, "1")
+
+	// Test case for tidb_greatest_least_ignore_null
+	d = GetSystemVar(v, variable.TiDBGreatestLeastIgnoreNull)
+	c.Assert(d.GetString(), Equals, "0")
+	c.Assert(v.GreatestLeastIgnoreNull, IsFalse)
+	SetSystemVar(v, variable.TiDBGreatestLeastIgnoreNull, types.NewStringDatum("1"))
+	c.Assert(v.GreatestLeastIgnoreNull, IsTrue)
+	d = GetSystemVar(v, variable.TiDBGreatestLeastIgnoreNull)
+	c.Assert(d.GetString(), Equals, "1")
+	SetSystemVar(v, variable.TiDBGreatestLeastIgnoreNull, types.NewStringDatum("0"))
+	c.Assert(v.GreatestLeastIgnoreNull, IsFalse)
+
+	// Test case for tidb_round_trig_result
+	d = GetSystemVar(v, variable.TiDBRoundTrigResult)
+	c.Assert(d.GetString(), Equals, "0")
+	c.Assert(v.RoundTrigResult, IsFalse)
+	SetSystemVar(v, variable.TiDBRoundTrigResult, types.NewStringDatum("1"))
+	c.Assert(v.RoundTrigResult, IsTrue)
+	d = GetSystemVar(v, variable.TiDBRoundTrigResult)
+	c.Assert(d.GetString(), Equals, "1")
+	SetSystemVar(v, variable.TiDBRoundTrigResult, types.NewStringDatum("0"))
+	c.Assert(v.RoundTrigResult, IsFalse)
+
+	// Test case for tidb_round_half_even
+	d = GetSystemVar(v, variable.TiDBRoundHalfEven)
+	c.Assert(d.GetString(), Equals, "0")
+	c.Assert(v.RoundHalfEven, IsFalse)
+	SetSystemVar(v, variable.TiDBRoundHalfEven, types.NewStringDatum("1"))
+	c.Assert(v.RoundHalfEven, IsTrue)
+	d = GetSystemVar(v, variable.TiDBRoundHalfEven)
+	c.Assert(d.GetString(), Equals, "1")
+	SetSystemVar(v, variable.TiDBRoundHalfEven, types.NewStringDatum("0"))
+	c.Assert(v.RoundHalfEven, IsFalse)
 }
@@ -21,6 +21,7 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/juju/errors"
@@ -186,6 +187,8 @@ func runStmt(ctx context.Context, s ast.Statement) (ast.RecordSet, error) {
 	var err error
 	var rs ast.RecordSet
 	se := ctx.(*session)
+	// Killing a query only applies to the statement running when the flag is set.
+	atomic.StoreUint32(&se.sessionVars.Killed, 0)
 	rs, err = s.Exec(ctx)
 	// All the history should be added here.
 	getHistory(ctx).add(0, s)
@@ -143,6 +143,17 @@ func GetCollations() []*Collation {
 	return collations
 }
 
+// GetCollationByName returns the collation named name.
+func GetCollationByName(name string) (*Collation, error) {
+	name = strings.ToLower(name)
+	for _, c := range collations {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return nil, errors.Errorf("Unknown collation %s", name)
+}
+
 const (
 	// CharsetBin is used for marking binary charset.
 	CharsetBin = "binary"
@@ -87,3 +87,26 @@ func (s *testCharsetSuite) TestGetDefaultCollation(c *C) {
 		testGetDefaultCollation(c, t.cs, t.co, t.succ)
 	}
 }
+
+func (s *testCharsetSuite) TestGetCollationByName(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		name string
+		cs   string
+		succ bool
+	}{
+		{"utf8mb4_bin", "utf8mb4", true},
+		{"UTF8_General_CI", "utf8", true},
+		{"binary", "binary", true},
+		{"invalid_co", "", false},
+	}
+	for _, t := range tbl {
+		co, err := GetCollationByName(t.name)
+		if !t.succ {
+			c.Assert(err, NotNil)
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Assert(co.CharsetName, Equals, t.cs)
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/pingcap/tidb/sessionctx/variable"
+)
+
+func benchmarkToFloat64(b *testing.B, values ...interface{}) {
+	b.StopTimer()
+	sc := &variable.StatementContext{IgnoreTruncate: true}
+	datums := MakeDatums(values...)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range datums {
+			datums[j].ToFloat64(sc)
+		}
+	}
+}
+
+func BenchmarkFloatToFloat64(b *testing.B) {
+	benchmarkToFloat64(b, 1.5, -3.25, 1e10, 0.0)
+}
+
+func BenchmarkStringToFloat64(b *testing.B) {
+	benchmarkToFloat64(b, "1.5", " -3.25 ", "1e10", "1.5x")
+}
+
+func BenchmarkBytesToFloat64(b *testing.B) {
+	benchmarkToFloat64(b, []byte("1.5"), []byte(" -3.25 "), []byte("1e10"), []byte("1.5x"))
+}
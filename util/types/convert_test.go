@@ -420,6 +420,16 @@ func (s *testTypeConvertSuite) TestStrToNum(c *C) {
 	testStrToFloat(c, "11.xx", 11.0, false, nil)
 	testStrToFloat(c, "11.xx", 11.0, true, ErrTruncated)
 	testStrToFloat(c, "xx.11", 0.0, false, nil)
+	testStrToFloat(c, " 12.5 ", 12.5, true, nil)
+	testStrToFloat(c, "1e3", 1000, true, nil)
+	testStrToFloat(c, "-.5", -0.5, true, nil)
+	testStrToFloat(c, "+1.5E-1", 0.15, true, nil)
+	testStrToFloat(c, "123.", 123, true, nil)
+	testStrToFloat(c, "1e", 1, false, nil)
+	testStrToFloat(c, "1e", 1, true, ErrTruncated)
+	testStrToFloat(c, "1e+", 1, true, ErrTruncated)
+	testStrToFloat(c, "-", 0, true, ErrTruncated)
+	testStrToFloat(c, "1e5e", 1e5, true, ErrTruncated)
 }
 
 func (s *testTypeConvertSuite) TestFieldTypeToStr(c *C) {
@@ -15,6 +15,7 @@ package types
 
 import (
 	"io"
+	"math"
 	"testing"
 
 	. "github.com/pingcap/check"
@@ -162,6 +163,16 @@ func (s *testTypeEtcSuite) TestRound(c *C) {
 		{1.298, 1, 1.3},
 		{1.298, 0, 1},
 		{23.298, -1, 20},
+		{1.298, 400, 1.298},
+		{1.298, -400, 0},
+		{math.MaxFloat64, 2, math.MaxFloat64},
+		// The values are rounded as they're written, not as their binary representation.
+		{2.675, 2, 2.68},
+		{-2.675, 2, -2.68},
+		{0.285, 2, 0.29},
+		{1.005, 2, 1.01},
+		{1.45, 1, 1.5},
+		{1e-300, 2, 0},
 	}
 
 	for _, t := range tbl {
@@ -170,6 +181,35 @@ func (s *testTypeEtcSuite) TestRound(c *C) {
 	}
 }
 
+func (s *testTypeEtcSuite) TestRoundHalfEven(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		Input  float64
+		Dec    int
+		Expect float64
+	}{
+		{2.5, 0, 2},
+		{3.5, 0, 4},
+		{-2.5, 0, -2},
+		{-3.5, 0, -4},
+		{2.51, 0, 3},
+		{-1.58, 0, -2},
+		{0.125, 2, 0.12},
+		{250, -2, 200},
+		{1.298, 400, 1.298},
+		{1.298, -400, 0},
+		{2.675, 2, 2.68},
+		{0.285, 2, 0.28},
+		{0.295, 2, 0.3},
+		{1.005, 2, 1},
+	}
+
+	for _, t := range tbl {
+		f := RoundHalfEven(t.Input, t.Dec)
+		c.Assert(f, Equals, t.Expect, Commentf("%v %v", t.Input, t.Dec))
+	}
+}
+
 func (s *testTypeEtcSuite) TestTruncate(c *C) {
 	defer testleak.AfterTest(c)()
 	tbl := []struct {
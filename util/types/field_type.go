@@ -182,6 +182,8 @@ func DefaultCharsetForType(tp byte) (string, string) {
 	case mysql.TypeVarString, mysql.TypeString, mysql.TypeVarchar:
 		// Default charset for string types is utf8.
 		return mysql.DefaultCharset, mysql.DefaultCollationName
+	case mysql.TypeJSON:
+		return charset.CharsetUTF8MB4, "utf8mb4_bin"
 	}
 	return charset.CharsetBin, charset.CollationBin
 }
@@ -198,6 +200,10 @@ func MergeFieldType(a byte, b byte) byte {
 }
 
 func getFieldTypeIndex(tp byte) int {
+	if tp == mysql.TypeJSON {
+		// JSON values are merged as LONGTEXT since they are kept in text.
+		tp = mysql.TypeLongBlob
+	}
 	itp := int(tp)
 	if itp < fieldTypeTearFrom {
 		return itp
@@ -15,6 +15,7 @@ package types
 
 import (
 	"math"
+	"strconv"
 	"strings"
 	"unicode"
 )
@@ -35,11 +36,56 @@ func RoundFloat(f float64) float64 {
 // dec defaults to 0 if not specified. dec can be negative
 // to cause dec digits left of the decimal point of the
 // value f to become zero.
+// f is rounded as the decimal it's written as, so 2.675 is rounded to 2.68 though its float64 is slightly less.
 func Round(f float64, dec int) float64 {
 	shift := math.Pow10(dec)
-	f = f * shift
-	f = RoundFloat(f)
-	return f / shift
+	if shift == 0 {
+		return 0
+	}
+	tmp := shiftFloat(f, dec)
+	if math.IsInf(shift, 0) || math.IsInf(tmp, 0) {
+		// The value has no digits after dec decimal places.
+		return f
+	}
+	return RoundFloat(tmp) / shift
+}
+
+// RoundHalfEven rounds the argument f to dec decimal places like Round,
+// but a value exactly halfway between is rounded to the even neighbor.
+func RoundHalfEven(f float64, dec int) float64 {
+	shift := math.Pow10(dec)
+	if shift == 0 {
+		return 0
+	}
+	tmp := shiftFloat(f, dec)
+	if math.IsInf(shift, 0) || math.IsInf(tmp, 0) {
+		return f
+	}
+	res := math.Trunc(tmp)
+	diff := math.Abs(tmp - res)
+	if diff > 0.5 || (diff == 0.5 && math.Mod(res, 2) != 0) {
+		res += math.Copysign(1, tmp)
+	}
+	return res / shift
+}
+
+// shiftFloat returns f * 10^dec, the shift is done on the shortest decimal representation of f.
+// The float64 of 2.675 is 2.67499999999999982236431605997495353221893310546875, multiplying it by 100
+// results in 267.49999999999997 while shifting "2.675e+00" results in exactly 267.5.
+func shiftFloat(f float64, dec int) float64 {
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	idx := strings.IndexByte(s, 'e')
+	if idx < 0 {
+		// NaN or Inf.
+		return f
+	}
+	exp, err := strconv.Atoi(s[idx+1:])
+	if err != nil {
+		return f * math.Pow10(dec)
+	}
+	// ParseFloat returns Inf if the result overflows.
+	shifted, _ := strconv.ParseFloat(s[:idx+1]+strconv.Itoa(exp+dec), 64)
+	return shifted
 }
 
 func getMaxFloat(flen int, decimal int) float64 {
@@ -305,7 +305,8 @@ func roundTime(t gotime.Time, fsp int) gotime.Time {
 	return t.Round(d)
 }
 
-func (t Time) roundFrac(fsp int) (Time, error) {
+// RoundFrac rounds the fractional seconds to fsp digits with the "round half up" rule, a DATE is returned unchanged.
+func (t Time) RoundFrac(fsp int) (Time, error) {
 	if t.Type == mysql.TypeDate {
 		// date type has no fsp
 		return t, nil
@@ -439,6 +440,7 @@ func (t *Time) check() error {
 
 // Sub subtracts t1 from t, returns a duration value.
 // Note that sub should not be done on different time types.
+// The result isn't limited to the TIME range, but it saturates if it doesn't fit in a gotime.Duration.
 func (t *Time) Sub(t1 *Time) Duration {
 	var duration gotime.Duration
 	if t.Type == mysql.TypeTimestamp && t1.Type == mysql.TypeTimestamp {
@@ -447,7 +449,11 @@ func (t *Time) Sub(t1 *Time) Duration {
 		duration = a.Sub(b)
 	} else {
 		seconds, microseconds, neg := calcTimeDiff(t.Time, t1.Time, 1)
-		duration = gotime.Duration(seconds*1e9 + microseconds*1e3)
+		if int64(seconds) >= math.MaxInt64/int64(gotime.Second) {
+			duration = math.MaxInt64
+		} else {
+			duration = gotime.Duration(seconds*1e9 + microseconds*1e3)
+		}
 		if neg {
 			duration = -duration
 		}
@@ -457,6 +463,9 @@ func (t *Time) Sub(t1 *Time) Duration {
 	if fsp < t1.Fsp {
 		fsp = t1.Fsp
 	}
+	if fsp == UnspecifiedFsp {
+		fsp = DefaultFsp
+	}
 	return Duration{
 		Duration: duration,
 		Fsp:      fsp,
@@ -595,7 +595,7 @@ func (s *testTimeSuite) TestRoundFrac(c *C) {
 	for _, t := range tbl {
 		v, err := ParseTime(t.Input, mysql.TypeDatetime, MaxFsp)
 		c.Assert(err, IsNil)
-		nv, err := v.roundFrac(t.Fsp)
+		nv, err := v.RoundFrac(t.Fsp)
 		c.Assert(err, IsNil)
 		c.Assert(nv.String(), Equals, t.Except)
 	}
@@ -709,6 +709,29 @@ func (s *testTimeSuite) TestCompare(c *C) {
 	}
 }
 
+func (s *testTimeSuite) TestSub(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		Arg1 string
+		Arg2 string
+		Fsp  int
+		Ret  string
+	}{
+		{"2011-10-10 11:11:11", "2011-10-09 11:11:10.5", 1, "24:00:00.5"},
+		{"2000-01-01 00:00:00", "2010-01-01 00:00:00", UnspecifiedFsp, "-87672:00:00"},
+		// The difference which doesn't fit in a time.Duration saturates.
+		{"9999-12-31 23:59:59", "0000-01-01 00:00:00", 0, "2562047:47:16"},
+	}
+	for _, t := range tbl {
+		t1, err := ParseTime(t.Arg1, mysql.TypeDatetime, MaxFsp)
+		c.Assert(err, IsNil)
+		t2, err := ParseTime(t.Arg2, mysql.TypeDatetime, MaxFsp)
+		c.Assert(err, IsNil)
+		t1.Fsp, t2.Fsp = t.Fsp, t.Fsp
+		c.Assert(t1.Sub(&t2).String(), Equals, t.Ret)
+	}
+}
+
 func (s *testTimeSuite) TestDurationClock(c *C) {
 	defer testleak.AfterTest(c)()
 	// test hour, minute, second and micro second